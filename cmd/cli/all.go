@@ -0,0 +1,42 @@
+package cli
+
+import (
+	"tenant/cmd/webservice"
+	"tenant/cmd/worker"
+	"tenant/internal/container"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/sync/errgroup"
+)
+
+// allCmd represents the all command
+var allCmd = &cobra.Command{
+	Use:   "all",
+	Short: "Run the HTTP API and the tenant queue consumers in one process",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		sc, err := initSharedComponent(container.Database, container.RabbitMQ)
+		if err != nil {
+			return err
+		}
+		defer sc.Close()
+
+		ctx, stop := signalContext(cmd)
+		defer stop()
+
+		// Both roles share the broker client, caches and listener, so the
+		// probes of the API check the consumers of this process
+		cc := container.NewHandlerComponent(sc)
+
+		// Stop both roles as soon as either of them fails
+		g, ctx := errgroup.WithContext(ctx)
+		go runTenantListener(ctx, sc, cc)
+		g.Go(func() error { return webservice.Run(ctx, sc, cc) })
+		g.Go(func() error { return worker.Run(ctx, sc, cc) })
+		return g.Wait()
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(allCmd)
+}
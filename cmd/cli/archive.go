@@ -0,0 +1,53 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"tenant/internal/container"
+	"tenant/pkg/derrors"
+
+	"github.com/spf13/cobra"
+)
+
+// archiveCmd groups the message archive commands
+var archiveCmd = &cobra.Command{
+	Use:   "archive",
+	Short: "Manage the archive of published payloads",
+}
+
+// archivePruneCmd represents the archive prune command
+var archivePruneCmd = &cobra.Command{
+	Use:   "prune",
+	Short: "Remove archived payloads, message statuses and idempotency keys that expired",
+	Long: `Remove archived payloads and message statuses older than the retention of their tenant,
+drop the expired monthly archive partitions, create the upcoming ones and remove expired idempotency keys.
+Workers run the same job every archive.pruneInterval, this command runs it once.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		sc, err := initSharedComponent(container.Database, container.RabbitMQ)
+		if err != nil {
+			return derrors.Wrap(&err, "failed to prune archive")
+		}
+		defer sc.Close()
+
+		cc := container.NewHandlerComponent(sc)
+
+		result, err := cc.ArchiveUsecase.Prune(cmd.Context())
+		if err != nil {
+			return derrors.Wrap(&err, "failed to prune archive")
+		}
+		if result.Skipped {
+			return derrors.New(derrors.Conflict, "archive is being pruned by another instance")
+		}
+
+		out, _ := json.Marshal(result)
+		fmt.Println(string(out))
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(archiveCmd)
+	archiveCmd.AddCommand(archivePruneCmd)
+}
@@ -5,8 +5,8 @@ package cli
 
 import (
 	"tenant/internal/container"
+	"tenant/pkg/derrors"
 
-	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 )
 
@@ -15,26 +15,24 @@ var createCmd = &cobra.Command{
 	Use:   "create [name]",
 	Short: "Create a new tenant",
 	Args:  cobra.ExactArgs(1),
-	Run: func(cmd *cobra.Command, args []string) {
+	RunE: func(cmd *cobra.Command, args []string) error {
 		name := args[0]
 
-		sc, err := container.InitSharedComponent()
+		sc, err := initSharedComponent(container.Database, container.RabbitMQ)
 		if err != nil {
-			logrus.Infof("Failed to create tenant: %v\n", err)
-			return
+			return derrors.Wrap(&err, "failed to create tenant")
 		}
-		defer sc.DB.Close()
-		defer sc.RabbitMQConn.Close()
+		defer sc.Close()
 
 		cc := container.NewHandlerComponent(sc)
 
 		tenant, err := cc.TenantUsecase.CreateTenant(cmd.Context(), name)
 		if err != nil {
-			logrus.Infof("Failed to create tenant: %v\n", err)
-			return
+			return derrors.Wrap(&err, "failed to create tenant")
 		}
 
-		logrus.Infof("Tenant created successfully: %+v\n", tenant.ClientID)
+		sc.Log.Infof("Tenant created successfully: %+v\n", tenant.ClientID)
+		return nil
 	},
 }
 
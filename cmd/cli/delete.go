@@ -5,8 +5,8 @@ package cli
 
 import (
 	"tenant/internal/container"
+	"tenant/pkg/derrors"
 
-	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 )
 
@@ -15,25 +15,23 @@ var deleteCmd = &cobra.Command{
 	Use:   "delete [client-id]",
 	Short: "Delete a tenant",
 	Args:  cobra.ExactArgs(1),
-	Run: func(cmd *cobra.Command, args []string) {
+	RunE: func(cmd *cobra.Command, args []string) error {
 		clientID := args[0]
-		sc, err := container.InitSharedComponent()
+		sc, err := initSharedComponent(container.Database, container.RabbitMQ)
 		if err != nil {
-			logrus.Infof("Failed to delete tenant: %v\n", err)
-			return
+			return derrors.Wrap(&err, "failed to delete tenant")
 		}
-		defer sc.DB.Close()
-		defer sc.RabbitMQConn.Close()
+		defer sc.Close()
 
 		cc := container.NewHandlerComponent(sc)
 
 		err = cc.TenantUsecase.DeleteTenant(cmd.Context(), clientID)
 		if err != nil {
-			logrus.Infof("Failed to delete tenant: %v\n", err)
-			return
+			return derrors.Wrap(&err, "failed to delete tenant")
 		}
 
-		logrus.Info("Tenant deleted successfully")
+		sc.Log.Info("Tenant deleted successfully")
+		return nil
 	},
 }
 
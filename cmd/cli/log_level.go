@@ -0,0 +1,92 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"tenant/pkg/derrors"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	logLevelAddr     string
+	logLevelWorker   bool
+	logLevelClientID string
+	logLevelTTL      string
+)
+
+// logLevelCmd represents the log-level command
+var logLevelCmd = &cobra.Command{
+	Use:   "log-level [level]",
+	Short: "Show or change the log level of a running service",
+	Long: `Show or change the log level of a running service through its admin API.
+Without a level the current global level and tenant overrides are printed.
+Use --client-id to only change the level for one tenant and --ttl to revert automatically.
+Messages are consumed by workers, use --worker to target the worker listening on worker.port.
+Every process has its own level, change it on each of them.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		addr := logLevelAddr
+		if logLevelWorker && !cmd.Flags().Changed("addr") {
+			conf, err := loadConfig()
+			if err != nil {
+				return err
+			}
+			addr = "http://localhost:" + conf.Worker.Port
+		}
+		url := strings.TrimRight(addr, "/") + "/v1/admin/log-level"
+		client := &http.Client{Timeout: 10 * time.Second}
+
+		var (
+			resp *http.Response
+			err  error
+		)
+		if len(args) == 0 {
+			resp, err = client.Get(url)
+		} else {
+			body, _ := json.Marshal(map[string]string{
+				"level":     args[0],
+				"client_id": logLevelClientID,
+				"ttl":       logLevelTTL,
+			})
+			req, reqErr := http.NewRequestWithContext(cmd.Context(), http.MethodPut, url, bytes.NewReader(body))
+			if reqErr != nil {
+				return derrors.WrapStack(reqErr, derrors.InvalidArgument, "failed to change log level")
+			}
+			req.Header.Set("Content-Type", "application/json")
+			resp, err = client.Do(req)
+		}
+		if err != nil {
+			return derrors.WrapStack(err, derrors.Unavailable, "failed to reach admin API")
+		}
+		defer resp.Body.Close()
+
+		out, _ := io.ReadAll(resp.Body)
+		if resp.StatusCode != http.StatusOK {
+			// Keep the error code reported by the service for the exit code
+			var problem struct {
+				Code string `json:"code"`
+			}
+			_ = json.Unmarshal(out, &problem)
+			return derrors.New(derrors.ParseCode(problem.Code), "failed to change log level: %s %s", resp.Status, out)
+		}
+
+		fmt.Println(string(out))
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(logLevelCmd)
+
+	logLevelCmd.Flags().StringVar(&logLevelAddr, "addr", "http://localhost:8080", "Base URL of the running service")
+	logLevelCmd.Flags().BoolVar(&logLevelWorker, "worker", false, "Target the local worker on worker.port instead of the API, --addr takes precedence")
+	logLevelCmd.Flags().StringVar(&logLevelClientID, "client-id", "", "Only change the level for this tenant")
+	logLevelCmd.Flags().StringVar(&logLevelTTL, "ttl", "", "Revert the change after this duration, e.g. 15m")
+}
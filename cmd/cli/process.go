@@ -5,8 +5,9 @@ package cli
 
 import (
 	"tenant/internal/container"
+	"tenant/internal/model"
+	"tenant/pkg/derrors"
 
-	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 )
 
@@ -15,26 +16,24 @@ var processCmd = &cobra.Command{
 	Use:   "process [client-id] [payload]",
 	Short: "Process a tenant",
 	Args:  cobra.ExactArgs(2),
-	Run: func(cmd *cobra.Command, args []string) {
+	RunE: func(cmd *cobra.Command, args []string) error {
 		clientID := args[0]
 		payload := args[1]
-		sc, err := container.InitSharedComponent()
+		sc, err := initSharedComponent(container.Database, container.RabbitMQ)
 		if err != nil {
-			logrus.Infof("Failed to process tenant: %v\n", err)
-			return
+			return derrors.Wrap(&err, "failed to process tenant")
 		}
-		defer sc.DB.Close()
-		defer sc.RabbitMQConn.Close()
+		defer sc.Close()
 
 		cc := container.NewHandlerComponent(sc)
 
-		err = cc.TenantUsecase.ProcessPayload(cmd.Context(), clientID, payload)
+		messageID, err := cc.TenantUsecase.ProcessPayload(cmd.Context(), clientID, &model.Payload{Body: payload})
 		if err != nil {
-			logrus.Infof("Failed to process tenant: %v\n", err)
-			return
+			return derrors.Wrap(&err, "failed to process tenant")
 		}
 
-		logrus.Info("Process Tenant successfully")
+		sc.Log.Infof("Process Tenant successfully, message ID %s", messageID)
+		return nil
 	},
 }
 
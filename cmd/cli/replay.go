@@ -0,0 +1,82 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"tenant/internal/container"
+	"tenant/internal/model"
+	"tenant/pkg/derrors"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	replayFrom       string
+	replayTo         string
+	replayMessageIDs []string
+	replayRate       float64
+	replayBackground bool
+)
+
+// replayCmd represents the replay command
+var replayCmd = &cobra.Command{
+	Use:   "replay [client-id]",
+	Short: "Republish archived payloads of a tenant to its process queue",
+	Long: `Republish archived payloads of a tenant to its process queue, oldest first and at most --rate per second.
+Select the payloads with --from and --to, with --id or both. The replay runs in this command and prints
+its progress, interrupting it leaves the job to be resumed by a worker. Use --background to queue
+the job for workers instead.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		job := &model.ReplayJob{ClientID: args[0], MessageIDs: replayMessageIDs, Rate: replayRate}
+		for flag, value := range map[string]string{"from": replayFrom, "to": replayTo} {
+			if value == "" {
+				continue
+			}
+			t, err := time.Parse(time.RFC3339, value)
+			if err != nil {
+				return derrors.New(derrors.InvalidArgument, "--%s must be an RFC 3339 time, such as 2026-10-19T12:00:00Z", flag)
+			}
+			if flag == "from" {
+				job.From = &t
+			} else {
+				job.To = &t
+			}
+		}
+
+		sc, err := initSharedComponent(container.Database, container.RabbitMQ)
+		if err != nil {
+			return derrors.Wrap(&err, "failed to replay messages")
+		}
+		defer sc.Close()
+
+		cc := container.NewHandlerComponent(sc)
+
+		if replayBackground {
+			job, err = cc.ReplayUsecase.CreateReplay(cmd.Context(), job)
+		} else {
+			job, err = cc.ReplayUsecase.Replay(cmd.Context(), job, func(job *model.ReplayJob) {
+				sc.Log.Infof("Replay %s: %d/%d published, %d failed", job.ID, job.Published, job.Total, job.Failed)
+			})
+		}
+		if err != nil {
+			return derrors.Wrap(&err, "failed to replay messages")
+		}
+
+		out, _ := json.Marshal(job)
+		fmt.Println(string(out))
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(replayCmd)
+
+	replayCmd.Flags().StringVar(&replayFrom, "from", "", "Replay payloads published at or after this RFC 3339 time")
+	replayCmd.Flags().StringVar(&replayTo, "to", "", "Replay payloads published before this RFC 3339 time")
+	replayCmd.Flags().StringArrayVar(&replayMessageIDs, "id", nil, "Replay this message, may be repeated")
+	replayCmd.Flags().Float64Var(&replayRate, "rate", 0, "Messages per second, the configured replay.rate when zero")
+	replayCmd.Flags().BoolVar(&replayBackground, "background", false, "Queue the replay for workers instead of running it")
+}
@@ -4,23 +4,86 @@ Copyright © 2025 NAME HERE <EMAIL ADDRESS>
 package cli
 
 import (
+	"context"
 	"os"
+	"os/signal"
+	"syscall"
+
+	"tenant/infrastructure/config"
+	"tenant/internal/container"
+	"tenant/pkg/derrors"
 
 	"github.com/spf13/cobra"
 )
 
+var (
+	configPath string
+	logLevel   string
+	port       string
+)
+
 // rootCmd represents the base command when called without any subcommands
 var rootCmd = &cobra.Command{
 	Use:   "tenant",
 	Short: "CLI tool to manage tenants",
 	Long:  "A CLI tool for creating, deleting, and managing tenants with RabbitMQ integration.",
+
+	// Errors are reported through the exit code, usage is only useful for
+	// argument errors which cobra reports before running the command
+	SilenceUsage: true,
 }
 
 // Execute adds all child commands to the root command and sets flags appropriately.
 // This is called by main.main(). It only needs to happen once to the rootCmd.
+// The process exits with the code derrors.ToExitCode assigns to the error.
 func Execute() {
 	err := rootCmd.Execute()
 	if err != nil {
-		os.Exit(1)
+		os.Exit(derrors.ToExitCode(err))
+	}
+}
+
+func init() {
+	rootCmd.PersistentFlags().StringVar(&configPath, "config", os.Getenv("CONFIG_PATH"), "Path to the config file (default is ./config.yaml)")
+	rootCmd.PersistentFlags().StringVar(&logLevel, "log-level", "", "Override the configured log level")
+	rootCmd.PersistentFlags().StringVar(&port, "port", "", "Override the configured HTTP port")
+}
+
+// loadConfig reads the config file and applies the global flag overrides.
+func loadConfig() (*config.Config, error) {
+	if err := config.Init(configPath); err != nil {
+		return nil, derrors.WrapStack(err, derrors.InvalidArgument, "failed to load config")
+	}
+
+	conf := config.Get()
+	if logLevel != "" {
+		conf.Logging.Level = logLevel
 	}
+	if port != "" {
+		conf.Server.Port = port
+	}
+	return conf, nil
+}
+
+// initSharedComponent loads the config and connects only the dependencies
+// the running command needs. Callers must Close the returned component.
+func initSharedComponent(deps ...container.Dependency) (*container.SharedComponent, error) {
+	conf, err := loadConfig()
+	if err != nil {
+		return nil, err
+	}
+	return container.InitSharedComponent(conf, deps...)
+}
+
+// runTenantListener receives tenant events for the roles sharing cc until ctx
+// is cancelled.
+func runTenantListener(ctx context.Context, sc *container.SharedComponent, cc *container.HandlerComponent) {
+	if err := cc.TenantListener.Run(ctx); err != nil {
+		sc.Log.Errorf("error: tenant listener: %s", err)
+	}
+}
+
+// signalContext returns a context cancelled on SIGINT or SIGTERM.
+func signalContext(cmd *cobra.Command) (context.Context, context.CancelFunc) {
+	return signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
 }
@@ -0,0 +1,145 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"tenant/internal/container"
+	"tenant/internal/model"
+	"tenant/pkg/derrors"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	scheduleCron     string
+	scheduleTimezone string
+	schedulePayload  string
+	scheduleHeaders  map[string]string
+	scheduleDisabled bool
+)
+
+// scheduleCmd groups the cron schedule commands
+var scheduleCmd = &cobra.Command{
+	Use:   "schedule",
+	Short: "Manage the recurring payloads of tenants",
+	Long: `Manage the cron schedules publishing a payload to the queue of a tenant every time their expression fires.
+The elected worker runs them every cron.interval.`,
+}
+
+// scheduleCreateCmd represents the schedule create command
+var scheduleCreateCmd = &cobra.Command{
+	Use:   "create [client-id] [name]",
+	Short: "Create a cron schedule",
+	Long: `Create a cron schedule publishing --payload every time --cron fires in --timezone.
+String values of the payload may use {{.ScheduleID}}, {{.Name}}, {{.ClientID}} and {{.ScheduledAt}}.`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if !json.Valid([]byte(schedulePayload)) {
+			return derrors.New(derrors.InvalidArgument, "--payload must be a JSON value")
+		}
+
+		return withCronUsecase(func(cc *container.HandlerComponent) (interface{}, error) {
+			return cc.CronUsecase.CreateSchedule(cmd.Context(), &model.CronSchedule{
+				ClientID:   args[0],
+				Name:       args[1],
+				Expression: scheduleCron,
+				Timezone:   scheduleTimezone,
+				Payload:    json.RawMessage(schedulePayload),
+				Headers:    scheduleHeaders,
+				Enabled:    !scheduleDisabled,
+			})
+		})
+	},
+}
+
+// scheduleListCmd represents the schedule list command
+var scheduleListCmd = &cobra.Command{
+	Use:   "list [client-id]",
+	Short: "List the cron schedules of a tenant",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return withCronUsecase(func(cc *container.HandlerComponent) (interface{}, error) {
+			return cc.CronUsecase.ListSchedules(cmd.Context(), args[0])
+		})
+	},
+}
+
+// scheduleEnableCmd and scheduleDisableCmd represent the schedule enable and
+// disable commands
+var (
+	scheduleEnableCmd = &cobra.Command{
+		Use:   "enable [client-id] [schedule-id]",
+		Short: "Enable a cron schedule, its next run is computed from now",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return setScheduleEnabled(cmd, args, true)
+		},
+	}
+	scheduleDisableCmd = &cobra.Command{
+		Use:   "disable [client-id] [schedule-id]",
+		Short: "Disable a cron schedule",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return setScheduleEnabled(cmd, args, false)
+		},
+	}
+)
+
+// scheduleDeleteCmd represents the schedule delete command
+var scheduleDeleteCmd = &cobra.Command{
+	Use:   "delete [client-id] [schedule-id]",
+	Short: "Delete a cron schedule",
+	Args:  cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return withCronUsecase(func(cc *container.HandlerComponent) (interface{}, error) {
+			if err := cc.CronUsecase.DeleteSchedule(cmd.Context(), args[0], args[1]); err != nil {
+				return nil, err
+			}
+			return map[string]string{"schedule_id": args[1]}, nil
+		})
+	},
+}
+
+func setScheduleEnabled(cmd *cobra.Command, args []string, enabled bool) error {
+	return withCronUsecase(func(cc *container.HandlerComponent) (interface{}, error) {
+		schedule, err := cc.CronUsecase.GetSchedule(cmd.Context(), args[0], args[1])
+		if err != nil {
+			return nil, err
+		}
+		schedule.Enabled = enabled
+		return cc.CronUsecase.UpdateSchedule(cmd.Context(), schedule)
+	})
+}
+
+// withCronUsecase connects to Postgres and RabbitMQ, runs fn and prints its
+// result as JSON
+func withCronUsecase(fn func(cc *container.HandlerComponent) (interface{}, error)) error {
+	sc, err := initSharedComponent(container.Database, container.RabbitMQ)
+	if err != nil {
+		return derrors.Wrap(&err, "failed to manage schedules")
+	}
+	defer sc.Close()
+
+	result, err := fn(container.NewHandlerComponent(sc))
+	if err != nil {
+		return derrors.Wrap(&err, "failed to manage schedules")
+	}
+
+	out, _ := json.Marshal(result)
+	fmt.Println(string(out))
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(scheduleCmd)
+	scheduleCmd.AddCommand(scheduleCreateCmd, scheduleListCmd, scheduleEnableCmd, scheduleDisableCmd, scheduleDeleteCmd)
+
+	scheduleCreateCmd.Flags().StringVar(&scheduleCron, "cron", "", "Cron expression, such as \"*/5 * * * *\" or \"@every 15m\"")
+	scheduleCreateCmd.Flags().StringVar(&scheduleTimezone, "timezone", "UTC", "IANA timezone the expression is evaluated in")
+	scheduleCreateCmd.Flags().StringVar(&schedulePayload, "payload", "", "JSON payload template")
+	scheduleCreateCmd.Flags().StringToStringVar(&scheduleHeaders, "header", nil, "Header of the published messages as key=value, may be repeated")
+	scheduleCreateCmd.Flags().BoolVar(&scheduleDisabled, "disabled", false, "Create the schedule disabled")
+	_ = scheduleCreateCmd.MarkFlagRequired("cron")
+	_ = scheduleCreateCmd.MarkFlagRequired("payload")
+}
@@ -0,0 +1,36 @@
+package cli
+
+import (
+	"tenant/cmd/webservice"
+	"tenant/internal/container"
+
+	"github.com/spf13/cobra"
+)
+
+// serveCmd represents the serve command
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Run the HTTP API",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		sc, err := initSharedComponent(container.Database, container.RabbitMQ)
+		if err != nil {
+			return err
+		}
+		defer sc.Close()
+
+		ctx, stop := signalContext(cmd)
+		defer stop()
+
+		cc := container.NewHandlerComponent(sc)
+		// Keep the tenant cache coherent with the other instances
+		if cc.TenantCache != nil {
+			go runTenantListener(ctx, sc, cc)
+		}
+		return webservice.Run(ctx, sc, cc)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(serveCmd)
+}
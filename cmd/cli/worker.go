@@ -0,0 +1,33 @@
+package cli
+
+import (
+	"tenant/cmd/worker"
+	"tenant/internal/container"
+
+	"github.com/spf13/cobra"
+)
+
+// workerCmd represents the worker command
+var workerCmd = &cobra.Command{
+	Use:   "worker",
+	Short: "Run the tenant queue consumers only",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		sc, err := initSharedComponent(container.Database, container.RabbitMQ)
+		if err != nil {
+			return err
+		}
+		defer sc.Close()
+
+		ctx, stop := signalContext(cmd)
+		defer stop()
+
+		cc := container.NewHandlerComponent(sc)
+		go runTenantListener(ctx, sc, cc)
+		return worker.Run(ctx, sc, cc)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(workerCmd)
+}
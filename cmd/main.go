@@ -1,13 +1,7 @@
 package main
 
 import (
-	"os"
-
 	"tenant/cmd/cli"
-	"tenant/cmd/webservice"
-	"tenant/internal/container"
-
-	"github.com/sirupsen/logrus"
 )
 
 // @title Api Documentation for tenant apps backend
@@ -22,22 +16,6 @@ import (
 
 // @BasePath /v1
 func main() {
-
-	sc, err := container.InitSharedComponent()
-	if err != nil {
-		logrus.Infof("Failed to create tenant: %v\n", err)
-		return
-	}
-	defer sc.DB.Close()
-	defer sc.RabbitMQConn.Close()
-
-	// Jalankan CLI atau REST API
-	if len(os.Args) > 1 {
-		cli.Execute()
-	} else {
-		if err := webservice.Run(sc); err != nil {
-			logrus.Errorf("Error: %v", err)
-			os.Exit(1)
-		}
-	}
+	// Jalankan CLI, REST API (serve), worker atau keduanya (all)
+	cli.Execute()
 }
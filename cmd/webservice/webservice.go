@@ -4,9 +4,7 @@ import (
 	"context"
 	"fmt"
 	"net/http"
-	"os"
-	"os/signal"
-	"syscall"
+	apimiddleware "tenant/internal/api/http/middleware"
 	"tenant/internal/api/http/router"
 
 	"time"
@@ -19,14 +17,19 @@ import (
 	"github.com/sirupsen/logrus"
 )
 
-func Run(sc *container.SharedComponent) error {
+// Run serves the HTTP API until ctx is cancelled, then shuts the server down
+// gracefully. The caller runs the tenant listener of cc, which keeps the
+// tenant cache coherent with the other instances.
+func Run(ctx context.Context, sc *container.SharedComponent, cc *container.HandlerComponent) error {
 
-	cc := container.NewHandlerComponent(sc)
+	log := sc.Log
 
-	logrus.Info("Initializing the web server ...")
+	log.Info("Initializing the web server ...")
 	e := echo.New()
 	e.Pre(middleware.RemoveTrailingSlash())
 	e.Use(middleware.Recover())
+	e.Use(apimiddleware.RequestID())
+	e.Use(apimiddleware.Logger(log))
 	e.Use(func(next echo.HandlerFunc) echo.HandlerFunc {
 		return func(c echo.Context) error {
 			c.Request().Header.Set("Cache-Control", "max-age:3600, public")
@@ -35,15 +38,17 @@ func Run(sc *container.SharedComponent) error {
 	})
 
 	e.Use(middleware.RequestLoggerWithConfig(middleware.RequestLoggerConfig{
-		LogURI:    true,
-		LogStatus: true,
+		LogURI:       true,
+		LogStatus:    true,
+		LogRequestID: true,
 		LogValuesFunc: func(c echo.Context, v middleware.RequestLoggerValues) error {
-			logrus.WithFields(logrus.Fields{
-				"Latency":   v.Latency.String(),
-				"Remote IP": c.RealIP(),
-				"URI":       v.URI,
-				"Method":    c.Request().Method,
-				"status":    v.Status,
+			log.WithFields(logrus.Fields{
+				"Latency":    v.Latency.String(),
+				"Remote IP":  c.RealIP(),
+				"URI":        v.URI,
+				"Method":     c.Request().Method,
+				"status":     v.Status,
+				"request_id": v.RequestID,
 			}).Info("request")
 
 			return nil
@@ -65,36 +70,26 @@ func Run(sc *container.SharedComponent) error {
 	serverErrors := make(chan error, 1)
 	// mulai listening server
 	go func() {
-		logrus.Infof("server listening on %v", server.Addr)
+		log.Infof("server listening on %v", server.Addr)
 		serverErrors <- e.StartServer(server)
 	}()
 
-	// Membuat channel untuk mendengarkan sinyal interupsi/terminate dari OS.
-	// Menggunakan channel buffered karena paket signal membutuhkannya.
-	shutdown := make(chan os.Signal, 1)
-	signal.Notify(shutdown, os.Interrupt, syscall.SIGTERM)
-
 	// Mengontrol penerimaan data dari channel,
-	// jika ada error saat listenAndServe server maupun ada sinyal shutdown yang diterima
+	// jika ada error saat listenAndServe server maupun context dibatalkan (sinyal shutdown)
 	select {
 	case err := <-serverErrors:
 		return fmt.Errorf("starting server: %v", err)
 
-	case <-shutdown:
-		logrus.Info("caught signal, shutting down")
+	case <-ctx.Done():
+		log.Info("caught signal, shutting down")
 
 		// Jika ada shutdown, meminta tambahan waktu 10 detik untuk menyelesaikan proses yang sedang berjalan.
 		const timeout = 10 * time.Second
 		ctx, cancel := context.WithTimeout(context.Background(), timeout)
 		defer cancel()
 
-		defer sc.DB.Close()
-		if err := sc.RabbitMQConn.Close(); err != nil {
-			logrus.Errorf("error: gracefully shutting down rabbitmq connection : %s", err)
-		}
-
 		if err := server.Shutdown(ctx); err != nil {
-			logrus.Errorf("error: gracefully shutting down server: %s", err)
+			log.Errorf("error: gracefully shutting down server: %s", err)
 			if err := server.Close(); err != nil {
 				return fmt.Errorf("could not stop server gracefully: %v", err)
 			}
@@ -0,0 +1,83 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"tenant/internal/api/http/router"
+	"tenant/internal/container"
+
+	"github.com/labstack/echo/v4"
+)
+
+// Run consumes the queues of every tenant until ctx is cancelled. Tenants
+// created or deleted while running are picked up on the next sync, or right
+// away while the caller runs the tenant listener of cc.
+func Run(ctx context.Context, sc *container.SharedComponent, cc *container.HandlerComponent) error {
+
+	log := sc.Log
+
+	log.Info("Initializing the worker ...")
+
+	// Health probes, so orchestrators can restart a worker that lost its
+	// broker, and the log level of the consumers
+	if sc.Conf.Worker.Port != "" {
+		e := echo.New()
+		e.HideBanner = true
+		e.HidePort = true
+		router.Worker(e, cc)
+
+		server := &http.Server{
+			Addr:         "0.0.0.0:" + sc.Conf.Worker.Port,
+			WriteTimeout: 15 * time.Second,
+			ReadTimeout:  15 * time.Second,
+		}
+		go func() {
+			log.Infof("worker endpoints listening on %v", server.Addr)
+			if err := e.StartServer(server); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				log.Errorf("error: worker probes server: %s", err)
+			}
+		}()
+		defer func() {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			if err := server.Shutdown(ctx); err != nil {
+				log.Errorf("error: gracefully shutting down worker probes server: %s", err)
+			}
+		}()
+	}
+
+	// Workers share the pruning job, one instance prunes at a time
+	if sc.Conf.Archive.PruneInterval > 0 {
+		go cc.ArchiveUsecase.RunPruner(ctx, sc.Conf.Archive.PruneInterval)
+	}
+
+	// Queued replays are run by the first worker claiming them
+	if sc.Conf.Replay.PollInterval > 0 {
+		go cc.ReplayUsecase.RunReplays(ctx, sc.Conf.Replay.PollInterval)
+	}
+
+	// Workers share the scheduled payloads, each due payload is published once
+	if sc.Conf.Schedule.Interval > 0 {
+		go cc.ScheduledUsecase.RunScheduler(ctx, sc.Conf.Schedule.Interval)
+	}
+
+	// One elected worker runs the cron schedules of every tenant
+	if sc.Conf.Cron.Interval > 0 {
+		go cc.CronUsecase.RunScheduler(ctx, sc.Conf.Cron.Interval)
+	}
+
+	interval := sc.Conf.Worker.SyncInterval
+	if interval <= 0 {
+		interval = 5 * time.Second
+	}
+
+	if err := cc.ConsumerUsecase.Run(ctx, interval); err != nil {
+		return err
+	}
+
+	log.Info("caught signal, stopping worker")
+	return nil
+}
@@ -1,16 +1,26 @@
 package config
 
 import (
-	"log"
+	"fmt"
+	"time"
 
 	"github.com/spf13/viper"
 )
 
 type Config struct {
-	Server   ServerConfig
-	Database DatabaseConfig
-	RabbitMQ RabbitMQConfig
-	Logging  LoggingConfig
+	Server    ServerConfig
+	Database  DatabaseConfig
+	RabbitMQ  RabbitMQConfig
+	Logging   LoggingConfig
+	Worker    WorkerConfig
+	Cache     CacheConfig
+	Process   ProcessConfig
+	Archive   ArchiveConfig
+	Replay    ReplayConfig
+	Schedule  ScheduleConfig
+	Cron      CronConfig
+	Webhook   WebhookConfig
+	Processor ProcessorConfig
 }
 
 type ServerConfig struct {
@@ -31,10 +41,157 @@ type RabbitMQConfig struct {
 	AutoReconnect  bool
 	MaxReconnects  int
 	ReconnectDelay int
+
+	// PublisherChannels bounds the channels used concurrently to publish
+	PublisherChannels int
+
+	// Prefetch bounds the unacked deliveries of each consumer
+	Prefetch int
+}
+
+type WorkerConfig struct {
+	// Port serves the worker health probes, empty disables them
+	Port         string
+	SyncInterval time.Duration
+
+	// ID identifies the worker in the lease table, replay jobs, the cron
+	// leadership and webhook health. A random ID is generated at startup
+	// when empty.
+	ID string
+
+	// Replicas bounds how many workers consume each tenant queue
+	Replicas int
+
+	// LeaseTTL is how long a tenant lease survives without renewal
+	LeaseTTL time.Duration
+
+	// MaxAttempts is how many times a message is processed before it is
+	// moved to the dead letter queue of its tenant
+	MaxAttempts int
+
+	// HandlerTimeout bounds a processing attempt, zero disables it
+	HandlerTimeout time.Duration
+}
+
+type ProcessConfig struct {
+	// MaxBatchSize bounds the payloads of a single batch request
+	MaxBatchSize int
+
+	// IdempotencyTTL is how long an Idempotency-Key is remembered
+	IdempotencyTTL time.Duration
+
+	// IdempotencyTimeout is how long an Idempotency-Key is held for a request
+	// that never completes
+	IdempotencyTimeout time.Duration
+
+	// MaxDelay bounds how far in the future a payload may be scheduled
+	MaxDelay time.Duration
+}
+
+type CronConfig struct {
+	// Interval is how often the elected worker runs the due cron schedules,
+	// zero disables cron schedules in workers
+	Interval time.Duration
+
+	// LeaderTTL hands the cron scheduler over to another worker once its
+	// leader stopped renewing its lease for this long
+	LeaderTTL time.Duration
+}
+
+type WebhookConfig struct {
+	// Timeout bounds every request made to a webhook
+	Timeout time.Duration
+
+	// Retries is how many times a failed request is sent again before the
+	// processing attempt of the message fails
+	Retries int
+
+	// Backoff is the delay before the first retry, it doubles on every
+	// retry up to MaxBackoff
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+
+	// BreakerFailures is how many consecutive failed requests open the
+	// breaker of a webhook, pausing its tenant for BreakerCooldown. Zero
+	// disables the breakers.
+	BreakerFailures int
+	BreakerCooldown time.Duration
+
+	// AllowPrivateNetworks lets webhooks reach loopback, private and
+	// link-local addresses, for local development only
+	AllowPrivateNetworks bool
+}
+
+type ProcessorConfig struct {
+	// CacheTTL is how long workers reuse the processor chain of a tenant,
+	// changes made through the API apply within this delay
+	CacheTTL time.Duration
+
+	// FileDir is the directory the file processor writes to, every tenant
+	// gets a subdirectory
+	FileDir string
+}
+
+type ScheduleConfig struct {
+	// Interval is how often workers publish the scheduled payloads that are
+	// due, zero disables publishing them in workers
+	Interval time.Duration
+
+	// BatchSize is how many due payloads a worker publishes at once
+	BatchSize int
+
+	// MaxAttempts is how many times the publication of a payload fails
+	// before it is marked failed and no longer published
+	MaxAttempts int
+
+	// Backoff delays a payload after its first failed publication, it
+	// doubles on every failure up to MaxBackoff
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+}
+
+type ArchiveConfig struct {
+	// Retention keeps archived messages of tenants without a retention policy
+	Retention time.Duration
+
+	// PartitionsAhead is how many monthly partitions are created in advance
+	PartitionsAhead int
+
+	// PruneInterval is how often workers prune expired data, zero disables
+	// the pruning job
+	PruneInterval time.Duration
+}
+
+type ReplayConfig struct {
+	// Rate is the messages per second of replays that do not set one
+	Rate float64
+
+	// MaxRate bounds the rate a replay may ask for
+	MaxRate float64
+
+	// PollInterval is how often workers look for queued replays, zero
+	// disables running replays in workers
+	PollInterval time.Duration
+
+	// StaleAfter hands a replay over to another worker once its worker
+	// stopped reporting progress for this long
+	StaleAfter time.Duration
+}
+
+type CacheConfig struct {
+	// TenantTTL caches tenant lookups, zero disables the cache
+	TenantTTL time.Duration
+
+	// TenantNegativeTTL caches lookups of unknown client IDs
+	TenantNegativeTTL time.Duration
+
+	TenantMaxEntries int
 }
 
 type LoggingConfig struct {
 	Level      string
+	Format     string
+	Console    bool
 	File       string
 	MaxSize    int
 	MaxBackups int
@@ -43,30 +200,77 @@ type LoggingConfig struct {
 
 var appConfig *Config
 
-// ReadConfig populates configurations from environment variables.
-func Init() {
+// Init populates configurations from the config file at path, or from
+// config.yaml in the working directory when path is empty.
+func Init(path string) error {
 
 	appConfig = &Config{}
 
-	viper.SetConfigName("config")
-	viper.SetConfigType("yaml")
-	viper.AddConfigPath(".")
+	if path != "" {
+		viper.SetConfigFile(path)
+	} else {
+		viper.SetConfigName("config")
+		viper.SetConfigType("yaml")
+		viper.AddConfigPath(".")
+	}
 
 	// Set default values
 	viper.SetDefault("server.port", "8080")
+	viper.SetDefault("rabbitmq.publisherChannels", 8)
+	viper.SetDefault("rabbitmq.prefetch", 16)
+	viper.SetDefault("worker.port", "8081")
+	viper.SetDefault("worker.syncInterval", "5s")
+	viper.SetDefault("worker.replicas", 1)
+	viper.SetDefault("worker.leaseTTL", "30s")
+	viper.SetDefault("worker.maxAttempts", 3)
+	viper.SetDefault("worker.handlerTimeout", "5m")
+	viper.SetDefault("process.maxBatchSize", 1000)
+	viper.SetDefault("process.idempotencyTTL", "24h")
+	viper.SetDefault("process.idempotencyTimeout", "1m")
+	viper.SetDefault("process.maxDelay", "168h")
+	viper.SetDefault("schedule.interval", "1s")
+	viper.SetDefault("schedule.batchSize", 100)
+	viper.SetDefault("schedule.maxAttempts", 10)
+	viper.SetDefault("schedule.backoff", "5s")
+	viper.SetDefault("schedule.maxBackoff", "10m")
+	viper.SetDefault("cron.interval", "5s")
+	viper.SetDefault("cron.leaderTTL", "30s")
+	viper.SetDefault("webhook.timeout", "10s")
+	viper.SetDefault("webhook.retries", 3)
+	viper.SetDefault("webhook.backoff", "1s")
+	viper.SetDefault("webhook.maxBackoff", "30s")
+	viper.SetDefault("webhook.breakerFailures", 5)
+	viper.SetDefault("webhook.breakerCooldown", "30s")
+	viper.SetDefault("webhook.allowPrivateNetworks", false)
+	viper.SetDefault("processor.cacheTTL", "30s")
+	viper.SetDefault("processor.fileDir", "./data/processor")
+	viper.SetDefault("archive.retention", "720h")
+	viper.SetDefault("archive.partitionsAhead", 1)
+	viper.SetDefault("archive.pruneInterval", "1h")
+	viper.SetDefault("replay.rate", 100)
+	viper.SetDefault("replay.maxRate", 1000)
+	viper.SetDefault("replay.pollInterval", "5s")
+	viper.SetDefault("replay.staleAfter", "30s")
+	viper.SetDefault("cache.tenantTTL", "30s")
+	viper.SetDefault("cache.tenantNegativeTTL", "5s")
+	viper.SetDefault("cache.tenantMaxEntries", 10000)
 	viper.SetDefault("logging.level", "info")
+	viper.SetDefault("logging.format", "json")
+	viper.SetDefault("logging.console", true)
 	viper.SetDefault("logging.file", "app.log")
 	viper.SetDefault("logging.maxSize", 10)
 	viper.SetDefault("logging.maxBackups", 5)
 	viper.SetDefault("logging.maxAge", 30)
 
 	if err := viper.ReadInConfig(); err != nil {
-		log.Fatalf("Failed to read config file: %s", err)
+		return fmt.Errorf("failed to read config file: %w", err)
 	}
 
 	if err := viper.Unmarshal(&appConfig); err != nil {
-		log.Fatalf("Failed to unmarshal file: %s", err)
+		return fmt.Errorf("failed to unmarshal file: %w", err)
 	}
+
+	return nil
 }
 
 // Get private instance config
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS tenant_leases;
+DROP TABLE IF EXISTS workers;
@@ -0,0 +1,16 @@
+CREATE TABLE IF NOT EXISTS workers (
+    id VARCHAR(64) PRIMARY KEY,
+    hostname VARCHAR(255) NOT NULL,
+    started_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    heartbeat_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS tenant_leases (
+    client_id VARCHAR(27) NOT NULL,
+    worker_id VARCHAR(64) NOT NULL,
+    acquired_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    PRIMARY KEY (client_id, worker_id)
+);
+
+CREATE INDEX IF NOT EXISTS tenant_leases_worker_id_idx ON tenant_leases (worker_id);
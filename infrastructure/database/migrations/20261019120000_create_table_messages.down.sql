@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS message_events;
+DROP TABLE IF EXISTS messages;
@@ -0,0 +1,21 @@
+CREATE TABLE IF NOT EXISTS messages (
+    message_id VARCHAR(27) PRIMARY KEY,
+    client_id VARCHAR(27) NOT NULL,
+    status VARCHAR(32) NOT NULL,
+    correlation_id VARCHAR(255),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS messages_client_id_created_at_idx ON messages (client_id, created_at);
+
+CREATE TABLE IF NOT EXISTS message_events (
+    id BIGSERIAL PRIMARY KEY,
+    message_id VARCHAR(27) NOT NULL REFERENCES messages (message_id) ON DELETE CASCADE,
+    status VARCHAR(32) NOT NULL,
+    attempt INT NOT NULL DEFAULT 0,
+    detail TEXT,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS message_events_message_id_idx ON message_events (message_id, id);
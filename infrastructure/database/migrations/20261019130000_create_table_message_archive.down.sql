@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS archive_retention_policies;
+DROP TABLE IF EXISTS message_archive;
@@ -0,0 +1,43 @@
+-- Published payloads, partitioned by month of publication so expired months
+-- are dropped instead of deleted row by row
+CREATE TABLE IF NOT EXISTS message_archive (
+    message_id VARCHAR(27) NOT NULL,
+    client_id VARCHAR(27) NOT NULL,
+    correlation_id VARCHAR(255),
+    content_type VARCHAR(255) NOT NULL,
+    headers JSONB NOT NULL DEFAULT '{}',
+    payload JSONB NOT NULL,
+    size INT NOT NULL,
+    published_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    archived_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (client_id, published_at, message_id)
+) PARTITION BY RANGE (published_at);
+
+-- Catches rows outside the monthly partitions created by the pruning job
+CREATE TABLE IF NOT EXISTS message_archive_default PARTITION OF message_archive DEFAULT;
+
+CREATE INDEX IF NOT EXISTS message_archive_message_id_idx ON message_archive (client_id, message_id);
+CREATE INDEX IF NOT EXISTS message_archive_headers_idx ON message_archive USING GIN (headers jsonb_path_ops);
+CREATE INDEX IF NOT EXISTS message_archive_payload_fts_idx ON message_archive USING GIN (to_tsvector('simple', payload::text));
+
+DO $$
+DECLARE
+    month DATE;
+BEGIN
+    FOR i IN 0..1 LOOP
+        month := date_trunc('month', NOW() AT TIME ZONE 'UTC')::DATE + make_interval(months => i);
+        EXECUTE format(
+            'CREATE TABLE IF NOT EXISTS %I PARTITION OF message_archive FOR VALUES FROM (%L) TO (%L)',
+            'message_archive_' || to_char(month, '"y"YYYY"m"MM'),
+            month::TIMESTAMP AT TIME ZONE 'UTC',
+            (month + INTERVAL '1 month')::TIMESTAMP AT TIME ZONE 'UTC'
+        );
+    END LOOP;
+END $$;
+
+-- Archive retention of the tenants that do not use the default
+CREATE TABLE IF NOT EXISTS archive_retention_policies (
+    client_id VARCHAR(27) PRIMARY KEY REFERENCES tenants (client_id),
+    retention_seconds BIGINT NOT NULL CHECK (retention_seconds > 0),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
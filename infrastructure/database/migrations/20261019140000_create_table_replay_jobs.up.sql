@@ -0,0 +1,26 @@
+CREATE TABLE IF NOT EXISTS replay_jobs (
+    id VARCHAR(27) PRIMARY KEY,
+    client_id VARCHAR(27) NOT NULL REFERENCES tenants (client_id),
+    status VARCHAR(32) NOT NULL,
+    from_time TIMESTAMP WITH TIME ZONE,
+    to_time TIMESTAMP WITH TIME ZONE,
+    message_ids TEXT[],
+    rate DOUBLE PRECISION NOT NULL CHECK (rate > 0),
+    total BIGINT NOT NULL DEFAULT 0,
+    published BIGINT NOT NULL DEFAULT 0,
+    failed BIGINT NOT NULL DEFAULT 0,
+    error TEXT,
+    -- Position of the last replayed message, so a job taken over by another
+    -- worker resumes where it stopped
+    cursor_published_at TIMESTAMP WITH TIME ZONE,
+    cursor_message_id VARCHAR(27),
+    worker_id VARCHAR(255),
+    heartbeat_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    started_at TIMESTAMP WITH TIME ZONE,
+    finished_at TIMESTAMP WITH TIME ZONE,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS replay_jobs_active_idx ON replay_jobs (created_at)
+    WHERE status IN ('pending', 'running');
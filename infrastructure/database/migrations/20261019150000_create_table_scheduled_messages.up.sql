@@ -0,0 +1,18 @@
+CREATE TABLE IF NOT EXISTS scheduled_messages (
+    message_id VARCHAR(27) PRIMARY KEY,
+    client_id VARCHAR(27) NOT NULL REFERENCES tenants (client_id),
+    payload JSONB NOT NULL,
+    headers JSONB NOT NULL DEFAULT '{}',
+    correlation_id VARCHAR(255),
+    content_type VARCHAR(255),
+    priority SMALLINT NOT NULL DEFAULT 0,
+    expiration_ms BIGINT NOT NULL DEFAULT 0,
+    deliver_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    -- Failed publish attempts, the message is retried on the next run
+    attempts INT NOT NULL DEFAULT 0,
+    last_error TEXT,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS scheduled_messages_deliver_at_idx ON scheduled_messages (deliver_at);
+CREATE INDEX IF NOT EXISTS scheduled_messages_client_id_deliver_at_idx ON scheduled_messages (client_id, deliver_at, message_id);
@@ -0,0 +1,28 @@
+CREATE TABLE IF NOT EXISTS cron_schedules (
+    id VARCHAR(27) PRIMARY KEY,
+    client_id VARCHAR(27) NOT NULL REFERENCES tenants (client_id),
+    name VARCHAR(255) NOT NULL,
+    expression VARCHAR(255) NOT NULL,
+    timezone VARCHAR(64) NOT NULL DEFAULT 'UTC',
+    payload JSONB NOT NULL,
+    headers JSONB NOT NULL DEFAULT '{}',
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    next_run_at TIMESTAMP WITH TIME ZONE,
+    last_run_at TIMESTAMP WITH TIME ZONE,
+    last_message_id VARCHAR(27),
+    last_error TEXT,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    UNIQUE (client_id, name)
+);
+
+CREATE INDEX IF NOT EXISTS cron_schedules_next_run_at_idx ON cron_schedules (next_run_at) WHERE enabled;
+
+-- One row per elected role, held by the worker that renews it before it
+-- expires
+CREATE TABLE IF NOT EXISTS leader_leases (
+    name VARCHAR(64) PRIMARY KEY,
+    worker_id VARCHAR(64) NOT NULL,
+    acquired_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
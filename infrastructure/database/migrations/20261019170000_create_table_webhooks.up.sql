@@ -0,0 +1,29 @@
+CREATE TABLE IF NOT EXISTS webhooks (
+    id VARCHAR(27) PRIMARY KEY,
+    client_id VARCHAR(27) NOT NULL REFERENCES tenants (client_id),
+    url TEXT NOT NULL,
+    secret VARCHAR(255) NOT NULL,
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    UNIQUE (client_id, url)
+);
+
+-- One row per HTTP request made to a webhook
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+    id BIGSERIAL PRIMARY KEY,
+    webhook_id VARCHAR(27) NOT NULL REFERENCES webhooks (id) ON DELETE CASCADE,
+    client_id VARCHAR(27) NOT NULL,
+    message_id VARCHAR(27) NOT NULL,
+    attempt INT NOT NULL,
+    retry INT NOT NULL DEFAULT 0,
+    status_code INT,
+    duration_ms INT NOT NULL DEFAULT 0,
+    error TEXT,
+    succeeded BOOLEAN NOT NULL,
+    delivered_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS webhook_deliveries_webhook_id_idx ON webhook_deliveries (webhook_id, delivered_at DESC);
+CREATE INDEX IF NOT EXISTS webhook_deliveries_message_id_idx ON webhook_deliveries (message_id) WHERE succeeded;
+CREATE INDEX IF NOT EXISTS webhook_deliveries_delivered_at_idx ON webhook_deliveries (delivered_at);
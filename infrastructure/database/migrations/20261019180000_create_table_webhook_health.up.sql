@@ -0,0 +1,16 @@
+-- Last circuit breaker state reported by a worker for each webhook
+CREATE TABLE IF NOT EXISTS webhook_health (
+    webhook_id VARCHAR(27) PRIMARY KEY REFERENCES webhooks (id) ON DELETE CASCADE,
+    client_id VARCHAR(27) NOT NULL,
+    worker_id VARCHAR(64) NOT NULL,
+    state VARCHAR(16) NOT NULL,
+    consecutive_failures INT NOT NULL DEFAULT 0,
+    successes BIGINT NOT NULL DEFAULT 0,
+    failures BIGINT NOT NULL DEFAULT 0,
+    last_latency_ms INT NOT NULL DEFAULT 0,
+    avg_latency_ms INT NOT NULL DEFAULT 0,
+    last_error TEXT,
+    opened_at TIMESTAMP WITH TIME ZONE,
+    retry_at TIMESTAMP WITH TIME ZONE,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
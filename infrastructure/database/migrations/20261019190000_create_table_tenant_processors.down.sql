@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS sink_messages;
+DROP TABLE IF EXISTS tenant_processors;
@@ -0,0 +1,20 @@
+-- Processor chain of the tenants that do not use the default one
+CREATE TABLE IF NOT EXISTS tenant_processors (
+    client_id VARCHAR(27) PRIMARY KEY REFERENCES tenants (client_id),
+    processors JSONB NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+-- Messages stored by the postgres processor
+CREATE TABLE IF NOT EXISTS sink_messages (
+    client_id VARCHAR(27) NOT NULL,
+    message_id VARCHAR(27) NOT NULL,
+    correlation_id VARCHAR(255),
+    content_type VARCHAR(255) NOT NULL,
+    headers JSONB NOT NULL DEFAULT '{}',
+    payload JSONB NOT NULL,
+    received_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (client_id, message_id)
+);
+
+CREATE INDEX IF NOT EXISTS sink_messages_received_at_idx ON sink_messages (client_id, received_at DESC);
@@ -0,0 +1 @@
+ALTER TABLE scheduled_messages DROP COLUMN IF EXISTS failed_at;
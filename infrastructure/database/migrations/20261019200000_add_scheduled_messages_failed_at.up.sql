@@ -0,0 +1,2 @@
+-- Set once the publication of a payload is given up after too many attempts
+ALTER TABLE scheduled_messages ADD COLUMN IF NOT EXISTS failed_at TIMESTAMP WITH TIME ZONE;
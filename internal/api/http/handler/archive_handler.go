@@ -0,0 +1,204 @@
+package handler
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"tenant/internal/api/http/handler/request"
+	"tenant/internal/container"
+	"tenant/internal/model"
+	"tenant/internal/usecase"
+	"tenant/pkg/api"
+	"tenant/pkg/derrors"
+
+	"github.com/labstack/echo/v4"
+)
+
+type (
+	archiveHandler struct {
+		archiveUsecase usecase.ArchiveUsecase
+	}
+
+	ArchiveHandler interface {
+		SearchMessages(c echo.Context) error
+		GetRetention(c echo.Context) error
+		SetRetention(c echo.Context) error
+		ResetRetention(c echo.Context) error
+	}
+)
+
+// maxSearchLimit bounds the page size of a search
+const maxSearchLimit = 500
+
+func NewArchiveHandler(hc *container.HandlerComponent) ArchiveHandler {
+	return &archiveHandler{archiveUsecase: hc.ArchiveUsecase}
+}
+
+// SearchMessages searches the archived payloads of a tenant
+// Search Messages
+// @Summary Search Messages
+// @Description Search the archived payloads of a tenant, newest first. Every header filter must match, q is a full-text search on the payload. Pass next_cursor as cursor to get the next page.
+// @Tags message
+// @ID search-messages
+// @Produce json
+// @Param clientID path string true "clientID"
+// @Param from query string false "published at or after, RFC 3339"
+// @Param to query string false "published before, RFC 3339"
+// @Param header query []string false "header filter as key:value" collectionFormat(multi)
+// @Param q query string false "full-text search on the payload"
+// @Param limit query int false "page size, at most 500" default(50)
+// @Param cursor query string false "next_cursor of the previous page"
+// @Success 200 {object} model.ArchivePage
+// @Failure 400 {object} api.Problem
+// @Failure 404 {object} api.Problem
+// @Failure 500 {object} api.Problem
+// @Router /tenants/{clientID}/messages [get]
+func (h *archiveHandler) SearchMessages(c echo.Context) error {
+	clientID := c.Param("clientID")
+	if clientID == "" {
+		return api.RenderErrorResponse(c, c.Request(), derrors.New(derrors.InvalidArgument, "client_id is required"))
+	}
+
+	query, err := parseArchiveQuery(c)
+	if err != nil {
+		return api.RenderErrorResponse(c, c.Request(), err)
+	}
+	query.ClientID = clientID
+
+	page, err := h.archiveUsecase.Search(c.Request().Context(), query)
+	if err != nil {
+		return api.RenderErrorResponse(c, c.Request(), err)
+	}
+
+	return api.ResponseOK(c, page)
+}
+
+// parseArchiveQuery reads the search filters from the query string
+func parseArchiveQuery(c echo.Context) (*model.ArchiveQuery, error) {
+	query := &model.ArchiveQuery{Text: c.QueryParam("q")}
+
+	for _, name := range []string{"from", "to"} {
+		value := c.QueryParam(name)
+		if value == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return nil, api.NewValidationError(name, "must be an RFC 3339 time, such as \"2026-10-19T12:00:00Z\"")
+		}
+		if name == "from" {
+			query.From = t
+		} else {
+			query.To = t
+		}
+	}
+
+	for _, header := range c.QueryParams()["header"] {
+		key, value, ok := strings.Cut(header, ":")
+		if !ok || key == "" {
+			return nil, api.NewValidationError("header", "must be key:value")
+		}
+		if query.Headers == nil {
+			query.Headers = make(map[string]string)
+		}
+		query.Headers[key] = value
+	}
+
+	if value := c.QueryParam("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit < 1 || limit > maxSearchLimit {
+			return nil, api.NewValidationError("limit", "must be between 1 and "+strconv.Itoa(maxSearchLimit))
+		}
+		query.Limit = limit
+	}
+
+	if value := c.QueryParam("cursor"); value != "" {
+		cursor, err := model.ParseArchiveCursor(value)
+		if err != nil {
+			return nil, api.NewValidationError("cursor", err.Error())
+		}
+		query.After = cursor
+	}
+
+	return query, nil
+}
+
+// GetRetention returns how long the archived payloads of a tenant are kept
+// Get Retention
+// @Summary Get Retention
+// @Description Get how long the archived payloads of a tenant are kept
+// @Tags message
+// @ID get-retention
+// @Produce json
+// @Param clientID path string true "clientID"
+// @Success 200 {object} model.RetentionPolicy
+// @Failure 404 {object} api.Problem
+// @Failure 500 {object} api.Problem
+// @Router /tenants/{clientID}/retention [get]
+func (h *archiveHandler) GetRetention(c echo.Context) error {
+	policy, err := h.archiveUsecase.GetRetention(c.Request().Context(), c.Param("clientID"))
+	if err != nil {
+		return api.RenderErrorResponse(c, c.Request(), err)
+	}
+
+	return api.ResponseOK(c, policy)
+}
+
+// SetRetention overrides how long the archived payloads of a tenant are kept
+// Set Retention
+// @Summary Set Retention
+// @Description Override the default retention of the archived payloads of a tenant
+// @Tags message
+// @ID set-retention
+// @Produce json
+// @Param clientID path string true "clientID"
+// @Param retention body request.SetRetentionRequest true "retention payload"
+// @Success 200 {object} model.RetentionPolicy
+// @Failure 400 {object} api.Problem
+// @Failure 404 {object} api.Problem
+// @Failure 500 {object} api.Problem
+// @Router /tenants/{clientID}/retention [put]
+func (h *archiveHandler) SetRetention(c echo.Context) error {
+	var req request.SetRetentionRequest
+	if err := c.Bind(&req); err != nil {
+		return api.RenderErrorResponse(c, c.Request(), err)
+	}
+
+	if err := c.Validate(req); err != nil {
+		return api.RenderErrorResponse(c, c.Request(), err)
+	}
+
+	retention, err := time.ParseDuration(req.Retention)
+	if err != nil || retention < time.Second {
+		return api.RenderErrorResponse(c, c.Request(), api.NewValidationError("retention", "must be a duration of at least 1s, such as \"720h\""))
+	}
+
+	policy, err := h.archiveUsecase.SetRetention(c.Request().Context(), c.Param("clientID"), retention)
+	if err != nil {
+		return api.RenderErrorResponse(c, c.Request(), err)
+	}
+
+	return api.ResponseOK(c, policy)
+}
+
+// ResetRetention moves a tenant back to the default retention
+// Reset Retention
+// @Summary Reset Retention
+// @Description Move a tenant back to the default retention of archived payloads
+// @Tags message
+// @ID reset-retention
+// @Produce json
+// @Param clientID path string true "clientID"
+// @Success 200 {object} model.RetentionPolicy
+// @Failure 404 {object} api.Problem
+// @Failure 500 {object} api.Problem
+// @Router /tenants/{clientID}/retention [delete]
+func (h *archiveHandler) ResetRetention(c echo.Context) error {
+	policy, err := h.archiveUsecase.ResetRetention(c.Request().Context(), c.Param("clientID"))
+	if err != nil {
+		return api.RenderErrorResponse(c, c.Request(), err)
+	}
+
+	return api.ResponseOK(c, policy)
+}
@@ -0,0 +1,156 @@
+package handler_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"tenant/internal/api/http/handler"
+	"tenant/internal/container"
+	"tenant/internal/model"
+	"tenant/internal/test"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestSearchMessagesHandler(t *testing.T) {
+	e := echo.New()
+	mockComponent := test.InitMockComponent(t)
+
+	hc := &container.HandlerComponent{
+		ArchiveUsecase: mockComponent.ArchiveUsecase,
+	}
+
+	h := handler.NewArchiveHandler(hc)
+
+	var testCases = []struct {
+		caseName     string
+		query        string
+		mockSetup    func()
+		expectedCode int
+		expectedBody string
+	}{
+		{
+			caseName: "SearchMessages_Success",
+			query:    "from=2026-10-01T00:00:00Z&header=x-source:billing&header=x-region:eu&q=invoice&limit=10",
+			mockSetup: func() {
+				mockComponent.ArchiveUsecase.On("Search", mock.Anything, mock.MatchedBy(func(query *model.ArchiveQuery) bool {
+					return query.ClientID == "test-client" && query.Limit == 10 && query.Text == "invoice" &&
+						query.From.Equal(time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)) && query.To.IsZero() &&
+						query.Headers["x-source"] == "billing" && query.Headers["x-region"] == "eu"
+				})).Return(&model.ArchivePage{Messages: []*model.ArchivedMessage{{MessageID: "msg-1", Payload: []byte(`{"invoice":1}`)}}}, nil).Once()
+			},
+			expectedCode: http.StatusOK,
+			expectedBody: `"payload":{"invoice":1}`,
+		},
+		{
+			caseName:     "SearchMessages_InvalidTime",
+			query:        "to=yesterday",
+			mockSetup:    func() {},
+			expectedCode: http.StatusBadRequest,
+			expectedBody: `"field":"to"`,
+		},
+		{
+			caseName:     "SearchMessages_InvalidHeader",
+			query:        "header=x-source",
+			mockSetup:    func() {},
+			expectedCode: http.StatusBadRequest,
+			expectedBody: `"field":"header"`,
+		},
+		{
+			caseName:     "SearchMessages_LimitTooLarge",
+			query:        "limit=501",
+			mockSetup:    func() {},
+			expectedCode: http.StatusBadRequest,
+			expectedBody: `"field":"limit"`,
+		},
+		{
+			caseName:     "SearchMessages_InvalidCursor",
+			query:        "cursor=not-a-cursor",
+			mockSetup:    func() {},
+			expectedCode: http.StatusBadRequest,
+			expectedBody: `"field":"cursor"`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.caseName, func(t *testing.T) {
+			tc.mockSetup()
+
+			req := httptest.NewRequest(http.MethodGet, "/tenants/test-client/messages?"+tc.query, nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetParamNames("clientID")
+			c.SetParamValues("test-client")
+
+			err := h.SearchMessages(c)
+			if err != nil {
+				t.Errorf("Handler returned error: %v", err)
+			}
+
+			assert.Equal(t, tc.expectedCode, rec.Code)
+			assert.Contains(t, rec.Body.String(), tc.expectedBody)
+		})
+	}
+}
+
+func TestSetRetentionHandler(t *testing.T) {
+	e := echo.New()
+	e.Validator = &requestValidator{}
+	mockComponent := test.InitMockComponent(t)
+
+	hc := &container.HandlerComponent{
+		ArchiveUsecase: mockComponent.ArchiveUsecase,
+	}
+
+	h := handler.NewArchiveHandler(hc)
+
+	var testCases = []struct {
+		caseName     string
+		body         string
+		mockSetup    func()
+		expectedCode int
+		expectedBody string
+	}{
+		{
+			caseName: "SetRetention_Success",
+			body:     `{"retention":"168h"}`,
+			mockSetup: func() {
+				mockComponent.ArchiveUsecase.On("SetRetention", mock.Anything, "test-client", 168*time.Hour).Return(&model.RetentionPolicy{ClientID: "test-client", Retention: 168 * time.Hour}, nil).Once()
+			},
+			expectedCode: http.StatusOK,
+			expectedBody: `"retention":"168h0m0s"`,
+		},
+		{
+			caseName:     "SetRetention_InvalidDuration",
+			body:         `{"retention":"a week"}`,
+			mockSetup:    func() {},
+			expectedCode: http.StatusBadRequest,
+			expectedBody: `"field":"retention"`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.caseName, func(t *testing.T) {
+			tc.mockSetup()
+
+			req := httptest.NewRequest(http.MethodPut, "/tenants/test-client/retention", strings.NewReader(tc.body))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetParamNames("clientID")
+			c.SetParamValues("test-client")
+
+			err := h.SetRetention(c)
+			if err != nil {
+				t.Errorf("Handler returned error: %v", err)
+			}
+
+			assert.Equal(t, tc.expectedCode, rec.Code)
+			assert.Contains(t, rec.Body.String(), tc.expectedBody)
+		})
+	}
+}
@@ -0,0 +1,42 @@
+package handler
+
+import (
+	"tenant/internal/container"
+	"tenant/internal/usecase"
+	"tenant/pkg/api"
+
+	"github.com/labstack/echo/v4"
+)
+
+type (
+	assignmentHandler struct {
+		consumerUsecase usecase.ConsumerUsecase
+	}
+
+	AssignmentHandler interface {
+		GetAssignments(c echo.Context) error
+	}
+)
+
+func NewAssignmentHandler(hc *container.HandlerComponent) AssignmentHandler {
+	return &assignmentHandler{consumerUsecase: hc.ConsumerUsecase}
+}
+
+// GetAssignments returns the live workers and the tenants each of them consumes
+// Get Assignments
+// @Summary Get Assignments
+// @Description List the live workers and the tenant leases they hold
+// @Tags admin
+// @ID get-assignments
+// @Produce json
+// @Success 200 {object} model.Assignments
+// @Failure 500 {object} api.Problem
+// @Router /admin/assignments [get]
+func (h *assignmentHandler) GetAssignments(c echo.Context) error {
+	assignments, err := h.consumerUsecase.Assignments(c.Request().Context())
+	if err != nil {
+		return api.RenderErrorResponse(c, c.Request(), err)
+	}
+
+	return api.ResponseOK(c, assignments)
+}
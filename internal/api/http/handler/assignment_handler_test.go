@@ -0,0 +1,72 @@
+package handler_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"tenant/internal/api/http/handler"
+	"tenant/internal/container"
+	"tenant/internal/model"
+	"tenant/internal/test"
+	"tenant/pkg/derrors"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestGetAssignmentsHandler(t *testing.T) {
+	e := echo.New()
+	mockComponent := test.InitMockComponent(t)
+
+	hc := &container.HandlerComponent{
+		ConsumerUsecase: mockComponent.ConsumerUsecase,
+	}
+
+	h := handler.NewAssignmentHandler(hc)
+
+	var testCases = []struct {
+		caseName     string
+		mockSetup    func()
+		expectedCode int
+		expectedBody string
+	}{
+		{
+			caseName: "GetAssignments_Success",
+			mockSetup: func() {
+				mockComponent.ConsumerUsecase.On("Assignments", mock.Anything).Return(&model.Assignments{
+					Workers: []*model.Worker{{ID: "worker-1"}},
+					Leases:  []*model.TenantLease{{ClientID: "test-client", WorkerID: "worker-1"}},
+				}, nil).Once()
+			},
+			expectedCode: http.StatusOK,
+			expectedBody: `"client_id":"test-client","worker_id":"worker-1"`,
+		},
+		{
+			caseName: "GetAssignments_DatabaseUnavailable",
+			mockSetup: func() {
+				mockComponent.ConsumerUsecase.On("Assignments", mock.Anything).Return(nil, derrors.New(derrors.Unavailable, "connection refused")).Once()
+			},
+			expectedCode: http.StatusServiceUnavailable,
+			expectedBody: `"code":"unavailable"`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.caseName, func(t *testing.T) {
+			tc.mockSetup()
+
+			req := httptest.NewRequest(http.MethodGet, "/admin/assignments", nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+
+			err := h.GetAssignments(c)
+			if err != nil {
+				t.Errorf("Handler returned error: %v", err)
+			}
+
+			assert.Equal(t, tc.expectedCode, rec.Code)
+			assert.Contains(t, rec.Body.String(), tc.expectedBody)
+		})
+	}
+}
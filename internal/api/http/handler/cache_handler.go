@@ -0,0 +1,62 @@
+package handler
+
+import (
+	"tenant/internal/container"
+	"tenant/internal/repository"
+	"tenant/pkg/api"
+	"tenant/pkg/derrors"
+
+	"github.com/labstack/echo/v4"
+)
+
+type (
+	cacheHandler struct {
+		tenantCache repository.TenantCache
+	}
+
+	CacheHandler interface {
+		GetTenantCacheStats(c echo.Context) error
+		PurgeTenantCache(c echo.Context) error
+	}
+)
+
+func NewCacheHandler(hc *container.HandlerComponent) CacheHandler {
+	return &cacheHandler{tenantCache: hc.TenantCache}
+}
+
+// GetTenantCacheStats returns the hit and miss counters of the tenant cache
+// Get Tenant Cache Stats
+// @Summary Get Tenant Cache Stats
+// @Description Get the hit, miss and eviction counters of the tenant lookup cache of this instance
+// @Tags admin
+// @ID get-tenant-cache-stats
+// @Produce json
+// @Success 200 {object} repository.CacheStats
+// @Failure 404 {object} api.Problem
+// @Router /admin/tenant-cache [get]
+func (h *cacheHandler) GetTenantCacheStats(c echo.Context) error {
+	if h.tenantCache == nil {
+		return api.RenderErrorResponse(c, c.Request(), derrors.New(derrors.NotFound, "tenant cache is disabled"))
+	}
+
+	return api.ResponseOK(c, h.tenantCache.Stats())
+}
+
+// PurgeTenantCache forgets every cached tenant lookup
+// Purge Tenant Cache
+// @Summary Purge Tenant Cache
+// @Description Forget every cached tenant lookup of this instance
+// @Tags admin
+// @ID purge-tenant-cache
+// @Produce json
+// @Success 200 {object} repository.CacheStats
+// @Failure 404 {object} api.Problem
+// @Router /admin/tenant-cache [delete]
+func (h *cacheHandler) PurgeTenantCache(c echo.Context) error {
+	if h.tenantCache == nil {
+		return api.RenderErrorResponse(c, c.Request(), derrors.New(derrors.NotFound, "tenant cache is disabled"))
+	}
+
+	h.tenantCache.Purge()
+	return api.ResponseOK(c, h.tenantCache.Stats())
+}
@@ -0,0 +1,80 @@
+package handler_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"tenant/internal/api/http/handler"
+	"tenant/internal/container"
+	"tenant/internal/model"
+	"tenant/internal/repository"
+	"tenant/internal/test"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestTenantCacheHandler(t *testing.T) {
+	e := echo.New()
+	mockComponent := test.InitMockComponent(t)
+
+	tenantCache := repository.NewTenantCache(mockComponent.TenantRepository, repository.TenantCacheConfig{TTL: time.Minute})
+	mockComponent.TenantRepository.On("GetTenantByClientID", mock.Anything, "test-client").Return(&model.Tenant{ClientID: "test-client"}, nil).Once()
+	_, _ = tenantCache.GetTenantByClientID(context.Background(), "test-client")
+	_, _ = tenantCache.GetTenantByClientID(context.Background(), "test-client")
+
+	var testCases = []struct {
+		caseName     string
+		tenantCache  repository.TenantCache
+		method       string
+		expectedCode int
+		expectedBody string
+	}{
+		{
+			caseName:     "GetTenantCacheStats_Success",
+			tenantCache:  tenantCache,
+			method:       http.MethodGet,
+			expectedCode: http.StatusOK,
+			expectedBody: `"hits":1,"negative_hits":0,"misses":1,"evictions":0,"entries":1`,
+		},
+		{
+			caseName:     "PurgeTenantCache_Success",
+			tenantCache:  tenantCache,
+			method:       http.MethodDelete,
+			expectedCode: http.StatusOK,
+			expectedBody: `"entries":0`,
+		},
+		{
+			caseName:     "GetTenantCacheStats_Disabled",
+			method:       http.MethodGet,
+			expectedCode: http.StatusNotFound,
+			expectedBody: `"detail":"tenant cache is disabled"`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.caseName, func(t *testing.T) {
+			h := handler.NewCacheHandler(&container.HandlerComponent{TenantCache: tc.tenantCache})
+
+			req := httptest.NewRequest(tc.method, "/admin/tenant-cache", nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+
+			var err error
+			if tc.method == http.MethodDelete {
+				err = h.PurgeTenantCache(c)
+			} else {
+				err = h.GetTenantCacheStats(c)
+			}
+			if err != nil {
+				t.Errorf("Handler returned error: %v", err)
+			}
+
+			assert.Equal(t, tc.expectedCode, rec.Code)
+			assert.Contains(t, rec.Body.String(), tc.expectedBody)
+		})
+	}
+}
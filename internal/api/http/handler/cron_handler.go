@@ -0,0 +1,197 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"tenant/internal/api/http/handler/request"
+	"tenant/internal/container"
+	"tenant/internal/model"
+	"tenant/internal/usecase"
+	"tenant/pkg/api"
+	"tenant/pkg/derrors"
+
+	"github.com/labstack/echo/v4"
+)
+
+type (
+	cronHandler struct {
+		cronUsecase usecase.CronUsecase
+	}
+
+	CronHandler interface {
+		CreateSchedule(c echo.Context) error
+		ListSchedules(c echo.Context) error
+		GetSchedule(c echo.Context) error
+		UpdateSchedule(c echo.Context) error
+		DeleteSchedule(c echo.Context) error
+	}
+)
+
+func NewCronHandler(hc *container.HandlerComponent) CronHandler {
+	return &cronHandler{cronUsecase: hc.CronUsecase}
+}
+
+// CreateSchedule creates a recurring payload of a tenant
+// Create Schedule
+// @Summary Create Schedule
+// @Description Publish a payload to the queue of a tenant every time a cron expression fires in a timezone. String values of the payload may use {{.ScheduleID}}, {{.Name}}, {{.ClientID}} and {{.ScheduledAt}}.
+// @Tags schedule
+// @ID create-schedule
+// @Accept json
+// @Produce json
+// @Param clientID path string true "clientID"
+// @Param schedule body request.CronScheduleRequest true "schedule payload"
+// @Success 201 {object} model.CronSchedule
+// @Failure 400 {object} api.Problem
+// @Failure 404 {object} api.Problem
+// @Failure 409 {object} api.Problem
+// @Failure 500 {object} api.Problem
+// @Router /tenants/{clientID}/schedules [post]
+func (h *cronHandler) CreateSchedule(c echo.Context) error {
+	schedule, err := bindCronSchedule(c)
+	if err != nil {
+		return api.RenderErrorResponse(c, c.Request(), err)
+	}
+
+	schedule, err = h.cronUsecase.CreateSchedule(c.Request().Context(), schedule)
+	if err != nil {
+		return api.RenderErrorResponse(c, c.Request(), err)
+	}
+
+	return api.ResponseSuccess(c, schedule, "schedule created", http.StatusCreated)
+}
+
+// ListSchedules lists the recurring payloads of a tenant
+// List Schedules
+// @Summary List Schedules
+// @Description List the cron schedules of a tenant by name
+// @Tags schedule
+// @ID list-schedules
+// @Produce json
+// @Param clientID path string true "clientID"
+// @Success 200 {array} model.CronSchedule
+// @Failure 404 {object} api.Problem
+// @Failure 500 {object} api.Problem
+// @Router /tenants/{clientID}/schedules [get]
+func (h *cronHandler) ListSchedules(c echo.Context) error {
+	schedules, err := h.cronUsecase.ListSchedules(c.Request().Context(), c.Param("clientID"))
+	if err != nil {
+		return api.RenderErrorResponse(c, c.Request(), err)
+	}
+
+	return api.ResponseOK(c, schedules)
+}
+
+// GetSchedule returns a recurring payload of a tenant
+// Get Schedule
+// @Summary Get Schedule
+// @Description Get a cron schedule of a tenant with its next run and the outcome of its last run
+// @Tags schedule
+// @ID get-schedule
+// @Produce json
+// @Param clientID path string true "clientID"
+// @Param scheduleID path string true "scheduleID"
+// @Success 200 {object} model.CronSchedule
+// @Failure 404 {object} api.Problem
+// @Failure 500 {object} api.Problem
+// @Router /tenants/{clientID}/schedules/{scheduleID} [get]
+func (h *cronHandler) GetSchedule(c echo.Context) error {
+	schedule, err := h.cronUsecase.GetSchedule(c.Request().Context(), c.Param("clientID"), c.Param("scheduleID"))
+	if err != nil {
+		return api.RenderErrorResponse(c, c.Request(), err)
+	}
+
+	return api.ResponseOK(c, schedule)
+}
+
+// UpdateSchedule replaces a recurring payload of a tenant
+// Update Schedule
+// @Summary Update Schedule
+// @Description Replace the definition of a cron schedule, its next run is computed again
+// @Tags schedule
+// @ID update-schedule
+// @Accept json
+// @Produce json
+// @Param clientID path string true "clientID"
+// @Param scheduleID path string true "scheduleID"
+// @Param schedule body request.CronScheduleRequest true "schedule payload"
+// @Success 200 {object} model.CronSchedule
+// @Failure 400 {object} api.Problem
+// @Failure 404 {object} api.Problem
+// @Failure 409 {object} api.Problem
+// @Failure 500 {object} api.Problem
+// @Router /tenants/{clientID}/schedules/{scheduleID} [put]
+func (h *cronHandler) UpdateSchedule(c echo.Context) error {
+	schedule, err := bindCronSchedule(c)
+	if err != nil {
+		return api.RenderErrorResponse(c, c.Request(), err)
+	}
+	schedule.ID = c.Param("scheduleID")
+
+	schedule, err = h.cronUsecase.UpdateSchedule(c.Request().Context(), schedule)
+	if err != nil {
+		return api.RenderErrorResponse(c, c.Request(), err)
+	}
+
+	return api.ResponseOK(c, schedule)
+}
+
+// DeleteSchedule deletes a recurring payload of a tenant
+// Delete Schedule
+// @Summary Delete Schedule
+// @Description Delete a cron schedule of a tenant
+// @Tags schedule
+// @ID delete-schedule
+// @Produce json
+// @Param clientID path string true "clientID"
+// @Param scheduleID path string true "scheduleID"
+// @Success 200 {object} map[string]string
+// @Failure 404 {object} api.Problem
+// @Failure 500 {object} api.Problem
+// @Router /tenants/{clientID}/schedules/{scheduleID} [delete]
+func (h *cronHandler) DeleteSchedule(c echo.Context) error {
+	scheduleID := c.Param("scheduleID")
+	if err := h.cronUsecase.DeleteSchedule(c.Request().Context(), c.Param("clientID"), scheduleID); err != nil {
+		return api.RenderErrorResponse(c, c.Request(), err)
+	}
+
+	return api.ResponseOK(c, map[string]interface{}{
+		"message":     "Schedule deleted",
+		"schedule_id": scheduleID,
+	})
+}
+
+// bindCronSchedule reads a schedule of the tenant in the path from the body
+func bindCronSchedule(c echo.Context) (*model.CronSchedule, error) {
+	var req request.CronScheduleRequest
+	if err := c.Bind(&req); err != nil {
+		return nil, err
+	}
+
+	if err := c.Validate(req); err != nil {
+		return nil, err
+	}
+
+	if req.Payload == nil {
+		return nil, api.NewValidationError("payload", "non zero value required")
+	}
+	payload, err := json.Marshal(req.Payload)
+	if err != nil {
+		return nil, derrors.WrapStack(err, derrors.InvalidArgument, "failed to encode payload")
+	}
+
+	enabled := true
+	if req.Enabled != nil {
+		enabled = *req.Enabled
+	}
+	return &model.CronSchedule{
+		ClientID:   c.Param("clientID"),
+		Name:       req.Name,
+		Expression: req.Cron,
+		Timezone:   req.Timezone,
+		Payload:    payload,
+		Headers:    req.Headers,
+		Enabled:    enabled,
+	}, nil
+}
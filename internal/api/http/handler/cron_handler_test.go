@@ -0,0 +1,132 @@
+package handler_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"tenant/internal/api/http/handler"
+	"tenant/internal/container"
+	"tenant/internal/model"
+	"tenant/internal/test"
+	"tenant/pkg/derrors"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestCreateScheduleHandler(t *testing.T) {
+	e := echo.New()
+	e.Validator = &requestValidator{}
+	mockComponent := test.InitMockComponent(t)
+
+	hc := &container.HandlerComponent{
+		CronUsecase: mockComponent.CronUsecase,
+	}
+
+	h := handler.NewCronHandler(hc)
+
+	var testCases = []struct {
+		caseName     string
+		body         string
+		mockSetup    func()
+		expectedCode int
+		expectedBody string
+	}{
+		{
+			caseName: "CreateSchedule_Success",
+			body:     `{"name":"heartbeat","cron":"*/5 * * * *","timezone":"Europe/Paris","payload":{"at":"{{.ScheduledAt}}"}}`,
+			mockSetup: func() {
+				mockComponent.CronUsecase.On("CreateSchedule", mock.Anything, mock.MatchedBy(func(schedule *model.CronSchedule) bool {
+					return schedule.ClientID == "test-client" && schedule.Name == "heartbeat" && schedule.Expression == "*/5 * * * *" &&
+						schedule.Timezone == "Europe/Paris" && string(schedule.Payload) == `{"at":"{{.ScheduledAt}}"}` && schedule.Enabled
+				})).Return(&model.CronSchedule{ID: "sched-1", Name: "heartbeat", Enabled: true}, nil).Once()
+			},
+			expectedCode: http.StatusCreated,
+			expectedBody: `"schedule_id":"sched-1"`,
+		},
+		{
+			caseName: "CreateSchedule_Disabled",
+			body:     `{"name":"report","cron":"@hourly","payload":"report","enabled":false}`,
+			mockSetup: func() {
+				mockComponent.CronUsecase.On("CreateSchedule", mock.Anything, mock.MatchedBy(func(schedule *model.CronSchedule) bool {
+					return schedule.Name == "report" && !schedule.Enabled
+				})).Return(&model.CronSchedule{ID: "sched-2", Name: "report"}, nil).Once()
+			},
+			expectedCode: http.StatusCreated,
+			expectedBody: `"enabled":false`,
+		},
+		{
+			caseName:     "CreateSchedule_MissingPayload",
+			body:         `{"name":"heartbeat","cron":"@hourly"}`,
+			mockSetup:    func() {},
+			expectedCode: http.StatusBadRequest,
+			expectedBody: `"field":"payload"`,
+		},
+		{
+			caseName:     "CreateSchedule_MissingCron",
+			body:         `{"name":"heartbeat","payload":{}}`,
+			mockSetup:    func() {},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			caseName: "CreateSchedule_DuplicateName",
+			body:     `{"name":"heartbeat","cron":"@hourly","payload":{}}`,
+			mockSetup: func() {
+				mockComponent.CronUsecase.On("CreateSchedule", mock.Anything, mock.Anything).Return(nil, derrors.New(derrors.Duplicate, "a schedule named \"heartbeat\" already exists")).Once()
+			},
+			expectedCode: http.StatusConflict,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.caseName, func(t *testing.T) {
+			tc.mockSetup()
+
+			req := httptest.NewRequest(http.MethodPost, "/tenants/test-client/schedules", strings.NewReader(tc.body))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetParamNames("clientID")
+			c.SetParamValues("test-client")
+
+			err := h.CreateSchedule(c)
+			if err != nil {
+				t.Errorf("Handler returned error: %v", err)
+			}
+
+			assert.Equal(t, tc.expectedCode, rec.Code)
+			assert.Contains(t, rec.Body.String(), tc.expectedBody)
+		})
+	}
+}
+
+func TestDeleteScheduleHandler(t *testing.T) {
+	e := echo.New()
+	mockComponent := test.InitMockComponent(t)
+
+	hc := &container.HandlerComponent{
+		CronUsecase: mockComponent.CronUsecase,
+	}
+
+	h := handler.NewCronHandler(hc)
+
+	mockComponent.CronUsecase.On("DeleteSchedule", mock.Anything, "test-client", "sched-1").Return(nil).Once()
+	mockComponent.CronUsecase.On("DeleteSchedule", mock.Anything, "test-client", "sched-2").Return(derrors.New(derrors.NotFound, "schedule not found")).Once()
+
+	for scheduleID, expectedCode := range map[string]int{"sched-1": http.StatusOK, "sched-2": http.StatusNotFound} {
+		req := httptest.NewRequest(http.MethodDelete, "/tenants/test-client/schedules/"+scheduleID, nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetParamNames("clientID", "scheduleID")
+		c.SetParamValues("test-client", scheduleID)
+
+		err := h.DeleteSchedule(c)
+		if err != nil {
+			t.Errorf("Handler returned error: %v", err)
+		}
+
+		assert.Equal(t, expectedCode, rec.Code)
+	}
+}
@@ -0,0 +1,60 @@
+package handler
+
+import (
+	"net/http"
+
+	"tenant/internal/container"
+	"tenant/pkg/api"
+	"tenant/pkg/health"
+
+	"github.com/labstack/echo/v4"
+)
+
+type (
+	healthHandler struct {
+		health *health.Health
+	}
+
+	HealthHandler interface {
+		Liveness(c echo.Context) error
+		Readiness(c echo.Context) error
+	}
+)
+
+func NewHealthHandler(hc *container.HandlerComponent) HealthHandler {
+	return &healthHandler{health: hc.Health}
+}
+
+// Liveness reports that the process is running
+// Liveness
+// @Summary Liveness probe
+// @Description Reports that the process is alive without checking dependencies
+// @Tags health
+// @ID liveness
+// @Produce json
+// @Success 200 {object} map[string]string
+// @Router /healthz [get]
+func (h *healthHandler) Liveness(c echo.Context) error {
+	return api.ResponseOK(c, map[string]interface{}{
+		"status": health.StatusUp,
+	})
+}
+
+// Readiness checks every dependency and reports whether the service can take traffic
+// Readiness
+// @Summary Readiness probe
+// @Description Checks Postgres, RabbitMQ and the consumer registry, returns 503 when any of them is down
+// @Tags health
+// @ID readiness
+// @Produce json
+// @Success 200 {object} health.Report
+// @Failure 503 {object} health.Report
+// @Router /readyz [get]
+func (h *healthHandler) Readiness(c echo.Context) error {
+	report := h.health.Check(c.Request().Context())
+	if !report.Up() {
+		return api.Response(c, report, api.StatusError, api.StatusMessageServiceUnavailable, http.StatusServiceUnavailable)
+	}
+
+	return api.ResponseOK(c, report)
+}
@@ -0,0 +1,92 @@
+package handler_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"tenant/internal/api/http/handler"
+	"tenant/internal/container"
+	"tenant/pkg/health"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLivenessHandler(t *testing.T) {
+	e := echo.New()
+	hc := &container.HandlerComponent{
+		Health: health.New(time.Second),
+	}
+
+	h := handler.NewHealthHandler(hc)
+
+	req := httptest.NewRequest(http.MethodGet, "/healthz", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	err := h.Liveness(c)
+	if err != nil {
+		t.Errorf("Handler returned error: %v", err)
+	}
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"status":"up"`)
+}
+
+func TestReadinessHandler(t *testing.T) {
+	e := echo.New()
+
+	var testCases = []struct {
+		caseName     string
+		checkers     map[string]health.Checker
+		expectedCode int
+		expectedBody string
+	}{
+		{
+			caseName: "Readiness_AllUp",
+			checkers: map[string]health.Checker{
+				"postgres": health.CheckerFunc(func(context.Context) error { return nil }),
+				"rabbitmq": health.CheckerFunc(func(context.Context) error { return nil }),
+			},
+			expectedCode: http.StatusOK,
+			expectedBody: `"rabbitmq":{"status":"up"`,
+		},
+		{
+			caseName: "Readiness_BrokerDown",
+			checkers: map[string]health.Checker{
+				"postgres": health.CheckerFunc(func(context.Context) error { return nil }),
+				"rabbitmq": health.CheckerFunc(func(context.Context) error { return errors.New("rabbitmq connection is closed") }),
+			},
+			expectedCode: http.StatusServiceUnavailable,
+			expectedBody: `"error":"rabbitmq connection is closed"`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.caseName, func(t *testing.T) {
+			hc := &container.HandlerComponent{
+				Health: health.New(time.Second),
+			}
+			for name, checker := range tc.checkers {
+				hc.Health.Register(name, checker)
+			}
+
+			h := handler.NewHealthHandler(hc)
+
+			req := httptest.NewRequest(http.MethodGet, "/readyz", nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+
+			err := h.Readiness(c)
+			if err != nil {
+				t.Errorf("Handler returned error: %v", err)
+			}
+
+			assert.Equal(t, tc.expectedCode, rec.Code)
+			assert.Contains(t, rec.Body.String(), tc.expectedBody)
+		})
+	}
+}
@@ -0,0 +1,85 @@
+package handler
+
+import (
+	"time"
+
+	"tenant/internal/api/http/handler/request"
+	"tenant/internal/container"
+	"tenant/pkg/api"
+	"tenant/pkg/derrors"
+	"tenant/pkg/logger"
+
+	"github.com/labstack/echo/v4"
+	"github.com/sirupsen/logrus"
+)
+
+type (
+	logLevelHandler struct {
+		levels *logger.LevelController
+	}
+
+	LogLevelHandler interface {
+		GetLogLevel(c echo.Context) error
+		SetLogLevel(c echo.Context) error
+	}
+)
+
+func NewLogLevelHandler(hc *container.HandlerComponent) LogLevelHandler {
+	return &logLevelHandler{levels: hc.LogLevel}
+}
+
+// GetLogLevel returns the global log level and every active tenant override
+// Get Log Level
+// @Summary Get Log Level
+// @Description Get the global log level and active per tenant overrides
+// @Tags admin
+// @ID get-log-level
+// @Produce json
+// @Success 200 {object} logger.LevelStatus
+// @Router /admin/log-level [get]
+func (h *logLevelHandler) GetLogLevel(c echo.Context) error {
+	return api.ResponseOK(c, h.levels.Status())
+}
+
+// SetLogLevel changes the log level globally, or for one tenant when client_id is set
+// Set Log Level
+// @Summary Set Log Level
+// @Description Change the log level globally or for a single client ID, reverting after the optional ttl (e.g. "15m")
+// @Tags admin
+// @ID set-log-level
+// @Produce json
+// @Param level body request.SetLogLevelRequest true "log level payload"
+// @Success 200 {object} logger.LevelStatus
+// @Failure 400 {object} api.Problem
+// @Router /admin/log-level [put]
+func (h *logLevelHandler) SetLogLevel(c echo.Context) error {
+	var req request.SetLogLevelRequest
+	if err := c.Bind(&req); err != nil {
+		return api.RenderErrorResponse(c, c.Request(), err)
+	}
+
+	if err := c.Validate(req); err != nil {
+		return api.RenderErrorResponse(c, c.Request(), err)
+	}
+
+	level, err := logrus.ParseLevel(req.Level)
+	if err != nil {
+		return api.RenderErrorResponse(c, c.Request(), derrors.New(derrors.InvalidArgument, "level %q is invalid", req.Level))
+	}
+
+	var ttl time.Duration
+	if req.TTL != "" {
+		ttl, err = time.ParseDuration(req.TTL)
+		if err != nil || ttl < 0 {
+			return api.RenderErrorResponse(c, c.Request(), derrors.New(derrors.InvalidArgument, "ttl %q is invalid", req.TTL))
+		}
+	}
+
+	if req.ClientID != "" {
+		h.levels.SetClientLevel(req.ClientID, level, ttl)
+	} else {
+		h.levels.SetLevel(level, ttl)
+	}
+
+	return api.ResponseOK(c, h.levels.Status())
+}
@@ -0,0 +1,56 @@
+package handler
+
+import (
+	"tenant/internal/container"
+	"tenant/internal/usecase"
+	"tenant/pkg/api"
+	"tenant/pkg/derrors"
+
+	"github.com/labstack/echo/v4"
+)
+
+type (
+	messageHandler struct {
+		messageUsecase usecase.MessageUsecase
+	}
+
+	MessageHandler interface {
+		GetMessage(c echo.Context) error
+	}
+)
+
+func NewMessageHandler(hc *container.HandlerComponent) MessageHandler {
+	return &messageHandler{messageUsecase: hc.MessageUsecase}
+}
+
+// GetMessage returns the status of a message and its timeline
+// Get Message
+// @Summary Get Message
+// @Description Get the status of a published message and its status transitions
+// @Tags message
+// @ID get-message
+// @Produce json
+// @Param clientID path string true "clientID"
+// @Param messageID path string true "messageID"
+// @Success 200 {object} model.Message
+// @Failure 400 {object} api.Problem
+// @Failure 404 {object} api.Problem
+// @Failure 500 {object} api.Problem
+// @Router /tenants/{clientID}/messages/{messageID} [get]
+func (h *messageHandler) GetMessage(c echo.Context) error {
+	clientID := c.Param("clientID")
+	if clientID == "" {
+		return api.RenderErrorResponse(c, c.Request(), derrors.New(derrors.InvalidArgument, "client_id is required"))
+	}
+	messageID := c.Param("messageID")
+	if messageID == "" {
+		return api.RenderErrorResponse(c, c.Request(), derrors.New(derrors.InvalidArgument, "message_id is required"))
+	}
+
+	message, err := h.messageUsecase.GetMessage(c.Request().Context(), clientID, messageID)
+	if err != nil {
+		return api.RenderErrorResponse(c, c.Request(), err)
+	}
+
+	return api.ResponseOK(c, message)
+}
@@ -0,0 +1,89 @@
+package handler_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"tenant/internal/api/http/handler"
+	"tenant/internal/container"
+	"tenant/internal/model"
+	"tenant/internal/test"
+	"tenant/pkg/derrors"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestGetMessageHandler(t *testing.T) {
+	e := echo.New()
+	mockComponent := test.InitMockComponent(t)
+
+	hc := &container.HandlerComponent{
+		MessageUsecase: mockComponent.MessageUsecase,
+	}
+
+	h := handler.NewMessageHandler(hc)
+
+	var testCases = []struct {
+		caseName     string
+		messageID    string
+		mockSetup    func()
+		expectedCode int
+		expectedBody string
+	}{
+		{
+			caseName:  "GetMessage_Success",
+			messageID: "msg-1",
+			mockSetup: func() {
+				mockComponent.MessageUsecase.On("GetMessage", mock.Anything, "test-client", "msg-1").Return(&model.Message{
+					ID:       "msg-1",
+					ClientID: "test-client",
+					Status:   model.MessageProcessed,
+					Events: []*model.MessageEvent{
+						{Status: model.MessageAccepted},
+						{Status: model.MessagePublished},
+						{Status: model.MessageProcessed, Attempt: 1},
+					},
+				}, nil).Once()
+			},
+			expectedCode: http.StatusOK,
+			expectedBody: `"status":"processed"`,
+		},
+		{
+			caseName:  "GetMessage_NotFound",
+			messageID: "missing",
+			mockSetup: func() {
+				mockComponent.MessageUsecase.On("GetMessage", mock.Anything, "test-client", "missing").Return(nil, derrors.New(derrors.NotFound, "message not found")).Once()
+			},
+			expectedCode: http.StatusNotFound,
+			expectedBody: `"code":"not_found"`,
+		},
+		{
+			caseName:     "GetMessage_MissingID",
+			mockSetup:    func() {},
+			expectedCode: http.StatusBadRequest,
+			expectedBody: "message_id is required",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.caseName, func(t *testing.T) {
+			tc.mockSetup()
+
+			req := httptest.NewRequest(http.MethodGet, "/tenants/test-client/messages/"+tc.messageID, nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetParamNames("clientID", "messageID")
+			c.SetParamValues("test-client", tc.messageID)
+
+			err := h.GetMessage(c)
+			if err != nil {
+				t.Errorf("Handler returned error: %v", err)
+			}
+
+			assert.Equal(t, tc.expectedCode, rec.Code)
+			assert.Contains(t, rec.Body.String(), tc.expectedBody)
+		})
+	}
+}
@@ -0,0 +1,125 @@
+package handler
+
+import (
+	"strings"
+
+	"tenant/internal/api/http/handler/request"
+	"tenant/internal/container"
+	"tenant/internal/model"
+	"tenant/internal/usecase"
+	"tenant/pkg/api"
+
+	"github.com/labstack/echo/v4"
+)
+
+type (
+	processorHandler struct {
+		processorUsecase usecase.ProcessorUsecase
+	}
+
+	ProcessorHandler interface {
+		ListProcessorTypes(c echo.Context) error
+		GetProcessors(c echo.Context) error
+		SetProcessors(c echo.Context) error
+		ResetProcessors(c echo.Context) error
+	}
+)
+
+func NewProcessorHandler(hc *container.HandlerComponent) ProcessorHandler {
+	return &processorHandler{processorUsecase: hc.ProcessorUsecase}
+}
+
+// ListProcessorTypes lists the processor types tenants may select
+// List Processor Types
+// @Summary List Processor Types
+// @Description List the processor types tenants may select for their messages
+// @Tags processor
+// @ID list-processor-types
+// @Produce json
+// @Success 200 {array} string
+// @Router /processors [get]
+func (h *processorHandler) ListProcessorTypes(c echo.Context) error {
+	return api.ResponseOK(c, h.processorUsecase.ListProcessorTypes())
+}
+
+// GetProcessors returns the processor chain of a tenant
+// Get Processors
+// @Summary Get Processors
+// @Description Get the processors run, in order, on every message consumed for a tenant
+// @Tags processor
+// @ID get-processors
+// @Produce json
+// @Param clientID path string true "clientID"
+// @Success 200 {object} model.ProcessorChain
+// @Failure 404 {object} api.Problem
+// @Failure 500 {object} api.Problem
+// @Router /tenants/{clientID}/processors [get]
+func (h *processorHandler) GetProcessors(c echo.Context) error {
+	chain, err := h.processorUsecase.GetProcessors(c.Request().Context(), c.Param("clientID"))
+	if err != nil {
+		return api.RenderErrorResponse(c, c.Request(), err)
+	}
+
+	return api.ResponseOK(c, chain)
+}
+
+// SetProcessors replaces the processor chain of a tenant
+// Set Processors
+// @Summary Set Processors
+// @Description Replace the processors run, in order, on every message consumed for a tenant. A failed processor fails the message, which is retried from the first processor.
+// @Tags processor
+// @ID set-processors
+// @Accept json
+// @Produce json
+// @Param clientID path string true "clientID"
+// @Param processors body request.SetProcessorsRequest true "processors payload"
+// @Success 200 {object} model.ProcessorChain
+// @Failure 400 {object} api.Problem
+// @Failure 404 {object} api.Problem
+// @Failure 500 {object} api.Problem
+// @Router /tenants/{clientID}/processors [put]
+func (h *processorHandler) SetProcessors(c echo.Context) error {
+	var req request.SetProcessorsRequest
+	if err := c.Bind(&req); err != nil {
+		return api.RenderErrorResponse(c, c.Request(), err)
+	}
+
+	if len(req.Processors) == 0 {
+		return api.RenderErrorResponse(c, c.Request(), api.NewValidationError("processors", "at least one processor is required"))
+	}
+	specs := make([]model.ProcessorSpec, 0, len(req.Processors))
+	for _, processor := range req.Processors {
+		if strings.TrimSpace(processor.Type) == "" {
+			return api.RenderErrorResponse(c, c.Request(), api.NewValidationError("processors", "every processor needs a type"))
+		}
+		specs = append(specs, model.ProcessorSpec{Type: processor.Type, Options: processor.Options})
+	}
+
+	chain, err := h.processorUsecase.SetProcessors(c.Request().Context(), c.Param("clientID"), specs)
+	if err != nil {
+		return api.RenderErrorResponse(c, c.Request(), err)
+	}
+
+	return api.ResponseOK(c, chain)
+}
+
+// ResetProcessors moves a tenant back to the default processor chain
+// Reset Processors
+// @Summary Reset Processors
+// @Description Move a tenant back to the default processors, which deliver its messages to its webhooks
+// @Tags processor
+// @ID reset-processors
+// @Produce json
+// @Param clientID path string true "clientID"
+// @Success 200 {object} model.ProcessorChain
+// @Failure 404 {object} api.Problem
+// @Failure 500 {object} api.Problem
+// @Router /tenants/{clientID}/processors [delete]
+func (h *processorHandler) ResetProcessors(c echo.Context) error {
+	chain, err := h.processorUsecase.ResetProcessors(c.Request().Context(), c.Param("clientID"))
+	if err != nil {
+		return api.RenderErrorResponse(c, c.Request(), err)
+	}
+
+	return api.ResponseOK(c, chain)
+}
@@ -0,0 +1,115 @@
+package handler_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"tenant/internal/api/http/handler"
+	"tenant/internal/container"
+	"tenant/internal/model"
+	"tenant/internal/test"
+	"tenant/pkg/derrors"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestSetProcessorsHandler(t *testing.T) {
+	e := echo.New()
+	mockComponent := test.InitMockComponent(t)
+
+	hc := &container.HandlerComponent{
+		ProcessorUsecase: mockComponent.ProcessorUsecase,
+	}
+
+	h := handler.NewProcessorHandler(hc)
+
+	var testCases = []struct {
+		caseName     string
+		body         string
+		mockSetup    func()
+		expectedCode int
+		expectedBody string
+	}{
+		{
+			caseName: "SetProcessors_Success",
+			body:     `{"processors":[{"type":"postgres"},{"type":"forward","options":{"client_id":"other-client"}}]}`,
+			mockSetup: func() {
+				specs := []model.ProcessorSpec{
+					{Type: model.ProcessorPostgres},
+					{Type: model.ProcessorForward, Options: json.RawMessage(`{"client_id":"other-client"}`)},
+				}
+				mockComponent.ProcessorUsecase.On("SetProcessors", mock.Anything, "test-client", specs).Return(&model.ProcessorChain{ClientID: "test-client", Processors: specs}, nil).Once()
+			},
+			expectedCode: http.StatusOK,
+			expectedBody: `"options":{"client_id":"other-client"}`,
+		},
+		{
+			caseName:     "SetProcessors_Empty",
+			body:         `{"processors":[]}`,
+			mockSetup:    func() {},
+			expectedCode: http.StatusBadRequest,
+			expectedBody: `"field":"processors"`,
+		},
+		{
+			caseName: "SetProcessors_UnknownType",
+			body:     `{"processors":[{"type":"kafka"}]}`,
+			mockSetup: func() {
+				mockComponent.ProcessorUsecase.On("SetProcessors", mock.Anything, "test-client", mock.Anything).Return(nil, derrors.New(derrors.InvalidArgument, `processors[0]: unknown type "kafka"`)).Once()
+			},
+			expectedCode: http.StatusBadRequest,
+			expectedBody: "unknown type",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.caseName, func(t *testing.T) {
+			tc.mockSetup()
+
+			req := httptest.NewRequest(http.MethodPut, "/tenants/test-client/processors", strings.NewReader(tc.body))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetParamNames("clientID")
+			c.SetParamValues("test-client")
+
+			err := h.SetProcessors(c)
+			if err != nil {
+				t.Errorf("Handler returned error: %v", err)
+			}
+
+			assert.Equal(t, tc.expectedCode, rec.Code)
+			assert.Contains(t, rec.Body.String(), tc.expectedBody)
+		})
+	}
+}
+
+func TestGetProcessorsHandler(t *testing.T) {
+	e := echo.New()
+	mockComponent := test.InitMockComponent(t)
+
+	hc := &container.HandlerComponent{
+		ProcessorUsecase: mockComponent.ProcessorUsecase,
+	}
+
+	h := handler.NewProcessorHandler(hc)
+
+	mockComponent.ProcessorUsecase.On("GetProcessors", mock.Anything, "test-client").Return(&model.ProcessorChain{
+		ClientID:   "test-client",
+		Processors: []model.ProcessorSpec{{Type: model.ProcessorWebhook}},
+		Default:    true,
+	}, nil).Once()
+
+	req := httptest.NewRequest(http.MethodGet, "/tenants/test-client/processors", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("clientID")
+	c.SetParamValues("test-client")
+
+	assert.Nil(t, h.GetProcessors(c))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"processors":[{"type":"webhook"}],"default":true`)
+}
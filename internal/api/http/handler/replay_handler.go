@@ -0,0 +1,126 @@
+package handler
+
+import (
+	"net/http"
+	"time"
+
+	"tenant/internal/api/http/handler/request"
+	"tenant/internal/container"
+	"tenant/internal/model"
+	"tenant/internal/usecase"
+	"tenant/pkg/api"
+
+	"github.com/labstack/echo/v4"
+)
+
+type (
+	replayHandler struct {
+		replayUsecase usecase.ReplayUsecase
+	}
+
+	ReplayHandler interface {
+		CreateReplay(c echo.Context) error
+		GetReplay(c echo.Context) error
+		CancelReplay(c echo.Context) error
+	}
+)
+
+func NewReplayHandler(hc *container.HandlerComponent) ReplayHandler {
+	return &replayHandler{replayUsecase: hc.ReplayUsecase}
+}
+
+// CreateReplay queues the replay of archived payloads of a tenant
+// Create Replay
+// @Summary Create Replay
+// @Description Republish archived payloads of a tenant to its process queue, oldest first, as a background job. Select the payloads with a time range, a list of message IDs or both. Replayed messages get new message IDs and the x-replay-of and x-replay-job headers.
+// @Tags message
+// @ID create-replay
+// @Accept json
+// @Produce json
+// @Param clientID path string true "clientID"
+// @Param replay body request.ReplayRequest true "replay payload"
+// @Success 202 {object} model.ReplayJob
+// @Failure 400 {object} api.Problem
+// @Failure 404 {object} api.Problem
+// @Failure 500 {object} api.Problem
+// @Router /tenants/{clientID}/replay [post]
+func (h *replayHandler) CreateReplay(c echo.Context) error {
+	var req request.ReplayRequest
+	if err := c.Bind(&req); err != nil {
+		return api.RenderErrorResponse(c, c.Request(), err)
+	}
+
+	job := &model.ReplayJob{ClientID: c.Param("clientID"), MessageIDs: req.MessageIDs, Rate: req.Rate}
+	for name, value := range map[string]string{"from": req.From, "to": req.To} {
+		if value == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return api.RenderErrorResponse(c, c.Request(), api.NewValidationError(name, "must be an RFC 3339 time, such as \"2026-10-19T12:00:00Z\""))
+		}
+		if name == "from" {
+			job.From = &t
+		} else {
+			job.To = &t
+		}
+	}
+	if job.From == nil && len(job.MessageIDs) == 0 {
+		return api.RenderErrorResponse(c, c.Request(), api.NewValidationError("from", "from or message_ids is required"))
+	}
+	if req.Rate < 0 {
+		return api.RenderErrorResponse(c, c.Request(), api.NewValidationError("rate", "must be positive"))
+	}
+
+	job, err := h.replayUsecase.CreateReplay(c.Request().Context(), job)
+	if err != nil {
+		return api.RenderErrorResponse(c, c.Request(), err)
+	}
+
+	return api.ResponseSuccess(c, job, "replay queued", http.StatusAccepted)
+}
+
+// GetReplay returns a replay job and its progress
+// Get Replay
+// @Summary Get Replay
+// @Description Get the status and progress of a replay job
+// @Tags message
+// @ID get-replay
+// @Produce json
+// @Param clientID path string true "clientID"
+// @Param jobID path string true "jobID"
+// @Success 200 {object} model.ReplayJob
+// @Failure 404 {object} api.Problem
+// @Failure 500 {object} api.Problem
+// @Router /tenants/{clientID}/replay/{jobID} [get]
+func (h *replayHandler) GetReplay(c echo.Context) error {
+	job, err := h.replayUsecase.GetReplay(c.Request().Context(), c.Param("clientID"), c.Param("jobID"))
+	if err != nil {
+		return api.RenderErrorResponse(c, c.Request(), err)
+	}
+
+	return api.ResponseOK(c, job)
+}
+
+// CancelReplay stops a pending or running replay job
+// Cancel Replay
+// @Summary Cancel Replay
+// @Description Stop a pending or running replay job, the messages already replayed stay published
+// @Tags message
+// @ID cancel-replay
+// @Produce json
+// @Param clientID path string true "clientID"
+// @Param jobID path string true "jobID"
+// @Success 200 {object} model.ReplayJob
+// @Failure 404 {object} api.Problem
+// @Failure 409 {object} api.Problem
+// @Failure 500 {object} api.Problem
+// @Router /tenants/{clientID}/replay/{jobID} [delete]
+func (h *replayHandler) CancelReplay(c echo.Context) error {
+	job, err := h.replayUsecase.CancelReplay(c.Request().Context(), c.Param("clientID"), c.Param("jobID"))
+	if err != nil {
+		return api.RenderErrorResponse(c, c.Request(), err)
+	}
+
+	return api.ResponseOK(c, job)
+}
@@ -0,0 +1,133 @@
+package handler_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"tenant/internal/api/http/handler"
+	"tenant/internal/container"
+	"tenant/internal/model"
+	"tenant/internal/test"
+	"tenant/pkg/derrors"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestCreateReplayHandler(t *testing.T) {
+	e := echo.New()
+	mockComponent := test.InitMockComponent(t)
+
+	hc := &container.HandlerComponent{
+		ReplayUsecase: mockComponent.ReplayUsecase,
+	}
+
+	h := handler.NewReplayHandler(hc)
+
+	var testCases = []struct {
+		caseName     string
+		body         string
+		mockSetup    func()
+		expectedCode int
+		expectedBody string
+	}{
+		{
+			caseName: "CreateReplay_TimeRange",
+			body:     `{"from":"2026-10-01T00:00:00Z","to":"2026-10-02T00:00:00Z","rate":50}`,
+			mockSetup: func() {
+				mockComponent.ReplayUsecase.On("CreateReplay", mock.Anything, mock.MatchedBy(func(job *model.ReplayJob) bool {
+					return job.ClientID == "test-client" && job.Rate == 50 &&
+						job.From.Equal(time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)) && job.To.Equal(time.Date(2026, 10, 2, 0, 0, 0, 0, time.UTC))
+				})).Return(&model.ReplayJob{ID: "job-1", Status: model.ReplayPending, Total: 42}, nil).Once()
+			},
+			expectedCode: http.StatusAccepted,
+			expectedBody: `"job_id":"job-1"`,
+		},
+		{
+			caseName: "CreateReplay_MessageIDs",
+			body:     `{"message_ids":["msg-1","msg-2"]}`,
+			mockSetup: func() {
+				mockComponent.ReplayUsecase.On("CreateReplay", mock.Anything, mock.MatchedBy(func(job *model.ReplayJob) bool {
+					return job.From == nil && len(job.MessageIDs) == 2
+				})).Return(&model.ReplayJob{ID: "job-2", Status: model.ReplayPending, Total: 2}, nil).Once()
+			},
+			expectedCode: http.StatusAccepted,
+			expectedBody: `"total":2`,
+		},
+		{
+			caseName:     "CreateReplay_NoSelection",
+			body:         `{"rate":10}`,
+			mockSetup:    func() {},
+			expectedCode: http.StatusBadRequest,
+			expectedBody: `"field":"from"`,
+		},
+		{
+			caseName:     "CreateReplay_InvalidTime",
+			body:         `{"from":"last week"}`,
+			mockSetup:    func() {},
+			expectedCode: http.StatusBadRequest,
+			expectedBody: `"field":"from"`,
+		},
+		{
+			caseName: "CreateReplay_TenantNotFound",
+			body:     `{"message_ids":["msg-1"]}`,
+			mockSetup: func() {
+				mockComponent.ReplayUsecase.On("CreateReplay", mock.Anything, mock.Anything).Return(nil, derrors.New(derrors.NotFound, "tenant not found")).Once()
+			},
+			expectedCode: http.StatusNotFound,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.caseName, func(t *testing.T) {
+			tc.mockSetup()
+
+			req := httptest.NewRequest(http.MethodPost, "/tenants/test-client/replay", strings.NewReader(tc.body))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetParamNames("clientID")
+			c.SetParamValues("test-client")
+
+			err := h.CreateReplay(c)
+			if err != nil {
+				t.Errorf("Handler returned error: %v", err)
+			}
+
+			assert.Equal(t, tc.expectedCode, rec.Code)
+			assert.Contains(t, rec.Body.String(), tc.expectedBody)
+		})
+	}
+}
+
+func TestCancelReplayHandler(t *testing.T) {
+	e := echo.New()
+	mockComponent := test.InitMockComponent(t)
+
+	hc := &container.HandlerComponent{
+		ReplayUsecase: mockComponent.ReplayUsecase,
+	}
+
+	h := handler.NewReplayHandler(hc)
+
+	mockComponent.ReplayUsecase.On("CancelReplay", mock.Anything, "test-client", "job-1").Return(&model.ReplayJob{ID: "job-1", Status: model.ReplayCancelled}, nil).Once()
+	mockComponent.ReplayUsecase.On("CancelReplay", mock.Anything, "test-client", "job-2").Return(nil, derrors.New(derrors.Conflict, "replay job is already completed")).Once()
+
+	for jobID, expectedCode := range map[string]int{"job-1": http.StatusOK, "job-2": http.StatusConflict} {
+		req := httptest.NewRequest(http.MethodDelete, "/tenants/test-client/replay/"+jobID, nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetParamNames("clientID", "jobID")
+		c.SetParamValues("test-client", jobID)
+
+		err := h.CancelReplay(c)
+		if err != nil {
+			t.Errorf("Handler returned error: %v", err)
+		}
+
+		assert.Equal(t, expectedCode, rec.Code)
+	}
+}
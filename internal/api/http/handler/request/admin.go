@@ -0,0 +1,7 @@
+package request
+
+type SetLogLevelRequest struct {
+	Level    string `json:"level" valid:"required"`
+	ClientID string `json:"client_id"`
+	TTL      string `json:"ttl"`
+}
@@ -0,0 +1,19 @@
+package request
+
+type SetRetentionRequest struct {
+	// Retention is a duration such as "720h"
+	Retention string `json:"retention" valid:"required"`
+}
+
+type ReplayRequest struct {
+	// From and To are RFC 3339 times bounding when the messages were
+	// published, To is optional
+	From string `json:"from"`
+	To   string `json:"to"`
+
+	// MessageIDs replays only the listed messages
+	MessageIDs []string `json:"message_ids"`
+
+	// Rate is the messages per second, the configured rate when zero
+	Rate float64 `json:"rate"`
+}
@@ -0,0 +1,18 @@
+package request
+
+type CronScheduleRequest struct {
+	Name string `json:"name" valid:"required,stringlength(1|255)"`
+
+	// Cron is a standard five field expression or a descriptor such as
+	// "@hourly" or "@every 15m"
+	Cron     string `json:"cron" valid:"required"`
+	Timezone string `json:"timezone"`
+
+	// Payload is a JSON value whose strings may use {{.ScheduleID}},
+	// {{.Name}}, {{.ClientID}} and {{.ScheduledAt}}
+	Payload interface{}       `json:"payload"`
+	Headers map[string]string `json:"headers"`
+
+	// Enabled defaults to true
+	Enabled *bool `json:"enabled"`
+}
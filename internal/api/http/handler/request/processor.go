@@ -0,0 +1,13 @@
+package request
+
+import "encoding/json"
+
+type SetProcessorsRequest struct {
+	// Processors run in order on every consumed message
+	Processors []ProcessorRequest `json:"processors"`
+}
+
+type ProcessorRequest struct {
+	Type    string          `json:"type" example:"webhook"`
+	Options json.RawMessage `json:"options" swaggertype:"object"`
+}
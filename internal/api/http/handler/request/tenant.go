@@ -1,9 +1,25 @@
 package request
 
 type CreateTenantRequest struct {
-	Name string `json:"name" validate:"required"`
+	Name string `json:"name" valid:"required"`
 }
 
 type ProcessPayloadRequest struct {
-	Payload interface{} `json:"payload" validate:"required"`
+	// Payload may be any JSON value, so its presence is checked by the handler
+	// instead of the validator
+	Payload interface{} `json:"payload"`
+
+	Headers       map[string]string `json:"headers"`
+	CorrelationID string            `json:"correlation_id" valid:"stringlength(1|255)"`
+	ContentType   string            `json:"content_type" valid:"stringlength(1|255)"`
+	Priority      int               `json:"priority" valid:"range(0|9)"`
+
+	// Expiration is a duration such as "30s", the message is discarded when
+	// it is not consumed in time
+	Expiration string `json:"expiration"`
+
+	// DeliverAt is an RFC 3339 time and Delay a duration such as "10m", at
+	// most one of them delays the publication of the payload
+	DeliverAt string `json:"deliver_at"`
+	Delay     string `json:"delay"`
 }
@@ -0,0 +1,12 @@
+package request
+
+type WebhookRequest struct {
+	URL string `json:"url" valid:"required"`
+
+	// Secret signs the deliveries, one is generated on creation when empty
+	// and the current one is kept on update
+	Secret string `json:"secret" valid:"stringlength(16|255)"`
+
+	// Enabled defaults to true
+	Enabled *bool `json:"enabled"`
+}
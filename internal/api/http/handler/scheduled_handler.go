@@ -0,0 +1,86 @@
+package handler
+
+import (
+	"strconv"
+
+	"tenant/internal/container"
+	"tenant/internal/usecase"
+	"tenant/pkg/api"
+
+	"github.com/labstack/echo/v4"
+)
+
+type (
+	scheduledHandler struct {
+		scheduledUsecase usecase.ScheduledUsecase
+	}
+
+	ScheduledHandler interface {
+		ListScheduled(c echo.Context) error
+		CancelScheduled(c echo.Context) error
+	}
+)
+
+// maxScheduledListLimit bounds the scheduled payloads listed at once
+const maxScheduledListLimit = 500
+
+func NewScheduledHandler(hc *container.HandlerComponent) ScheduledHandler {
+	return &scheduledHandler{scheduledUsecase: hc.ScheduledUsecase}
+}
+
+// ListScheduled lists the payloads of a tenant waiting for their delivery time
+// List Scheduled
+// @Summary List Scheduled
+// @Description List the payloads of a tenant waiting for their delivery time, earliest first
+// @Tags message
+// @ID list-scheduled
+// @Produce json
+// @Param clientID path string true "clientID"
+// @Param limit query int false "number of payloads, at most 500" default(50)
+// @Success 200 {array} model.ScheduledMessage
+// @Failure 400 {object} api.Problem
+// @Failure 404 {object} api.Problem
+// @Failure 500 {object} api.Problem
+// @Router /tenants/{clientID}/scheduled [get]
+func (h *scheduledHandler) ListScheduled(c echo.Context) error {
+	var limit int
+	if value := c.QueryParam("limit"); value != "" {
+		var err error
+		limit, err = strconv.Atoi(value)
+		if err != nil || limit < 1 || limit > maxScheduledListLimit {
+			return api.RenderErrorResponse(c, c.Request(), api.NewValidationError("limit", "must be between 1 and "+strconv.Itoa(maxScheduledListLimit)))
+		}
+	}
+
+	messages, err := h.scheduledUsecase.ListScheduled(c.Request().Context(), c.Param("clientID"), limit)
+	if err != nil {
+		return api.RenderErrorResponse(c, c.Request(), err)
+	}
+
+	return api.ResponseOK(c, messages)
+}
+
+// CancelScheduled drops a payload before its delivery time
+// Cancel Scheduled
+// @Summary Cancel Scheduled
+// @Description Drop a scheduled payload before its delivery time, its message status becomes cancelled
+// @Tags message
+// @ID cancel-scheduled
+// @Produce json
+// @Param clientID path string true "clientID"
+// @Param messageID path string true "messageID"
+// @Success 200 {object} map[string]string
+// @Failure 404 {object} api.Problem
+// @Failure 500 {object} api.Problem
+// @Router /tenants/{clientID}/scheduled/{messageID} [delete]
+func (h *scheduledHandler) CancelScheduled(c echo.Context) error {
+	messageID := c.Param("messageID")
+	if err := h.scheduledUsecase.CancelScheduled(c.Request().Context(), c.Param("clientID"), messageID); err != nil {
+		return api.RenderErrorResponse(c, c.Request(), err)
+	}
+
+	return api.ResponseOK(c, map[string]interface{}{
+		"message":    "Scheduled payload cancelled",
+		"message_id": messageID,
+	})
+}
@@ -0,0 +1,104 @@
+package handler_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"tenant/internal/api/http/handler"
+	"tenant/internal/container"
+	"tenant/internal/model"
+	"tenant/internal/test"
+	"tenant/pkg/derrors"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestListScheduledHandler(t *testing.T) {
+	e := echo.New()
+	mockComponent := test.InitMockComponent(t)
+
+	hc := &container.HandlerComponent{
+		ScheduledUsecase: mockComponent.ScheduledUsecase,
+	}
+
+	h := handler.NewScheduledHandler(hc)
+
+	var testCases = []struct {
+		caseName     string
+		query        string
+		mockSetup    func()
+		expectedCode int
+		expectedBody string
+	}{
+		{
+			caseName: "ListScheduled_Success",
+			query:    "limit=10",
+			mockSetup: func() {
+				mockComponent.ScheduledUsecase.On("ListScheduled", mock.Anything, "test-client", 10).Return([]*model.ScheduledMessage{
+					{MessageID: "msg-1", Payload: []byte(`{"a":1}`), DeliverAt: time.Date(2026, 10, 20, 0, 0, 0, 0, time.UTC)},
+				}, nil).Once()
+			},
+			expectedCode: http.StatusOK,
+			expectedBody: `"deliver_at":"2026-10-20T00:00:00Z"`,
+		},
+		{
+			caseName:     "ListScheduled_InvalidLimit",
+			query:        "limit=0",
+			mockSetup:    func() {},
+			expectedCode: http.StatusBadRequest,
+			expectedBody: `"field":"limit"`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.caseName, func(t *testing.T) {
+			tc.mockSetup()
+
+			req := httptest.NewRequest(http.MethodGet, "/tenants/test-client/scheduled?"+tc.query, nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetParamNames("clientID")
+			c.SetParamValues("test-client")
+
+			err := h.ListScheduled(c)
+			if err != nil {
+				t.Errorf("Handler returned error: %v", err)
+			}
+
+			assert.Equal(t, tc.expectedCode, rec.Code)
+			assert.Contains(t, rec.Body.String(), tc.expectedBody)
+		})
+	}
+}
+
+func TestCancelScheduledHandler(t *testing.T) {
+	e := echo.New()
+	mockComponent := test.InitMockComponent(t)
+
+	hc := &container.HandlerComponent{
+		ScheduledUsecase: mockComponent.ScheduledUsecase,
+	}
+
+	h := handler.NewScheduledHandler(hc)
+
+	mockComponent.ScheduledUsecase.On("CancelScheduled", mock.Anything, "test-client", "msg-1").Return(nil).Once()
+	mockComponent.ScheduledUsecase.On("CancelScheduled", mock.Anything, "test-client", "msg-2").Return(derrors.New(derrors.NotFound, "scheduled message not found")).Once()
+
+	for messageID, expectedCode := range map[string]int{"msg-1": http.StatusOK, "msg-2": http.StatusNotFound} {
+		req := httptest.NewRequest(http.MethodDelete, "/tenants/test-client/scheduled/"+messageID, nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetParamNames("clientID", "messageID")
+		c.SetParamValues("test-client", messageID)
+
+		err := h.CancelScheduled(c)
+		if err != nil {
+			t.Errorf("Handler returned error: %v", err)
+		}
+
+		assert.Equal(t, expectedCode, rec.Code)
+	}
+}
@@ -1,7 +1,15 @@
 package handler
 
 import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"time"
+
 	"tenant/internal/container"
+	"tenant/internal/model"
 	"tenant/pkg/api"
 	"tenant/pkg/derrors"
 
@@ -14,17 +22,36 @@ import (
 type (
 	tenantHandler struct {
 		tenantUsecase usecase.TenantUsecase
+		maxBatchSize  int
 	}
 
 	TenantHandler interface {
 		CreateTenant(c echo.Context) error
 		DeleteTenant(c echo.Context) error
 		ProcessPayload(c echo.Context) error
+		ProcessBatch(c echo.Context) error
 	}
 )
 
+const (
+	defaultMaxBatchSize = 1000
+
+	// ndjsonChunkSize is how many NDJSON lines are published at once, so a
+	// streamed body is published while it is still being read
+	ndjsonChunkSize = 500
+
+	// maxNDJSONLine bounds a single NDJSON payload
+	maxNDJSONLine = 1 << 20
+
+	maxIdempotencyKey = 255
+)
+
 func NewTenantHandler(hc *container.HandlerComponent) TenantHandler {
-	return &tenantHandler{tenantUsecase: hc.TenantUsecase}
+	maxBatchSize := defaultMaxBatchSize
+	if hc.Config != nil && hc.Config.Process.MaxBatchSize > 0 {
+		maxBatchSize = hc.Config.Process.MaxBatchSize
+	}
+	return &tenantHandler{tenantUsecase: hc.TenantUsecase, maxBatchSize: maxBatchSize}
 }
 
 // CreateTenant handles tenant creation requests
@@ -36,6 +63,8 @@ func NewTenantHandler(hc *container.HandlerComponent) TenantHandler {
 // @Produce json
 // @Param user body request.CreateTenantRequest true "create tenant payload"
 // @Success 200 {object} map[string]string
+// @Failure 400 {object} api.Problem
+// @Failure 500 {object} api.Problem
 // @Router /tenant [post]
 func (h *tenantHandler) CreateTenant(c echo.Context) error {
 	var req request.CreateTenantRequest
@@ -68,6 +97,9 @@ func (h *tenantHandler) CreateTenant(c echo.Context) error {
 // @Produce json
 // @Param clientID path string true "clientID"
 // @Success 200 {object} map[string]string
+// @Failure 400 {object} api.Problem
+// @Failure 404 {object} api.Problem
+// @Failure 500 {object} api.Problem
 // @Router /tenant/{clientID} [delete]
 func (h *tenantHandler) DeleteTenant(c echo.Context) error {
 	clientID := c.Param("clientID")
@@ -89,13 +121,18 @@ func (h *tenantHandler) DeleteTenant(c echo.Context) error {
 // ProcessPayload handles requests to publish payload to a tenant's RabbitMQ queue
 // Process Tenant
 // @Summary Process Tenant
-// @Description Process Tenant
+// @Description Publish a payload to the queue of a tenant. With deliver_at or delay the payload is stored and published by a worker once due.
 // @Tags tenant
 // @ID process-tenant
 // @Produce json
 // @Param clientID path string true "clientID"
 // @Param user body request.ProcessPayloadRequest true "process tenant payload"
-// @Success 200 {object} map[string]string
+// @Param Idempotency-Key header string false "retries with the same key return the original message ID without publishing again"
+// @Success 200 {object} map[string]string "message, message_id and deliver_at when scheduled"
+// @Failure 400 {object} api.Problem
+// @Failure 404 {object} api.Problem
+// @Failure 409 {object} api.Problem
+// @Failure 500 {object} api.Problem
 // @Router /tenant/{clientID}/process [post]
 func (h *tenantHandler) ProcessPayload(c echo.Context) error {
 	var req request.ProcessPayloadRequest
@@ -112,13 +149,265 @@ func (h *tenantHandler) ProcessPayload(c echo.Context) error {
 		return api.RenderErrorResponse(c, c.Request(), err)
 	}
 
+	if req.Payload == nil {
+		return api.RenderErrorResponse(c, c.Request(), api.NewValidationError("payload", "non zero value required"))
+	}
+
+	payload := &model.Payload{
+		Body:           req.Payload,
+		Headers:        req.Headers,
+		CorrelationID:  req.CorrelationID,
+		ContentType:    req.ContentType,
+		Priority:       uint8(req.Priority),
+		IdempotencyKey: c.Request().Header.Get(api.HeaderIdempotencyKey),
+	}
+	if len(payload.IdempotencyKey) > maxIdempotencyKey {
+		return api.RenderErrorResponse(c, c.Request(), api.NewValidationError(api.HeaderIdempotencyKey, fmt.Sprintf("must be at most %d characters", maxIdempotencyKey)))
+	}
+	if req.Expiration != "" {
+		expiration, err := time.ParseDuration(req.Expiration)
+		if err != nil || expiration < time.Millisecond {
+			return api.RenderErrorResponse(c, c.Request(), api.NewValidationError("expiration", "must be a duration of at least 1ms, such as \"30s\""))
+		}
+		payload.Expiration = expiration
+	}
+	switch {
+	case req.DeliverAt != "" && req.Delay != "":
+		return api.RenderErrorResponse(c, c.Request(), api.NewValidationError("delay", "cannot be combined with deliver_at"))
+	case req.DeliverAt != "":
+		deliverAt, err := time.Parse(time.RFC3339, req.DeliverAt)
+		if err != nil {
+			return api.RenderErrorResponse(c, c.Request(), api.NewValidationError("deliver_at", "must be an RFC 3339 time, such as \"2026-10-19T12:00:00Z\""))
+		}
+		payload.DeliverAt = deliverAt
+	case req.Delay != "":
+		delay, err := time.ParseDuration(req.Delay)
+		if err != nil || delay < 0 {
+			return api.RenderErrorResponse(c, c.Request(), api.NewValidationError("delay", "must be a positive duration, such as \"10m\""))
+		}
+		payload.DeliverAt = time.Now().Add(delay)
+	}
+
 	ctx := c.Request().Context()
-	err := h.tenantUsecase.ProcessPayload(ctx, clientID, req.Payload)
+	messageID, err := h.tenantUsecase.ProcessPayload(ctx, clientID, payload)
 	if err != nil {
 		return api.RenderErrorResponse(c, c.Request(), err)
 	}
 
+	if payload.DeliverAt.After(time.Now()) {
+		return api.ResponseOK(c, map[string]interface{}{
+			"message":    "Payload scheduled successfully",
+			"message_id": messageID,
+			"deliver_at": payload.DeliverAt.UTC().Format(time.RFC3339),
+		})
+	}
 	return api.ResponseOK(c, map[string]interface{}{
-		"message": "Payload processed successfully",
+		"message":    "Payload processed successfully",
+		"message_id": messageID,
+	})
+}
+
+// ProcessBatch handles requests to publish many payloads to a tenant's RabbitMQ queue
+// Process Tenant Batch
+// @Summary Process Tenant Batch
+// @Description Publish a JSON array of payloads, or one payload per line with Content-Type application/x-ndjson. Every payload gets its own message ID, and payloads that fail are reported per item without failing the batch.
+// @Tags tenant
+// @ID process-tenant-batch
+// @Accept json
+// @Accept application/x-ndjson
+// @Produce json
+// @Param clientID path string true "clientID"
+// @Param payloads body []interface{} true "payloads"
+// @Success 200 {object} model.BatchResult
+// @Failure 400 {object} api.Problem
+// @Failure 404 {object} api.Problem
+// @Failure 413 {object} api.Problem
+// @Failure 500 {object} api.Problem
+// @Router /tenant/{clientID}/process/batch [post]
+func (h *tenantHandler) ProcessBatch(c echo.Context) error {
+	clientID := c.Param("clientID")
+	if clientID == "" {
+		return api.RenderErrorResponse(c, c.Request(), derrors.New(derrors.InvalidArgument, "client_id is required"))
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(c.Request().Header.Get(echo.HeaderContentType))
+	if mediaType == api.NDJSONHeader {
+		return h.processNDJSON(c, clientID)
+	}
+
+	var payloads []interface{}
+	if err := c.Bind(&payloads); err != nil {
+		return api.RenderErrorResponse(c, c.Request(), err)
+	}
+	if len(payloads) == 0 {
+		return api.RenderErrorResponse(c, c.Request(), api.NewValidationError("payloads", "at least one payload is required"))
+	}
+	if len(payloads) > h.maxBatchSize {
+		return api.RenderErrorResponse(c, c.Request(), derrors.New(derrors.PayloadTooLarge, "batch of %d payloads exceeds the limit of %d", len(payloads), h.maxBatchSize))
+	}
+
+	items := make([]batchItem, len(payloads))
+	for i, payload := range payloads {
+		items[i] = batchItem{index: i, payload: payload}
+	}
+
+	result := &model.BatchResult{Results: make([]*model.BatchItemResult, 0, len(items))}
+	if err := h.publishBatch(c, clientID, items, result); err != nil {
+		return api.RenderErrorResponse(c, c.Request(), err)
+	}
+
+	return api.ResponseOK(c, result)
+}
+
+// processNDJSON publishes an NDJSON body in chunks while reading it. Lines
+// that are not valid JSON are reported as failed items. Once a chunk was
+// handled, a failure no longer fails the request: the items of the failed
+// chunk and the unread lines are reported as failed with the earlier results.
+func (h *tenantHandler) processNDJSON(c echo.Context, clientID string) error {
+	scanner := bufio.NewScanner(c.Request().Body)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxNDJSONLine)
+
+	result := &model.BatchResult{Results: []*model.BatchItemResult{}}
+	chunk := make([]batchItem, 0, ndjsonChunkSize)
+	index := 0
+
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		if index == h.maxBatchSize {
+			// Keep what was published, but do not read an unbounded body
+			result.Add(&model.BatchItemResult{
+				Index:  index,
+				Status: model.BatchItemFailed,
+				Code:   derrors.PayloadTooLarge.String(),
+				Error:  fmt.Sprintf("batch exceeds the limit of %d payloads, the remaining lines were not read", h.maxBatchSize),
+			})
+			break
+		}
+
+		item := batchItem{index: index}
+		if err := json.Unmarshal(line, &item.payload); err != nil {
+			item.err = fmt.Errorf("line %d is not valid JSON: %w", index+1, err)
+		}
+		chunk = append(chunk, item)
+		index++
+
+		if len(chunk) == ndjsonChunkSize {
+			if err := h.publishBatch(c, clientID, chunk, result); err != nil {
+				if len(result.Results) == 0 {
+					return api.RenderErrorResponse(c, c.Request(), err)
+				}
+				failBatchItems(chunk, err, result)
+				if hasMoreLines(scanner) {
+					failRemainingLines(index, err, result)
+				}
+				return api.ResponseOK(c, result)
+			}
+			chunk = chunk[:0]
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		err = derrors.WrapStack(err, derrors.InvalidArgument, "failed to read NDJSON body")
+		if len(result.Results) == 0 {
+			return api.RenderErrorResponse(c, c.Request(), err)
+		}
+		failBatchItems(chunk, err, result)
+		failRemainingLines(index, err, result)
+		return api.ResponseOK(c, result)
+	}
+
+	if len(chunk) > 0 {
+		if err := h.publishBatch(c, clientID, chunk, result); err != nil {
+			if len(result.Results) == 0 {
+				return api.RenderErrorResponse(c, c.Request(), err)
+			}
+			failBatchItems(chunk, err, result)
+		}
+	}
+	if index == 0 {
+		return api.RenderErrorResponse(c, c.Request(), api.NewValidationError("payloads", "at least one payload is required"))
+	}
+
+	return api.ResponseOK(c, result)
+}
+
+// failBatchItems reports every item of a chunk that could not be published,
+// invalid items keep their own error
+func failBatchItems(items []batchItem, err error, result *model.BatchResult) {
+	for _, item := range items {
+		switch {
+		case item.err != nil:
+			result.Add(&model.BatchItemResult{Index: item.index, Status: model.BatchItemFailed, Code: derrors.InvalidArgument.String(), Error: item.err.Error()})
+		case item.payload == nil:
+			result.Add(&model.BatchItemResult{Index: item.index, Status: model.BatchItemFailed, Code: derrors.InvalidArgument.String(), Error: "payload is required"})
+		default:
+			result.Add(&model.BatchItemResult{Index: item.index, Status: model.BatchItemFailed, Code: derrors.CodeOf(err).String(), Error: err.Error()})
+		}
+	}
+}
+
+// failRemainingLines reports the lines from index on, which were not read
+func failRemainingLines(index int, err error, result *model.BatchResult) {
+	result.Add(&model.BatchItemResult{
+		Index:  index,
+		Status: model.BatchItemFailed,
+		Code:   derrors.CodeOf(err).String(),
+		Error:  fmt.Sprintf("the remaining lines were not read: %v", err),
 	})
 }
+
+// hasMoreLines reports whether the body has another non-empty line
+func hasMoreLines(scanner *bufio.Scanner) bool {
+	for scanner.Scan() {
+		if len(bytes.TrimSpace(scanner.Bytes())) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// batchItem is a payload of a batch request, err is set when it cannot be
+// published at all
+type batchItem struct {
+	index   int
+	payload interface{}
+	err     error
+}
+
+// publishBatch publishes the valid items and records the outcome of every
+// item in result, keeping the request order.
+func (h *tenantHandler) publishBatch(c echo.Context, clientID string, items []batchItem, result *model.BatchResult) error {
+	payloads := make([]interface{}, 0, len(items))
+	for _, item := range items {
+		if item.err == nil && item.payload != nil {
+			payloads = append(payloads, item.payload)
+		}
+	}
+
+	published := &model.BatchResult{}
+	if len(payloads) > 0 {
+		var err error
+		published, err = h.tenantUsecase.ProcessBatch(c.Request().Context(), clientID, payloads)
+		if err != nil {
+			return err
+		}
+	}
+
+	next := 0
+	for _, item := range items {
+		switch {
+		case item.err != nil:
+			result.Add(&model.BatchItemResult{Index: item.index, Status: model.BatchItemFailed, Code: derrors.InvalidArgument.String(), Error: item.err.Error()})
+		case item.payload == nil:
+			result.Add(&model.BatchItemResult{Index: item.index, Status: model.BatchItemFailed, Code: derrors.InvalidArgument.String(), Error: "payload is required"})
+		default:
+			itemResult := published.Results[next]
+			itemResult.Index = item.index
+			result.Add(itemResult)
+			next++
+		}
+	}
+	return nil
+}
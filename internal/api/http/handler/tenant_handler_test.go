@@ -1,16 +1,21 @@
 package handler_test
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"strings"
+	"tenant/infrastructure/config"
 	"tenant/internal/api/http/handler"
 	"tenant/internal/container"
 	"tenant/internal/model"
 	"tenant/internal/test"
+	"tenant/pkg/api"
+	"tenant/pkg/derrors"
 
 	"testing"
+	"time"
 
 	"github.com/asaskevich/govalidator"
 	"github.com/labstack/echo/v4"
@@ -59,6 +64,22 @@ func TestCreateTenantHandler(t *testing.T) {
 			expectedCode: http.StatusOK,
 			expectedBody: `"message":"Tenant created successfully"`,
 		},
+		{
+			caseName:     "CreateTenant_MissingName",
+			requestBody:  `{}`,
+			mockSetup:    func() {},
+			expectedCode: http.StatusBadRequest,
+			expectedBody: `"errors":[{"field":"name","message":"non zero value required"}]`,
+		},
+		{
+			caseName:    "CreateTenant_Error",
+			requestBody: `{"name":"Broken Tenant"}`,
+			mockSetup: func() {
+				mockComponent.TenantUsecase.On("CreateTenant", mock.Anything, "Broken Tenant").Return(nil, errors.New("connection refused"))
+			},
+			expectedCode: http.StatusInternalServerError,
+			expectedBody: `"code":"unknown"`,
+		},
 	}
 
 	for _, tc := range testCases {
@@ -132,6 +153,59 @@ func TestDeleteTenantHandler(t *testing.T) {
 	}
 }
 
+func TestDeleteTenantHandlerErrors(t *testing.T) {
+	e := echo.New()
+	e.Validator = &requestValidator{}
+	mockComponent := test.InitMockComponent(t)
+
+	hc := &container.HandlerComponent{
+		TenantUsecase: mockComponent.TenantUsecase,
+	}
+
+	h := handler.NewTenantHandler(hc)
+
+	var testCases = []struct {
+		caseName     string
+		clientID     string
+		mockSetup    func()
+		expectedCode int
+		expectedBody string
+	}{
+		{
+			caseName: "DeleteTenant_NotFound",
+			clientID: "missing-client",
+			mockSetup: func() {
+				mockComponent.TenantUsecase.On("DeleteTenant", mock.Anything, "missing-client").Return(derrors.New(derrors.NotFound, "tenant not found or already deleted"))
+			},
+			expectedCode: http.StatusNotFound,
+			expectedBody: `"code":"not_found"`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.caseName, func(t *testing.T) {
+			tc.mockSetup()
+
+			path := fmt.Sprintf("/tenants/%s", tc.clientID)
+			req := httptest.NewRequest(http.MethodDelete, path, nil)
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetParamNames("clientID")
+			c.SetParamValues(tc.clientID)
+
+			err := h.DeleteTenant(c)
+			if err != nil {
+				t.Errorf("Handler returned error: %v", err)
+			}
+
+			assert.Equal(t, tc.expectedCode, rec.Code)
+			assert.Equal(t, api.ProblemJSONHeader, rec.Header().Get(echo.HeaderContentType))
+			assert.Contains(t, rec.Body.String(), tc.expectedBody)
+		})
+	}
+}
+
 func TestProcessTenantHandler(t *testing.T) {
 	e := echo.New()
 	e.Validator = &requestValidator{}
@@ -156,7 +230,7 @@ func TestProcessTenantHandler(t *testing.T) {
 			requestBody: `{"payload":"Test Tenant"}`,
 			clientID:    "test-client",
 			mockSetup: func() {
-				mockComponent.TenantUsecase.On("ProcessPayload", mock.Anything, "test-client", "Test Tenant").Return(nil)
+				mockComponent.TenantUsecase.On("ProcessPayload", mock.Anything, "test-client", &model.Payload{Body: "Test Tenant"}).Return("msg-1", nil).Once()
 			},
 			expectedCode: http.StatusOK,
 			expectedBody: `"message":"Process Tenant successfully"`,
@@ -183,3 +257,326 @@ func TestProcessTenantHandler(t *testing.T) {
 		})
 	}
 }
+
+func TestProcessTenantHandlerResponses(t *testing.T) {
+	e := echo.New()
+	e.Validator = &requestValidator{}
+	mockComponent := test.InitMockComponent(t)
+
+	hc := &container.HandlerComponent{
+		TenantUsecase: mockComponent.TenantUsecase,
+	}
+
+	h := handler.NewTenantHandler(hc)
+
+	var testCases = []struct {
+		caseName     string
+		requestBody  string
+		clientID     string
+		header       map[string]string
+		mockSetup    func()
+		expectedCode int
+		expectedBody string
+	}{
+		{
+			caseName:    "ProcessTenant_MessageID",
+			requestBody: `{"payload":"Test Tenant"}`,
+			clientID:    "test-client",
+			mockSetup: func() {
+				mockComponent.TenantUsecase.On("ProcessPayload", mock.Anything, "test-client", &model.Payload{Body: "Test Tenant"}).Return("msg-1", nil).Once()
+			},
+			expectedCode: http.StatusOK,
+			expectedBody: `"message":"Payload processed successfully","message_id":"msg-1"`,
+		},
+		{
+			caseName:    "ProcessTenant_WithProperties",
+			requestBody: `{"payload":{"a":1},"headers":{"x-source":"test"},"correlation_id":"order-42","content_type":"application/json","priority":5,"expiration":"30s"}`,
+			clientID:    "test-client",
+			mockSetup: func() {
+				mockComponent.TenantUsecase.On("ProcessPayload", mock.Anything, "test-client", &model.Payload{
+					Body:          map[string]interface{}{"a": float64(1)},
+					Headers:       map[string]string{"x-source": "test"},
+					CorrelationID: "order-42",
+					ContentType:   "application/json",
+					Priority:      5,
+					Expiration:    30 * time.Second,
+				}).Return("msg-2", nil).Once()
+			},
+			expectedCode: http.StatusOK,
+			expectedBody: `"message_id":"msg-2"`,
+		},
+		{
+			caseName:    "ProcessTenant_IdempotencyKey",
+			requestBody: `{"payload":"a"}`,
+			clientID:    "test-client",
+			header:      map[string]string{api.HeaderIdempotencyKey: "key-1"},
+			mockSetup: func() {
+				mockComponent.TenantUsecase.On("ProcessPayload", mock.Anything, "test-client", &model.Payload{Body: "a", IdempotencyKey: "key-1"}).Return("msg-1", nil).Once()
+			},
+			expectedCode: http.StatusOK,
+			expectedBody: `"message_id":"msg-1"`,
+		},
+		{
+			caseName:    "ProcessTenant_IdempotencyKeyReused",
+			requestBody: `{"payload":"b"}`,
+			clientID:    "test-client",
+			header:      map[string]string{api.HeaderIdempotencyKey: "key-1"},
+			mockSetup: func() {
+				mockComponent.TenantUsecase.On("ProcessPayload", mock.Anything, "test-client", &model.Payload{Body: "b", IdempotencyKey: "key-1"}).Return("", derrors.New(derrors.InvalidArgument, "idempotency key \"key-1\" was already used with a different payload")).Once()
+			},
+			expectedCode: http.StatusBadRequest,
+			expectedBody: `already used with a different payload`,
+		},
+		{
+			caseName:     "ProcessTenant_IdempotencyKeyTooLong",
+			requestBody:  `{"payload":"a"}`,
+			clientID:     "test-client",
+			header:       map[string]string{api.HeaderIdempotencyKey: strings.Repeat("k", 256)},
+			mockSetup:    func() {},
+			expectedCode: http.StatusBadRequest,
+			expectedBody: `"field":"Idempotency-Key"`,
+		},
+		{
+			caseName:     "ProcessTenant_InvalidPriority",
+			requestBody:  `{"payload":"a","priority":10}`,
+			clientID:     "test-client",
+			mockSetup:    func() {},
+			expectedCode: http.StatusBadRequest,
+			expectedBody: `"field":"priority"`,
+		},
+		{
+			caseName:     "ProcessTenant_InvalidExpiration",
+			requestBody:  `{"payload":"a","expiration":"soon"}`,
+			clientID:     "test-client",
+			mockSetup:    func() {},
+			expectedCode: http.StatusBadRequest,
+			expectedBody: `"field":"expiration"`,
+		},
+		{
+			caseName:    "ProcessTenant_Delay",
+			requestBody: `{"payload":"a","delay":"10m"}`,
+			clientID:    "test-client",
+			mockSetup: func() {
+				mockComponent.TenantUsecase.On("ProcessPayload", mock.Anything, "test-client", mock.MatchedBy(func(payload *model.Payload) bool {
+					return time.Until(payload.DeliverAt) > 9*time.Minute && time.Until(payload.DeliverAt) <= 10*time.Minute
+				})).Return("msg-3", nil).Once()
+			},
+			expectedCode: http.StatusOK,
+			expectedBody: `"deliver_at"`,
+		},
+		{
+			caseName:     "ProcessTenant_DelayAndDeliverAt",
+			requestBody:  `{"payload":"a","delay":"10m","deliver_at":"2026-10-19T12:00:00Z"}`,
+			clientID:     "test-client",
+			mockSetup:    func() {},
+			expectedCode: http.StatusBadRequest,
+			expectedBody: `"field":"delay"`,
+		},
+		{
+			caseName:     "ProcessTenant_InvalidDeliverAt",
+			requestBody:  `{"payload":"a","deliver_at":"tomorrow"}`,
+			clientID:     "test-client",
+			mockSetup:    func() {},
+			expectedCode: http.StatusBadRequest,
+			expectedBody: `"field":"deliver_at"`,
+		},
+		{
+			caseName:     "ProcessTenant_MissingPayload",
+			requestBody:  `{}`,
+			clientID:     "test-client",
+			mockSetup:    func() {},
+			expectedCode: http.StatusBadRequest,
+			expectedBody: `"field":"payload"`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.caseName, func(t *testing.T) {
+			tc.mockSetup()
+
+			req := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/tenants/%s/process", tc.clientID), strings.NewReader(tc.requestBody))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			for key, value := range tc.header {
+				req.Header.Set(key, value)
+			}
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetParamNames("clientID")
+			c.SetParamValues(tc.clientID)
+
+			err := h.ProcessPayload(c)
+			if err != nil {
+				t.Errorf("Handler returned error: %v", err)
+			}
+
+			assert.Equal(t, tc.expectedCode, rec.Code)
+			assert.Contains(t, rec.Body.String(), tc.expectedBody)
+		})
+	}
+}
+
+func TestProcessBatchHandler(t *testing.T) {
+	e := echo.New()
+	e.Validator = &requestValidator{}
+	mockComponent := test.InitMockComponent(t)
+
+	hc := &container.HandlerComponent{
+		Config:        &config.Config{Process: config.ProcessConfig{MaxBatchSize: 3}},
+		TenantUsecase: mockComponent.TenantUsecase,
+	}
+
+	h := handler.NewTenantHandler(hc)
+
+	published := func(ids ...string) *model.BatchResult {
+		result := &model.BatchResult{}
+		for i, id := range ids {
+			result.Add(&model.BatchItemResult{Index: i, MessageID: id, Status: model.BatchItemPublished})
+		}
+		return result
+	}
+
+	var testCases = []struct {
+		caseName     string
+		contentType  string
+		requestBody  string
+		mockSetup    func()
+		expectedCode int
+		expectedBody []string
+	}{
+		{
+			caseName:    "ProcessBatch_Array",
+			contentType: echo.MIMEApplicationJSON,
+			requestBody: `[{"a":1},"b"]`,
+			mockSetup: func() {
+				mockComponent.TenantUsecase.On("ProcessBatch", mock.Anything, "test-client", []interface{}{map[string]interface{}{"a": float64(1)}, "b"}).Return(published("msg-1", "msg-2"), nil).Once()
+			},
+			expectedCode: http.StatusOK,
+			expectedBody: []string{`"published":2,"failed":0`, `{"index":1,"message_id":"msg-2","status":"published"}`},
+		},
+		{
+			caseName:    "ProcessBatch_PartialFailure",
+			contentType: echo.MIMEApplicationJSON,
+			requestBody: `["a",null,"c"]`,
+			mockSetup: func() {
+				result := published("msg-1")
+				result.Add(&model.BatchItemResult{Index: 1, MessageID: "msg-3", Status: model.BatchItemFailed, Code: "unavailable", Error: "channel closed"})
+				mockComponent.TenantUsecase.On("ProcessBatch", mock.Anything, "test-client", []interface{}{"a", "c"}).Return(result, nil).Once()
+			},
+			expectedCode: http.StatusOK,
+			expectedBody: []string{
+				`"published":1,"failed":2`,
+				`{"index":1,"status":"failed","code":"invalid_argument","error":"payload is required"}`,
+				`{"index":2,"message_id":"msg-3","status":"failed","code":"unavailable","error":"channel closed"}`,
+			},
+		},
+		{
+			caseName:    "ProcessBatch_NDJSON",
+			contentType: api.NDJSONHeader,
+			requestBody: "{\"a\":1}\n\nnot json\n\"b\"\n",
+			mockSetup: func() {
+				mockComponent.TenantUsecase.On("ProcessBatch", mock.Anything, "test-client", []interface{}{map[string]interface{}{"a": float64(1)}, "b"}).Return(published("msg-1", "msg-2"), nil).Once()
+			},
+			expectedCode: http.StatusOK,
+			expectedBody: []string{
+				`"published":2,"failed":1`,
+				`{"index":1,"status":"failed","code":"invalid_argument","error":"line 2 is not valid JSON`,
+				`{"index":2,"message_id":"msg-2","status":"published"}`,
+			},
+		},
+		{
+			caseName:    "ProcessBatch_NDJSONOverLimit",
+			contentType: api.NDJSONHeader,
+			requestBody: "1\n2\n3\n4\n5\n",
+			mockSetup: func() {
+				mockComponent.TenantUsecase.On("ProcessBatch", mock.Anything, "test-client", []interface{}{float64(1), float64(2), float64(3)}).Return(published("msg-1", "msg-2", "msg-3"), nil).Once()
+			},
+			expectedCode: http.StatusOK,
+			expectedBody: []string{`"published":3,"failed":1`, `{"index":3,"status":"failed","code":"payload_too_large"`},
+		},
+		{
+			caseName:     "ProcessBatch_ArrayOverLimit",
+			contentType:  echo.MIMEApplicationJSON,
+			requestBody:  `[1,2,3,4]`,
+			mockSetup:    func() {},
+			expectedCode: http.StatusRequestEntityTooLarge,
+			expectedBody: []string{`"code":"payload_too_large"`},
+		},
+		{
+			caseName:     "ProcessBatch_Empty",
+			contentType:  echo.MIMEApplicationJSON,
+			requestBody:  `[]`,
+			mockSetup:    func() {},
+			expectedCode: http.StatusBadRequest,
+			expectedBody: []string{`"field":"payloads"`},
+		},
+		{
+			caseName:    "ProcessBatch_TenantNotFound",
+			contentType: echo.MIMEApplicationJSON,
+			requestBody: `["a"]`,
+			mockSetup: func() {
+				mockComponent.TenantUsecase.On("ProcessBatch", mock.Anything, "test-client", []interface{}{"a"}).Return(nil, derrors.New(derrors.NotFound, "tenant not found")).Once()
+			},
+			expectedCode: http.StatusNotFound,
+			expectedBody: []string{`"code":"not_found"`},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.caseName, func(t *testing.T) {
+			tc.mockSetup()
+
+			req := httptest.NewRequest(http.MethodPost, "/tenants/test-client/process/batch", strings.NewReader(tc.requestBody))
+			req.Header.Set(echo.HeaderContentType, tc.contentType)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetParamNames("clientID")
+			c.SetParamValues("test-client")
+
+			err := h.ProcessBatch(c)
+			if err != nil {
+				t.Errorf("Handler returned error: %v", err)
+			}
+
+			assert.Equal(t, tc.expectedCode, rec.Code)
+			for _, body := range tc.expectedBody {
+				assert.Contains(t, rec.Body.String(), body)
+			}
+		})
+	}
+}
+
+func TestProcessNDJSONPartialFailure(t *testing.T) {
+	e := echo.New()
+	e.Validator = &requestValidator{}
+	mockComponent := test.InitMockComponent(t)
+
+	hc := &container.HandlerComponent{
+		Config:        &config.Config{Process: config.ProcessConfig{MaxBatchSize: 2000}},
+		TenantUsecase: mockComponent.TenantUsecase,
+	}
+
+	h := handler.NewTenantHandler(hc)
+
+	// The first chunk of 500 lines is published, the second one fails
+	first := &model.BatchResult{}
+	for i := 0; i < 500; i++ {
+		first.Add(&model.BatchItemResult{Index: i, MessageID: fmt.Sprintf("msg-%d", i), Status: model.BatchItemPublished})
+	}
+	mockComponent.TenantUsecase.On("ProcessBatch", mock.Anything, "test-client", mock.Anything).Return(first, nil).Once()
+	mockComponent.TenantUsecase.On("ProcessBatch", mock.Anything, "test-client", mock.Anything).Return(nil, derrors.New(derrors.Unavailable, "connection refused")).Once()
+
+	req := httptest.NewRequest(http.MethodPost, "/tenants/test-client/process/batch", strings.NewReader(strings.Repeat("1\n", 1200)))
+	req.Header.Set(echo.HeaderContentType, api.NDJSONHeader)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("clientID")
+	c.SetParamValues("test-client")
+
+	assert.Nil(t, h.ProcessBatch(c))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"published":500,"failed":501`)
+	assert.Contains(t, rec.Body.String(), `{"index":499,"message_id":"msg-499","status":"published"}`)
+	assert.Contains(t, rec.Body.String(), `{"index":500,"status":"failed","code":"unavailable","error":"connection refused"}`)
+	assert.Contains(t, rec.Body.String(), `{"index":1000,"status":"failed","code":"unavailable","error":"the remaining lines were not read: connection refused"}`)
+	mockComponent.TenantUsecase.AssertExpectations(t)
+}
@@ -0,0 +1,245 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"tenant/internal/api/http/handler/request"
+	"tenant/internal/container"
+	"tenant/internal/model"
+	"tenant/internal/usecase"
+	"tenant/pkg/api"
+
+	"github.com/labstack/echo/v4"
+)
+
+// maxDeliveriesLimit bounds the delivery log returned at once
+const maxDeliveriesLimit = 500
+
+type (
+	webhookHandler struct {
+		webhookUsecase usecase.WebhookUsecase
+	}
+
+	WebhookHandler interface {
+		CreateWebhook(c echo.Context) error
+		ListWebhooks(c echo.Context) error
+		GetWebhook(c echo.Context) error
+		UpdateWebhook(c echo.Context) error
+		DeleteWebhook(c echo.Context) error
+		ListDeliveries(c echo.Context) error
+		GetHealth(c echo.Context) error
+	}
+)
+
+func NewWebhookHandler(hc *container.HandlerComponent) WebhookHandler {
+	return &webhookHandler{webhookUsecase: hc.WebhookUsecase}
+}
+
+// CreateWebhook registers a webhook of a tenant
+// Create Webhook
+// @Summary Create Webhook
+// @Description Deliver every message of a tenant to a URL as an HTTP POST signed with HMAC-SHA256. The secret is generated when none is given, and only returned in this response.
+// @Tags webhook
+// @ID create-webhook
+// @Accept json
+// @Produce json
+// @Param clientID path string true "clientID"
+// @Param webhook body request.WebhookRequest true "webhook payload"
+// @Success 201 {object} model.Webhook
+// @Failure 400 {object} api.Problem
+// @Failure 404 {object} api.Problem
+// @Failure 409 {object} api.Problem
+// @Failure 500 {object} api.Problem
+// @Router /tenants/{clientID}/webhooks [post]
+func (h *webhookHandler) CreateWebhook(c echo.Context) error {
+	hook, err := bindWebhook(c)
+	if err != nil {
+		return api.RenderErrorResponse(c, c.Request(), err)
+	}
+
+	hook, err = h.webhookUsecase.CreateWebhook(c.Request().Context(), hook)
+	if err != nil {
+		return api.RenderErrorResponse(c, c.Request(), err)
+	}
+
+	return api.ResponseSuccess(c, hook, "webhook created", http.StatusCreated)
+}
+
+// ListWebhooks lists the webhooks of a tenant
+// List Webhooks
+// @Summary List Webhooks
+// @Description List the webhooks of a tenant, without their secret
+// @Tags webhook
+// @ID list-webhooks
+// @Produce json
+// @Param clientID path string true "clientID"
+// @Success 200 {array} model.Webhook
+// @Failure 404 {object} api.Problem
+// @Failure 500 {object} api.Problem
+// @Router /tenants/{clientID}/webhooks [get]
+func (h *webhookHandler) ListWebhooks(c echo.Context) error {
+	hooks, err := h.webhookUsecase.ListWebhooks(c.Request().Context(), c.Param("clientID"))
+	if err != nil {
+		return api.RenderErrorResponse(c, c.Request(), err)
+	}
+
+	return api.ResponseOK(c, hooks)
+}
+
+// GetWebhook returns a webhook of a tenant
+// Get Webhook
+// @Summary Get Webhook
+// @Description Get a webhook of a tenant, without its secret
+// @Tags webhook
+// @ID get-webhook
+// @Produce json
+// @Param clientID path string true "clientID"
+// @Param webhookID path string true "webhookID"
+// @Success 200 {object} model.Webhook
+// @Failure 404 {object} api.Problem
+// @Failure 500 {object} api.Problem
+// @Router /tenants/{clientID}/webhooks/{webhookID} [get]
+func (h *webhookHandler) GetWebhook(c echo.Context) error {
+	hook, err := h.webhookUsecase.GetWebhook(c.Request().Context(), c.Param("clientID"), c.Param("webhookID"))
+	if err != nil {
+		return api.RenderErrorResponse(c, c.Request(), err)
+	}
+
+	return api.ResponseOK(c, hook)
+}
+
+// UpdateWebhook replaces a webhook of a tenant
+// Update Webhook
+// @Summary Update Webhook
+// @Description Replace the URL and state of a webhook. A new secret rotates the current one.
+// @Tags webhook
+// @ID update-webhook
+// @Accept json
+// @Produce json
+// @Param clientID path string true "clientID"
+// @Param webhookID path string true "webhookID"
+// @Param webhook body request.WebhookRequest true "webhook payload"
+// @Success 200 {object} model.Webhook
+// @Failure 400 {object} api.Problem
+// @Failure 404 {object} api.Problem
+// @Failure 409 {object} api.Problem
+// @Failure 500 {object} api.Problem
+// @Router /tenants/{clientID}/webhooks/{webhookID} [put]
+func (h *webhookHandler) UpdateWebhook(c echo.Context) error {
+	hook, err := bindWebhook(c)
+	if err != nil {
+		return api.RenderErrorResponse(c, c.Request(), err)
+	}
+	hook.ID = c.Param("webhookID")
+
+	hook, err = h.webhookUsecase.UpdateWebhook(c.Request().Context(), hook)
+	if err != nil {
+		return api.RenderErrorResponse(c, c.Request(), err)
+	}
+
+	return api.ResponseOK(c, hook)
+}
+
+// DeleteWebhook deletes a webhook of a tenant
+// Delete Webhook
+// @Summary Delete Webhook
+// @Description Delete a webhook of a tenant along with its delivery log
+// @Tags webhook
+// @ID delete-webhook
+// @Produce json
+// @Param clientID path string true "clientID"
+// @Param webhookID path string true "webhookID"
+// @Success 200 {object} map[string]string
+// @Failure 404 {object} api.Problem
+// @Failure 500 {object} api.Problem
+// @Router /tenants/{clientID}/webhooks/{webhookID} [delete]
+func (h *webhookHandler) DeleteWebhook(c echo.Context) error {
+	webhookID := c.Param("webhookID")
+	if err := h.webhookUsecase.DeleteWebhook(c.Request().Context(), c.Param("clientID"), webhookID); err != nil {
+		return api.RenderErrorResponse(c, c.Request(), err)
+	}
+
+	return api.ResponseOK(c, map[string]interface{}{
+		"message":    "Webhook deleted",
+		"webhook_id": webhookID,
+	})
+}
+
+// ListDeliveries returns the delivery log of a webhook
+// List Webhook Deliveries
+// @Summary List Webhook Deliveries
+// @Description List the latest requests made to a webhook, newest first, with their status code, duration and error
+// @Tags webhook
+// @ID list-webhook-deliveries
+// @Produce json
+// @Param clientID path string true "clientID"
+// @Param webhookID path string true "webhookID"
+// @Param limit query int false "number of deliveries, at most 500" default(50)
+// @Success 200 {array} model.WebhookDelivery
+// @Failure 400 {object} api.Problem
+// @Failure 404 {object} api.Problem
+// @Failure 500 {object} api.Problem
+// @Router /tenants/{clientID}/webhooks/{webhookID}/deliveries [get]
+func (h *webhookHandler) ListDeliveries(c echo.Context) error {
+	var limit int
+	if value := c.QueryParam("limit"); value != "" {
+		var err error
+		limit, err = strconv.Atoi(value)
+		if err != nil || limit < 1 || limit > maxDeliveriesLimit {
+			return api.RenderErrorResponse(c, c.Request(), api.NewValidationError("limit", "must be between 1 and "+strconv.Itoa(maxDeliveriesLimit)))
+		}
+	}
+
+	deliveries, err := h.webhookUsecase.ListDeliveries(c.Request().Context(), c.Param("clientID"), c.Param("webhookID"), limit)
+	if err != nil {
+		return api.RenderErrorResponse(c, c.Request(), err)
+	}
+
+	return api.ResponseOK(c, deliveries)
+}
+
+// GetHealth returns the circuit breaker of a webhook
+// Get Webhook Health
+// @Summary Get Webhook Health
+// @Description Get the circuit breaker state, consecutive failures and latency of a webhook as last reported by a worker. The messages of the tenant are held back while the breaker is open.
+// @Tags webhook
+// @ID get-webhook-health
+// @Produce json
+// @Param clientID path string true "clientID"
+// @Param webhookID path string true "webhookID"
+// @Success 200 {object} model.WebhookHealth
+// @Failure 404 {object} api.Problem
+// @Failure 500 {object} api.Problem
+// @Router /tenants/{clientID}/webhooks/{webhookID}/health [get]
+func (h *webhookHandler) GetHealth(c echo.Context) error {
+	health, err := h.webhookUsecase.GetHealth(c.Request().Context(), c.Param("clientID"), c.Param("webhookID"))
+	if err != nil {
+		return api.RenderErrorResponse(c, c.Request(), err)
+	}
+
+	return api.ResponseOK(c, health)
+}
+
+// bindWebhook reads a webhook of the tenant in the path from the body
+func bindWebhook(c echo.Context) (*model.Webhook, error) {
+	var req request.WebhookRequest
+	if err := c.Bind(&req); err != nil {
+		return nil, err
+	}
+
+	if err := c.Validate(req); err != nil {
+		return nil, err
+	}
+
+	enabled := true
+	if req.Enabled != nil {
+		enabled = *req.Enabled
+	}
+	return &model.Webhook{
+		ClientID: c.Param("clientID"),
+		URL:      req.URL,
+		Secret:   req.Secret,
+		Enabled:  enabled,
+	}, nil
+}
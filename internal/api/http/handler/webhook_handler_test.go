@@ -0,0 +1,198 @@
+package handler_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"tenant/internal/api/http/handler"
+	"tenant/internal/container"
+	"tenant/internal/model"
+	"tenant/internal/test"
+	"tenant/pkg/derrors"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestCreateWebhookHandler(t *testing.T) {
+	e := echo.New()
+	e.Validator = &requestValidator{}
+	mockComponent := test.InitMockComponent(t)
+
+	hc := &container.HandlerComponent{
+		WebhookUsecase: mockComponent.WebhookUsecase,
+	}
+
+	h := handler.NewWebhookHandler(hc)
+
+	var testCases = []struct {
+		caseName     string
+		body         string
+		mockSetup    func()
+		expectedCode int
+		expectedBody string
+	}{
+		{
+			caseName: "CreateWebhook_Success",
+			body:     `{"url":"https://example.com/hook"}`,
+			mockSetup: func() {
+				mockComponent.WebhookUsecase.On("CreateWebhook", mock.Anything, mock.MatchedBy(func(hook *model.Webhook) bool {
+					return hook.ClientID == "test-client" && hook.URL == "https://example.com/hook" && hook.Secret == "" && hook.Enabled
+				})).Return(&model.Webhook{ID: "hook-1", URL: "https://example.com/hook", Secret: "whsec_generated", Enabled: true}, nil).Once()
+			},
+			expectedCode: http.StatusCreated,
+			expectedBody: `"secret":"whsec_generated"`,
+		},
+		{
+			caseName:     "CreateWebhook_MissingURL",
+			body:         `{"enabled":true}`,
+			mockSetup:    func() {},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			caseName:     "CreateWebhook_SecretTooShort",
+			body:         `{"url":"https://example.com/hook","secret":"short"}`,
+			mockSetup:    func() {},
+			expectedCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.caseName, func(t *testing.T) {
+			tc.mockSetup()
+
+			req := httptest.NewRequest(http.MethodPost, "/tenants/test-client/webhooks", strings.NewReader(tc.body))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetParamNames("clientID")
+			c.SetParamValues("test-client")
+
+			err := h.CreateWebhook(c)
+			if err != nil {
+				t.Errorf("Handler returned error: %v", err)
+			}
+
+			assert.Equal(t, tc.expectedCode, rec.Code)
+			assert.Contains(t, rec.Body.String(), tc.expectedBody)
+		})
+	}
+}
+
+func TestListWebhookDeliveriesHandler(t *testing.T) {
+	e := echo.New()
+	mockComponent := test.InitMockComponent(t)
+
+	hc := &container.HandlerComponent{
+		WebhookUsecase: mockComponent.WebhookUsecase,
+	}
+
+	h := handler.NewWebhookHandler(hc)
+
+	var testCases = []struct {
+		caseName     string
+		query        string
+		mockSetup    func()
+		expectedCode int
+		expectedBody string
+	}{
+		{
+			caseName: "ListDeliveries_Success",
+			query:    "limit=10",
+			mockSetup: func() {
+				mockComponent.WebhookUsecase.On("ListDeliveries", mock.Anything, "test-client", "hook-1", 10).Return([]*model.WebhookDelivery{
+					{ID: 1, WebhookID: "hook-1", MessageID: "msg-1", Attempt: 1, StatusCode: http.StatusServiceUnavailable, Duration: 120 * time.Millisecond},
+				}, nil).Once()
+			},
+			expectedCode: http.StatusOK,
+			expectedBody: `"duration":"120ms"`,
+		},
+		{
+			caseName:     "ListDeliveries_InvalidLimit",
+			query:        "limit=0",
+			mockSetup:    func() {},
+			expectedCode: http.StatusBadRequest,
+			expectedBody: `"field":"limit"`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.caseName, func(t *testing.T) {
+			tc.mockSetup()
+
+			req := httptest.NewRequest(http.MethodGet, "/tenants/test-client/webhooks/hook-1/deliveries?"+tc.query, nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetParamNames("clientID", "webhookID")
+			c.SetParamValues("test-client", "hook-1")
+
+			err := h.ListDeliveries(c)
+			if err != nil {
+				t.Errorf("Handler returned error: %v", err)
+			}
+
+			assert.Equal(t, tc.expectedCode, rec.Code)
+			assert.Contains(t, rec.Body.String(), tc.expectedBody)
+		})
+	}
+}
+
+func TestGetWebhookHealthHandler(t *testing.T) {
+	e := echo.New()
+	mockComponent := test.InitMockComponent(t)
+
+	hc := &container.HandlerComponent{
+		WebhookUsecase: mockComponent.WebhookUsecase,
+	}
+
+	h := handler.NewWebhookHandler(hc)
+
+	var testCases = []struct {
+		caseName     string
+		mockSetup    func()
+		expectedCode int
+		expectedBody string
+	}{
+		{
+			caseName: "GetHealth_Open",
+			mockSetup: func() {
+				mockComponent.WebhookUsecase.On("GetHealth", mock.Anything, "test-client", "hook-1").Return(&model.WebhookHealth{
+					WebhookID: "hook-1", ClientID: "test-client", State: model.WebhookOpen, ConsecutiveFailures: 5, AvgLatency: 2 * time.Second,
+				}, nil).Once()
+			},
+			expectedCode: http.StatusOK,
+			expectedBody: `"state":"open"`,
+		},
+		{
+			caseName: "GetHealth_NotFound",
+			mockSetup: func() {
+				mockComponent.WebhookUsecase.On("GetHealth", mock.Anything, "test-client", "hook-1").Return(nil, derrors.New(derrors.NotFound, "webhook not found")).Once()
+			},
+			expectedCode: http.StatusNotFound,
+			expectedBody: "webhook not found",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.caseName, func(t *testing.T) {
+			tc.mockSetup()
+
+			req := httptest.NewRequest(http.MethodGet, "/tenants/test-client/webhooks/hook-1/health", nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetParamNames("clientID", "webhookID")
+			c.SetParamValues("test-client", "hook-1")
+
+			err := h.GetHealth(c)
+			if err != nil {
+				t.Errorf("Handler returned error: %v", err)
+			}
+
+			assert.Equal(t, tc.expectedCode, rec.Code)
+			assert.Contains(t, rec.Body.String(), tc.expectedBody)
+		})
+	}
+}
@@ -0,0 +1,20 @@
+package middleware
+
+import (
+	"tenant/pkg/logger"
+
+	"github.com/labstack/echo/v4"
+	"github.com/sirupsen/logrus"
+)
+
+// Logger stores the application logger in the request context so helpers
+// without direct access to it can log through logger.FromContext.
+func Logger(log *logrus.Logger) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			req := c.Request()
+			c.SetRequest(req.WithContext(logger.NewContext(req.Context(), log)))
+			return next(c)
+		}
+	}
+}
@@ -0,0 +1,24 @@
+package middleware
+
+import (
+	"tenant/pkg/logger"
+
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+	"github.com/segmentio/ksuid"
+)
+
+// RequestID reuses the incoming X-Request-ID header or generates a new one,
+// echoes it on the response and stores it in the request context so it
+// reaches usecases, logs and published messages.
+func RequestID() echo.MiddlewareFunc {
+	return middleware.RequestIDWithConfig(middleware.RequestIDConfig{
+		Generator: func() string {
+			return ksuid.New().String()
+		},
+		RequestIDHandler: func(c echo.Context, requestID string) {
+			req := c.Request()
+			c.SetRequest(req.WithContext(logger.WithRequestID(req.Context(), requestID)))
+		},
+	})
+}
@@ -2,7 +2,9 @@ package router
 
 import (
 	"net/http"
+	"tenant/internal/api/http/handler"
 	"tenant/internal/container"
+	"tenant/pkg/metrics"
 
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
@@ -21,8 +23,10 @@ func Init(e *echo.Echo, hc *container.HandlerComponent, sc *container.SharedComp
 	e.GET("/docs/index.html", echoSwagger.WrapHandler)
 	e.GET("/docs/doc.json", echoSwagger.WrapHandler)
 	e.GET("/docs/*", echoSwagger.WrapHandler)
-	e.GET("/ping", ping)
+	Probes(e, hc)
+
 	publicRouter(e, hc)
+	adminRouter(e, hc)
 
 	e.Use(middleware.CORSWithConfig(middleware.CORSConfig{
 		AllowOrigins:     []string{"*"},
@@ -32,6 +36,31 @@ func Init(e *echo.Echo, hc *container.HandlerComponent, sc *container.SharedComp
 	}))
 }
 
+// Worker registers the endpoints of the worker process: the probes, and the
+// log level of this process since messages are only consumed by workers.
+func Worker(e *echo.Echo, hc *container.HandlerComponent) {
+	e.Pre(middleware.Rewrite(map[string]string{
+		"/v1/*": "/$1",
+	}))
+
+	Probes(e, hc)
+	logLevelRouter(e.Group("/admin"), hc)
+}
+
+// Probes registers the ping and health probe endpoints, shared by the API and
+// the worker process.
+func Probes(e *echo.Echo, hc *container.HandlerComponent) {
+	e.GET("/ping", ping)
+
+	// Health probes
+	healthHandler := handler.NewHealthHandler(hc)
+	e.GET("/healthz", healthHandler.Liveness)
+	e.GET("/readyz", healthHandler.Readiness)
+
+	// Metrics of this process in the Prometheus text format
+	e.GET("/metrics", echo.WrapHandler(metrics.Default))
+}
+
 // ping write pong to http.ResponseWriter.
 func ping(c echo.Context) error {
 	return c.String(http.StatusOK, "pong")
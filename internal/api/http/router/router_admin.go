@@ -0,0 +1,38 @@
+package router
+
+import (
+	"tenant/internal/api/http/handler"
+	"tenant/internal/container"
+
+	"github.com/labstack/echo/v4"
+)
+
+func adminRouter(e *echo.Echo, hc *container.HandlerComponent) {
+
+	adminRoute := e.Group("/admin")
+
+	// Log level
+	logLevelRouter(adminRoute, hc)
+
+	// Tenant to worker assignment
+	assignmentHandler := handler.NewAssignmentHandler(hc)
+	{
+		adminRoute.GET("/assignments", assignmentHandler.GetAssignments)
+	}
+
+	// Tenant lookup cache
+	cacheHandler := handler.NewCacheHandler(hc)
+	{
+		adminRoute.GET("/tenant-cache", cacheHandler.GetTenantCacheStats)
+		adminRoute.DELETE("/tenant-cache", cacheHandler.PurgeTenantCache)
+	}
+
+}
+
+// logLevelRouter registers the log level endpoints, shared by the API and the
+// worker process
+func logLevelRouter(adminRoute *echo.Group, hc *container.HandlerComponent) {
+	logLevelHandler := handler.NewLogLevelHandler(hc)
+	adminRoute.GET("/log-level", logLevelHandler.GetLogLevel)
+	adminRoute.PUT("/log-level", logLevelHandler.SetLogLevel)
+}
@@ -15,7 +15,76 @@ func publicRouter(e *echo.Echo, hc *container.HandlerComponent) {
 	{
 		tenantRoute.POST("", tenantHandler.CreateTenant)
 		tenantRoute.POST("/:clientID/process", tenantHandler.ProcessPayload)
+		tenantRoute.POST("/:clientID/process/batch", tenantHandler.ProcessBatch)
 		tenantRoute.DELETE("/:clientID", tenantHandler.DeleteTenant)
 	}
 
+	// Message
+	messageHandler := handler.NewMessageHandler(hc)
+	archiveHandler := handler.NewArchiveHandler(hc)
+	messageRoute := e.Group("/tenants/:clientID/messages")
+	{
+		messageRoute.GET("", archiveHandler.SearchMessages)
+		messageRoute.GET("/:messageID", messageHandler.GetMessage)
+	}
+
+	// Archive retention
+	retentionRoute := e.Group("/tenants/:clientID/retention")
+	{
+		retentionRoute.GET("", archiveHandler.GetRetention)
+		retentionRoute.PUT("", archiveHandler.SetRetention)
+		retentionRoute.DELETE("", archiveHandler.ResetRetention)
+	}
+
+	// Replay
+	replayHandler := handler.NewReplayHandler(hc)
+	replayRoute := e.Group("/tenants/:clientID/replay")
+	{
+		replayRoute.POST("", replayHandler.CreateReplay)
+		replayRoute.GET("/:jobID", replayHandler.GetReplay)
+		replayRoute.DELETE("/:jobID", replayHandler.CancelReplay)
+	}
+
+	// Scheduled payloads
+	scheduledHandler := handler.NewScheduledHandler(hc)
+	scheduledRoute := e.Group("/tenants/:clientID/scheduled")
+	{
+		scheduledRoute.GET("", scheduledHandler.ListScheduled)
+		scheduledRoute.DELETE("/:messageID", scheduledHandler.CancelScheduled)
+	}
+
+	// Cron schedules
+	cronHandler := handler.NewCronHandler(hc)
+	cronRoute := e.Group("/tenants/:clientID/schedules")
+	{
+		cronRoute.POST("", cronHandler.CreateSchedule)
+		cronRoute.GET("", cronHandler.ListSchedules)
+		cronRoute.GET("/:scheduleID", cronHandler.GetSchedule)
+		cronRoute.PUT("/:scheduleID", cronHandler.UpdateSchedule)
+		cronRoute.DELETE("/:scheduleID", cronHandler.DeleteSchedule)
+	}
+
+	// Webhooks
+	webhookHandler := handler.NewWebhookHandler(hc)
+	webhookRoute := e.Group("/tenants/:clientID/webhooks")
+	{
+		webhookRoute.POST("", webhookHandler.CreateWebhook)
+		webhookRoute.GET("", webhookHandler.ListWebhooks)
+		webhookRoute.GET("/:webhookID", webhookHandler.GetWebhook)
+		webhookRoute.PUT("/:webhookID", webhookHandler.UpdateWebhook)
+		webhookRoute.DELETE("/:webhookID", webhookHandler.DeleteWebhook)
+		webhookRoute.GET("/:webhookID/deliveries", webhookHandler.ListDeliveries)
+		webhookRoute.GET("/:webhookID/health", webhookHandler.GetHealth)
+	}
+
+	// Processors
+	processorHandler := handler.NewProcessorHandler(hc)
+	e.GET("/processors", processorHandler.ListProcessorTypes)
+	processorRoute := e.Group("/tenants/:clientID/processors")
+	{
+		processorRoute.GET("", processorHandler.GetProcessors)
+		processorRoute.PUT("", processorHandler.SetProcessors)
+		processorRoute.DELETE("", processorHandler.ResetProcessors)
+	}
+
 }
@@ -1,31 +1,181 @@
 package container
 
 import (
+	"context"
+	"time"
+
 	"tenant/infrastructure/config"
+	"tenant/internal/model"
 	"tenant/internal/repository"
 	"tenant/internal/service/messaging"
+	"tenant/internal/service/notification"
+	"tenant/internal/service/webhook"
 	"tenant/internal/usecase"
+	"tenant/pkg/health"
+	"tenant/pkg/logger"
 )
 
 type HandlerComponent struct {
 	Config *config.Config
 
+	// Health checks used by the readiness probe
+	Health *health.Health
+
+	// Runtime log level control
+	LogLevel *logger.LevelController
+
+	// Cached tenant lookups, nil when disabled
+	TenantCache repository.TenantCache
+
+	// Tenant events broadcast by the other instances
+	TenantListener *notification.TenantListener
+
 	// Usecase
-	TenantUsecase usecase.TenantUsecase
+	TenantUsecase    usecase.TenantUsecase
+	ConsumerUsecase  usecase.ConsumerUsecase
+	MessageUsecase   usecase.MessageUsecase
+	ArchiveUsecase   usecase.ArchiveUsecase
+	ReplayUsecase    usecase.ReplayUsecase
+	ScheduledUsecase usecase.ScheduledUsecase
+	CronUsecase      usecase.CronUsecase
+	WebhookUsecase   usecase.WebhookUsecase
+	ProcessorUsecase usecase.ProcessorUsecase
 }
 
 func NewHandlerComponent(sc *SharedComponent) *HandlerComponent {
 
 	// Library
-	mq := messaging.NewRabbitMQ(sc.RabbitMQConn)
+	mq := messaging.NewRabbitMQ(sc.RabbitMQConn, sc.Log, messaging.Config{
+		PublisherChannels: sc.Conf.RabbitMQ.PublisherChannels,
+		Prefetch:          sc.Conf.RabbitMQ.Prefetch,
+		Middlewares: []messaging.Middleware{
+			messaging.Recover(sc.Log),
+			messaging.TraceContext(),
+			messaging.Logging(sc.Log),
+			messaging.Metrics(),
+			messaging.Timeout(sc.Conf.Worker.HandlerTimeout),
+		},
+	})
 
 	tenantRepo := repository.NewTenantRepository(sc.DB)
-	tenantUsecase := usecase.NewTenantUsecase(tenantRepo, mq)
+
+	var tenantCache repository.TenantCache
+	if sc.Conf.Cache.TenantTTL > 0 {
+		tenantCache = repository.NewTenantCache(tenantRepo, repository.TenantCacheConfig{
+			TTL:         sc.Conf.Cache.TenantTTL,
+			NegativeTTL: sc.Conf.Cache.TenantNegativeTTL,
+			MaxEntries:  sc.Conf.Cache.TenantMaxEntries,
+		})
+		tenantRepo = tenantCache
+	}
+
+	idempotencyRepo := repository.NewIdempotencyRepository(sc.DB)
+	messageRepo := repository.NewMessageRepository(sc.DB)
+	archiveRepo := repository.NewArchiveRepository(sc.DB)
+	scheduledRepo := repository.NewScheduledRepository(sc.DB)
+	tenantUsecase := usecase.NewTenantUsecase(tenantRepo, idempotencyRepo, messageRepo, archiveRepo, scheduledRepo, mq, sc.Log, usecase.TenantConfig{
+		IdempotencyTTL:     sc.Conf.Process.IdempotencyTTL,
+		IdempotencyTimeout: sc.Conf.Process.IdempotencyTimeout,
+		MaxDelay:           sc.Conf.Process.MaxDelay,
+	})
+	webhookRepo := repository.NewWebhookRepository(sc.DB)
+	webhookUsecase := usecase.NewWebhookUsecase(webhookRepo, tenantRepo, webhook.NewSender(webhook.Config{
+		Timeout:              sc.Conf.Webhook.Timeout,
+		AllowPrivateNetworks: sc.Conf.Webhook.AllowPrivateNetworks,
+	}), sc.Log, usecase.WebhookConfig{
+		Retries:         sc.Conf.Webhook.Retries,
+		Backoff:         sc.Conf.Webhook.Backoff,
+		MaxBackoff:      sc.Conf.Webhook.MaxBackoff,
+		WorkerID:        sc.Conf.Worker.ID,
+		BreakerFailures: sc.Conf.Webhook.BreakerFailures,
+		BreakerCooldown: sc.Conf.Webhook.BreakerCooldown,
+
+		AllowPrivateNetworks: sc.Conf.Webhook.AllowPrivateNetworks,
+	})
+
+	// Processors tenants may select for their consumed messages
+	processorRepo := repository.NewProcessorRepository(sc.DB)
+	processors := usecase.NewProcessorRegistry()
+	processors.Register(model.ProcessorLog, usecase.LogProcessor(sc.Log))
+	processors.Register(model.ProcessorWebhook, usecase.WebhookProcessor(webhookUsecase))
+	processors.Register(model.ProcessorPostgres, usecase.PostgresProcessor(processorRepo))
+	processors.Register(model.ProcessorFile, usecase.FileProcessor(sc.Conf.Processor.FileDir))
+	processors.Register(model.ProcessorForward, usecase.ForwardProcessor(tenantUsecase))
+	processorUsecase := usecase.NewProcessorUsecase(processorRepo, tenantRepo, processors, sc.Log, usecase.ProcessorConfig{
+		CacheTTL: sc.Conf.Processor.CacheTTL,
+	})
+
+	leaseRepo := repository.NewLeaseRepository(sc.DB)
+	consumerUsecase := usecase.NewConsumerUsecase(tenantRepo, leaseRepo, messageRepo, processorUsecase, mq, sc.Log, usecase.ConsumerConfig{
+		WorkerID:    sc.Conf.Worker.ID,
+		Replicas:    sc.Conf.Worker.Replicas,
+		LeaseTTL:    sc.Conf.Worker.LeaseTTL,
+		MaxAttempts: sc.Conf.Worker.MaxAttempts,
+	})
+	messageUsecase := usecase.NewMessageUsecase(messageRepo, sc.Log)
+	archiveUsecase := usecase.NewArchiveUsecase(archiveRepo, tenantRepo, idempotencyRepo, sc.Log, usecase.ArchiveConfig{
+		Retention:       sc.Conf.Archive.Retention,
+		PartitionsAhead: sc.Conf.Archive.PartitionsAhead,
+	})
+	replayRepo := repository.NewReplayRepository(sc.DB)
+	replayUsecase := usecase.NewReplayUsecase(replayRepo, archiveRepo, tenantRepo, messageRepo, mq, sc.Log, usecase.ReplayConfig{
+		WorkerID:   sc.Conf.Worker.ID,
+		Rate:       sc.Conf.Replay.Rate,
+		MaxRate:    sc.Conf.Replay.MaxRate,
+		StaleAfter: sc.Conf.Replay.StaleAfter,
+	})
+	scheduledUsecase := usecase.NewScheduledUsecase(scheduledRepo, tenantRepo, messageRepo, archiveRepo, mq, sc.Log, usecase.ScheduledConfig{
+		BatchSize:   sc.Conf.Schedule.BatchSize,
+		MaxAttempts: sc.Conf.Schedule.MaxAttempts,
+		Backoff:     sc.Conf.Schedule.Backoff,
+		MaxBackoff:  sc.Conf.Schedule.MaxBackoff,
+	})
+	cronRepo := repository.NewCronRepository(sc.DB)
+	cronUsecase := usecase.NewCronUsecase(cronRepo, leaseRepo, tenantRepo, tenantUsecase, sc.Log, usecase.CronConfig{
+		WorkerID:  sc.Conf.Worker.ID,
+		LeaderTTL: sc.Conf.Cron.LeaderTTL,
+	})
+
+	// Tenants created or deleted elsewhere are picked up without waiting
+	// for the next sync or the cache TTL
+	tenantListener := notification.NewTenantListener(sc.DB, sc.Log)
+	tenantListener.Subscribe(func(ctx context.Context, event model.TenantEvent) {
+		consumerUsecase.Resync()
+	})
+	if tenantCache != nil {
+		tenantListener.Subscribe(func(ctx context.Context, event model.TenantEvent) {
+			if event.Type == model.TenantEventReset {
+				tenantCache.Purge()
+				return
+			}
+			tenantCache.Invalidate(event.ClientID)
+		})
+	}
+
+	// Health
+	hc := health.New(5 * time.Second)
+	hc.Register("postgres", health.CheckerFunc(sc.DB.Ping))
+	hc.Register("rabbitmq", health.CheckerFunc(func(context.Context) error { return mq.Ping() }))
+	hc.Register("consumers", health.CheckerFunc(func(context.Context) error { return mq.CheckConsumers() }))
 
 	return &HandlerComponent{
 		Config: sc.Conf,
+		Health: hc,
+
+		LogLevel: sc.LogLevel,
+
+		TenantCache:    tenantCache,
+		TenantListener: tenantListener,
 
 		// Usecase
-		TenantUsecase: tenantUsecase,
+		TenantUsecase:    tenantUsecase,
+		ConsumerUsecase:  consumerUsecase,
+		MessageUsecase:   messageUsecase,
+		ArchiveUsecase:   archiveUsecase,
+		ReplayUsecase:    replayUsecase,
+		ScheduledUsecase: scheduledUsecase,
+		CronUsecase:      cronUsecase,
+		WebhookUsecase:   webhookUsecase,
+		ProcessorUsecase: processorUsecase,
 	}
 }
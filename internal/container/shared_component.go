@@ -3,47 +3,109 @@ package container
 import (
 	"tenant/infrastructure/config"
 	"tenant/infrastructure/database"
+	"tenant/pkg/derrors"
 	"tenant/pkg/logger"
 	"time"
 
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/rabbitmq/amqp091-go"
+	"github.com/segmentio/ksuid"
 	"github.com/sirupsen/logrus"
 )
 
+// Dependency is an external resource a command may need.
+type Dependency int
+
+const (
+	Database Dependency = iota
+	RabbitMQ
+)
+
 // SharedComponent holds shared dependencies between components
 type SharedComponent struct {
 	Conf         *config.Config
 	Log          *logrus.Logger
+	LogLevel     *logger.LevelController
 	DB           *pgxpool.Pool
 	RabbitMQConn *amqp091.Connection
 }
 
-func InitSharedComponent() (*SharedComponent, error) {
+// InitSharedComponent starts the logger and connects only the requested
+// dependencies, so commands do not pay for what they do not use.
+func InitSharedComponent(conf *config.Config, deps ...Dependency) (*SharedComponent, error) {
 
-	// Load configuration
-	config.Init()
-	conf := config.Get()
+	// Every component of the process shares one worker ID, so leases, replay
+	// jobs, the cron leadership and webhook health name the same worker
+	if conf.Worker.ID == "" {
+		conf.Worker.ID = ksuid.New().String()
+	}
 
 	// Start Logger
 	log := logger.NewLogger(*conf)
 
+	sharedComponent := &SharedComponent{
+		Conf:     conf,
+		Log:      log,
+		LogLevel: logger.NewLevelController(log),
+	}
+
+	for _, dep := range deps {
+		var err error
+		switch dep {
+		case Database:
+			err = sharedComponent.initDatabase()
+		case RabbitMQ:
+			err = sharedComponent.initRabbitMQ()
+		}
+		if err != nil {
+			sharedComponent.Close()
+			return nil, err
+		}
+	}
+
+	return sharedComponent, nil
+}
+
+// Close releases every dependency that was connected.
+func (sc *SharedComponent) Close() {
+	if sc.RabbitMQConn != nil && !sc.RabbitMQConn.IsClosed() {
+		if err := sc.RabbitMQConn.Close(); err != nil {
+			sc.Log.Errorf("error: gracefully shutting down rabbitmq connection : %s", err)
+		}
+	}
+	if sc.DB != nil {
+		sc.DB.Close()
+	}
+}
+
+func (sc *SharedComponent) initDatabase() error {
 	// Start Database
-	database, err := database.InitializeDatabase(conf)
+	database, err := database.InitializeDatabase(sc.Conf)
 	if err != nil {
-		log.Errorf("web failed to init db %v", err)
-		return nil, err
+		sc.Log.Errorf("web failed to init db %v", err)
+		return derrors.HandlePgxError(err, "failed to init db")
 	}
 
+	sc.DB = database
+	return nil
+}
+
+func (sc *SharedComponent) initRabbitMQ() error {
+	conf := sc.Conf
+	log := sc.Log
+
 	mqConn, err := amqp091.Dial(conf.RabbitMQ.URL)
 	if err != nil {
 		log.Errorf("failed to connect to RabbitMQ: %v", err)
-		return nil, err
+		return derrors.HandleAMQPError(err, "failed to connect to RabbitMQ")
 	}
 
 	go func() {
 		for {
-			<-mqConn.NotifyClose(make(chan *amqp091.Error))
+			// A nil error means the connection was closed on purpose
+			if closeErr := <-mqConn.NotifyClose(make(chan *amqp091.Error, 1)); closeErr == nil {
+				return
+			}
 			for i := 0; i < conf.RabbitMQ.MaxReconnects; i++ {
 				if mqConnr, err := amqp091.Dial(conf.RabbitMQ.URL); err == nil {
 					log.Println("Reconnected to RabbitMQ")
@@ -56,12 +118,6 @@ func InitSharedComponent() (*SharedComponent, error) {
 		}
 	}()
 
-	sharedComponent := &SharedComponent{
-		DB:           database,
-		Conf:         conf,
-		Log:          log,
-		RabbitMQConn: mqConn,
-	}
-
-	return sharedComponent, nil
+	sc.RabbitMQConn = mqConn
+	return nil
 }
@@ -0,0 +1,113 @@
+package model
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ArchivedMessage is a published payload kept for audits
+type ArchivedMessage struct {
+	MessageID     string            `json:"message_id"`
+	ClientID      string            `json:"client_id"`
+	CorrelationID string            `json:"correlation_id,omitempty"`
+	ContentType   string            `json:"content_type"`
+	Headers       map[string]string `json:"headers,omitempty"`
+	Payload       json.RawMessage   `json:"payload" swaggertype:"object"`
+
+	// Size is the size of the published body in bytes
+	Size        int       `json:"size"`
+	PublishedAt time.Time `json:"published_at"`
+	ArchivedAt  time.Time `json:"archived_at"`
+}
+
+// ArchiveQuery filters the archived messages of a tenant. Zero fields do not
+// filter.
+type ArchiveQuery struct {
+	ClientID string
+	From     time.Time
+	To       time.Time
+
+	// Headers matches messages holding every one of these headers
+	Headers map[string]string
+
+	// Text is a full-text search on the payload
+	Text string
+
+	// MessageIDs matches only the listed messages
+	MessageIDs []string
+
+	Limit int
+
+	// Ascending returns the oldest messages first instead of the newest
+	Ascending bool
+
+	// After resumes a search after the last message of the previous page
+	After *ArchiveCursor
+}
+
+// ArchiveCursor is the position of a message in search results
+type ArchiveCursor struct {
+	PublishedAt time.Time
+	MessageID   string
+}
+
+// String encodes the cursor for the next_cursor of a page
+func (c ArchiveCursor) String() string {
+	raw := strconv.FormatInt(c.PublishedAt.UnixNano(), 10) + ":" + c.MessageID
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// ParseArchiveCursor decodes a cursor returned as next_cursor
+func ParseArchiveCursor(s string) (*ArchiveCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, errors.New("malformed cursor")
+	}
+	nanos, messageID, ok := strings.Cut(string(raw), ":")
+	if !ok || messageID == "" {
+		return nil, errors.New("malformed cursor")
+	}
+	n, err := strconv.ParseInt(nanos, 10, 64)
+	if err != nil {
+		return nil, errors.New("malformed cursor")
+	}
+	return &ArchiveCursor{PublishedAt: time.Unix(0, n), MessageID: messageID}, nil
+}
+
+// ArchivePage is a page of search results. NextCursor is empty on the last
+// page.
+type ArchivePage struct {
+	Messages   []*ArchivedMessage `json:"messages"`
+	NextCursor string             `json:"next_cursor,omitempty"`
+}
+
+// RetentionPolicy is how long the archived messages of a tenant are kept.
+// Default is set when the tenant uses the configured retention.
+type RetentionPolicy struct {
+	ClientID  string        `json:"client_id"`
+	Retention time.Duration `json:"retention" swaggertype:"string" example:"720h0m0s"`
+	Default   bool          `json:"default"`
+}
+
+// MarshalJSON renders the retention as a duration string such as "720h0m0s"
+func (p RetentionPolicy) MarshalJSON() ([]byte, error) {
+	type policy RetentionPolicy
+	return json.Marshal(struct {
+		policy
+		Retention string `json:"retention"`
+	}{policy: policy(p), Retention: p.Retention.String()})
+}
+
+// PruneResult counts what a run of the pruning job removed
+type PruneResult struct {
+	ArchivedMessages  int64    `json:"archived_messages"`
+	Messages          int64    `json:"messages"`
+	IdempotencyKeys   int64    `json:"idempotency_keys"`
+	WebhookDeliveries int64    `json:"webhook_deliveries"`
+	DroppedPartitions []string `json:"dropped_partitions,omitempty"`
+	Skipped           bool     `json:"skipped,omitempty"`
+}
@@ -0,0 +1,34 @@
+package model
+
+// Statuses of a BatchItemResult
+const (
+	BatchItemPublished = "published"
+	BatchItemFailed    = "failed"
+)
+
+// BatchItemResult is the outcome of one payload of a batch, Index is its
+// position in the request
+type BatchItemResult struct {
+	Index     int    `json:"index"`
+	MessageID string `json:"message_id,omitempty"`
+	Status    string `json:"status"`
+	Code      string `json:"code,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
+
+// BatchResult is the outcome of every payload of a batch
+type BatchResult struct {
+	Published int                `json:"published"`
+	Failed    int                `json:"failed"`
+	Results   []*BatchItemResult `json:"results"`
+}
+
+// Add records an item result and updates the counters
+func (r *BatchResult) Add(item *BatchItemResult) {
+	if item.Status == BatchItemPublished {
+		r.Published++
+	} else {
+		r.Failed++
+	}
+	r.Results = append(r.Results, item)
+}
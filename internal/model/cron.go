@@ -0,0 +1,40 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// CronSchedule publishes a payload to the queue of its tenant every time its
+// cron expression fires in its timezone. String values of Payload are
+// templates, rendered with a CronRun on every run.
+type CronSchedule struct {
+	ID         string            `json:"schedule_id"`
+	ClientID   string            `json:"client_id"`
+	Name       string            `json:"name"`
+	Expression string            `json:"cron" example:"*/5 * * * *"`
+	Timezone   string            `json:"timezone" example:"Europe/Paris"`
+	Payload    json.RawMessage   `json:"payload" swaggertype:"object"`
+	Headers    map[string]string `json:"headers,omitempty"`
+	Enabled    bool              `json:"enabled"`
+
+	// NextRunAt is nil while the schedule is disabled
+	NextRunAt     *time.Time `json:"next_run_at,omitempty"`
+	LastRunAt     *time.Time `json:"last_run_at,omitempty"`
+	LastMessageID string     `json:"last_message_id,omitempty"`
+	LastError     string     `json:"last_error,omitempty"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// CronRun is the data available to payload templates, such as
+// {{.ScheduledAt}}
+type CronRun struct {
+	ScheduleID string
+	Name       string
+	ClientID   string
+
+	// ScheduledAt is the RFC 3339 time the run was due
+	ScheduledAt string
+}
@@ -0,0 +1,72 @@
+package model
+
+import (
+	"time"
+)
+
+// Payload is a message published to the queue of a tenant, along with the
+// AMQP properties set by the client
+type Payload struct {
+	Body          interface{}
+	Headers       map[string]string
+	CorrelationID string
+	ContentType   string
+	Priority      uint8
+	Expiration    time.Duration
+
+	// DeliverAt delays the publication of the payload until this time, it is
+	// published right away when zero or past. It is not part of the
+	// idempotency fingerprint since a delay resolves to a new time on retries.
+	DeliverAt time.Time `json:"-"`
+
+	// IdempotencyKey makes retries of the same payload publish it only once
+	IdempotencyKey string `json:"-"`
+}
+
+// IdempotencyRecord remembers the message published for an Idempotency-Key,
+// MessageID is empty while the first request is still publishing
+type IdempotencyRecord struct {
+	ClientID    string
+	Key         string
+	RequestHash string
+	MessageID   string
+	CreatedAt   time.Time
+	ExpiresAt   time.Time
+}
+
+// Statuses of a Message, in the order a successful message goes through them
+const (
+	MessageAccepted     = "accepted"
+	MessagePublished    = "published"
+	MessageDelivered    = "delivered"
+	MessageProcessed    = "processed"
+	MessageFailed       = "failed"
+	MessageDeadLettered = "dead_lettered"
+
+	// MessageScheduled precedes MessagePublished for delayed payloads, which
+	// end as MessageCancelled when cancelled before being published
+	MessageScheduled = "scheduled"
+	MessageCancelled = "cancelled"
+)
+
+// Message tracks a payload from its acceptance to its processing, Events is
+// its timeline
+type Message struct {
+	ID            string          `json:"message_id"`
+	ClientID      string          `json:"client_id"`
+	Status        string          `json:"status"`
+	CorrelationID string          `json:"correlation_id,omitempty"`
+	CreatedAt     time.Time       `json:"created_at"`
+	UpdatedAt     time.Time       `json:"updated_at"`
+	Events        []*MessageEvent `json:"events,omitempty"`
+}
+
+// MessageEvent is a status transition of a message. Attempt is the
+// processing attempt for consumer transitions.
+type MessageEvent struct {
+	MessageID string    `json:"-"`
+	Status    string    `json:"status"`
+	Attempt   int       `json:"attempt,omitempty"`
+	Detail    string    `json:"detail,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
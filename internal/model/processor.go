@@ -0,0 +1,47 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Types of ProcessorSpec registered by default
+const (
+	// ProcessorLog logs every message
+	ProcessorLog = "log"
+	// ProcessorWebhook delivers every message to the webhooks of the tenant
+	ProcessorWebhook = "webhook"
+	// ProcessorPostgres stores every message in the sink_messages table
+	ProcessorPostgres = "postgres"
+	// ProcessorFile appends every message to a file as a JSON line
+	ProcessorFile = "file"
+	// ProcessorForward publishes every message to another tenant
+	ProcessorForward = "forward"
+)
+
+// ProcessorSpec selects a processor and its options, the options depend on
+// the type
+type ProcessorSpec struct {
+	Type    string          `json:"type" example:"webhook"`
+	Options json.RawMessage `json:"options,omitempty" swaggertype:"object"`
+}
+
+// ProcessorChain is the processors a tenant runs on every consumed message,
+// in order. Default is set when the tenant uses the default chain.
+type ProcessorChain struct {
+	ClientID   string          `json:"client_id"`
+	Processors []ProcessorSpec `json:"processors"`
+	Default    bool            `json:"default"`
+	UpdatedAt  *time.Time      `json:"updated_at,omitempty"`
+}
+
+// SinkMessage is a consumed message stored by the postgres processor
+type SinkMessage struct {
+	MessageID     string            `json:"message_id"`
+	ClientID      string            `json:"client_id"`
+	CorrelationID string            `json:"correlation_id,omitempty"`
+	ContentType   string            `json:"content_type"`
+	Headers       map[string]string `json:"headers,omitempty"`
+	Payload       json.RawMessage   `json:"payload" swaggertype:"object"`
+	ReceivedAt    time.Time         `json:"received_at"`
+}
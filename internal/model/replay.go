@@ -0,0 +1,46 @@
+package model
+
+import (
+	"time"
+)
+
+// Statuses of a ReplayJob
+const (
+	ReplayPending   = "pending"
+	ReplayRunning   = "running"
+	ReplayCompleted = "completed"
+	ReplayFailed    = "failed"
+	ReplayCancelled = "cancelled"
+)
+
+// ReplayJob republishes archived messages of a tenant to its queue. It
+// selects the messages published between From and To, or the messages
+// listed in MessageIDs, and publishes at most Rate messages per second.
+type ReplayJob struct {
+	ID         string     `json:"job_id"`
+	ClientID   string     `json:"client_id"`
+	Status     string     `json:"status"`
+	From       *time.Time `json:"from,omitempty"`
+	To         *time.Time `json:"to,omitempty"`
+	MessageIDs []string   `json:"message_ids,omitempty"`
+	Rate       float64    `json:"rate"`
+
+	Total     int64  `json:"total"`
+	Published int64  `json:"published"`
+	Failed    int64  `json:"failed"`
+	Error     string `json:"error,omitempty"`
+
+	// Cursor is the last replayed message, WorkerID the process running
+	// the job
+	Cursor   *ArchiveCursor `json:"-"`
+	WorkerID string         `json:"-"`
+
+	CreatedAt  time.Time  `json:"created_at"`
+	StartedAt  *time.Time `json:"started_at,omitempty"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+}
+
+// Done reports whether the job reached a final status
+func (j *ReplayJob) Done() bool {
+	return j.Status == ReplayCompleted || j.Status == ReplayFailed || j.Status == ReplayCancelled
+}
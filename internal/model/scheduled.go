@@ -0,0 +1,28 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// ScheduledMessage is a payload waiting for its delivery time before being
+// published to the queue of its tenant
+type ScheduledMessage struct {
+	MessageID     string            `json:"message_id"`
+	ClientID      string            `json:"client_id"`
+	Payload       json.RawMessage   `json:"payload" swaggertype:"object"`
+	Headers       map[string]string `json:"headers,omitempty"`
+	CorrelationID string            `json:"correlation_id,omitempty"`
+	ContentType   string            `json:"content_type,omitempty"`
+	Priority      uint8             `json:"priority,omitempty"`
+	Expiration    time.Duration     `json:"-"`
+	DeliverAt     time.Time         `json:"deliver_at"`
+
+	// Attempts counts the failed publications, LastError is the cause of the
+	// last one. FailedAt is set once the publication is given up, the payload
+	// is kept until cancelled.
+	Attempts  int        `json:"attempts,omitempty"`
+	LastError string     `json:"last_error,omitempty"`
+	FailedAt  *time.Time `json:"failed_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
@@ -12,3 +12,19 @@ type Tenant struct {
 	UpdatedAt time.Time
 	DeletedAt *time.Time
 }
+
+// Types of TenantEvent
+const (
+	TenantEventCreated = "created"
+	TenantEventDeleted = "deleted"
+
+	// TenantEventReset is emitted locally when the listener (re)connects,
+	// since events may have been missed while it was not listening
+	TenantEventReset = "reset"
+)
+
+// TenantEvent describes a change to a tenant, broadcast to every instance
+type TenantEvent struct {
+	Type     string `json:"type"`
+	ClientID string `json:"client_id,omitempty"`
+}
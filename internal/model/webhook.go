@@ -0,0 +1,92 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Webhook receives every message of its tenant as a signed HTTP POST
+type Webhook struct {
+	ID       string `json:"webhook_id"`
+	ClientID string `json:"client_id"`
+	URL      string `json:"url" example:"https://example.com/hooks/tenant"`
+
+	// Secret signs the deliveries, it is only returned when it is set
+	Secret  string `json:"secret,omitempty"`
+	Enabled bool   `json:"enabled"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// WebhookDelivery records an HTTP request made to deliver a message to a
+// webhook
+type WebhookDelivery struct {
+	ID        int64  `json:"id"`
+	WebhookID string `json:"webhook_id"`
+	ClientID  string `json:"client_id"`
+	MessageID string `json:"message_id"`
+
+	// Attempt is the processing attempt of the message, Retry counts the
+	// requests made to the webhook within that attempt, starting at 0
+	Attempt int `json:"attempt"`
+	Retry   int `json:"retry"`
+
+	// StatusCode is zero when no response was received
+	StatusCode int           `json:"status_code,omitempty"`
+	Duration   time.Duration `json:"duration" swaggertype:"string" example:"120ms"`
+	Error      string        `json:"error,omitempty"`
+	Succeeded  bool          `json:"succeeded"`
+
+	DeliveredAt time.Time `json:"delivered_at"`
+}
+
+// MarshalJSON renders the duration as a string such as "120ms"
+func (d WebhookDelivery) MarshalJSON() ([]byte, error) {
+	type delivery WebhookDelivery
+	return json.Marshal(struct {
+		delivery
+		Duration string `json:"duration"`
+	}{delivery: delivery(d), Duration: d.Duration.String()})
+}
+
+// Circuit breaker states of a webhook
+const (
+	WebhookClosed   = "closed"
+	WebhookHalfOpen = "half_open"
+	WebhookOpen     = "open"
+)
+
+// WebhookHealth is the circuit breaker of a webhook as last reported by a
+// worker delivering to it. The counters cover the requests made by that
+// worker since it started.
+type WebhookHealth struct {
+	WebhookID string `json:"webhook_id"`
+	ClientID  string `json:"client_id"`
+	WorkerID  string `json:"worker_id,omitempty"`
+
+	// State is closed, half_open or open. An open webhook holds back the
+	// messages of its tenant until RetryAt.
+	State               string `json:"state" example:"closed"`
+	ConsecutiveFailures int    `json:"consecutive_failures"`
+	Successes           int64  `json:"successes"`
+	Failures            int64  `json:"failures"`
+
+	LastLatency time.Duration `json:"last_latency" swaggertype:"string" example:"120ms"`
+	AvgLatency  time.Duration `json:"avg_latency" swaggertype:"string" example:"95ms"`
+	LastError   string        `json:"last_error,omitempty"`
+
+	OpenedAt  *time.Time `json:"opened_at,omitempty"`
+	RetryAt   *time.Time `json:"retry_at,omitempty"`
+	UpdatedAt *time.Time `json:"updated_at,omitempty"`
+}
+
+// MarshalJSON renders the latencies as strings such as "120ms"
+func (h WebhookHealth) MarshalJSON() ([]byte, error) {
+	type health WebhookHealth
+	return json.Marshal(struct {
+		health
+		LastLatency string `json:"last_latency"`
+		AvgLatency  string `json:"avg_latency"`
+	}{health: health(h), LastLatency: h.LastLatency.String(), AvgLatency: h.AvgLatency.String()})
+}
@@ -0,0 +1,25 @@
+package model
+
+import (
+	"time"
+)
+
+type Worker struct {
+	ID          string    `json:"id"`
+	Hostname    string    `json:"hostname"`
+	StartedAt   time.Time `json:"started_at"`
+	HeartbeatAt time.Time `json:"heartbeat_at"`
+}
+
+type TenantLease struct {
+	ClientID   string    `json:"client_id"`
+	WorkerID   string    `json:"worker_id"`
+	AcquiredAt time.Time `json:"acquired_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+}
+
+// Assignments is a snapshot of the live workers and the tenants they hold
+type Assignments struct {
+	Workers []*Worker      `json:"workers"`
+	Leases  []*TenantLease `json:"leases"`
+}
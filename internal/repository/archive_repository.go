@@ -0,0 +1,326 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"tenant/internal/model"
+	"tenant/pkg/derrors"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// archivePartitionPrefix names the monthly partitions of message_archive,
+// followed by the month as y2006m01
+const archivePartitionPrefix = "message_archive_"
+
+// ArchiveRepository keeps published payloads for audits, along with the
+// retention policy of every tenant
+type ArchiveRepository interface {
+	Archive(ctx context.Context, messages ...*model.ArchivedMessage) error
+	Search(ctx context.Context, query *model.ArchiveQuery) ([]*model.ArchivedMessage, error)
+	Count(ctx context.Context, query *model.ArchiveQuery) (int64, error)
+	GetRetention(ctx context.Context, clientID string) (*model.RetentionPolicy, error)
+	SetRetention(ctx context.Context, policy *model.RetentionPolicy) error
+	DeleteRetention(ctx context.Context, clientID string) error
+	Prune(ctx context.Context, now time.Time, defaultRetention time.Duration, partitionsAhead int) (*model.PruneResult, error)
+}
+
+type archiveRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewArchiveRepository(db *pgxpool.Pool) ArchiveRepository {
+	return &archiveRepository{db: db}
+}
+
+// Archive stores published messages in a single round trip. Archiving a
+// message twice keeps the first copy.
+func (r *archiveRepository) Archive(ctx context.Context, messages ...*model.ArchivedMessage) error {
+	if len(messages) == 0 {
+		return nil
+	}
+
+	batch := &pgx.Batch{}
+	for _, message := range messages {
+		headers := message.Headers
+		if headers == nil {
+			headers = map[string]string{}
+		}
+		batch.Queue(`
+            INSERT INTO message_archive (message_id, client_id, correlation_id, content_type, headers, payload, size, published_at, archived_at)
+            VALUES ($1, $2, NULLIF($3, ''), $4, $5, $6, $7, $8, NOW())
+            ON CONFLICT DO NOTHING
+        `, message.MessageID, message.ClientID, message.CorrelationID, message.ContentType, headers, message.Payload, message.Size, message.PublishedAt)
+	}
+
+	err := r.db.SendBatch(ctx, batch).Close()
+	return derrors.HandlePgxError(err, "archive %d messages", len(messages))
+}
+
+// Search returns the archived messages of a tenant matching query, newest
+// first unless query is Ascending
+func (r *archiveRepository) Search(ctx context.Context, query *model.ArchiveQuery) ([]*model.ArchivedMessage, error) {
+	where, args := archiveConditions(query)
+
+	order, compare := "DESC", "<"
+	if query.Ascending {
+		order, compare = "ASC", ">"
+	}
+	if query.After != nil {
+		args = append(args, query.After.PublishedAt, query.After.MessageID)
+		where += fmt.Sprintf(" AND (published_at, message_id) %s ($%d, $%d)", compare, len(args)-1, len(args))
+	}
+	args = append(args, query.Limit)
+
+	sql := `
+        SELECT message_id, client_id, COALESCE(correlation_id, ''), content_type, headers, payload, size, published_at, archived_at
+        FROM message_archive
+        WHERE ` + where + `
+        ORDER BY published_at ` + order + `, message_id ` + order + `
+        LIMIT ` + fmt.Sprintf("$%d", len(args))
+
+	rows, err := r.db.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, derrors.HandlePgxError(err, "search archived messages")
+	}
+	defer rows.Close()
+
+	messages := make([]*model.ArchivedMessage, 0, query.Limit)
+	for rows.Next() {
+		var message model.ArchivedMessage
+		err := rows.Scan(
+			&message.MessageID,
+			&message.ClientID,
+			&message.CorrelationID,
+			&message.ContentType,
+			&message.Headers,
+			&message.Payload,
+			&message.Size,
+			&message.PublishedAt,
+			&message.ArchivedAt,
+		)
+		if err != nil {
+			return nil, derrors.HandlePgxError(err, "scan archived message")
+		}
+		messages = append(messages, &message)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, derrors.HandlePgxError(err, "search archived messages")
+	}
+	return messages, nil
+}
+
+// Count returns how many archived messages match query, ignoring its
+// cursor and limit
+func (r *archiveRepository) Count(ctx context.Context, query *model.ArchiveQuery) (int64, error) {
+	where, args := archiveConditions(query)
+
+	var count int64
+	err := r.db.QueryRow(ctx, `SELECT COUNT(*) FROM message_archive WHERE `+where, args...).Scan(&count)
+	if err != nil {
+		return 0, derrors.HandlePgxError(err, "count archived messages")
+	}
+	return count, nil
+}
+
+// archiveConditions returns the WHERE clause selecting the messages matching
+// the filters of query, along with its arguments
+func archiveConditions(query *model.ArchiveQuery) (string, []interface{}) {
+	conditions := []string{"client_id = $1"}
+	args := []interface{}{query.ClientID}
+	arg := func(value interface{}) string {
+		args = append(args, value)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	if !query.From.IsZero() {
+		conditions = append(conditions, "published_at >= "+arg(query.From))
+	}
+	if !query.To.IsZero() {
+		conditions = append(conditions, "published_at < "+arg(query.To))
+	}
+	if len(query.Headers) > 0 {
+		conditions = append(conditions, "headers @> "+arg(query.Headers))
+	}
+	if query.Text != "" {
+		conditions = append(conditions, "to_tsvector('simple', payload::text) @@ plainto_tsquery('simple', "+arg(query.Text)+")")
+	}
+	if len(query.MessageIDs) > 0 {
+		conditions = append(conditions, "message_id = ANY("+arg(query.MessageIDs)+")")
+	}
+	return strings.Join(conditions, " AND "), args
+}
+
+// GetRetention returns the retention policy of a tenant, NotFound when the
+// tenant uses the default retention
+func (r *archiveRepository) GetRetention(ctx context.Context, clientID string) (*model.RetentionPolicy, error) {
+	var seconds int64
+	err := r.db.QueryRow(ctx, `SELECT retention_seconds FROM archive_retention_policies WHERE client_id = $1`, clientID).Scan(&seconds)
+	if err != nil {
+		return nil, derrors.HandlePgxError(err, "get retention of tenant %q", clientID)
+	}
+	return &model.RetentionPolicy{ClientID: clientID, Retention: time.Duration(seconds) * time.Second}, nil
+}
+
+// SetRetention creates or replaces the retention policy of a tenant
+func (r *archiveRepository) SetRetention(ctx context.Context, policy *model.RetentionPolicy) error {
+	query := `
+        INSERT INTO archive_retention_policies (client_id, retention_seconds, updated_at)
+        VALUES ($1, $2, NOW())
+        ON CONFLICT (client_id) DO UPDATE
+        SET retention_seconds = EXCLUDED.retention_seconds, updated_at = EXCLUDED.updated_at
+    `
+	_, err := r.db.Exec(ctx, query, policy.ClientID, int64(policy.Retention/time.Second))
+	return derrors.HandlePgxError(err, "set retention of tenant %q", policy.ClientID)
+}
+
+// DeleteRetention moves a tenant back to the default retention
+func (r *archiveRepository) DeleteRetention(ctx context.Context, clientID string) error {
+	_, err := r.db.Exec(ctx, `DELETE FROM archive_retention_policies WHERE client_id = $1`, clientID)
+	return derrors.HandlePgxError(err, "delete retention of tenant %q", clientID)
+}
+
+// Prune deletes the archived messages and message statuses older than the
+// retention of their tenant, drops the monthly partitions older than every
+// retention and creates the partitions of the current month and the
+// partitionsAhead next ones. Only one instance prunes at a time, the others
+// report the run as skipped.
+func (r *archiveRepository) Prune(ctx context.Context, now time.Time, defaultRetention time.Duration, partitionsAhead int) (result *model.PruneResult, err error) {
+	defer derrors.Wrap(&err, "prune archive")
+
+	conn, err := r.db.Acquire(ctx)
+	if err != nil {
+		return nil, derrors.HandlePgxError(err, "acquire connection")
+	}
+	defer conn.Release()
+
+	var locked bool
+	if err := conn.QueryRow(ctx, `SELECT pg_try_advisory_lock(hashtext('archive_prune'))`).Scan(&locked); err != nil {
+		return nil, derrors.HandlePgxError(err, "lock archive")
+	}
+	if !locked {
+		return &model.PruneResult{Skipped: true}, nil
+	}
+	defer func() {
+		// The connection returns to the pool, the lock must not stay with it
+		if _, err := conn.Exec(context.Background(), `SELECT pg_advisory_unlock(hashtext('archive_prune'))`); err != nil {
+			conn.Conn().Close(context.Background())
+		}
+	}()
+
+	result = &model.PruneResult{}
+	defaultSeconds := defaultRetention.Seconds()
+
+	cmdTag, err := conn.Exec(ctx, `
+        DELETE FROM message_archive a
+        WHERE a.published_at < $1::timestamptz - make_interval(secs => COALESCE(
+            (SELECT p.retention_seconds FROM archive_retention_policies p WHERE p.client_id = a.client_id), $2
+        ))
+    `, now, defaultSeconds)
+	if err != nil {
+		return nil, derrors.HandlePgxError(err, "delete expired archived messages")
+	}
+	result.ArchivedMessages = cmdTag.RowsAffected()
+
+	cmdTag, err = conn.Exec(ctx, `
+        DELETE FROM messages m
+        WHERE m.created_at < $1::timestamptz - make_interval(secs => COALESCE(
+            (SELECT p.retention_seconds FROM archive_retention_policies p WHERE p.client_id = m.client_id), $2
+        ))
+    `, now, defaultSeconds)
+	if err != nil {
+		return nil, derrors.HandlePgxError(err, "delete expired messages")
+	}
+	result.Messages = cmdTag.RowsAffected()
+
+	cmdTag, err = conn.Exec(ctx, `
+        DELETE FROM webhook_deliveries d
+        WHERE d.delivered_at < $1::timestamptz - make_interval(secs => COALESCE(
+            (SELECT p.retention_seconds FROM archive_retention_policies p WHERE p.client_id = d.client_id), $2
+        ))
+    `, now, defaultSeconds)
+	if err != nil {
+		return nil, derrors.HandlePgxError(err, "delete expired webhook deliveries")
+	}
+	result.WebhookDeliveries = cmdTag.RowsAffected()
+
+	// Whole months older than the longest retention hold nothing to keep
+	var longest float64
+	err = conn.QueryRow(ctx, `SELECT GREATEST($1, COALESCE(MAX(retention_seconds), 0)) FROM archive_retention_policies`, defaultSeconds).Scan(&longest)
+	if err != nil {
+		return nil, derrors.HandlePgxError(err, "get longest retention")
+	}
+	expired := now.Add(-time.Duration(longest * float64(time.Second)))
+
+	partitions, err := listArchivePartitions(ctx, conn.Conn())
+	if err != nil {
+		return nil, err
+	}
+	for name, month := range partitions {
+		if month.AddDate(0, 1, 0).After(expired) {
+			continue
+		}
+		if _, err := conn.Exec(ctx, `DROP TABLE IF EXISTS `+pgx.Identifier{name}.Sanitize()); err != nil {
+			return nil, derrors.HandlePgxError(err, "drop partition %s", name)
+		}
+		result.DroppedPartitions = append(result.DroppedPartitions, name)
+	}
+
+	month := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i <= partitionsAhead; i++ {
+		from := month.AddDate(0, i, 0)
+		name := archivePartitionName(from)
+		if _, ok := partitions[name]; ok {
+			continue
+		}
+		query := fmt.Sprintf(
+			`CREATE TABLE IF NOT EXISTS %s PARTITION OF message_archive FOR VALUES FROM ('%s') TO ('%s')`,
+			pgx.Identifier{name}.Sanitize(), from.Format(time.RFC3339), from.AddDate(0, 1, 0).Format(time.RFC3339),
+		)
+		if _, err := conn.Exec(ctx, query); err != nil {
+			// Rows of that month already went to the default partition
+			return result, derrors.HandlePgxError(err, "create partition %s", name)
+		}
+	}
+
+	return result, nil
+}
+
+// listArchivePartitions returns the monthly partitions of message_archive by
+// name, along with the first instant of their month
+func listArchivePartitions(ctx context.Context, conn *pgx.Conn) (map[string]time.Time, error) {
+	query := `
+        SELECT c.relname
+        FROM pg_inherits i
+        JOIN pg_class c ON c.oid = i.inhrelid
+        WHERE i.inhparent = 'message_archive'::regclass
+    `
+	rows, err := conn.Query(ctx, query)
+	if err != nil {
+		return nil, derrors.HandlePgxError(err, "list archive partitions")
+	}
+	names, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		return nil, derrors.HandlePgxError(err, "list archive partitions")
+	}
+
+	partitions := make(map[string]time.Time, len(names))
+	for _, name := range names {
+		// Skips the default partition
+		month, err := time.Parse("y2006m01", strings.TrimPrefix(name, archivePartitionPrefix))
+		if err != nil {
+			continue
+		}
+		partitions[name] = month
+	}
+	return partitions, nil
+}
+
+// archivePartitionName returns the name of the partition holding month
+func archivePartitionName(month time.Time) string {
+	return archivePartitionPrefix + month.UTC().Format("y2006m01")
+}
@@ -0,0 +1,166 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"tenant/internal/model"
+	"tenant/pkg/derrors"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// CronRepository stores the recurring payloads of tenants
+type CronRepository interface {
+	CreateSchedule(ctx context.Context, schedule *model.CronSchedule) error
+	GetSchedule(ctx context.Context, clientID, scheduleID string) (*model.CronSchedule, error)
+	ListSchedules(ctx context.Context, clientID string) ([]*model.CronSchedule, error)
+	UpdateSchedule(ctx context.Context, schedule *model.CronSchedule) error
+	DeleteSchedule(ctx context.Context, clientID, scheduleID string) error
+	ListDue(ctx context.Context, now time.Time, limit int) ([]*model.CronSchedule, error)
+	RecordRun(ctx context.Context, schedule *model.CronSchedule, dueAt time.Time) error
+}
+
+type cronRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewCronRepository(db *pgxpool.Pool) CronRepository {
+	return &cronRepository{db: db}
+}
+
+const cronScheduleColumns = `
+    s.id, s.client_id, s.name, s.expression, s.timezone, s.payload, s.headers, s.enabled,
+    s.next_run_at, s.last_run_at, COALESCE(s.last_message_id, ''), COALESCE(s.last_error, ''), s.created_at, s.updated_at
+`
+
+func (r *cronRepository) CreateSchedule(ctx context.Context, schedule *model.CronSchedule) error {
+	query := `
+        INSERT INTO cron_schedules (id, client_id, name, expression, timezone, payload, headers, enabled, next_run_at, created_at, updated_at)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, NOW(), NOW())
+        RETURNING created_at, updated_at
+    `
+	err := r.db.QueryRow(ctx, query, schedule.ID, schedule.ClientID, schedule.Name, schedule.Expression, schedule.Timezone,
+		schedule.Payload, cronHeaders(schedule), schedule.Enabled, schedule.NextRunAt).Scan(&schedule.CreatedAt, &schedule.UpdatedAt)
+	return derrors.HandlePgxError(err, "create schedule %q for tenant %q", schedule.Name, schedule.ClientID)
+}
+
+func (r *cronRepository) GetSchedule(ctx context.Context, clientID, scheduleID string) (*model.CronSchedule, error) {
+	query := `SELECT ` + cronScheduleColumns + ` FROM cron_schedules s WHERE s.client_id = $1 AND s.id = $2`
+	rows, err := r.db.Query(ctx, query, clientID, scheduleID)
+	if err != nil {
+		return nil, derrors.HandlePgxError(err, "get schedule %q", scheduleID)
+	}
+	schedule, err := pgx.CollectExactlyOneRow(rows, scanCronSchedule)
+	if err != nil {
+		return nil, derrors.HandlePgxError(err, "get schedule %q", scheduleID)
+	}
+	return schedule, nil
+}
+
+// ListSchedules returns the schedules of a tenant by name
+func (r *cronRepository) ListSchedules(ctx context.Context, clientID string) ([]*model.CronSchedule, error) {
+	query := `SELECT ` + cronScheduleColumns + ` FROM cron_schedules s WHERE s.client_id = $1 ORDER BY s.name`
+	rows, err := r.db.Query(ctx, query, clientID)
+	if err != nil {
+		return nil, derrors.HandlePgxError(err, "list schedules of tenant %q", clientID)
+	}
+	schedules, err := pgx.CollectRows(rows, scanCronSchedule)
+	if err != nil {
+		return nil, derrors.HandlePgxError(err, "list schedules of tenant %q", clientID)
+	}
+	return schedules, nil
+}
+
+// UpdateSchedule replaces the definition of a schedule, keeping the outcome
+// of its last run
+func (r *cronRepository) UpdateSchedule(ctx context.Context, schedule *model.CronSchedule) error {
+	query := `
+        UPDATE cron_schedules
+        SET name = $3, expression = $4, timezone = $5, payload = $6, headers = $7, enabled = $8, next_run_at = $9, updated_at = NOW()
+        WHERE client_id = $1 AND id = $2
+        RETURNING created_at, updated_at
+    `
+	err := r.db.QueryRow(ctx, query, schedule.ClientID, schedule.ID, schedule.Name, schedule.Expression, schedule.Timezone,
+		schedule.Payload, cronHeaders(schedule), schedule.Enabled, schedule.NextRunAt).Scan(&schedule.CreatedAt, &schedule.UpdatedAt)
+	return derrors.HandlePgxError(err, "update schedule %q", schedule.ID)
+}
+
+func (r *cronRepository) DeleteSchedule(ctx context.Context, clientID, scheduleID string) error {
+	cmdTag, err := r.db.Exec(ctx, `DELETE FROM cron_schedules WHERE client_id = $1 AND id = $2`, clientID, scheduleID)
+	if err != nil {
+		return derrors.HandlePgxError(err, "delete schedule %q", scheduleID)
+	}
+	if cmdTag.RowsAffected() == 0 {
+		return derrors.New(derrors.NotFound, "no schedule %q", scheduleID)
+	}
+	return nil
+}
+
+// ListDue returns up to limit enabled schedules of live tenants due at now,
+// the most overdue first
+func (r *cronRepository) ListDue(ctx context.Context, now time.Time, limit int) ([]*model.CronSchedule, error) {
+	query := `
+        SELECT ` + cronScheduleColumns + `
+        FROM cron_schedules s
+        JOIN tenants t ON t.client_id = s.client_id AND t.deleted_at IS NULL
+        WHERE s.enabled AND s.next_run_at <= $1
+        ORDER BY s.next_run_at
+        LIMIT $2
+    `
+	rows, err := r.db.Query(ctx, query, now, limit)
+	if err != nil {
+		return nil, derrors.HandlePgxError(err, "list due schedules")
+	}
+	schedules, err := pgx.CollectRows(rows, scanCronSchedule)
+	if err != nil {
+		return nil, derrors.HandlePgxError(err, "list due schedules")
+	}
+	return schedules, nil
+}
+
+// RecordRun stores the outcome of the run due at dueAt and the next run of
+// the schedule. A schedule updated since it was listed keeps its new next
+// run.
+func (r *cronRepository) RecordRun(ctx context.Context, schedule *model.CronSchedule, dueAt time.Time) error {
+	query := `
+        UPDATE cron_schedules
+        SET last_run_at = $2, last_message_id = NULLIF($3, ''), last_error = NULLIF($4, ''),
+            next_run_at = CASE WHEN next_run_at = $5 THEN $6 ELSE next_run_at END
+        WHERE id = $1
+    `
+	_, err := r.db.Exec(ctx, query, schedule.ID, schedule.LastRunAt, schedule.LastMessageID, schedule.LastError, dueAt, schedule.NextRunAt)
+	return derrors.HandlePgxError(err, "record run of schedule %q", schedule.ID)
+}
+
+func cronHeaders(schedule *model.CronSchedule) map[string]string {
+	if schedule.Headers == nil {
+		return map[string]string{}
+	}
+	return schedule.Headers
+}
+
+func scanCronSchedule(row pgx.CollectableRow) (*model.CronSchedule, error) {
+	var schedule model.CronSchedule
+	err := row.Scan(
+		&schedule.ID,
+		&schedule.ClientID,
+		&schedule.Name,
+		&schedule.Expression,
+		&schedule.Timezone,
+		&schedule.Payload,
+		&schedule.Headers,
+		&schedule.Enabled,
+		&schedule.NextRunAt,
+		&schedule.LastRunAt,
+		&schedule.LastMessageID,
+		&schedule.LastError,
+		&schedule.CreatedAt,
+		&schedule.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &schedule, nil
+}
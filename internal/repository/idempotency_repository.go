@@ -0,0 +1,117 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"tenant/internal/model"
+	"tenant/pkg/derrors"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// IdempotencyRepository stores the Idempotency-Key of every payload per
+// tenant until it expires
+type IdempotencyRepository interface {
+	Reserve(ctx context.Context, record *model.IdempotencyRecord, timeout time.Duration) (*model.IdempotencyRecord, error)
+	Complete(ctx context.Context, clientID, key, messageID string, ttl time.Duration) error
+	Release(ctx context.Context, clientID, key string) error
+	DeleteExpired(ctx context.Context) (int64, error)
+}
+
+type idempotencyRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewIdempotencyRepository(db *pgxpool.Pool) IdempotencyRepository {
+	return &idempotencyRepository{db: db}
+}
+
+// Reserve claims the key of record until its request completes, or for
+// timeout when it never does. It returns nil when the key was free or
+// expired, and the live record holding the key otherwise.
+func (r *idempotencyRepository) Reserve(ctx context.Context, record *model.IdempotencyRecord, timeout time.Duration) (*model.IdempotencyRecord, error) {
+	insert := `
+        INSERT INTO idempotency_keys (client_id, idempotency_key, request_hash, created_at, expires_at)
+        VALUES ($1, $2, $3, NOW(), NOW() + make_interval(secs => $4))
+        ON CONFLICT (client_id, idempotency_key) DO UPDATE
+        SET request_hash = EXCLUDED.request_hash,
+            message_id = NULL,
+            created_at = EXCLUDED.created_at,
+            expires_at = EXCLUDED.expires_at
+        WHERE idempotency_keys.expires_at <= NOW()
+        RETURNING created_at, expires_at
+    `
+	selectExisting := `
+        SELECT client_id, idempotency_key, request_hash, COALESCE(message_id, ''), created_at, expires_at
+        FROM idempotency_keys
+        WHERE client_id = $1 AND idempotency_key = $2
+    `
+
+	// The holder of the key may release it between the two queries, in
+	// which case the insert is tried again
+	for attempt := 0; ; attempt++ {
+		err := r.db.QueryRow(ctx, insert, record.ClientID, record.Key, record.RequestHash, timeout.Seconds()).Scan(&record.CreatedAt, &record.ExpiresAt)
+		if err == nil {
+			return nil, nil
+		}
+		if !errors.Is(err, pgx.ErrNoRows) {
+			return nil, derrors.HandlePgxError(err, "reserve idempotency key")
+		}
+
+		var existing model.IdempotencyRecord
+		err = r.db.QueryRow(ctx, selectExisting, record.ClientID, record.Key).Scan(
+			&existing.ClientID,
+			&existing.Key,
+			&existing.RequestHash,
+			&existing.MessageID,
+			&existing.CreatedAt,
+			&existing.ExpiresAt,
+		)
+		if errors.Is(err, pgx.ErrNoRows) && attempt == 0 {
+			continue
+		}
+		if err != nil {
+			return nil, derrors.HandlePgxError(err, "get idempotency key")
+		}
+		return &existing, nil
+	}
+}
+
+// Complete records the message published for a reserved key, which is then
+// remembered for ttl after it was reserved
+func (r *idempotencyRepository) Complete(ctx context.Context, clientID, key, messageID string, ttl time.Duration) error {
+	query := `
+        UPDATE idempotency_keys
+        SET message_id = $3, expires_at = created_at + make_interval(secs => $4)
+        WHERE client_id = $1 AND idempotency_key = $2
+    `
+	if _, err := r.db.Exec(ctx, query, clientID, key, messageID, ttl.Seconds()); err != nil {
+		return derrors.HandlePgxError(err, "complete idempotency key")
+	}
+	return nil
+}
+
+// Release frees a reserved key whose payload could not be published, so
+// the client can retry with it
+func (r *idempotencyRepository) Release(ctx context.Context, clientID, key string) error {
+	query := `
+        DELETE FROM idempotency_keys
+        WHERE client_id = $1 AND idempotency_key = $2 AND message_id IS NULL
+    `
+	if _, err := r.db.Exec(ctx, query, clientID, key); err != nil {
+		return derrors.HandlePgxError(err, "release idempotency key")
+	}
+	return nil
+}
+
+// DeleteExpired removes the expired keys and returns how many were removed
+func (r *idempotencyRepository) DeleteExpired(ctx context.Context) (int64, error) {
+	cmdTag, err := r.db.Exec(ctx, `DELETE FROM idempotency_keys WHERE expires_at <= NOW()`)
+	if err != nil {
+		return 0, derrors.HandlePgxError(err, "delete expired idempotency keys")
+	}
+	return cmdTag.RowsAffected(), nil
+}
@@ -0,0 +1,215 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"tenant/internal/model"
+	"tenant/pkg/derrors"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// LeaseRepository tracks the live workers and which of them consume each
+// tenant queue. Workers and leases that are not renewed before they expire
+// are ignored, so a crashed worker releases its tenants on its own.
+type LeaseRepository interface {
+	Heartbeat(ctx context.Context, worker *model.Worker, ttl time.Duration) error
+	ListWorkers(ctx context.Context) ([]*model.Worker, error)
+	AcquireLease(ctx context.Context, clientID, workerID string, ttl time.Duration, maxHolders int) (bool, error)
+	ReleaseLease(ctx context.Context, clientID, workerID string) error
+	ReleaseWorker(ctx context.Context, workerID string) error
+	ListLeases(ctx context.Context) ([]*model.TenantLease, error)
+	AcquireLeadership(ctx context.Context, name, workerID string, ttl time.Duration) (bool, error)
+	ReleaseLeadership(ctx context.Context, name, workerID string) error
+}
+
+type leaseRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewLeaseRepository(db *pgxpool.Pool) LeaseRepository {
+	return &leaseRepository{db: db}
+}
+
+// Heartbeat registers the worker, or renews it, and forgets the workers whose
+// heartbeat is older than ttl.
+func (r *leaseRepository) Heartbeat(ctx context.Context, worker *model.Worker, ttl time.Duration) error {
+	query := `
+        INSERT INTO workers (id, hostname, started_at, heartbeat_at)
+        VALUES ($1, $2, $3, NOW())
+        ON CONFLICT (id) DO UPDATE SET heartbeat_at = NOW()
+        RETURNING heartbeat_at
+    `
+	if err := r.db.QueryRow(ctx, query, worker.ID, worker.Hostname, worker.StartedAt).Scan(&worker.HeartbeatAt); err != nil {
+		return derrors.HandlePgxError(err, "heartbeat worker %q", worker.ID)
+	}
+
+	query = `
+        DELETE FROM workers
+        WHERE heartbeat_at < NOW() - make_interval(secs => $1)
+    `
+	if _, err := r.db.Exec(ctx, query, ttl.Seconds()); err != nil {
+		return derrors.HandlePgxError(err, "expire workers")
+	}
+	return nil
+}
+
+// ListWorkers returns the workers with a recent heartbeat, oldest first
+func (r *leaseRepository) ListWorkers(ctx context.Context) ([]*model.Worker, error) {
+	query := `
+        SELECT id, hostname, started_at, heartbeat_at
+        FROM workers
+        ORDER BY started_at, id
+    `
+	rows, err := r.db.Query(ctx, query)
+	if err != nil {
+		return nil, derrors.HandlePgxError(err, "list workers")
+	}
+	defer rows.Close()
+
+	var workers []*model.Worker
+	for rows.Next() {
+		var worker model.Worker
+		if err := rows.Scan(
+			&worker.ID,
+			&worker.Hostname,
+			&worker.StartedAt,
+			&worker.HeartbeatAt,
+		); err != nil {
+			return nil, derrors.HandlePgxError(err, "scan worker")
+		}
+		workers = append(workers, &worker)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, derrors.HandlePgxError(err, "list workers")
+	}
+	return workers, nil
+}
+
+// AcquireLease takes or renews the lease of workerID on a tenant. It reports
+// false when maxHolders other workers already hold an unexpired lease. The
+// check and the insert run under a transaction scoped advisory lock on the
+// tenant, so concurrent workers cannot both take the last slot.
+func (r *leaseRepository) AcquireLease(ctx context.Context, clientID, workerID string, ttl time.Duration, maxHolders int) (acquired bool, err error) {
+	err = pgx.BeginFunc(ctx, r.db, func(tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx, `SELECT pg_advisory_xact_lock(hashtext('tenant_lease:' || $1))`, clientID); err != nil {
+			return err
+		}
+
+		query := `
+            INSERT INTO tenant_leases (client_id, worker_id, acquired_at, expires_at)
+            SELECT $1, $2, NOW(), NOW() + make_interval(secs => $3)
+            WHERE (
+                SELECT COUNT(*)
+                FROM tenant_leases
+                WHERE client_id = $1 AND worker_id <> $2 AND expires_at > NOW()
+            ) < $4
+            ON CONFLICT (client_id, worker_id) DO UPDATE SET expires_at = EXCLUDED.expires_at
+        `
+		cmdTag, err := tx.Exec(ctx, query, clientID, workerID, ttl.Seconds(), maxHolders)
+		if err != nil {
+			return err
+		}
+		acquired = cmdTag.RowsAffected() > 0
+
+		// Leases of workers that stopped renewing them only take up space
+		_, err = tx.Exec(ctx, `DELETE FROM tenant_leases WHERE client_id = $1 AND expires_at <= NOW()`, clientID)
+		return err
+	})
+	if err != nil {
+		return false, derrors.HandlePgxError(err, "acquire lease on tenant %q", clientID)
+	}
+	return acquired, nil
+}
+
+func (r *leaseRepository) ReleaseLease(ctx context.Context, clientID, workerID string) error {
+	query := `
+        DELETE FROM tenant_leases
+        WHERE client_id = $1 AND worker_id = $2
+    `
+	if _, err := r.db.Exec(ctx, query, clientID, workerID); err != nil {
+		return derrors.HandlePgxError(err, "release lease on tenant %q", clientID)
+	}
+	return nil
+}
+
+// ReleaseWorker drops every lease of a worker and unregisters it, so the
+// remaining workers take over its tenants without waiting for expiry.
+func (r *leaseRepository) ReleaseWorker(ctx context.Context, workerID string) error {
+	err := pgx.BeginFunc(ctx, r.db, func(tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx, `DELETE FROM tenant_leases WHERE worker_id = $1`, workerID); err != nil {
+			return err
+		}
+		_, err := tx.Exec(ctx, `DELETE FROM workers WHERE id = $1`, workerID)
+		return err
+	})
+	if err != nil {
+		return derrors.HandlePgxError(err, "release worker %q", workerID)
+	}
+	return nil
+}
+
+// ListLeases returns the unexpired leases ordered by tenant
+func (r *leaseRepository) ListLeases(ctx context.Context) ([]*model.TenantLease, error) {
+	query := `
+        SELECT client_id, worker_id, acquired_at, expires_at
+        FROM tenant_leases
+        WHERE expires_at > NOW()
+        ORDER BY client_id, worker_id
+    `
+	rows, err := r.db.Query(ctx, query)
+	if err != nil {
+		return nil, derrors.HandlePgxError(err, "list leases")
+	}
+	defer rows.Close()
+
+	var leases []*model.TenantLease
+	for rows.Next() {
+		var lease model.TenantLease
+		if err := rows.Scan(
+			&lease.ClientID,
+			&lease.WorkerID,
+			&lease.AcquiredAt,
+			&lease.ExpiresAt,
+		); err != nil {
+			return nil, derrors.HandlePgxError(err, "scan lease")
+		}
+		leases = append(leases, &lease)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, derrors.HandlePgxError(err, "list leases")
+	}
+	return leases, nil
+}
+
+// AcquireLeadership takes or renews the lease of workerID on the role name,
+// such as running the cron scheduler. It reports false while another worker
+// holds an unexpired lease on the role.
+func (r *leaseRepository) AcquireLeadership(ctx context.Context, name, workerID string, ttl time.Duration) (bool, error) {
+	query := `
+        INSERT INTO leader_leases (name, worker_id, acquired_at, expires_at)
+        VALUES ($1, $2, NOW(), NOW() + make_interval(secs => $3))
+        ON CONFLICT (name) DO UPDATE
+        SET worker_id = EXCLUDED.worker_id,
+            acquired_at = CASE WHEN leader_leases.worker_id = EXCLUDED.worker_id THEN leader_leases.acquired_at ELSE NOW() END,
+            expires_at = EXCLUDED.expires_at
+        WHERE leader_leases.worker_id = EXCLUDED.worker_id OR leader_leases.expires_at <= NOW()
+    `
+	cmdTag, err := r.db.Exec(ctx, query, name, workerID, ttl.Seconds())
+	if err != nil {
+		return false, derrors.HandlePgxError(err, "acquire leadership of %q", name)
+	}
+	return cmdTag.RowsAffected() > 0, nil
+}
+
+// ReleaseLeadership gives up the role name, so another worker takes it
+// without waiting for expiry
+func (r *leaseRepository) ReleaseLeadership(ctx context.Context, name, workerID string) error {
+	query := `DELETE FROM leader_leases WHERE name = $1 AND worker_id = $2`
+	if _, err := r.db.Exec(ctx, query, name, workerID); err != nil {
+		return derrors.HandlePgxError(err, "release leadership of %q", name)
+	}
+	return nil
+}
@@ -0,0 +1,170 @@
+package repository
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"tenant/internal/model"
+	"tenant/pkg/derrors"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// MessageRepository records the status transitions of messages
+type MessageRepository interface {
+	CreateMessages(ctx context.Context, messages ...*model.Message) error
+	RecordEvents(ctx context.Context, events ...*model.MessageEvent) error
+	GetMessage(ctx context.Context, clientID, messageID string) (*model.Message, error)
+}
+
+type messageRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewMessageRepository(db *pgxpool.Pool) MessageRepository {
+	return &messageRepository{db: db}
+}
+
+// CreateMessages records messages as accepted, in a single round trip
+func (r *messageRepository) CreateMessages(ctx context.Context, messages ...*model.Message) error {
+	if len(messages) == 0 {
+		return nil
+	}
+
+	now := time.Now()
+	batch := &pgx.Batch{}
+	for _, message := range messages {
+		message.Status = model.MessageAccepted
+		message.CreatedAt, message.UpdatedAt = now, now
+
+		batch.Queue(`
+            INSERT INTO messages (message_id, client_id, status, correlation_id, created_at, updated_at)
+            VALUES ($1, $2, $3, NULLIF($4, ''), $5, $5)
+        `, message.ID, message.ClientID, message.Status, message.CorrelationID, now)
+		batch.Queue(`
+            INSERT INTO message_events (message_id, status, created_at)
+            VALUES ($1, $2, $3)
+        `, message.ID, message.Status, now)
+	}
+
+	err := pgx.BeginFunc(ctx, r.db, func(tx pgx.Tx) error {
+		return tx.SendBatch(ctx, batch).Close()
+	})
+	return derrors.HandlePgxError(err, "create %d messages", len(messages))
+}
+
+// messageStatusRanks orders the statuses of a message. A message never moves
+// back to a lower rank, so the publisher recording a message as published
+// after a consumer already received it leaves the consumer status in place.
+// Statuses of the same rank replace each other, as a failed message is
+// delivered again when retried.
+var messageStatusRanks = map[string]int{
+	model.MessageAccepted:     0,
+	model.MessageScheduled:    0,
+	model.MessagePublished:    1,
+	model.MessageDelivered:    2,
+	model.MessageFailed:       2,
+	model.MessageProcessed:    3,
+	model.MessageDeadLettered: 3,
+	model.MessageCancelled:    3,
+}
+
+// replaceableStatuses returns the statuses a message may move from to reach
+// status, sorted
+func replaceableStatuses(status string) []string {
+	rank, ok := messageStatusRanks[status]
+	if !ok {
+		return nil
+	}
+	var statuses []string
+	for current, currentRank := range messageStatusRanks {
+		if currentRank <= rank {
+			statuses = append(statuses, current)
+		}
+	}
+	sort.Strings(statuses)
+	return statuses
+}
+
+// RecordEvents appends events to the timeline of their messages and moves
+// the messages to the status of their latest event, unless they already
+// reached a later status. Events of unknown messages, such as messages
+// published before they were tracked, are ignored.
+func (r *messageRepository) RecordEvents(ctx context.Context, events ...*model.MessageEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+
+	batch := &pgx.Batch{}
+	for _, event := range events {
+		if event.CreatedAt.IsZero() {
+			event.CreatedAt = time.Now()
+		}
+
+		batch.Queue(`
+            INSERT INTO message_events (message_id, status, attempt, detail, created_at)
+            SELECT $1, $2, $3, NULLIF($4, ''), $5
+            WHERE EXISTS (SELECT 1 FROM messages WHERE message_id = $1)
+        `, event.MessageID, event.Status, event.Attempt, event.Detail, event.CreatedAt)
+		batch.Queue(`
+            UPDATE messages
+            SET status = $2, updated_at = $3
+            WHERE message_id = $1 AND status = ANY($4)
+        `, event.MessageID, event.Status, event.CreatedAt, replaceableStatuses(event.Status))
+	}
+
+	err := pgx.BeginFunc(ctx, r.db, func(tx pgx.Tx) error {
+		return tx.SendBatch(ctx, batch).Close()
+	})
+	return derrors.HandlePgxError(err, "record %d message events", len(events))
+}
+
+// GetMessage returns a message of a tenant with its timeline, oldest first
+func (r *messageRepository) GetMessage(ctx context.Context, clientID, messageID string) (*model.Message, error) {
+	query := `
+        SELECT message_id, client_id, status, COALESCE(correlation_id, ''), created_at, updated_at
+        FROM messages
+        WHERE client_id = $1 AND message_id = $2
+    `
+	var message model.Message
+	err := r.db.QueryRow(ctx, query, clientID, messageID).Scan(
+		&message.ID,
+		&message.ClientID,
+		&message.Status,
+		&message.CorrelationID,
+		&message.CreatedAt,
+		&message.UpdatedAt,
+	)
+	if err != nil {
+		if derrors.IsErrCode(derrors.HandlePgxError(err, ""), derrors.NotFound) {
+			return nil, derrors.New(derrors.NotFound, "message not found")
+		}
+		return nil, derrors.HandlePgxError(err, "get message %q", messageID)
+	}
+
+	query = `
+        SELECT status, attempt, COALESCE(detail, ''), created_at
+        FROM message_events
+        WHERE message_id = $1
+        ORDER BY id
+    `
+	rows, err := r.db.Query(ctx, query, messageID)
+	if err != nil {
+		return nil, derrors.HandlePgxError(err, "list events of message %q", messageID)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		event := model.MessageEvent{MessageID: messageID}
+		if err := rows.Scan(&event.Status, &event.Attempt, &event.Detail, &event.CreatedAt); err != nil {
+			return nil, derrors.HandlePgxError(err, "scan message event")
+		}
+		message.Events = append(message.Events, &event)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, derrors.HandlePgxError(err, "list events of message %q", messageID)
+	}
+	return &message, nil
+}
@@ -0,0 +1,39 @@
+package repository
+
+import (
+	"slices"
+	"testing"
+
+	"tenant/internal/model"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReplaceableStatuses(t *testing.T) {
+	var testCases = []struct {
+		caseName  string
+		current   string
+		next      string
+		advancing bool
+	}{
+		{caseName: "AcceptedToPublished", current: model.MessageAccepted, next: model.MessagePublished, advancing: true},
+		{caseName: "ScheduledToPublished", current: model.MessageScheduled, next: model.MessagePublished, advancing: true},
+		{caseName: "PublishedToDelivered", current: model.MessagePublished, next: model.MessageDelivered, advancing: true},
+		{caseName: "FailedToDeliveredOnRetry", current: model.MessageFailed, next: model.MessageDelivered, advancing: true},
+		{caseName: "DeliveredToProcessed", current: model.MessageDelivered, next: model.MessageProcessed, advancing: true},
+		{caseName: "ScheduledToCancelled", current: model.MessageScheduled, next: model.MessageCancelled, advancing: true},
+		// The publisher records the confirm after the consumer received the message
+		{caseName: "DeliveredToPublishedOutOfOrder", current: model.MessageDelivered, next: model.MessagePublished},
+		{caseName: "ProcessedToPublishedOutOfOrder", current: model.MessageProcessed, next: model.MessagePublished},
+		{caseName: "ProcessedToDeliveredOnRedelivery", current: model.MessageProcessed, next: model.MessageDelivered},
+		{caseName: "CancelledToPublished", current: model.MessageCancelled, next: model.MessagePublished},
+		{caseName: "UnknownStatus", current: model.MessageAccepted, next: "unknown"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.caseName, func(t *testing.T) {
+			statuses := replaceableStatuses(tc.next)
+			assert.Equal(t, tc.advancing, slices.Contains(statuses, tc.current))
+		})
+	}
+}
@@ -0,0 +1,75 @@
+package repository
+
+import (
+	"context"
+
+	"tenant/internal/model"
+	"tenant/pkg/derrors"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// ProcessorRepository stores the processor chains of tenants and the
+// messages of the postgres processor
+type ProcessorRepository interface {
+	GetProcessors(ctx context.Context, clientID string) (*model.ProcessorChain, error)
+	SetProcessors(ctx context.Context, chain *model.ProcessorChain) error
+	DeleteProcessors(ctx context.Context, clientID string) error
+	SaveSinkMessage(ctx context.Context, message *model.SinkMessage) error
+}
+
+type processorRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewProcessorRepository(db *pgxpool.Pool) ProcessorRepository {
+	return &processorRepository{db: db}
+}
+
+// GetProcessors returns the processor chain of a tenant, NotFound when the
+// tenant uses the default chain
+func (r *processorRepository) GetProcessors(ctx context.Context, clientID string) (*model.ProcessorChain, error) {
+	chain := &model.ProcessorChain{ClientID: clientID}
+	err := r.db.QueryRow(ctx, `SELECT processors, updated_at FROM tenant_processors WHERE client_id = $1`, clientID).
+		Scan(&chain.Processors, &chain.UpdatedAt)
+	if err != nil {
+		return nil, derrors.HandlePgxError(err, "get processors of tenant %q", clientID)
+	}
+	return chain, nil
+}
+
+// SetProcessors creates or replaces the processor chain of a tenant
+func (r *processorRepository) SetProcessors(ctx context.Context, chain *model.ProcessorChain) error {
+	query := `
+        INSERT INTO tenant_processors (client_id, processors, updated_at)
+        VALUES ($1, $2, NOW())
+        ON CONFLICT (client_id) DO UPDATE
+        SET processors = EXCLUDED.processors, updated_at = EXCLUDED.updated_at
+        RETURNING updated_at
+    `
+	err := r.db.QueryRow(ctx, query, chain.ClientID, chain.Processors).Scan(&chain.UpdatedAt)
+	return derrors.HandlePgxError(err, "set processors of tenant %q", chain.ClientID)
+}
+
+// DeleteProcessors moves a tenant back to the default processor chain
+func (r *processorRepository) DeleteProcessors(ctx context.Context, clientID string) error {
+	_, err := r.db.Exec(ctx, `DELETE FROM tenant_processors WHERE client_id = $1`, clientID)
+	return derrors.HandlePgxError(err, "delete processors of tenant %q", clientID)
+}
+
+// SaveSinkMessage stores a consumed message, a message already stored by a
+// previous attempt is kept as is
+func (r *processorRepository) SaveSinkMessage(ctx context.Context, message *model.SinkMessage) error {
+	headers := message.Headers
+	if headers == nil {
+		headers = map[string]string{}
+	}
+	query := `
+        INSERT INTO sink_messages (client_id, message_id, correlation_id, content_type, headers, payload, received_at)
+        VALUES ($1, $2, NULLIF($3, ''), $4, $5, $6, $7)
+        ON CONFLICT DO NOTHING
+    `
+	_, err := r.db.Exec(ctx, query, message.ClientID, message.MessageID, message.CorrelationID, message.ContentType,
+		headers, message.Payload, message.ReceivedAt)
+	return derrors.HandlePgxError(err, "save message %q of tenant %q", message.MessageID, message.ClientID)
+}
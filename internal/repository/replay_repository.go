@@ -0,0 +1,180 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"tenant/internal/model"
+	"tenant/pkg/derrors"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// ReplayRepository stores the replay jobs of tenants and their progress
+type ReplayRepository interface {
+	CreateJob(ctx context.Context, job *model.ReplayJob) error
+	GetJob(ctx context.Context, clientID, jobID string) (*model.ReplayJob, error)
+	ClaimJob(ctx context.Context, workerID string, staleAfter time.Duration) (*model.ReplayJob, error)
+	UpdateProgress(ctx context.Context, job *model.ReplayJob) (bool, error)
+	FinishJob(ctx context.Context, job *model.ReplayJob) error
+	CancelJob(ctx context.Context, clientID, jobID string) error
+}
+
+type replayRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewReplayRepository(db *pgxpool.Pool) ReplayRepository {
+	return &replayRepository{db: db}
+}
+
+const replayJobColumns = `
+    id, client_id, status, from_time, to_time, COALESCE(message_ids, '{}'), rate,
+    total, published, failed, COALESCE(error, ''), cursor_published_at, COALESCE(cursor_message_id, ''),
+    COALESCE(worker_id, ''), created_at, started_at, finished_at
+`
+
+// CreateJob stores a new job. A running job is owned by its WorkerID from
+// the start.
+func (r *replayRepository) CreateJob(ctx context.Context, job *model.ReplayJob) error {
+	query := `
+        INSERT INTO replay_jobs (id, client_id, status, from_time, to_time, message_ids, rate, total, worker_id, heartbeat_at, created_at, started_at, updated_at)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8, NULLIF($9, ''), $10, $10, $11, $10)
+    `
+	now := time.Now()
+	job.CreatedAt = now
+	if job.Status == model.ReplayRunning {
+		job.StartedAt = &now
+	}
+
+	var messageIDs []string
+	if len(job.MessageIDs) > 0 {
+		messageIDs = job.MessageIDs
+	}
+	_, err := r.db.Exec(ctx, query, job.ID, job.ClientID, job.Status, job.From, job.To, messageIDs, job.Rate, job.Total, job.WorkerID, now, job.StartedAt)
+	return derrors.HandlePgxError(err, "create replay job for tenant %q", job.ClientID)
+}
+
+// GetJob returns a replay job of a tenant
+func (r *replayRepository) GetJob(ctx context.Context, clientID, jobID string) (*model.ReplayJob, error) {
+	query := `SELECT ` + replayJobColumns + ` FROM replay_jobs WHERE client_id = $1 AND id = $2`
+	job, err := scanReplayJob(r.db.QueryRow(ctx, query, clientID, jobID))
+	if err != nil {
+		return nil, derrors.HandlePgxError(err, "get replay job %q", jobID)
+	}
+	return job, nil
+}
+
+// ClaimJob hands the oldest pending job, or a running job whose worker did
+// not report progress for staleAfter, to workerID. Concurrent workers never
+// claim the same job. It returns NotFound when there is no job to run.
+func (r *replayRepository) ClaimJob(ctx context.Context, workerID string, staleAfter time.Duration) (*model.ReplayJob, error) {
+	query := `
+        UPDATE replay_jobs
+        SET status = 'running', worker_id = $1, heartbeat_at = NOW(), updated_at = NOW(),
+            started_at = COALESCE(started_at, NOW())
+        WHERE id = (
+            SELECT id FROM replay_jobs
+            WHERE status = 'pending'
+               OR (status = 'running' AND heartbeat_at < NOW() - make_interval(secs => $2))
+            ORDER BY created_at
+            FOR UPDATE SKIP LOCKED
+            LIMIT 1
+        )
+        RETURNING ` + replayJobColumns
+	job, err := scanReplayJob(r.db.QueryRow(ctx, query, workerID, staleAfter.Seconds()))
+	if err != nil {
+		return nil, derrors.HandlePgxError(err, "claim replay job")
+	}
+	return job, nil
+}
+
+// UpdateProgress records the counters and cursor of a running job. It
+// reports false when the job is no longer run by its worker, because it was
+// cancelled or taken over.
+func (r *replayRepository) UpdateProgress(ctx context.Context, job *model.ReplayJob) (bool, error) {
+	query := `
+        UPDATE replay_jobs
+        SET published = $3, failed = $4, cursor_published_at = $5, cursor_message_id = NULLIF($6, ''),
+            heartbeat_at = NOW(), updated_at = NOW()
+        WHERE id = $1 AND worker_id = $2 AND status = 'running'
+    `
+	var publishedAt *time.Time
+	var messageID string
+	if job.Cursor != nil {
+		publishedAt, messageID = &job.Cursor.PublishedAt, job.Cursor.MessageID
+	}
+	cmdTag, err := r.db.Exec(ctx, query, job.ID, job.WorkerID, job.Published, job.Failed, publishedAt, messageID)
+	if err != nil {
+		return false, derrors.HandlePgxError(err, "update replay job %q", job.ID)
+	}
+	return cmdTag.RowsAffected() > 0, nil
+}
+
+// FinishJob records the final status of a job run by its worker
+func (r *replayRepository) FinishJob(ctx context.Context, job *model.ReplayJob) error {
+	query := `
+        UPDATE replay_jobs
+        SET status = $3, published = $4, failed = $5, error = NULLIF($6, ''), finished_at = NOW(), updated_at = NOW()
+        WHERE id = $1 AND worker_id = $2 AND status = 'running'
+        RETURNING finished_at
+    `
+	var finishedAt time.Time
+	err := r.db.QueryRow(ctx, query, job.ID, job.WorkerID, job.Status, job.Published, job.Failed, job.Error).Scan(&finishedAt)
+	if err != nil {
+		return derrors.HandlePgxError(err, "finish replay job %q", job.ID)
+	}
+	job.FinishedAt = &finishedAt
+	return nil
+}
+
+// CancelJob stops a pending or running job. The worker running it stops at
+// its next progress update.
+func (r *replayRepository) CancelJob(ctx context.Context, clientID, jobID string) error {
+	query := `
+        UPDATE replay_jobs
+        SET status = 'cancelled', finished_at = NOW(), updated_at = NOW()
+        WHERE client_id = $1 AND id = $2 AND status IN ('pending', 'running')
+    `
+	cmdTag, err := r.db.Exec(ctx, query, clientID, jobID)
+	if err != nil {
+		return derrors.HandlePgxError(err, "cancel replay job %q", jobID)
+	}
+	if cmdTag.RowsAffected() == 0 {
+		return derrors.New(derrors.NotFound, "no pending or running replay job %q", jobID)
+	}
+	return nil
+}
+
+func scanReplayJob(row pgx.Row) (*model.ReplayJob, error) {
+	var job model.ReplayJob
+	var cursorPublishedAt *time.Time
+	var cursorMessageID string
+	err := row.Scan(
+		&job.ID,
+		&job.ClientID,
+		&job.Status,
+		&job.From,
+		&job.To,
+		&job.MessageIDs,
+		&job.Rate,
+		&job.Total,
+		&job.Published,
+		&job.Failed,
+		&job.Error,
+		&cursorPublishedAt,
+		&cursorMessageID,
+		&job.WorkerID,
+		&job.CreatedAt,
+		&job.StartedAt,
+		&job.FinishedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if cursorPublishedAt != nil && cursorMessageID != "" {
+		job.Cursor = &model.ArchiveCursor{PublishedAt: *cursorPublishedAt, MessageID: cursorMessageID}
+	}
+	return &job, nil
+}
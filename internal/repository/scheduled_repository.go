@@ -0,0 +1,164 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"tenant/internal/model"
+	"tenant/pkg/derrors"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// ScheduledRepository stores the payloads waiting for their delivery time
+type ScheduledRepository interface {
+	Schedule(ctx context.Context, message *model.ScheduledMessage) error
+	ListScheduled(ctx context.Context, clientID string, limit int) ([]*model.ScheduledMessage, error)
+	CancelScheduled(ctx context.Context, clientID, messageID string) error
+	PublishDue(ctx context.Context, now time.Time, limit int, publish func(messages []*model.ScheduledMessage) []error) (int, error)
+}
+
+type scheduledRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewScheduledRepository(db *pgxpool.Pool) ScheduledRepository {
+	return &scheduledRepository{db: db}
+}
+
+const scheduledMessageColumns = `
+    s.message_id, s.client_id, s.payload, s.headers, COALESCE(s.correlation_id, ''), COALESCE(s.content_type, ''),
+    s.priority, s.expiration_ms, s.deliver_at, s.attempts, COALESCE(s.last_error, ''), s.failed_at, s.created_at
+`
+
+// Schedule stores a payload until its delivery time
+func (r *scheduledRepository) Schedule(ctx context.Context, message *model.ScheduledMessage) error {
+	query := `
+        INSERT INTO scheduled_messages (message_id, client_id, payload, headers, correlation_id, content_type, priority, expiration_ms, deliver_at, created_at)
+        VALUES ($1, $2, $3, $4, NULLIF($5, ''), NULLIF($6, ''), $7, $8, $9, $10)
+    `
+	headers := message.Headers
+	if headers == nil {
+		headers = map[string]string{}
+	}
+	message.CreatedAt = time.Now()
+	_, err := r.db.Exec(ctx, query, message.MessageID, message.ClientID, message.Payload, headers, message.CorrelationID,
+		message.ContentType, int16(message.Priority), message.Expiration.Milliseconds(), message.DeliverAt, message.CreatedAt)
+	return derrors.HandlePgxError(err, "schedule message %q", message.MessageID)
+}
+
+// ListScheduled returns the next limit payloads of a tenant to be published,
+// earliest first
+func (r *scheduledRepository) ListScheduled(ctx context.Context, clientID string, limit int) ([]*model.ScheduledMessage, error) {
+	query := `
+        SELECT ` + scheduledMessageColumns + `
+        FROM scheduled_messages s
+        WHERE s.client_id = $1
+        ORDER BY s.deliver_at, s.message_id
+        LIMIT $2
+    `
+	rows, err := r.db.Query(ctx, query, clientID, limit)
+	if err != nil {
+		return nil, derrors.HandlePgxError(err, "list scheduled messages of tenant %q", clientID)
+	}
+	messages, err := pgx.CollectRows(rows, scanScheduledMessage)
+	if err != nil {
+		return nil, derrors.HandlePgxError(err, "list scheduled messages of tenant %q", clientID)
+	}
+	return messages, nil
+}
+
+// CancelScheduled removes a payload that is not published yet. It waits for
+// a publication in progress and returns NotFound once the payload is gone.
+func (r *scheduledRepository) CancelScheduled(ctx context.Context, clientID, messageID string) error {
+	query := `DELETE FROM scheduled_messages WHERE client_id = $1 AND message_id = $2`
+	cmdTag, err := r.db.Exec(ctx, query, clientID, messageID)
+	if err != nil {
+		return derrors.HandlePgxError(err, "cancel scheduled message %q", messageID)
+	}
+	if cmdTag.RowsAffected() == 0 {
+		return derrors.New(derrors.NotFound, "no scheduled message %q", messageID)
+	}
+	return nil
+}
+
+// PublishDue locks up to limit payloads of live tenants due at now and hands
+// them to publish, which reports an error per payload. Published payloads are
+// removed. Failed ones are kept with the DeliverAt and FailedAt that publish
+// set on them, and are not published again once marked failed. Locked rows
+// are skipped, so instances running concurrently publish different payloads.
+// A payload is published again if the transaction fails to commit after
+// publish.
+func (r *scheduledRepository) PublishDue(ctx context.Context, now time.Time, limit int, publish func(messages []*model.ScheduledMessage) []error) (int, error) {
+	query := `
+        SELECT ` + scheduledMessageColumns + `
+        FROM scheduled_messages s
+        JOIN tenants t ON t.client_id = s.client_id AND t.deleted_at IS NULL
+        WHERE s.deliver_at <= $1 AND s.failed_at IS NULL
+        ORDER BY s.deliver_at
+        LIMIT $2
+        FOR UPDATE OF s SKIP LOCKED
+    `
+	var published int
+	err := pgx.BeginFunc(ctx, r.db, func(tx pgx.Tx) error {
+		rows, err := tx.Query(ctx, query, now, limit)
+		if err != nil {
+			return err
+		}
+		messages, err := pgx.CollectRows(rows, scanScheduledMessage)
+		if err != nil || len(messages) == 0 {
+			return err
+		}
+
+		errs := publish(messages)
+
+		var done []string
+		batch := &pgx.Batch{}
+		for i, message := range messages {
+			if errs[i] == nil {
+				done = append(done, message.MessageID)
+				continue
+			}
+			batch.Queue(`
+                UPDATE scheduled_messages SET attempts = attempts + 1, last_error = $2, deliver_at = $3, failed_at = $4
+                WHERE message_id = $1
+            `, message.MessageID, errs[i].Error(), message.DeliverAt, message.FailedAt)
+		}
+		batch.Queue(`DELETE FROM scheduled_messages WHERE message_id = ANY($1)`, done)
+		published = len(done)
+
+		return tx.SendBatch(ctx, batch).Close()
+	})
+	if err != nil {
+		return 0, derrors.HandlePgxError(err, "publish due scheduled messages")
+	}
+	return published, nil
+}
+
+func scanScheduledMessage(row pgx.CollectableRow) (*model.ScheduledMessage, error) {
+	var message model.ScheduledMessage
+	var priority int16
+	var expiration int64
+	err := row.Scan(
+		&message.MessageID,
+		&message.ClientID,
+		&message.Payload,
+		&message.Headers,
+		&message.CorrelationID,
+		&message.ContentType,
+		&priority,
+		&expiration,
+		&message.DeliverAt,
+		&message.Attempts,
+		&message.LastError,
+		&message.FailedAt,
+		&message.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	message.Priority = uint8(priority)
+	message.Expiration = time.Duration(expiration) * time.Millisecond
+	return &message, nil
+}
@@ -0,0 +1,195 @@
+package repository
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"tenant/internal/model"
+	"tenant/pkg/derrors"
+
+	"golang.org/x/sync/singleflight"
+)
+
+// TenantCache is a TenantRepository that serves GetTenantByClientID from
+// memory. Unknown client IDs are cached too, for a shorter time, so repeated
+// lookups of a deleted or mistyped tenant do not reach Postgres either.
+type TenantCache interface {
+	TenantRepository
+	Invalidate(clientID string)
+	Purge()
+	Stats() CacheStats
+}
+
+// CacheStats counts the lookups served by a TenantCache since it was created
+type CacheStats struct {
+	Hits         uint64 `json:"hits"`
+	NegativeHits uint64 `json:"negative_hits"`
+	Misses       uint64 `json:"misses"`
+	Evictions    uint64 `json:"evictions"`
+	Entries      int    `json:"entries"`
+}
+
+// TenantCacheConfig controls how long lookups are cached
+type TenantCacheConfig struct {
+	TTL         time.Duration
+	NegativeTTL time.Duration
+	MaxEntries  int
+}
+
+type cacheEntry struct {
+	tenant    *model.Tenant // nil for a cached NotFound
+	expiresAt time.Time
+}
+
+type tenantCache struct {
+	TenantRepository
+
+	cfg   TenantCacheConfig
+	group singleflight.Group
+
+	// generation changes on every invalidation, so a lookup that raced with
+	// one does not cache what it read before it
+	mu         sync.RWMutex
+	entries    map[string]cacheEntry
+	generation uint64
+
+	hits, negativeHits, misses, evictions atomic.Uint64
+}
+
+// NewTenantCache wraps repo with a read-through cache of tenant lookups
+func NewTenantCache(repo TenantRepository, cfg TenantCacheConfig) TenantCache {
+	if cfg.MaxEntries <= 0 {
+		cfg.MaxEntries = 10000
+	}
+	return &tenantCache{
+		TenantRepository: repo,
+		cfg:              cfg,
+		entries:          make(map[string]cacheEntry),
+	}
+}
+
+func (c *tenantCache) GetTenantByClientID(ctx context.Context, clientID string) (*model.Tenant, error) {
+	c.mu.RLock()
+	entry, ok := c.entries[clientID]
+	generation := c.generation
+	c.mu.RUnlock()
+
+	if ok && time.Now().Before(entry.expiresAt) {
+		if entry.tenant == nil {
+			c.negativeHits.Add(1)
+			return nil, derrors.New(derrors.NotFound, "tenant not found")
+		}
+		c.hits.Add(1)
+		return copyTenant(entry.tenant), nil
+	}
+	c.misses.Add(1)
+
+	// Concurrent misses for the same tenant share a single query
+	v, err, _ := c.group.Do(clientID, func() (interface{}, error) {
+		tenant, err := c.TenantRepository.GetTenantByClientID(ctx, clientID)
+		switch {
+		case err == nil:
+			c.set(clientID, tenant, c.cfg.TTL, generation)
+		case derrors.IsErrCode(err, derrors.NotFound):
+			c.set(clientID, nil, c.cfg.NegativeTTL, generation)
+		}
+		return tenant, err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return copyTenant(v.(*model.Tenant)), nil
+}
+
+func (c *tenantCache) CreateTenant(ctx context.Context, tenant *model.Tenant) error {
+	err := c.TenantRepository.CreateTenant(ctx, tenant)
+	// Drop a cached NotFound for the new client ID
+	c.Invalidate(tenant.ClientID)
+	return err
+}
+
+func (c *tenantCache) SoftDeleteTenant(ctx context.Context, clientID string) error {
+	err := c.TenantRepository.SoftDeleteTenant(ctx, clientID)
+	c.Invalidate(clientID)
+	return err
+}
+
+// Invalidate forgets the cached lookup of a tenant
+func (c *tenantCache) Invalidate(clientID string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.entries, clientID)
+	c.generation++
+	c.group.Forget(clientID)
+}
+
+// Purge forgets every cached lookup
+func (c *tenantCache) Purge() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries = make(map[string]cacheEntry)
+	c.generation++
+}
+
+func (c *tenantCache) Stats() CacheStats {
+	c.mu.RLock()
+	entries := len(c.entries)
+	c.mu.RUnlock()
+
+	return CacheStats{
+		Hits:         c.hits.Load(),
+		NegativeHits: c.negativeHits.Load(),
+		Misses:       c.misses.Load(),
+		Evictions:    c.evictions.Load(),
+		Entries:      entries,
+	}
+}
+
+func (c *tenantCache) set(clientID string, tenant *model.Tenant, ttl time.Duration, generation uint64) {
+	if ttl <= 0 {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if generation != c.generation {
+		return
+	}
+
+	if _, ok := c.entries[clientID]; !ok && len(c.entries) >= c.cfg.MaxEntries {
+		c.evict()
+	}
+	c.entries[clientID] = cacheEntry{tenant: tenant, expiresAt: time.Now().Add(ttl)}
+}
+
+// evict makes room for one entry, dropping the expired entries or, when
+// there are none, an arbitrary one. Callers hold c.mu.
+func (c *tenantCache) evict() {
+	now := time.Now()
+	for clientID, entry := range c.entries {
+		if now.After(entry.expiresAt) {
+			delete(c.entries, clientID)
+			c.evictions.Add(1)
+		}
+	}
+	if len(c.entries) < c.cfg.MaxEntries {
+		return
+	}
+	for clientID := range c.entries {
+		delete(c.entries, clientID)
+		c.evictions.Add(1)
+		return
+	}
+}
+
+// copyTenant keeps callers from mutating the cached tenant
+func copyTenant(tenant *model.Tenant) *model.Tenant {
+	if tenant == nil {
+		return nil
+	}
+	t := *tenant
+	return &t
+}
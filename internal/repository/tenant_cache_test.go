@@ -0,0 +1,109 @@
+package repository_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"tenant/internal/model"
+	"tenant/internal/repository"
+	"tenant/internal/test/mockrepository"
+	"tenant/pkg/derrors"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestTenantCache(t *testing.T) {
+	ctx := context.Background()
+	cfg := repository.TenantCacheConfig{TTL: time.Minute, NegativeTTL: time.Minute}
+
+	t.Run("Hit", func(t *testing.T) {
+		mockRepo := mockrepository.NewTenantRepository(t)
+		cache := repository.NewTenantCache(mockRepo, cfg)
+		mockRepo.On("GetTenantByClientID", mock.Anything, "test-client").Return(&model.Tenant{ClientID: "test-client", Name: "Test Tenant"}, nil).Once()
+
+		for i := 0; i < 3; i++ {
+			tenant, err := cache.GetTenantByClientID(ctx, "test-client")
+			assert.NoError(t, err)
+			assert.Equal(t, "Test Tenant", tenant.Name)
+		}
+		assert.Equal(t, repository.CacheStats{Hits: 2, Misses: 1, Entries: 1}, cache.Stats())
+	})
+
+	t.Run("NegativeHit", func(t *testing.T) {
+		mockRepo := mockrepository.NewTenantRepository(t)
+		cache := repository.NewTenantCache(mockRepo, cfg)
+		mockRepo.On("GetTenantByClientID", mock.Anything, "missing-client").Return(nil, derrors.New(derrors.NotFound, "tenant not found")).Once()
+
+		for i := 0; i < 2; i++ {
+			_, err := cache.GetTenantByClientID(ctx, "missing-client")
+			assert.True(t, derrors.IsErrCode(err, derrors.NotFound))
+		}
+		assert.Equal(t, uint64(1), cache.Stats().NegativeHits)
+	})
+
+	t.Run("ErrorsAreNotCached", func(t *testing.T) {
+		mockRepo := mockrepository.NewTenantRepository(t)
+		cache := repository.NewTenantCache(mockRepo, cfg)
+		mockRepo.On("GetTenantByClientID", mock.Anything, "test-client").Return(nil, errors.New("connection refused")).Twice()
+
+		for i := 0; i < 2; i++ {
+			_, err := cache.GetTenantByClientID(ctx, "test-client")
+			assert.Error(t, err)
+		}
+		assert.Equal(t, 0, cache.Stats().Entries)
+	})
+
+	t.Run("Expiry", func(t *testing.T) {
+		mockRepo := mockrepository.NewTenantRepository(t)
+		cache := repository.NewTenantCache(mockRepo, repository.TenantCacheConfig{TTL: time.Millisecond})
+		mockRepo.On("GetTenantByClientID", mock.Anything, "test-client").Return(&model.Tenant{ClientID: "test-client"}, nil).Twice()
+
+		_, _ = cache.GetTenantByClientID(ctx, "test-client")
+		time.Sleep(5 * time.Millisecond)
+		_, _ = cache.GetTenantByClientID(ctx, "test-client")
+		assert.Equal(t, uint64(2), cache.Stats().Misses)
+	})
+
+	t.Run("DeleteInvalidates", func(t *testing.T) {
+		mockRepo := mockrepository.NewTenantRepository(t)
+		cache := repository.NewTenantCache(mockRepo, cfg)
+		mockRepo.On("GetTenantByClientID", mock.Anything, "test-client").Return(&model.Tenant{ClientID: "test-client"}, nil).Once()
+		mockRepo.On("SoftDeleteTenant", mock.Anything, "test-client").Return(nil).Once()
+		mockRepo.On("GetTenantByClientID", mock.Anything, "test-client").Return(nil, derrors.New(derrors.NotFound, "tenant not found")).Once()
+
+		_, err := cache.GetTenantByClientID(ctx, "test-client")
+		assert.NoError(t, err)
+		assert.NoError(t, cache.SoftDeleteTenant(ctx, "test-client"))
+		_, err = cache.GetTenantByClientID(ctx, "test-client")
+		assert.True(t, derrors.IsErrCode(err, derrors.NotFound))
+	})
+
+	t.Run("CreateDropsNegativeEntry", func(t *testing.T) {
+		mockRepo := mockrepository.NewTenantRepository(t)
+		cache := repository.NewTenantCache(mockRepo, cfg)
+		mockRepo.On("GetTenantByClientID", mock.Anything, "test-client").Return(nil, derrors.New(derrors.NotFound, "tenant not found")).Once()
+		mockRepo.On("CreateTenant", mock.Anything, mock.AnythingOfType("*model.Tenant")).Return(nil).Once()
+		mockRepo.On("GetTenantByClientID", mock.Anything, "test-client").Return(&model.Tenant{ClientID: "test-client"}, nil).Once()
+
+		_, err := cache.GetTenantByClientID(ctx, "test-client")
+		assert.Error(t, err)
+		assert.NoError(t, cache.CreateTenant(ctx, &model.Tenant{ClientID: "test-client"}))
+		_, err = cache.GetTenantByClientID(ctx, "test-client")
+		assert.NoError(t, err)
+	})
+
+	t.Run("MaxEntries", func(t *testing.T) {
+		mockRepo := mockrepository.NewTenantRepository(t)
+		cache := repository.NewTenantCache(mockRepo, repository.TenantCacheConfig{TTL: time.Minute, MaxEntries: 2})
+		mockRepo.On("GetTenantByClientID", mock.Anything, mock.Anything).Return(&model.Tenant{}, nil)
+
+		for _, clientID := range []string{"client-a", "client-b", "client-c"} {
+			_, _ = cache.GetTenantByClientID(ctx, clientID)
+		}
+		assert.Equal(t, 2, cache.Stats().Entries)
+		assert.Equal(t, uint64(1), cache.Stats().Evictions)
+	})
+}
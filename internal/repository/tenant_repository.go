@@ -2,22 +2,42 @@ package repository
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"time"
 
 	"tenant/internal/model"
 	"tenant/pkg/derrors"
 
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
+// constraintTenantClientID is the unique constraint on the client ID of
+// tenants, reported by name by translateError
+const constraintTenantClientID = "tenants_client_id_key"
+
+// TenantEventChannel is the Postgres NOTIFY channel tenant mutations are
+// broadcast on
+const TenantEventChannel = "tenant_events"
+
 type TenantRepository interface {
 	CreateTenant(ctx context.Context, tenant *model.Tenant) error
 	SoftDeleteTenant(ctx context.Context, clientID string) error
 	GetTenantByClientID(ctx context.Context, clientID string) (*model.Tenant, error)
+	ListTenants(ctx context.Context) ([]*model.Tenant, error)
+}
+
+// tenantDB is the part of *pgxpool.Pool the tenant repository uses
+type tenantDB interface {
+	Begin(ctx context.Context) (pgx.Tx, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
 }
 
 type tenantRepository struct {
-	db *pgxpool.Pool
+	db tenantDB
 }
 
 func NewTenantRepository(db *pgxpool.Pool) TenantRepository {
@@ -30,8 +50,13 @@ func (r *tenantRepository) CreateTenant(ctx context.Context, tenant *model.Tenan
         VALUES ($1, $2, $3, $4)
         RETURNING id
     `
-	err := r.db.QueryRow(ctx, query, tenant.ClientID, tenant.Name, time.Now(), time.Now()).Scan(&tenant.ID)
-	return err
+	err := pgx.BeginFunc(ctx, r.db, func(tx pgx.Tx) error {
+		if err := tx.QueryRow(ctx, query, tenant.ClientID, tenant.Name, time.Now(), time.Now()).Scan(&tenant.ID); err != nil {
+			return err
+		}
+		return notifyTenantEvent(ctx, tx, model.TenantEvent{Type: model.TenantEventCreated, ClientID: tenant.ClientID})
+	})
+	return translateError(err, "create tenant %q", tenant.Name)
 }
 
 func (r *tenantRepository) SoftDeleteTenant(ctx context.Context, clientID string) error {
@@ -40,11 +65,21 @@ func (r *tenantRepository) SoftDeleteTenant(ctx context.Context, clientID string
         SET deleted_at = $1, updated_at = $2
         WHERE client_id = $3 AND deleted_at IS NULL
     `
-	cmdTag, err := r.db.Exec(ctx, query, time.Now(), time.Now(), clientID)
+	var deleted bool
+	err := pgx.BeginFunc(ctx, r.db, func(tx pgx.Tx) error {
+		cmdTag, err := tx.Exec(ctx, query, time.Now(), time.Now(), clientID)
+		if err != nil {
+			return err
+		}
+		if deleted = cmdTag.RowsAffected() > 0; !deleted {
+			return nil
+		}
+		return notifyTenantEvent(ctx, tx, model.TenantEvent{Type: model.TenantEventDeleted, ClientID: clientID})
+	})
 	if err != nil {
-		return err
+		return translateError(err, "soft delete tenant %q", clientID)
 	}
-	if cmdTag.RowsAffected() == 0 {
+	if !deleted {
 		return derrors.New(derrors.NotFound, "tenant not found or already deleted")
 	}
 	return nil
@@ -65,7 +100,72 @@ func (r *tenantRepository) GetTenantByClientID(ctx context.Context, clientID str
 		&tenant.UpdatedAt,
 	)
 	if err != nil {
-		return nil, err
+		return nil, translateError(err, "get tenant %q", clientID)
 	}
 	return &tenant, nil
 }
+
+func (r *tenantRepository) ListTenants(ctx context.Context) ([]*model.Tenant, error) {
+	query := `
+        SELECT id, client_id, name, created_at, updated_at
+        FROM tenants
+        WHERE deleted_at IS NULL
+        ORDER BY id
+    `
+	rows, err := r.db.Query(ctx, query)
+	if err != nil {
+		return nil, translateError(err, "list tenants")
+	}
+	defer rows.Close()
+
+	var tenants []*model.Tenant
+	for rows.Next() {
+		var tenant model.Tenant
+		if err := rows.Scan(
+			&tenant.ID,
+			&tenant.ClientID,
+			&tenant.Name,
+			&tenant.CreatedAt,
+			&tenant.UpdatedAt,
+		); err != nil {
+			return nil, translateError(err, "scan tenant")
+		}
+		tenants = append(tenants, &tenant)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, translateError(err, "list tenants")
+	}
+	return tenants, nil
+}
+
+// notifyTenantEvent broadcasts a tenant event to the listeners of every
+// instance. Postgres delivers it when tx commits, and drops it on rollback.
+func notifyTenantEvent(ctx context.Context, tx pgx.Tx, event model.TenantEvent) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec(ctx, "SELECT pg_notify($1, $2)", TenantEventChannel, string(payload))
+	return err
+}
+
+// translateError classifies a pgx error into a typed derrors error, naming
+// the tenant constraint that was violated when there is one.
+func translateError(err error, format string, args ...any) error {
+	if err == nil {
+		return nil
+	}
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		return derrors.New(derrors.NotFound, "tenant not found")
+	}
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		if pgErr.ConstraintName == constraintTenantClientID {
+			return derrors.WrapStack(err, derrors.Duplicate, "tenant with this client_id already exists")
+		}
+	}
+
+	return derrors.HandlePgxError(err, format, args...)
+}
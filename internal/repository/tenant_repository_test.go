@@ -0,0 +1,203 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"tenant/internal/model"
+	"tenant/pkg/derrors"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTranslateError(t *testing.T) {
+	var testCases = []struct {
+		caseName string
+		err      error
+		code     derrors.ErrorCode
+		message  string
+	}{
+		{
+			caseName: "Nil",
+			err:      nil,
+		},
+		{
+			caseName: "NoRows",
+			err:      pgx.ErrNoRows,
+			code:     derrors.NotFound,
+			message:  "tenant not found",
+		},
+		{
+			caseName: "UniqueClientID",
+			err:      &pgconn.PgError{Code: "23505", ConstraintName: constraintTenantClientID},
+			code:     derrors.Duplicate,
+			message:  "client_id already exists",
+		},
+		{
+			caseName: "OtherCheckViolation",
+			err:      &pgconn.PgError{Code: "23514", ConstraintName: "tenants_other_check"},
+			code:     derrors.InvalidArgument,
+			message:  "get tenant",
+		},
+		{
+			caseName: "ContextCanceled",
+			err:      fmt.Errorf("query: %w", context.Canceled),
+			code:     derrors.Unknown,
+			message:  "get tenant",
+		},
+		{
+			caseName: "DeadlineExceeded",
+			err:      context.DeadlineExceeded,
+			code:     derrors.Timeout,
+			message:  "get tenant",
+		},
+		{
+			caseName: "Unknown",
+			err:      errors.New("unexpected"),
+			code:     derrors.Unknown,
+			message:  "get tenant",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.caseName, func(t *testing.T) {
+			err := translateError(tc.err, "get tenant %q", "test-client")
+			if tc.err == nil {
+				assert.Nil(t, err)
+				return
+			}
+
+			assert.True(t, derrors.IsErrCode(err, tc.code), "got %v", derrors.CodeOf(err))
+			assert.Contains(t, err.Error(), tc.message)
+		})
+	}
+}
+
+// fakeTenantDB fails every query of the tenant repository with err
+type fakeTenantDB struct {
+	err error
+}
+
+func (db *fakeTenantDB) Begin(ctx context.Context) (pgx.Tx, error) {
+	return &fakeTenantTx{err: db.err}, nil
+}
+
+func (db *fakeTenantDB) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	return nil, db.err
+}
+
+func (db *fakeTenantDB) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	return fakeTenantRow{err: db.err}
+}
+
+// fakeTenantTx implements the methods of pgx.Tx used by the repository
+type fakeTenantTx struct {
+	pgx.Tx
+	err error
+}
+
+func (tx *fakeTenantTx) Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error) {
+	return pgconn.CommandTag{}, tx.err
+}
+
+func (tx *fakeTenantTx) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	return fakeTenantRow{err: tx.err}
+}
+
+func (tx *fakeTenantTx) Commit(ctx context.Context) error   { return nil }
+func (tx *fakeTenantTx) Rollback(ctx context.Context) error { return nil }
+
+type fakeTenantRow struct {
+	err error
+}
+
+func (r fakeTenantRow) Scan(dest ...any) error {
+	return r.err
+}
+
+func TestTenantRepositoryErrors(t *testing.T) {
+	ctx := context.Background()
+
+	var testCases = []struct {
+		caseName string
+		err      error
+		call     func(repo TenantRepository) error
+		code     derrors.ErrorCode
+		message  string
+	}{
+		{
+			caseName: "GetTenant_NoRows",
+			err:      pgx.ErrNoRows,
+			call: func(repo TenantRepository) error {
+				_, err := repo.GetTenantByClientID(ctx, "missing-client")
+				return err
+			},
+			code:    derrors.NotFound,
+			message: "tenant not found",
+		},
+		{
+			caseName: "CreateTenant_UniqueViolation",
+			err:      &pgconn.PgError{Code: "23505", ConstraintName: constraintTenantClientID},
+			call: func(repo TenantRepository) error {
+				return repo.CreateTenant(ctx, &model.Tenant{ClientID: "test-client", Name: "Test"})
+			},
+			code:    derrors.Duplicate,
+			message: "client_id already exists",
+		},
+		{
+			caseName: "CreateTenant_CheckViolation",
+			err:      &pgconn.PgError{Code: "23514", ConstraintName: "tenants_name_check"},
+			call: func(repo TenantRepository) error {
+				return repo.CreateTenant(ctx, &model.Tenant{ClientID: "test-client", Name: "Test"})
+			},
+			code:    derrors.InvalidArgument,
+			message: `create tenant "Test"`,
+		},
+		{
+			caseName: "SoftDeleteTenant_Canceled",
+			err:      fmt.Errorf("query: %w", context.Canceled),
+			call: func(repo TenantRepository) error {
+				return repo.SoftDeleteTenant(ctx, "test-client")
+			},
+			code:    derrors.Unknown,
+			message: `soft delete tenant "test-client"`,
+		},
+		{
+			caseName: "ListTenants_Canceled",
+			err:      context.Canceled,
+			call: func(repo TenantRepository) error {
+				_, err := repo.ListTenants(ctx)
+				return err
+			},
+			code:    derrors.Unknown,
+			message: "list tenants",
+		},
+		{
+			caseName: "ListTenants_DeadlineExceeded",
+			err:      context.DeadlineExceeded,
+			call: func(repo TenantRepository) error {
+				_, err := repo.ListTenants(ctx)
+				return err
+			},
+			code:    derrors.Timeout,
+			message: "list tenants",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.caseName, func(t *testing.T) {
+			repo := &tenantRepository{db: &fakeTenantDB{err: tc.err}}
+
+			err := tc.call(repo)
+			assert.True(t, derrors.IsErrCode(err, tc.code), "got %v", derrors.CodeOf(err))
+			assert.Contains(t, err.Error(), tc.message)
+			if !errors.Is(tc.err, pgx.ErrNoRows) {
+				assert.ErrorIs(t, err, tc.err)
+			}
+		})
+	}
+}
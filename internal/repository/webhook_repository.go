@@ -0,0 +1,239 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"tenant/internal/model"
+	"tenant/pkg/derrors"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// WebhookRepository stores the webhooks of tenants and the log of their
+// deliveries
+type WebhookRepository interface {
+	CreateWebhook(ctx context.Context, webhook *model.Webhook) error
+	GetWebhook(ctx context.Context, clientID, webhookID string) (*model.Webhook, error)
+	ListWebhooks(ctx context.Context, clientID string) ([]*model.Webhook, error)
+	UpdateWebhook(ctx context.Context, webhook *model.Webhook) error
+	DeleteWebhook(ctx context.Context, clientID, webhookID string) error
+	RecordDelivery(ctx context.Context, delivery *model.WebhookDelivery) error
+	ListDeliveries(ctx context.Context, clientID, webhookID string, limit int) ([]*model.WebhookDelivery, error)
+	ListDelivered(ctx context.Context, messageID string) ([]string, error)
+	SaveHealth(ctx context.Context, health *model.WebhookHealth) error
+	GetHealth(ctx context.Context, clientID, webhookID string) (*model.WebhookHealth, error)
+}
+
+type webhookRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewWebhookRepository(db *pgxpool.Pool) WebhookRepository {
+	return &webhookRepository{db: db}
+}
+
+const webhookColumns = `id, client_id, url, secret, enabled, created_at, updated_at`
+
+func (r *webhookRepository) CreateWebhook(ctx context.Context, webhook *model.Webhook) error {
+	query := `
+        INSERT INTO webhooks (id, client_id, url, secret, enabled, created_at, updated_at)
+        VALUES ($1, $2, $3, $4, $5, NOW(), NOW())
+        RETURNING created_at, updated_at
+    `
+	err := r.db.QueryRow(ctx, query, webhook.ID, webhook.ClientID, webhook.URL, webhook.Secret, webhook.Enabled).
+		Scan(&webhook.CreatedAt, &webhook.UpdatedAt)
+	return derrors.HandlePgxError(err, "create webhook %q for tenant %q", webhook.URL, webhook.ClientID)
+}
+
+func (r *webhookRepository) GetWebhook(ctx context.Context, clientID, webhookID string) (*model.Webhook, error) {
+	query := `SELECT ` + webhookColumns + ` FROM webhooks WHERE client_id = $1 AND id = $2`
+	rows, err := r.db.Query(ctx, query, clientID, webhookID)
+	if err != nil {
+		return nil, derrors.HandlePgxError(err, "get webhook %q", webhookID)
+	}
+	webhook, err := pgx.CollectExactlyOneRow(rows, scanWebhook)
+	if err != nil {
+		return nil, derrors.HandlePgxError(err, "get webhook %q", webhookID)
+	}
+	return webhook, nil
+}
+
+// ListWebhooks returns the webhooks of a tenant, oldest first
+func (r *webhookRepository) ListWebhooks(ctx context.Context, clientID string) ([]*model.Webhook, error) {
+	query := `SELECT ` + webhookColumns + ` FROM webhooks WHERE client_id = $1 ORDER BY created_at, id`
+	rows, err := r.db.Query(ctx, query, clientID)
+	if err != nil {
+		return nil, derrors.HandlePgxError(err, "list webhooks of tenant %q", clientID)
+	}
+	webhooks, err := pgx.CollectRows(rows, scanWebhook)
+	if err != nil {
+		return nil, derrors.HandlePgxError(err, "list webhooks of tenant %q", clientID)
+	}
+	return webhooks, nil
+}
+
+// UpdateWebhook replaces the URL, secret and state of a webhook
+func (r *webhookRepository) UpdateWebhook(ctx context.Context, webhook *model.Webhook) error {
+	query := `
+        UPDATE webhooks
+        SET url = $3, secret = $4, enabled = $5, updated_at = NOW()
+        WHERE client_id = $1 AND id = $2
+        RETURNING created_at, updated_at
+    `
+	err := r.db.QueryRow(ctx, query, webhook.ClientID, webhook.ID, webhook.URL, webhook.Secret, webhook.Enabled).
+		Scan(&webhook.CreatedAt, &webhook.UpdatedAt)
+	return derrors.HandlePgxError(err, "update webhook %q", webhook.ID)
+}
+
+// DeleteWebhook deletes a webhook along with its delivery log
+func (r *webhookRepository) DeleteWebhook(ctx context.Context, clientID, webhookID string) error {
+	cmdTag, err := r.db.Exec(ctx, `DELETE FROM webhooks WHERE client_id = $1 AND id = $2`, clientID, webhookID)
+	if err != nil {
+		return derrors.HandlePgxError(err, "delete webhook %q", webhookID)
+	}
+	if cmdTag.RowsAffected() == 0 {
+		return derrors.New(derrors.NotFound, "no webhook %q", webhookID)
+	}
+	return nil
+}
+
+func (r *webhookRepository) RecordDelivery(ctx context.Context, delivery *model.WebhookDelivery) error {
+	query := `
+        INSERT INTO webhook_deliveries (webhook_id, client_id, message_id, attempt, retry, status_code, duration_ms, error, succeeded, delivered_at)
+        VALUES ($1, $2, $3, $4, $5, NULLIF($6, 0), $7, NULLIF($8, ''), $9, NOW())
+        RETURNING id, delivered_at
+    `
+	err := r.db.QueryRow(ctx, query, delivery.WebhookID, delivery.ClientID, delivery.MessageID, delivery.Attempt, delivery.Retry,
+		delivery.StatusCode, delivery.Duration.Milliseconds(), delivery.Error, delivery.Succeeded).Scan(&delivery.ID, &delivery.DeliveredAt)
+	return derrors.HandlePgxError(err, "record delivery of message %q to webhook %q", delivery.MessageID, delivery.WebhookID)
+}
+
+// ListDeliveries returns up to limit deliveries of a webhook, newest first
+func (r *webhookRepository) ListDeliveries(ctx context.Context, clientID, webhookID string, limit int) ([]*model.WebhookDelivery, error) {
+	query := `
+        SELECT id, webhook_id, client_id, message_id, attempt, retry, COALESCE(status_code, 0),
+            duration_ms, COALESCE(error, ''), succeeded, delivered_at
+        FROM webhook_deliveries
+        WHERE client_id = $1 AND webhook_id = $2
+        ORDER BY delivered_at DESC, id DESC
+        LIMIT $3
+    `
+	rows, err := r.db.Query(ctx, query, clientID, webhookID, limit)
+	if err != nil {
+		return nil, derrors.HandlePgxError(err, "list deliveries of webhook %q", webhookID)
+	}
+	deliveries, err := pgx.CollectRows(rows, scanWebhookDelivery)
+	if err != nil {
+		return nil, derrors.HandlePgxError(err, "list deliveries of webhook %q", webhookID)
+	}
+	return deliveries, nil
+}
+
+// ListDelivered returns the webhooks a message was already delivered to
+func (r *webhookRepository) ListDelivered(ctx context.Context, messageID string) ([]string, error) {
+	query := `SELECT DISTINCT webhook_id FROM webhook_deliveries WHERE message_id = $1 AND succeeded`
+	rows, err := r.db.Query(ctx, query, messageID)
+	if err != nil {
+		return nil, derrors.HandlePgxError(err, "list webhooks of message %q", messageID)
+	}
+	webhookIDs, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		return nil, derrors.HandlePgxError(err, "list webhooks of message %q", messageID)
+	}
+	return webhookIDs, nil
+}
+
+// SaveHealth stores the circuit breaker state reported by a worker,
+// replacing the previous report
+func (r *webhookRepository) SaveHealth(ctx context.Context, health *model.WebhookHealth) error {
+	query := `
+        INSERT INTO webhook_health (webhook_id, client_id, worker_id, state, consecutive_failures, successes, failures,
+            last_latency_ms, avg_latency_ms, last_error, opened_at, retry_at, updated_at)
+        SELECT $1, $2, $3, $4, $5, $6, $7, $8, $9, NULLIF($10, ''), $11, $12, NOW()
+        WHERE EXISTS (SELECT 1 FROM webhooks WHERE id = $1)
+        ON CONFLICT (webhook_id) DO UPDATE
+        SET worker_id = EXCLUDED.worker_id, state = EXCLUDED.state, consecutive_failures = EXCLUDED.consecutive_failures,
+            successes = EXCLUDED.successes, failures = EXCLUDED.failures, last_latency_ms = EXCLUDED.last_latency_ms,
+            avg_latency_ms = EXCLUDED.avg_latency_ms, last_error = EXCLUDED.last_error, opened_at = EXCLUDED.opened_at,
+            retry_at = EXCLUDED.retry_at, updated_at = EXCLUDED.updated_at
+    `
+	_, err := r.db.Exec(ctx, query, health.WebhookID, health.ClientID, health.WorkerID, health.State, health.ConsecutiveFailures,
+		health.Successes, health.Failures, health.LastLatency.Milliseconds(), health.AvgLatency.Milliseconds(), health.LastError,
+		health.OpenedAt, health.RetryAt)
+	return derrors.HandlePgxError(err, "save health of webhook %q", health.WebhookID)
+}
+
+// GetHealth returns the last circuit breaker state reported for a webhook
+func (r *webhookRepository) GetHealth(ctx context.Context, clientID, webhookID string) (*model.WebhookHealth, error) {
+	query := `
+        SELECT webhook_id, client_id, worker_id, state, consecutive_failures, successes, failures,
+            last_latency_ms, avg_latency_ms, COALESCE(last_error, ''), opened_at, retry_at, updated_at
+        FROM webhook_health
+        WHERE client_id = $1 AND webhook_id = $2
+    `
+	var health model.WebhookHealth
+	var lastLatencyMs, avgLatencyMs int64
+	err := r.db.QueryRow(ctx, query, clientID, webhookID).Scan(
+		&health.WebhookID,
+		&health.ClientID,
+		&health.WorkerID,
+		&health.State,
+		&health.ConsecutiveFailures,
+		&health.Successes,
+		&health.Failures,
+		&lastLatencyMs,
+		&avgLatencyMs,
+		&health.LastError,
+		&health.OpenedAt,
+		&health.RetryAt,
+		&health.UpdatedAt,
+	)
+	if err != nil {
+		return nil, derrors.HandlePgxError(err, "get health of webhook %q", webhookID)
+	}
+	health.LastLatency = time.Duration(lastLatencyMs) * time.Millisecond
+	health.AvgLatency = time.Duration(avgLatencyMs) * time.Millisecond
+	return &health, nil
+}
+
+func scanWebhook(row pgx.CollectableRow) (*model.Webhook, error) {
+	var webhook model.Webhook
+	err := row.Scan(
+		&webhook.ID,
+		&webhook.ClientID,
+		&webhook.URL,
+		&webhook.Secret,
+		&webhook.Enabled,
+		&webhook.CreatedAt,
+		&webhook.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &webhook, nil
+}
+
+func scanWebhookDelivery(row pgx.CollectableRow) (*model.WebhookDelivery, error) {
+	var delivery model.WebhookDelivery
+	var durationMs int64
+	err := row.Scan(
+		&delivery.ID,
+		&delivery.WebhookID,
+		&delivery.ClientID,
+		&delivery.MessageID,
+		&delivery.Attempt,
+		&delivery.Retry,
+		&delivery.StatusCode,
+		&durationMs,
+		&delivery.Error,
+		&delivery.Succeeded,
+		&delivery.DeliveredAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	delivery.Duration = time.Duration(durationMs) * time.Millisecond
+	return &delivery, nil
+}
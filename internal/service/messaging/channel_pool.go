@@ -0,0 +1,77 @@
+package messaging
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/rabbitmq/amqp091-go"
+)
+
+// channelPool hands out publisher channels so concurrent publishes do not
+// serialize on a single channel. At most size channels are in use at once,
+// callers beyond that wait for one to be returned. Channels are in confirm
+// mode, so publishers can wait for the broker to take responsibility for
+// their messages. Idle channels are closed with the connection on shutdown.
+type channelPool struct {
+	conn  *amqp091.Connection
+	idle  chan *amqp091.Channel
+	slots chan struct{}
+}
+
+func newChannelPool(conn *amqp091.Connection, size int) *channelPool {
+	if size <= 0 {
+		size = 1
+	}
+	return &channelPool{
+		conn:  conn,
+		idle:  make(chan *amqp091.Channel, size),
+		slots: make(chan struct{}, size),
+	}
+}
+
+// get returns an open channel, reusing an idle one when possible. Every
+// channel obtained from get must be given back with put.
+func (p *channelPool) get(ctx context.Context) (*amqp091.Channel, error) {
+	select {
+	case p.slots <- struct{}{}:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	for {
+		select {
+		case ch := <-p.idle:
+			if ch.IsClosed() {
+				continue
+			}
+			return ch, nil
+		default:
+			ch, err := p.conn.Channel()
+			if err != nil {
+				<-p.slots
+				return nil, fmt.Errorf("failed to open publisher channel: %w", err)
+			}
+			if err := ch.Confirm(false); err != nil {
+				_ = ch.Close()
+				<-p.slots
+				return nil, fmt.Errorf("failed to enable publisher confirms: %w", err)
+			}
+			return ch, nil
+		}
+	}
+}
+
+// put returns a channel to the pool. Channels closed by an exception are
+// dropped, the next get opens a fresh one.
+func (p *channelPool) put(ch *amqp091.Channel) {
+	defer func() { <-p.slots }()
+
+	if ch.IsClosed() {
+		return
+	}
+	select {
+	case p.idle <- ch:
+	default:
+		_ = ch.Close()
+	}
+}
@@ -0,0 +1,79 @@
+package messaging
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/rabbitmq/amqp091-go"
+)
+
+// HeaderAttempt is the AMQP header counting how many times a message was
+// delivered to a consumer, it is absent on the first attempt
+const HeaderAttempt = "x-attempt"
+
+// HeaderError is the AMQP header carrying why the last attempt of a
+// retried or dead-lettered message failed
+const HeaderError = "x-error"
+
+// HeaderReplayOf and HeaderReplayJob are the AMQP headers of a replayed
+// message, carrying the ID of the original message and of the replay job
+const (
+	HeaderReplayOf  = "x-replay-of"
+	HeaderReplayJob = "x-replay-job"
+)
+
+// HeaderScheduleID is the AMQP header carrying the cron schedule that
+// published a message
+const HeaderScheduleID = "x-schedule-id"
+
+// HeaderForwardedFrom and HeaderForwardedMessageID are the AMQP headers of a
+// message forwarded by another tenant, carrying its client ID and the ID of
+// the original message. HeaderForwardedPath lists every tenant the message
+// was forwarded by, comma separated, oldest first.
+const (
+	HeaderForwardedFrom      = "x-forwarded-from"
+	HeaderForwardedMessageID = "x-forwarded-message-id"
+	HeaderForwardedPath      = "x-forwarded-path"
+)
+
+// Delivery is a message received from a queue
+type Delivery struct {
+	MessageID     string
+	CorrelationID string
+	ContentType   string
+	Priority      uint8
+
+	// Headers holds the string valued AMQP headers
+	Headers map[string]string
+	Body    []byte
+
+	// Attempt starts at 1 and grows every time the message is retried
+	Attempt     int
+	Redelivered bool
+}
+
+// Handler processes a delivery. The delivery is acked when it returns nil
+// and requeued otherwise.
+type Handler func(ctx context.Context, delivery *Delivery) error
+
+func newDelivery(msg amqp091.Delivery) *Delivery {
+	delivery := &Delivery{
+		MessageID:     msg.MessageId,
+		CorrelationID: msg.CorrelationId,
+		ContentType:   msg.ContentType,
+		Priority:      msg.Priority,
+		Headers:       make(map[string]string, len(msg.Headers)),
+		Body:          msg.Body,
+		Attempt:       1,
+		Redelivered:   msg.Redelivered,
+	}
+	for key, value := range msg.Headers {
+		if s, ok := value.(string); ok {
+			delivery.Headers[key] = s
+		}
+	}
+	if attempt, err := strconv.Atoi(delivery.Headers[HeaderAttempt]); err == nil && attempt > 0 {
+		delivery.Attempt = attempt
+	}
+	return delivery
+}
@@ -0,0 +1,148 @@
+package messaging
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+	"time"
+
+	"tenant/pkg/logger"
+	"tenant/pkg/metrics"
+	"tenant/pkg/trace"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Middleware wraps a Handler with behavior shared by every consumer
+type Middleware func(next Handler) Handler
+
+var (
+	consumerMessages = metrics.NewCounter("tenant_consumer_messages_total",
+		"Messages handled by consumers, by queue and result (ack, nack)", "queue", "result")
+	consumerDuration = metrics.NewSummary("tenant_consumer_handle_duration_seconds",
+		"Duration of consumer handlers, by queue", "queue")
+	consumerPanics = metrics.NewCounter("tenant_consumer_panics_total",
+		"Panics recovered from consumer handlers, by queue", "queue")
+)
+
+type queueKey struct{}
+
+// PanicError is returned for a handler that panicked
+type PanicError struct {
+	Value interface{}
+	Stack []byte
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("handler panicked: %v", e.Value)
+}
+
+// Chain wraps handler with middlewares, the first middleware being the
+// outermost one
+func Chain(handler Handler, middlewares ...Middleware) Handler {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		handler = middlewares[i](handler)
+	}
+	return handler
+}
+
+// QueueName returns the name of the queue a delivery was consumed from, or
+// an empty string outside of a consumer handler
+func QueueName(ctx context.Context) string {
+	name, _ := ctx.Value(queueKey{}).(string)
+	return name
+}
+
+func withQueueName(ctx context.Context, queueName string) context.Context {
+	return context.WithValue(ctx, queueKey{}, queueName)
+}
+
+// Recover turns a panic of the handler into a PanicError, so the delivery is
+// rejected instead of the consumer dying with the process
+func Recover(log *logrus.Logger) Middleware {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, delivery *Delivery) (err error) {
+			defer func() {
+				if r := recover(); r != nil {
+					panicErr := &PanicError{Value: r, Stack: debug.Stack()}
+					consumerPanics.Inc(QueueName(ctx))
+					logger.WithContext(ctx, log).WithField("stack", string(panicErr.Stack)).
+						Errorf("recovered from panic handling message %s from queue '%s': %v", delivery.MessageID, QueueName(ctx), r)
+					err = panicErr
+				}
+			}()
+			return next(ctx, delivery)
+		}
+	}
+}
+
+// Logging annotates the logs of the handler with the message ID and logs the
+// outcome of every delivery
+func Logging(log *logrus.Logger) Middleware {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, delivery *Delivery) error {
+			ctx = logger.WithMessageID(ctx, delivery.MessageID)
+			start := time.Now()
+			logger.WithContext(ctx, log).Debugf("handling message %s (attempt %d) from queue '%s'", delivery.MessageID, delivery.Attempt, QueueName(ctx))
+
+			err := next(ctx, delivery)
+			entry := logger.WithContext(ctx, log).WithField("duration", time.Since(start).String())
+			if err != nil {
+				entry.Warnf("failed to handle message %s from queue '%s': %v", delivery.MessageID, QueueName(ctx), err)
+				return err
+			}
+			entry.Debugf("handled message %s from queue '%s'", delivery.MessageID, QueueName(ctx))
+			return nil
+		}
+	}
+}
+
+// Metrics counts the deliveries of every queue and observes how long their
+// handler took
+func Metrics() Middleware {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, delivery *Delivery) error {
+			queueName := QueueName(ctx)
+			start := time.Now()
+			err := next(ctx, delivery)
+			consumerDuration.Observe(time.Since(start).Seconds(), queueName)
+
+			result := "ack"
+			if err != nil {
+				result = "nack"
+			}
+			consumerMessages.Inc(queueName, result)
+			return err
+		}
+	}
+}
+
+// TraceContext propagates the W3C trace context of a delivery, or starts a
+// new one, to the handler. Its logs carry the trace ID and the messages it
+// publishes join the same trace. Nothing is exported to a tracing backend.
+func TraceContext() Middleware {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, delivery *Delivery) error {
+			span := trace.NewRoot()
+			if parent, ok := trace.Parse(delivery.Headers[trace.Header]); ok {
+				span = parent.Child()
+			}
+			return next(trace.WithSpan(ctx, span), delivery)
+		}
+	}
+}
+
+// Timeout bounds the time a handler may take, a zero timeout disables it.
+// The handler must honor the cancellation of its context.
+func Timeout(timeout time.Duration) Middleware {
+	return func(next Handler) Handler {
+		if timeout <= 0 {
+			return next
+		}
+		return func(ctx context.Context, delivery *Delivery) error {
+			ctx, cancel := context.WithTimeout(ctx, timeout)
+			defer cancel()
+			return next(ctx, delivery)
+		}
+	}
+}
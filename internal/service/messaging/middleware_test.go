@@ -0,0 +1,148 @@
+package messaging
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"tenant/pkg/logger"
+	"tenant/pkg/trace"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChain(t *testing.T) {
+	var calls []string
+	middleware := func(name string) Middleware {
+		return func(next Handler) Handler {
+			return func(ctx context.Context, delivery *Delivery) error {
+				calls = append(calls, name+" in")
+				err := next(ctx, delivery)
+				calls = append(calls, name+" out")
+				return err
+			}
+		}
+	}
+
+	handler := Chain(func(ctx context.Context, delivery *Delivery) error {
+		calls = append(calls, "handler")
+		return nil
+	}, middleware("first"), middleware("second"))
+
+	assert.Nil(t, handler(context.Background(), &Delivery{MessageID: "msg-1"}))
+	assert.Equal(t, []string{"first in", "second in", "handler", "second out", "first out"}, calls)
+}
+
+func TestRecover(t *testing.T) {
+	var buf bytes.Buffer
+	log := logrus.New()
+	log.SetOutput(&buf)
+	ctx := withQueueName(context.Background(), "client-a.process")
+
+	handler := Recover(log)(func(ctx context.Context, delivery *Delivery) error {
+		var headers map[string]string
+		headers["x-source"] = "test"
+		return nil
+	})
+
+	err := handler(ctx, &Delivery{MessageID: "msg-1"})
+	var panicErr *PanicError
+	assert.ErrorAs(t, err, &panicErr)
+	assert.EqualError(t, err, "handler panicked: assignment to entry in nil map")
+	assert.NotEmpty(t, panicErr.Stack)
+	assert.Contains(t, buf.String(), "recovered from panic handling message msg-1 from queue 'client-a.process'")
+
+	// Errors go through untouched
+	cause := errors.New("webhook responded 503")
+	handler = Recover(log)(func(ctx context.Context, delivery *Delivery) error { return cause })
+	assert.Equal(t, cause, handler(ctx, &Delivery{MessageID: "msg-1"}))
+}
+
+func TestLogging(t *testing.T) {
+	var buf bytes.Buffer
+	log := logrus.New()
+	log.SetOutput(&buf)
+	log.SetFormatter(&logrus.JSONFormatter{})
+
+	handler := Logging(log)(func(ctx context.Context, delivery *Delivery) error {
+		assert.Equal(t, "msg-1", logger.MessageID(ctx))
+		return errors.New("webhook responded 503")
+	})
+
+	ctx := logger.WithClientID(context.Background(), "client-a")
+	assert.NotNil(t, handler(ctx, &Delivery{MessageID: "msg-1"}))
+	assert.Contains(t, buf.String(), `"message_id":"msg-1"`)
+	assert.Contains(t, buf.String(), `"client_id":"client-a"`)
+	assert.Contains(t, buf.String(), "failed to handle message msg-1")
+}
+
+func TestTraceContext(t *testing.T) {
+	parent := trace.NewRoot()
+
+	var testCases = []struct {
+		caseName string
+		headers  map[string]string
+		results  func(span trace.Span)
+	}{
+		{
+			caseName: "TraceContext_ContinuesPublisherTrace",
+			headers:  map[string]string{trace.Header: parent.Traceparent()},
+			results: func(span trace.Span) {
+				assert.Equal(t, parent.TraceID, span.TraceID)
+				assert.Equal(t, parent.SpanID, span.ParentID)
+			},
+		},
+		{
+			caseName: "TraceContext_StartsNewTrace",
+			headers:  map[string]string{trace.Header: "invalid"},
+			results: func(span trace.Span) {
+				assert.NotEqual(t, parent.TraceID, span.TraceID)
+				assert.Empty(t, span.ParentID)
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.caseName, func(t *testing.T) {
+			var span trace.Span
+			handler := TraceContext()(func(ctx context.Context, delivery *Delivery) error {
+				var ok bool
+				span, ok = trace.FromContext(ctx)
+				assert.True(t, ok)
+
+				// Messages published by the handler join the trace
+				publishing := newPublishing(ctx, Message{ID: "msg-2", Headers: delivery.Headers}, []byte(`"a"`))
+				assert.Equal(t, span.Traceparent(), publishing.Headers[trace.Header])
+				return nil
+			})
+
+			assert.Nil(t, handler(context.Background(), &Delivery{MessageID: "msg-1", Headers: testCase.headers}))
+			testCase.results(span)
+		})
+	}
+}
+
+func TestShouldRequeue(t *testing.T) {
+	assert.True(t, shouldRequeue(errors.New("webhook responded 503")))
+	assert.False(t, shouldRequeue(&PanicError{Value: "nil map"}))
+	assert.False(t, shouldRequeue(fmt.Errorf("processor log: %w", &PanicError{Value: "nil map"})))
+}
+
+func TestTimeout(t *testing.T) {
+	handler := Timeout(10 * time.Millisecond)(func(ctx context.Context, delivery *Delivery) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	assert.ErrorIs(t, handler(context.Background(), &Delivery{}), context.DeadlineExceeded)
+
+	handler = Timeout(0)(func(ctx context.Context, delivery *Delivery) error {
+		_, ok := ctx.Deadline()
+		assert.False(t, ok)
+		return nil
+	})
+	assert.Nil(t, handler(context.Background(), &Delivery{}))
+}
@@ -3,78 +3,263 @@ package messaging
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"tenant/pkg/logger"
+	"tenant/pkg/trace"
 
 	"github.com/rabbitmq/amqp091-go"
 	"github.com/sirupsen/logrus"
 )
 
+// HeaderRequestID is the AMQP header carrying the ID of the request that
+// published a message.
+const HeaderRequestID = "x-request-id"
+
+// errNacked is returned for a message the broker refused to take
+var errNacked = errors.New("message was nacked by the broker")
+
+// DefaultContentType is the content type of messages that do not set one
+const DefaultContentType = "text/plain"
+
+// Message is a payload published along with its AMQP properties
+type Message struct {
+	// ID is stamped as the AMQP message ID
+	ID   string
+	Body interface{}
+
+	// Headers are added to the AMQP headers, they cannot override the
+	// request ID header nor the trace context of a traced ctx
+	Headers map[string]string
+
+	// CorrelationID defaults to the request ID of ctx
+	CorrelationID string
+
+	// ContentType defaults to DefaultContentType
+	ContentType string
+
+	Priority uint8
+
+	// Expiration discards the message if it is not consumed in time, zero
+	// keeps it until consumed
+	Expiration time.Duration
+}
+
 type Messagging interface {
-	Publish(ctx context.Context, queueName string, message interface{}) error
-	Consume(ctx context.Context, queueName string, handler func(string)) error
+	Publish(ctx context.Context, queueName string, message Message) error
+	PublishBatch(ctx context.Context, queueName string, messages []Message) []error
+	Consume(ctx context.Context, queueName string, handler Handler) error
 	CreateQueue(queueName string) error
 	DeleteQueue(queueName string) error
-	StartQueue(ctx context.Context, queueName string, handler func(string)) error
+	StartQueue(ctx context.Context, queueName string, handler Handler) error
+	StopQueue(queueName string) error
+	IsConsuming(queueName string) bool
+	Ping() error
+	CheckConsumers() error
+}
+
+// Config tunes the RabbitMQ client
+type Config struct {
+	// PublisherChannels bounds the channels used concurrently by Publish
+	PublisherChannels int
+
+	// Prefetch bounds the unacked deliveries of each consumer
+	Prefetch int
+
+	// Middlewares wrap the handler of every consumer, the first one being
+	// the outermost
+	Middlewares []Middleware
 }
 
 type RabbitMQ struct {
+	log     *logrus.Logger
+	conn    *amqp091.Connection
 	channel *amqp091.Channel
+
+	publishers  *channelPool
+	middlewares []Middleware
+
+	// declared memoizes the queues already declared by this instance, so
+	// Publish only declares a queue the first time it publishes to it
+	declared sync.Map
+
+	// consumers tracks every queue consumed by this instance and whether its
+	// delivery loop is still running.
+	mu        sync.RWMutex
+	consumers map[string]bool
 }
 
 // NewRabbitMQ initializes a new RabbitMQ instance
-func NewRabbitMQ(mqConn *amqp091.Connection) Messagging {
+func NewRabbitMQ(mqConn *amqp091.Connection, log *logrus.Logger, cfg Config) Messagging {
 	mqChannel, err := mqConn.Channel()
 	if err != nil {
-		logrus.Fatalf("failed to create RabbitMQ channel: %s", err)
+		log.Fatalf("failed to create RabbitMQ channel: %s", err)
+	}
+	if cfg.Prefetch > 0 {
+		// Applies to every consumer started on the channel afterwards
+		if err := mqChannel.Qos(cfg.Prefetch, 0, false); err != nil {
+			log.Fatalf("failed to set RabbitMQ prefetch: %s", err)
+		}
 	}
 
 	return &RabbitMQ{
-		channel: mqChannel,
+		log:         log,
+		conn:        mqConn,
+		channel:     mqChannel,
+		publishers:  newChannelPool(mqConn, cfg.PublisherChannels),
+		middlewares: cfg.Middlewares,
+		consumers:   make(map[string]bool),
 	}
 }
 
-// Publish sends a message to the specified queue
-func (mq *RabbitMQ) Publish(ctx context.Context, queueName string, message interface{}) error {
-	_, err := mq.channel.QueueDeclare(
-		queueName, true, false, false, false, nil,
-	)
+// Publish sends a message to the specified queue and waits for the broker
+// to confirm it
+func (mq *RabbitMQ) Publish(ctx context.Context, queueName string, message Message) error {
+	bodyJson, err := json.Marshal(message.Body)
 	if err != nil {
-		return fmt.Errorf("failed to declare queue: %w", err)
+		return fmt.Errorf("failed to marshal message: %w", err)
 	}
 
-	bodyJson, err := json.Marshal(message)
+	ch, err := mq.publishers.get(ctx)
 	if err != nil {
-		return fmt.Errorf("failed to marshal message: %w", err)
+		return err
+	}
+	defer mq.publishers.put(ch)
+
+	if err := mq.declare(ch, queueName); err != nil {
+		return err
 	}
 
-	err = mq.channel.PublishWithContext(
+	confirm, err := ch.PublishWithDeferredConfirmWithContext(
 		ctx,
 		"", // Exchange
 		queueName, false, false,
-		amqp091.Publishing{
-			ContentType: "text/plain",
-			Body:        bodyJson,
-		},
+		newPublishing(ctx, message, bodyJson),
 	)
 	if err != nil {
+		// The queue may have been deleted behind our back, declare it again next time
+		mq.declared.Delete(queueName)
+		return fmt.Errorf("failed to publish message: %w", err)
+	}
+	if err := waitConfirm(ctx, confirm); err != nil {
 		return fmt.Errorf("failed to publish message: %w", err)
 	}
 
+	logger.WithContext(ctx, mq.log).Debugf("published message %s of %d bytes to queue '%s'", message.ID, len(bodyJson), queueName)
 	return nil
 }
 
-// Consume sets up a consumer for the specified queue
-func (mq *RabbitMQ) Consume(ctx context.Context, queueName string, handler func(string)) error {
+// PublishBatch sends messages to the specified queue on a single channel,
+// publishing all of them before waiting for the confirms. The returned
+// errors are aligned with messages, nil for every message the broker
+// confirmed.
+func (mq *RabbitMQ) PublishBatch(ctx context.Context, queueName string, messages []Message) []error {
+	errs := make([]error, len(messages))
+	failAll := func(from int, err error) {
+		for i := from; i < len(messages); i++ {
+			errs[i] = err
+		}
+	}
+
+	ch, err := mq.publishers.get(ctx)
+	if err != nil {
+		failAll(0, err)
+		return errs
+	}
+	defer mq.publishers.put(ch)
+
+	if err := mq.declare(ch, queueName); err != nil {
+		failAll(0, err)
+		return errs
+	}
+
+	confirms := make([]*amqp091.DeferredConfirmation, len(messages))
+	for i, message := range messages {
+		bodyJson, err := json.Marshal(message.Body)
+		if err != nil {
+			errs[i] = fmt.Errorf("failed to marshal message: %w", err)
+			continue
+		}
+
+		confirms[i], err = ch.PublishWithDeferredConfirmWithContext(
+			ctx,
+			"", // Exchange
+			queueName, false, false,
+			newPublishing(ctx, message, bodyJson),
+		)
+		if err != nil {
+			// Nothing more can be published once the channel is gone
+			mq.declared.Delete(queueName)
+			failAll(i, fmt.Errorf("failed to publish message: %w", err))
+			break
+		}
+	}
+
+	published := 0
+	for i, confirm := range confirms {
+		if confirm == nil || errs[i] != nil {
+			continue
+		}
+		if err := waitConfirm(ctx, confirm); err != nil {
+			errs[i] = fmt.Errorf("failed to publish message: %w", err)
+			continue
+		}
+		published++
+	}
+
+	logger.WithContext(ctx, mq.log).Debugf("published %d of %d messages to queue '%s'", published, len(messages), queueName)
+	return errs
+}
+
+// Consume sets up a consumer for the specified queue. Deliveries are acked
+// once the handler, wrapped with the configured middlewares, returns nil and
+// requeued when it returns an error. A delivery whose handler panicked is
+// rejected, as it would panic again on every redelivery.
+func (mq *RabbitMQ) Consume(ctx context.Context, queueName string, handler Handler) error {
+	// The queue name doubles as consumer tag so StopQueue can cancel it
 	msgs, err := mq.channel.ConsumeWithContext(
-		ctx, queueName, "", true, false, false, false, nil,
+		ctx, queueName, queueName, false, false, false, false, nil,
 	)
 	if err != nil {
 		return fmt.Errorf("failed to register consumer: %w", err)
 	}
 
+	handler = Chain(handler, mq.middlewares...)
+	ctx = withQueueName(ctx, queueName)
+
+	mq.setConsumer(queueName, true)
 	go func() {
 		for msg := range msgs {
-			handler(string(msg.Body))
+			msgCtx := deliveryContext(ctx, msg)
+			logger.WithContext(msgCtx, mq.log).Debugf("received message %s of %d bytes from queue '%s'", msg.MessageId, len(msg.Body), queueName)
+
+			if err := handler(msgCtx, newDelivery(msg)); err != nil {
+				requeue := shouldRequeue(err)
+				if requeue {
+					logger.WithContext(msgCtx, mq.log).Errorf("requeueing message %s from queue '%s': %v", msg.MessageId, queueName, err)
+				} else {
+					logger.WithContext(msgCtx, mq.log).Errorf("rejecting message %s from queue '%s': %v", msg.MessageId, queueName, err)
+				}
+				if err := msg.Nack(false, requeue); err != nil {
+					logger.WithContext(msgCtx, mq.log).Errorf("failed to nack message %s: %v", msg.MessageId, err)
+				}
+				continue
+			}
+			if err := msg.Ack(false); err != nil {
+				logger.WithContext(msgCtx, mq.log).Errorf("failed to ack message %s: %v", msg.MessageId, err)
+			}
+		}
+
+		// The delivery channel is closed when the channel or connection dies,
+		// the consumer is cancelled by the broker or stopped by StopQueue.
+		if mq.markStopped(queueName) {
+			logger.WithContext(ctx, mq.log).Warnf("consumer for queue '%s' stopped", queueName)
 		}
 	}()
 
@@ -89,11 +274,14 @@ func (mq *RabbitMQ) CreateQueue(queueName string) error {
 	if err != nil {
 		return fmt.Errorf("failed to create queue '%s': %w", queueName, err)
 	}
+	mq.declared.Store(queueName, struct{}{})
 	return nil
 }
 
 // DeleteQueue deletes a RabbitMQ queue with the given name
 func (mq *RabbitMQ) DeleteQueue(queueName string) error {
+	mq.declared.Delete(queueName)
+
 	_, err := mq.channel.QueueDelete(
 		queueName, // Name of the queue
 		false,     // IfUnused: only delete if the queue has no consumers
@@ -107,7 +295,7 @@ func (mq *RabbitMQ) DeleteQueue(queueName string) error {
 }
 
 // StartQueue creates a queue (if not exists) and sets up a consumer
-func (mq *RabbitMQ) StartQueue(ctx context.Context, queueName string, handler func(string)) error {
+func (mq *RabbitMQ) StartQueue(ctx context.Context, queueName string, handler Handler) error {
 	// Create the queue if it does not exist
 	if err := mq.CreateQueue(queueName); err != nil {
 		return fmt.Errorf("failed to start queue: %w", err)
@@ -120,3 +308,156 @@ func (mq *RabbitMQ) StartQueue(ctx context.Context, queueName string, handler fu
 
 	return nil
 }
+
+// StopQueue cancels the consumer of the given queue, leaving the queue in place
+func (mq *RabbitMQ) StopQueue(queueName string) error {
+	mq.mu.Lock()
+	_, ok := mq.consumers[queueName]
+	delete(mq.consumers, queueName)
+	mq.mu.Unlock()
+
+	if !ok || mq.channel.IsClosed() {
+		return nil
+	}
+	if err := mq.channel.Cancel(queueName, false); err != nil {
+		return fmt.Errorf("failed to stop consumer for queue '%s': %w", queueName, err)
+	}
+	return nil
+}
+
+// IsConsuming reports whether the consumer of the given queue is running
+func (mq *RabbitMQ) IsConsuming(queueName string) bool {
+	mq.mu.RLock()
+	defer mq.mu.RUnlock()
+	return mq.consumers[queueName]
+}
+
+// Ping reports whether the RabbitMQ connection and channel are still open
+func (mq *RabbitMQ) Ping() error {
+	if mq.conn == nil || mq.conn.IsClosed() {
+		return errors.New("rabbitmq connection is closed")
+	}
+	if mq.channel.IsClosed() {
+		return errors.New("rabbitmq channel is closed")
+	}
+	return nil
+}
+
+// CheckConsumers returns an error listing every consumer whose delivery loop has stopped
+func (mq *RabbitMQ) CheckConsumers() error {
+	mq.mu.RLock()
+	defer mq.mu.RUnlock()
+
+	var stopped []string
+	for queueName, running := range mq.consumers {
+		if !running {
+			stopped = append(stopped, queueName)
+		}
+	}
+	if len(stopped) > 0 {
+		sort.Strings(stopped)
+		return fmt.Errorf("consumers stopped: %s", strings.Join(stopped, ", "))
+	}
+	return nil
+}
+
+func (mq *RabbitMQ) setConsumer(queueName string, running bool) {
+	mq.mu.Lock()
+	defer mq.mu.Unlock()
+	mq.consumers[queueName] = running
+}
+
+// markStopped records that the consumer of queueName died, unless it was
+// removed on purpose by StopQueue. It reports whether the consumer was known.
+func (mq *RabbitMQ) markStopped(queueName string) bool {
+	mq.mu.Lock()
+	defer mq.mu.Unlock()
+	if _, ok := mq.consumers[queueName]; !ok {
+		return false
+	}
+	mq.consumers[queueName] = false
+	return true
+}
+
+// declare declares queueName unless this instance already did
+func (mq *RabbitMQ) declare(ch *amqp091.Channel, queueName string) error {
+	if _, ok := mq.declared.Load(queueName); ok {
+		return nil
+	}
+	if _, err := ch.QueueDeclare(queueName, true, false, false, false, nil); err != nil {
+		return fmt.Errorf("failed to declare queue: %w", err)
+	}
+	mq.declared.Store(queueName, struct{}{})
+	return nil
+}
+
+// newPublishing builds the AMQP publishing of a message, stamping the
+// request ID and the trace context so consumers can correlate the message
+// with the request that produced it
+func newPublishing(ctx context.Context, message Message, body []byte) amqp091.Publishing {
+	requestID := logger.RequestID(ctx)
+
+	headers := make(amqp091.Table, len(message.Headers)+2)
+	for key, value := range message.Headers {
+		headers[key] = value
+	}
+	headers[HeaderRequestID] = requestID
+	if span, ok := trace.FromContext(ctx); ok {
+		headers[trace.Header] = span.Traceparent()
+	} else if _, ok := trace.Parse(message.Headers[trace.Header]); !ok {
+		headers[trace.Header] = trace.NewRoot().Traceparent()
+	}
+
+	publishing := amqp091.Publishing{
+		ContentType:   message.ContentType,
+		MessageId:     message.ID,
+		CorrelationId: message.CorrelationID,
+		Priority:      message.Priority,
+		Timestamp:     time.Now().UTC(),
+		Headers:       headers,
+		Body:          body,
+	}
+	if publishing.ContentType == "" {
+		publishing.ContentType = DefaultContentType
+	}
+	if publishing.CorrelationId == "" {
+		publishing.CorrelationId = requestID
+	}
+	if message.Expiration > 0 {
+		// AMQP expirations are in milliseconds
+		publishing.Expiration = strconv.FormatInt(message.Expiration.Milliseconds(), 10)
+	}
+	return publishing
+}
+
+// waitConfirm waits for the broker to ack or nack a published message
+func waitConfirm(ctx context.Context, confirm *amqp091.DeferredConfirmation) error {
+	acked, err := confirm.WaitContext(ctx)
+	if err != nil {
+		return err
+	}
+	if !acked {
+		return errNacked
+	}
+	return nil
+}
+
+// shouldRequeue reports whether a delivery whose handler failed with err may
+// be delivered again
+func shouldRequeue(err error) bool {
+	var panicErr *PanicError
+	return !errors.As(err, &panicErr)
+}
+
+// deliveryContext derives the context passed to a consumer handler, carrying
+// the request ID of the publisher when one was stamped on the message.
+func deliveryContext(ctx context.Context, msg amqp091.Delivery) context.Context {
+	requestID := msg.CorrelationId
+	if id, ok := msg.Headers[HeaderRequestID].(string); ok && id != "" {
+		requestID = id
+	}
+	if requestID == "" {
+		return ctx
+	}
+	return logger.WithRequestID(ctx, requestID)
+}
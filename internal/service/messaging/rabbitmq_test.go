@@ -0,0 +1,47 @@
+package messaging
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"tenant/pkg/logger"
+	"tenant/pkg/trace"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewPublishing(t *testing.T) {
+	ctx := logger.WithRequestID(context.Background(), "req-1")
+
+	t.Run("Defaults", func(t *testing.T) {
+		publishing := newPublishing(ctx, Message{ID: "msg-1"}, []byte(`"a"`))
+
+		assert.Equal(t, "msg-1", publishing.MessageId)
+		assert.Equal(t, DefaultContentType, publishing.ContentType)
+		assert.Equal(t, "req-1", publishing.CorrelationId)
+		assert.Equal(t, "req-1", publishing.Headers[HeaderRequestID])
+		assert.Empty(t, publishing.Expiration)
+		assert.WithinDuration(t, time.Now(), publishing.Timestamp, time.Minute)
+		_, ok := trace.Parse(publishing.Headers[trace.Header].(string))
+		assert.True(t, ok)
+	})
+
+	t.Run("Properties", func(t *testing.T) {
+		publishing := newPublishing(ctx, Message{
+			ID:            "msg-1",
+			Headers:       map[string]string{"x-source": "test", HeaderRequestID: "spoofed"},
+			CorrelationID: "order-42",
+			ContentType:   "application/json",
+			Priority:      5,
+			Expiration:    30 * time.Second,
+		}, []byte(`"a"`))
+
+		assert.Equal(t, "application/json", publishing.ContentType)
+		assert.Equal(t, "order-42", publishing.CorrelationId)
+		assert.Equal(t, uint8(5), publishing.Priority)
+		assert.Equal(t, "30000", publishing.Expiration)
+		assert.Equal(t, "test", publishing.Headers["x-source"])
+		assert.Equal(t, "req-1", publishing.Headers[HeaderRequestID])
+	})
+}
@@ -0,0 +1,139 @@
+package notification
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"tenant/internal/model"
+	"tenant/internal/repository"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	minReconnectDelay = time.Second
+	maxReconnectDelay = 30 * time.Second
+)
+
+// TenantEventHandler is called for every tenant event. Handlers run on the
+// listener goroutine, so they must not block.
+type TenantEventHandler func(ctx context.Context, event model.TenantEvent)
+
+// TenantListener receives the tenant events that tenantRepository NOTIFYs
+// and dispatches them to the subscribed handlers. While the listen
+// connection is down, subscribers rely on their own periodic polling; every
+// (re)connect dispatches a model.TenantEventReset so they can catch up on the
+// events they missed.
+type TenantListener struct {
+	db  *pgxpool.Pool
+	log *logrus.Logger
+
+	mu       sync.RWMutex
+	handlers []TenantEventHandler
+
+	listening atomic.Bool
+}
+
+// NewTenantListener initializes a new tenant listener
+func NewTenantListener(db *pgxpool.Pool, log *logrus.Logger) *TenantListener {
+	return &TenantListener{db: db, log: log}
+}
+
+// Subscribe registers a handler for every tenant event received afterwards
+func (l *TenantListener) Subscribe(handler TenantEventHandler) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.handlers = append(l.handlers, handler)
+}
+
+// Listening reports whether the listen connection is up
+func (l *TenantListener) Listening() bool {
+	return l.listening.Load()
+}
+
+// Run listens for tenant events until ctx is cancelled, reconnecting with
+// exponential backoff when the connection drops.
+func (l *TenantListener) Run(ctx context.Context) error {
+	delay := minReconnectDelay
+	for {
+		err := l.listen(ctx, func() { delay = minReconnectDelay })
+		l.listening.Store(false)
+		if ctx.Err() != nil {
+			return nil
+		}
+		l.log.Warnf("tenant listener disconnected, falling back to polling until it reconnects in %s: %v", delay, err)
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(delay):
+		}
+		delay = min(delay*2, maxReconnectDelay)
+	}
+}
+
+// listen holds a dedicated connection subscribed to the tenant channel and
+// dispatches notifications until it fails.
+func (l *TenantListener) listen(ctx context.Context, connected func()) error {
+	poolConn, err := l.db.Acquire(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to acquire listen connection: %w", err)
+	}
+	// A connection that is still LISTENing must not go back to the pool
+	conn := poolConn.Hijack()
+	defer conn.Close(context.Background())
+
+	if _, err := conn.Exec(ctx, "LISTEN "+pgx.Identifier{repository.TenantEventChannel}.Sanitize()); err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", repository.TenantEventChannel, err)
+	}
+
+	l.listening.Store(true)
+	connected()
+	l.log.Infof("listening for tenant events on %s", repository.TenantEventChannel)
+	l.dispatch(ctx, model.TenantEvent{Type: model.TenantEventReset})
+
+	for {
+		notification, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			return err
+		}
+
+		event, err := parseTenantEvent(notification.Payload)
+		if err != nil {
+			l.log.Errorf("ignoring malformed tenant event %q: %v", notification.Payload, err)
+			continue
+		}
+		l.log.Debugf("received tenant event %s for %s", event.Type, event.ClientID)
+		l.dispatch(ctx, event)
+	}
+}
+
+func (l *TenantListener) dispatch(ctx context.Context, event model.TenantEvent) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	for _, handler := range l.handlers {
+		handler(ctx, event)
+	}
+}
+
+func parseTenantEvent(payload string) (model.TenantEvent, error) {
+	var event model.TenantEvent
+	if err := json.Unmarshal([]byte(payload), &event); err != nil {
+		return event, err
+	}
+	switch event.Type {
+	case model.TenantEventCreated, model.TenantEventDeleted:
+		if event.ClientID == "" {
+			return event, fmt.Errorf("%s event without client_id", event.Type)
+		}
+		return event, nil
+	default:
+		return event, fmt.Errorf("unknown event type %q", event.Type)
+	}
+}
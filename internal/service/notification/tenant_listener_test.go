@@ -0,0 +1,55 @@
+package notification
+
+import (
+	"tenant/internal/model"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseTenantEvent(t *testing.T) {
+	var testCases = []struct {
+		caseName string
+		payload  string
+		event    model.TenantEvent
+		wantErr  bool
+	}{
+		{
+			caseName: "Created",
+			payload:  `{"type":"created","client_id":"test-client"}`,
+			event:    model.TenantEvent{Type: model.TenantEventCreated, ClientID: "test-client"},
+		},
+		{
+			caseName: "Deleted",
+			payload:  `{"type":"deleted","client_id":"test-client"}`,
+			event:    model.TenantEvent{Type: model.TenantEventDeleted, ClientID: "test-client"},
+		},
+		{
+			caseName: "MissingClientID",
+			payload:  `{"type":"deleted"}`,
+			wantErr:  true,
+		},
+		{
+			caseName: "ResetIsLocalOnly",
+			payload:  `{"type":"reset"}`,
+			wantErr:  true,
+		},
+		{
+			caseName: "Malformed",
+			payload:  `not json`,
+			wantErr:  true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.caseName, func(t *testing.T) {
+			event, err := parseTenantEvent(tc.payload)
+			if tc.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.event, event)
+		})
+	}
+}
@@ -0,0 +1,226 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/netip"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+// Headers of a webhook request. The signature is the hex encoded HMAC-SHA256
+// of "<timestamp>.<body>" keyed with the secret of the webhook, prefixed with
+// "sha256=".
+const (
+	HeaderMessageID     = "X-Webhook-Id"
+	HeaderTimestamp     = "X-Webhook-Timestamp"
+	HeaderSignature     = "X-Webhook-Signature"
+	HeaderAttempt       = "X-Webhook-Attempt"
+	HeaderCorrelationID = "X-Correlation-Id"
+)
+
+// maxErrorBody bounds how much of an error response is kept in its error
+const maxErrorBody = 512
+
+// Request is a message delivered to a webhook
+type Request struct {
+	URL    string
+	Secret string
+
+	MessageID     string
+	CorrelationID string
+	ContentType   string
+	Attempt       int
+
+	// Headers are sent along with the webhook headers, which they cannot
+	// override
+	Headers map[string]string
+	Body    []byte
+}
+
+// Response is the outcome of a request that reached the webhook
+type Response struct {
+	StatusCode int
+	Duration   time.Duration
+}
+
+type Sender interface {
+	Send(ctx context.Context, req *Request) (*Response, error)
+}
+
+// ErrBlockedAddress is returned for a webhook resolving to an address of the
+// private network of the service
+var ErrBlockedAddress = errors.New("webhook address is not allowed")
+
+// blockedPrefixes are the ranges IsBlockedIP refuses on top of the loopback,
+// private, link-local, multicast and unspecified addresses
+var blockedPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"), // Carrier-grade NAT
+	netip.MustParsePrefix("192.0.0.0/24"),
+	netip.MustParsePrefix("198.18.0.0/15"),
+	netip.MustParsePrefix("240.0.0.0/4"),
+}
+
+// Config tunes the HTTP client of webhooks
+type Config struct {
+	// Timeout bounds every request, including reading the response
+	Timeout time.Duration
+
+	// AllowPrivateNetworks lets webhooks reach loopback, private and
+	// link-local addresses, for local development only
+	AllowPrivateNetworks bool
+}
+
+type httpSender struct {
+	client *http.Client
+}
+
+// NewSender initializes a sender posting to webhooks over HTTP. Redirects
+// are not followed, and unless AllowPrivateNetworks is set the client
+// refuses to connect to an address IsBlockedIP reports, whatever the
+// hostname resolved to.
+func NewSender(cfg Config) Sender {
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 10 * time.Second
+	}
+
+	dialer := &net.Dialer{Timeout: cfg.Timeout, KeepAlive: 30 * time.Second}
+	if !cfg.AllowPrivateNetworks {
+		dialer.Control = controlAddress
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+
+	return &httpSender{client: &http.Client{
+		Timeout:   cfg.Timeout,
+		Transport: transport,
+		// A redirect is a response like any other, reported as a StatusError
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}}
+}
+
+// IsBlockedIP reports whether webhooks may not be sent to ip, as it belongs to
+// a loopback, private, link-local or otherwise non public range
+func IsBlockedIP(ip netip.Addr) bool {
+	ip = ip.Unmap()
+	if !ip.IsValid() || ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() || ip.IsMulticast() {
+		return true
+	}
+	for _, prefix := range blockedPrefixes {
+		if prefix.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// controlAddress refuses connections to blocked addresses. It runs once the
+// hostname is resolved, right before connecting, so DNS cannot hand out
+// another address than the one checked.
+func controlAddress(network, address string, _ syscall.RawConn) error {
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrBlockedAddress, address)
+	}
+	if IsBlockedIP(addrPort.Addr()) {
+		return fmt.Errorf("%w: %s", ErrBlockedAddress, addrPort.Addr())
+	}
+	return nil
+}
+
+// Send posts a signed message to a webhook. A response other than 2xx is
+// returned along with a *StatusError.
+func (s *httpSender) Send(ctx context.Context, req *Request) (*Response, error) {
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, req.URL, bytes.NewReader(req.Body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to build webhook request: %w", err)
+	}
+
+	for key, value := range req.Headers {
+		httpReq.Header.Set(key, value)
+	}
+	contentType := req.ContentType
+	if contentType == "" {
+		contentType = "application/json"
+	}
+	timestamp := time.Now().Unix()
+	httpReq.Header.Set("Content-Type", contentType)
+	httpReq.Header.Set(HeaderMessageID, req.MessageID)
+	httpReq.Header.Set(HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	httpReq.Header.Set(HeaderSignature, Sign(req.Secret, timestamp, req.Body))
+	httpReq.Header.Set(HeaderAttempt, strconv.Itoa(req.Attempt))
+	if req.CorrelationID != "" {
+		httpReq.Header.Set(HeaderCorrelationID, req.CorrelationID)
+	}
+
+	start := time.Now()
+	httpResp, err := s.client.Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+	defer httpResp.Body.Close()
+
+	body, _ := io.ReadAll(io.LimitReader(httpResp.Body, maxErrorBody))
+	// Drain the rest so the connection can be reused
+	io.Copy(io.Discard, httpResp.Body)
+
+	resp := &Response{StatusCode: httpResp.StatusCode, Duration: time.Since(start)}
+	if httpResp.StatusCode < 200 || httpResp.StatusCode > 299 {
+		return resp, &StatusError{StatusCode: httpResp.StatusCode, Body: string(body)}
+	}
+	return resp, nil
+}
+
+// Sign returns the signature of a body sent at timestamp
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte{'.'})
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// StatusError is returned for a response other than 2xx
+type StatusError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *StatusError) Error() string {
+	if e.Body == "" {
+		return fmt.Sprintf("webhook responded %d", e.StatusCode)
+	}
+	return fmt.Sprintf("webhook responded %d: %s", e.StatusCode, e.Body)
+}
+
+// Retryable reports whether a failed request may succeed when sent again.
+// Client errors and blocked addresses are final, except for timeouts and
+// rate limiting.
+func Retryable(err error) bool {
+	if errors.Is(err, ErrBlockedAddress) {
+		return false
+	}
+	var statusErr *StatusError
+	if !errors.As(err, &statusErr) {
+		return true
+	}
+	switch statusErr.StatusCode {
+	case http.StatusRequestTimeout, http.StatusTooManyRequests:
+		return true
+	}
+	return statusErr.StatusCode >= 500
+}
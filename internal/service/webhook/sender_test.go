@@ -0,0 +1,165 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSend(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Signed", func(t *testing.T) {
+		var received *http.Request
+		var body []byte
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			received = r
+			body, _ = io.ReadAll(r.Body)
+			w.WriteHeader(http.StatusNoContent)
+		}))
+		defer server.Close()
+
+		resp, err := NewSender(Config{AllowPrivateNetworks: true}).Send(ctx, &Request{
+			URL:           server.URL,
+			Secret:        "whsec_test",
+			MessageID:     "msg-1",
+			CorrelationID: "order-42",
+			Attempt:       2,
+			Headers:       map[string]string{"X-Source": "billing", HeaderSignature: "spoofed"},
+			Body:          []byte(`{"id":1}`),
+		})
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+
+		assert.Equal(t, http.MethodPost, received.Method)
+		assert.JSONEq(t, `{"id":1}`, string(body))
+		assert.Equal(t, "application/json", received.Header.Get("Content-Type"))
+		assert.Equal(t, "msg-1", received.Header.Get(HeaderMessageID))
+		assert.Equal(t, "order-42", received.Header.Get(HeaderCorrelationID))
+		assert.Equal(t, "2", received.Header.Get(HeaderAttempt))
+		assert.Equal(t, "billing", received.Header.Get("X-Source"))
+
+		// The receiver recomputes the signature from the timestamp and the body
+		timestamp, err := strconv.ParseInt(received.Header.Get(HeaderTimestamp), 10, 64)
+		assert.Nil(t, err)
+		assert.WithinDuration(t, time.Now(), time.Unix(timestamp, 0), time.Minute)
+		assert.Equal(t, Sign("whsec_test", timestamp, body), received.Header.Get(HeaderSignature))
+	})
+
+	t.Run("ErrorStatus", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, "unknown event", http.StatusUnprocessableEntity)
+		}))
+		defer server.Close()
+
+		resp, err := NewSender(Config{AllowPrivateNetworks: true}).Send(ctx, &Request{URL: server.URL, Body: []byte(`{}`)})
+		assert.Equal(t, http.StatusUnprocessableEntity, resp.StatusCode)
+
+		var statusErr *StatusError
+		assert.True(t, errors.As(err, &statusErr))
+		assert.Contains(t, statusErr.Error(), "unknown event")
+		assert.False(t, Retryable(err))
+	})
+
+	t.Run("Timeout", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			time.Sleep(200 * time.Millisecond)
+		}))
+		defer server.Close()
+
+		resp, err := NewSender(Config{Timeout: 20 * time.Millisecond, AllowPrivateNetworks: true}).Send(ctx, &Request{URL: server.URL})
+		assert.Nil(t, resp)
+		assert.NotNil(t, err)
+		assert.True(t, Retryable(err))
+	})
+}
+
+func TestSendBlocked(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("PrivateAddress", func(t *testing.T) {
+		called := false
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			called = true
+		}))
+		defer server.Close()
+
+		resp, err := NewSender(Config{}).Send(ctx, &Request{URL: server.URL, Body: []byte(`{}`)})
+		assert.Nil(t, resp)
+		assert.ErrorIs(t, err, ErrBlockedAddress)
+		assert.False(t, Retryable(err))
+		assert.False(t, called)
+	})
+
+	t.Run("RedirectNotFollowed", func(t *testing.T) {
+		called := false
+		target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			called = true
+		}))
+		defer target.Close()
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.Redirect(w, r, target.URL, http.StatusTemporaryRedirect)
+		}))
+		defer server.Close()
+
+		resp, err := NewSender(Config{AllowPrivateNetworks: true}).Send(ctx, &Request{URL: server.URL, Body: []byte(`{}`)})
+		assert.Equal(t, http.StatusTemporaryRedirect, resp.StatusCode)
+		var statusErr *StatusError
+		assert.True(t, errors.As(err, &statusErr))
+		assert.False(t, Retryable(err))
+		assert.False(t, called)
+	})
+}
+
+func TestIsBlockedIP(t *testing.T) {
+	var testCases = []struct {
+		ip      string
+		blocked bool
+	}{
+		{ip: "127.0.0.1", blocked: true},
+		{ip: "::1", blocked: true},
+		{ip: "10.1.2.3", blocked: true},
+		{ip: "172.16.0.1", blocked: true},
+		{ip: "192.168.1.1", blocked: true},
+		{ip: "169.254.169.254", blocked: true},
+		{ip: "::ffff:169.254.169.254", blocked: true},
+		{ip: "fd00::1", blocked: true},
+		{ip: "fe80::1", blocked: true},
+		{ip: "0.0.0.0", blocked: true},
+		{ip: "100.64.0.1", blocked: true},
+		{ip: "224.0.0.1", blocked: true},
+		{ip: "93.184.216.34"},
+		{ip: "2606:4700::1111"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.ip, func(t *testing.T) {
+			assert.Equal(t, tc.blocked, IsBlockedIP(netip.MustParseAddr(tc.ip)))
+		})
+	}
+}
+
+func TestSign(t *testing.T) {
+	signature := Sign("secret", 1760875200, []byte(`{"id":1}`))
+	assert.Equal(t, signature, Sign("secret", 1760875200, []byte(`{"id":1}`)))
+	assert.NotEqual(t, signature, Sign("secret", 1760875201, []byte(`{"id":1}`)))
+	assert.NotEqual(t, signature, Sign("other", 1760875200, []byte(`{"id":1}`)))
+	assert.Len(t, signature, len("sha256=")+64)
+}
+
+func TestRetryable(t *testing.T) {
+	assert.True(t, Retryable(errors.New("connection refused")))
+	assert.True(t, Retryable(&StatusError{StatusCode: http.StatusServiceUnavailable}))
+	assert.True(t, Retryable(&StatusError{StatusCode: http.StatusTooManyRequests}))
+	assert.True(t, Retryable(&StatusError{StatusCode: http.StatusRequestTimeout}))
+	assert.False(t, Retryable(&StatusError{StatusCode: http.StatusBadRequest}))
+	assert.False(t, Retryable(&StatusError{StatusCode: http.StatusGone}))
+}
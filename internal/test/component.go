@@ -10,13 +10,31 @@ import (
 type MockComponent struct {
 	Config           *config.Config
 	TenantRepository *mockrepository.TenantRepository
+	LeaseRepository  *mockrepository.LeaseRepository
 	TenantUsecase    *mockusecase.TenantUsecase
+	ConsumerUsecase  *mockusecase.ConsumerUsecase
+	MessageUsecase   *mockusecase.MessageUsecase
+	ArchiveUsecase   *mockusecase.ArchiveUsecase
+	ReplayUsecase    *mockusecase.ReplayUsecase
+	ScheduledUsecase *mockusecase.ScheduledUsecase
+	CronUsecase      *mockusecase.CronUsecase
+	WebhookUsecase   *mockusecase.WebhookUsecase
+	ProcessorUsecase *mockusecase.ProcessorUsecase
 }
 
 func InitMockComponent(t *testing.T) *MockComponent {
 	return &MockComponent{
 		Config:           &config.Config{},
 		TenantRepository: mockrepository.NewTenantRepository(t),
+		LeaseRepository:  mockrepository.NewLeaseRepository(t),
 		TenantUsecase:    mockusecase.NewTenantUsecase(t),
+		ConsumerUsecase:  mockusecase.NewConsumerUsecase(t),
+		MessageUsecase:   mockusecase.NewMessageUsecase(t),
+		ArchiveUsecase:   mockusecase.NewArchiveUsecase(t),
+		ReplayUsecase:    mockusecase.NewReplayUsecase(t),
+		ScheduledUsecase: mockusecase.NewScheduledUsecase(t),
+		CronUsecase:      mockusecase.NewCronUsecase(t),
+		WebhookUsecase:   mockusecase.NewWebhookUsecase(t),
+		ProcessorUsecase: mockusecase.NewProcessorUsecase(t),
 	}
 }
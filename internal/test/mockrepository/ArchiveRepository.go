@@ -0,0 +1,210 @@
+// Code generated by mockery v2.46.0. DO NOT EDIT.
+
+package mockrepository
+
+import (
+	context "context"
+	model "tenant/internal/model"
+
+	mock "github.com/stretchr/testify/mock"
+
+	time "time"
+)
+
+// ArchiveRepository is an autogenerated mock type for the ArchiveRepository type
+type ArchiveRepository struct {
+	mock.Mock
+}
+
+// Archive provides a mock function with given fields: ctx, messages
+func (_m *ArchiveRepository) Archive(ctx context.Context, messages ...*model.ArchivedMessage) error {
+	_va := make([]interface{}, len(messages))
+	for _i := range messages {
+		_va[_i] = messages[_i]
+	}
+	var _ca []interface{}
+	_ca = append(_ca, ctx)
+	_ca = append(_ca, _va...)
+	ret := _m.Called(_ca...)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Archive")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, ...*model.ArchivedMessage) error); ok {
+		r0 = rf(ctx, messages...)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Count provides a mock function with given fields: ctx, query
+func (_m *ArchiveRepository) Count(ctx context.Context, query *model.ArchiveQuery) (int64, error) {
+	ret := _m.Called(ctx, query)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Count")
+	}
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *model.ArchiveQuery) (int64, error)); ok {
+		return rf(ctx, query)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *model.ArchiveQuery) int64); ok {
+		r0 = rf(ctx, query)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *model.ArchiveQuery) error); ok {
+		r1 = rf(ctx, query)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// DeleteRetention provides a mock function with given fields: ctx, clientID
+func (_m *ArchiveRepository) DeleteRetention(ctx context.Context, clientID string) error {
+	ret := _m.Called(ctx, clientID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteRetention")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, clientID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetRetention provides a mock function with given fields: ctx, clientID
+func (_m *ArchiveRepository) GetRetention(ctx context.Context, clientID string) (*model.RetentionPolicy, error) {
+	ret := _m.Called(ctx, clientID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetRetention")
+	}
+
+	var r0 *model.RetentionPolicy
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*model.RetentionPolicy, error)); ok {
+		return rf(ctx, clientID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *model.RetentionPolicy); ok {
+		r0 = rf(ctx, clientID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.RetentionPolicy)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, clientID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Prune provides a mock function with given fields: ctx, now, defaultRetention, partitionsAhead
+func (_m *ArchiveRepository) Prune(ctx context.Context, now time.Time, defaultRetention time.Duration, partitionsAhead int) (*model.PruneResult, error) {
+	ret := _m.Called(ctx, now, defaultRetention, partitionsAhead)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Prune")
+	}
+
+	var r0 *model.PruneResult
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time, time.Duration, int) (*model.PruneResult, error)); ok {
+		return rf(ctx, now, defaultRetention, partitionsAhead)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time, time.Duration, int) *model.PruneResult); ok {
+		r0 = rf(ctx, now, defaultRetention, partitionsAhead)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.PruneResult)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, time.Time, time.Duration, int) error); ok {
+		r1 = rf(ctx, now, defaultRetention, partitionsAhead)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Search provides a mock function with given fields: ctx, query
+func (_m *ArchiveRepository) Search(ctx context.Context, query *model.ArchiveQuery) ([]*model.ArchivedMessage, error) {
+	ret := _m.Called(ctx, query)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Search")
+	}
+
+	var r0 []*model.ArchivedMessage
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *model.ArchiveQuery) ([]*model.ArchivedMessage, error)); ok {
+		return rf(ctx, query)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *model.ArchiveQuery) []*model.ArchivedMessage); ok {
+		r0 = rf(ctx, query)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*model.ArchivedMessage)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *model.ArchiveQuery) error); ok {
+		r1 = rf(ctx, query)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SetRetention provides a mock function with given fields: ctx, policy
+func (_m *ArchiveRepository) SetRetention(ctx context.Context, policy *model.RetentionPolicy) error {
+	ret := _m.Called(ctx, policy)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetRetention")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *model.RetentionPolicy) error); ok {
+		r0 = rf(ctx, policy)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewArchiveRepository creates a new instance of ArchiveRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewArchiveRepository(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *ArchiveRepository {
+	mock := &ArchiveRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,193 @@
+// Code generated by mockery v2.46.0. DO NOT EDIT.
+
+package mockrepository
+
+import (
+	context "context"
+	model "tenant/internal/model"
+
+	mock "github.com/stretchr/testify/mock"
+
+	time "time"
+)
+
+// CronRepository is an autogenerated mock type for the CronRepository type
+type CronRepository struct {
+	mock.Mock
+}
+
+// CreateSchedule provides a mock function with given fields: ctx, schedule
+func (_m *CronRepository) CreateSchedule(ctx context.Context, schedule *model.CronSchedule) error {
+	ret := _m.Called(ctx, schedule)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateSchedule")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *model.CronSchedule) error); ok {
+		r0 = rf(ctx, schedule)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DeleteSchedule provides a mock function with given fields: ctx, clientID, scheduleID
+func (_m *CronRepository) DeleteSchedule(ctx context.Context, clientID string, scheduleID string) error {
+	ret := _m.Called(ctx, clientID, scheduleID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteSchedule")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) error); ok {
+		r0 = rf(ctx, clientID, scheduleID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetSchedule provides a mock function with given fields: ctx, clientID, scheduleID
+func (_m *CronRepository) GetSchedule(ctx context.Context, clientID string, scheduleID string) (*model.CronSchedule, error) {
+	ret := _m.Called(ctx, clientID, scheduleID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetSchedule")
+	}
+
+	var r0 *model.CronSchedule
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) (*model.CronSchedule, error)); ok {
+		return rf(ctx, clientID, scheduleID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) *model.CronSchedule); ok {
+		r0 = rf(ctx, clientID, scheduleID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.CronSchedule)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, clientID, scheduleID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListDue provides a mock function with given fields: ctx, now, limit
+func (_m *CronRepository) ListDue(ctx context.Context, now time.Time, limit int) ([]*model.CronSchedule, error) {
+	ret := _m.Called(ctx, now, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListDue")
+	}
+
+	var r0 []*model.CronSchedule
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time, int) ([]*model.CronSchedule, error)); ok {
+		return rf(ctx, now, limit)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time, int) []*model.CronSchedule); ok {
+		r0 = rf(ctx, now, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*model.CronSchedule)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, time.Time, int) error); ok {
+		r1 = rf(ctx, now, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListSchedules provides a mock function with given fields: ctx, clientID
+func (_m *CronRepository) ListSchedules(ctx context.Context, clientID string) ([]*model.CronSchedule, error) {
+	ret := _m.Called(ctx, clientID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListSchedules")
+	}
+
+	var r0 []*model.CronSchedule
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) ([]*model.CronSchedule, error)); ok {
+		return rf(ctx, clientID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) []*model.CronSchedule); ok {
+		r0 = rf(ctx, clientID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*model.CronSchedule)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, clientID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RecordRun provides a mock function with given fields: ctx, schedule, dueAt
+func (_m *CronRepository) RecordRun(ctx context.Context, schedule *model.CronSchedule, dueAt time.Time) error {
+	ret := _m.Called(ctx, schedule, dueAt)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RecordRun")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *model.CronSchedule, time.Time) error); ok {
+		r0 = rf(ctx, schedule, dueAt)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UpdateSchedule provides a mock function with given fields: ctx, schedule
+func (_m *CronRepository) UpdateSchedule(ctx context.Context, schedule *model.CronSchedule) error {
+	ret := _m.Called(ctx, schedule)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateSchedule")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *model.CronSchedule) error); ok {
+		r0 = rf(ctx, schedule)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewCronRepository creates a new instance of CronRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewCronRepository(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *CronRepository {
+	mock := &CronRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
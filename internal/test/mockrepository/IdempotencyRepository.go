@@ -0,0 +1,125 @@
+// Code generated by mockery v2.46.0. DO NOT EDIT.
+
+package mockrepository
+
+import (
+	context "context"
+	model "tenant/internal/model"
+
+	mock "github.com/stretchr/testify/mock"
+
+	time "time"
+)
+
+// IdempotencyRepository is an autogenerated mock type for the IdempotencyRepository type
+type IdempotencyRepository struct {
+	mock.Mock
+}
+
+// Complete provides a mock function with given fields: ctx, clientID, key, messageID, ttl
+func (_m *IdempotencyRepository) Complete(ctx context.Context, clientID string, key string, messageID string, ttl time.Duration) error {
+	ret := _m.Called(ctx, clientID, key, messageID, ttl)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Complete")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string, time.Duration) error); ok {
+		r0 = rf(ctx, clientID, key, messageID, ttl)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DeleteExpired provides a mock function with given fields: ctx
+func (_m *IdempotencyRepository) DeleteExpired(ctx context.Context) (int64, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteExpired")
+	}
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) (int64, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) int64); ok {
+		r0 = rf(ctx)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Release provides a mock function with given fields: ctx, clientID, key
+func (_m *IdempotencyRepository) Release(ctx context.Context, clientID string, key string) error {
+	ret := _m.Called(ctx, clientID, key)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Release")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) error); ok {
+		r0 = rf(ctx, clientID, key)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Reserve provides a mock function with given fields: ctx, record, timeout
+func (_m *IdempotencyRepository) Reserve(ctx context.Context, record *model.IdempotencyRecord, timeout time.Duration) (*model.IdempotencyRecord, error) {
+	ret := _m.Called(ctx, record, timeout)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Reserve")
+	}
+
+	var r0 *model.IdempotencyRecord
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *model.IdempotencyRecord, time.Duration) (*model.IdempotencyRecord, error)); ok {
+		return rf(ctx, record, timeout)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *model.IdempotencyRecord, time.Duration) *model.IdempotencyRecord); ok {
+		r0 = rf(ctx, record, timeout)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.IdempotencyRecord)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *model.IdempotencyRecord, time.Duration) error); ok {
+		r1 = rf(ctx, record, timeout)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewIdempotencyRepository creates a new instance of IdempotencyRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewIdempotencyRepository(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *IdempotencyRepository {
+	mock := &IdempotencyRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,219 @@
+// Code generated by mockery v2.46.0. DO NOT EDIT.
+
+package mockrepository
+
+import (
+	context "context"
+	model "tenant/internal/model"
+
+	mock "github.com/stretchr/testify/mock"
+
+	time "time"
+)
+
+// LeaseRepository is an autogenerated mock type for the LeaseRepository type
+type LeaseRepository struct {
+	mock.Mock
+}
+
+// AcquireLeadership provides a mock function with given fields: ctx, name, workerID, ttl
+func (_m *LeaseRepository) AcquireLeadership(ctx context.Context, name string, workerID string, ttl time.Duration) (bool, error) {
+	ret := _m.Called(ctx, name, workerID, ttl)
+
+	if len(ret) == 0 {
+		panic("no return value specified for AcquireLeadership")
+	}
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, time.Duration) (bool, error)); ok {
+		return rf(ctx, name, workerID, ttl)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, time.Duration) bool); ok {
+		r0 = rf(ctx, name, workerID, ttl)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, time.Duration) error); ok {
+		r1 = rf(ctx, name, workerID, ttl)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// AcquireLease provides a mock function with given fields: ctx, clientID, workerID, ttl, maxHolders
+func (_m *LeaseRepository) AcquireLease(ctx context.Context, clientID string, workerID string, ttl time.Duration, maxHolders int) (bool, error) {
+	ret := _m.Called(ctx, clientID, workerID, ttl, maxHolders)
+
+	if len(ret) == 0 {
+		panic("no return value specified for AcquireLease")
+	}
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, time.Duration, int) (bool, error)); ok {
+		return rf(ctx, clientID, workerID, ttl, maxHolders)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, time.Duration, int) bool); ok {
+		r0 = rf(ctx, clientID, workerID, ttl, maxHolders)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, time.Duration, int) error); ok {
+		r1 = rf(ctx, clientID, workerID, ttl, maxHolders)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Heartbeat provides a mock function with given fields: ctx, worker, ttl
+func (_m *LeaseRepository) Heartbeat(ctx context.Context, worker *model.Worker, ttl time.Duration) error {
+	ret := _m.Called(ctx, worker, ttl)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Heartbeat")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *model.Worker, time.Duration) error); ok {
+		r0 = rf(ctx, worker, ttl)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// ListLeases provides a mock function with given fields: ctx
+func (_m *LeaseRepository) ListLeases(ctx context.Context) ([]*model.TenantLease, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListLeases")
+	}
+
+	var r0 []*model.TenantLease
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) ([]*model.TenantLease, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) []*model.TenantLease); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*model.TenantLease)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListWorkers provides a mock function with given fields: ctx
+func (_m *LeaseRepository) ListWorkers(ctx context.Context) ([]*model.Worker, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListWorkers")
+	}
+
+	var r0 []*model.Worker
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) ([]*model.Worker, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) []*model.Worker); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*model.Worker)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ReleaseLeadership provides a mock function with given fields: ctx, name, workerID
+func (_m *LeaseRepository) ReleaseLeadership(ctx context.Context, name string, workerID string) error {
+	ret := _m.Called(ctx, name, workerID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ReleaseLeadership")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) error); ok {
+		r0 = rf(ctx, name, workerID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// ReleaseLease provides a mock function with given fields: ctx, clientID, workerID
+func (_m *LeaseRepository) ReleaseLease(ctx context.Context, clientID string, workerID string) error {
+	ret := _m.Called(ctx, clientID, workerID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ReleaseLease")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) error); ok {
+		r0 = rf(ctx, clientID, workerID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// ReleaseWorker provides a mock function with given fields: ctx, workerID
+func (_m *LeaseRepository) ReleaseWorker(ctx context.Context, workerID string) error {
+	ret := _m.Called(ctx, workerID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ReleaseWorker")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, workerID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewLeaseRepository creates a new instance of LeaseRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewLeaseRepository(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *LeaseRepository {
+	mock := &LeaseRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
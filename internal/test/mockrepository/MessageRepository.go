@@ -0,0 +1,109 @@
+// Code generated by mockery v2.46.0. DO NOT EDIT.
+
+package mockrepository
+
+import (
+	context "context"
+	model "tenant/internal/model"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// MessageRepository is an autogenerated mock type for the MessageRepository type
+type MessageRepository struct {
+	mock.Mock
+}
+
+// CreateMessages provides a mock function with given fields: ctx, messages
+func (_m *MessageRepository) CreateMessages(ctx context.Context, messages ...*model.Message) error {
+	_va := make([]interface{}, len(messages))
+	for _i := range messages {
+		_va[_i] = messages[_i]
+	}
+	var _ca []interface{}
+	_ca = append(_ca, ctx)
+	_ca = append(_ca, _va...)
+	ret := _m.Called(_ca...)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateMessages")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, ...*model.Message) error); ok {
+		r0 = rf(ctx, messages...)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetMessage provides a mock function with given fields: ctx, clientID, messageID
+func (_m *MessageRepository) GetMessage(ctx context.Context, clientID string, messageID string) (*model.Message, error) {
+	ret := _m.Called(ctx, clientID, messageID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetMessage")
+	}
+
+	var r0 *model.Message
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) (*model.Message, error)); ok {
+		return rf(ctx, clientID, messageID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) *model.Message); ok {
+		r0 = rf(ctx, clientID, messageID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.Message)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, clientID, messageID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RecordEvents provides a mock function with given fields: ctx, events
+func (_m *MessageRepository) RecordEvents(ctx context.Context, events ...*model.MessageEvent) error {
+	_va := make([]interface{}, len(events))
+	for _i := range events {
+		_va[_i] = events[_i]
+	}
+	var _ca []interface{}
+	_ca = append(_ca, ctx)
+	_ca = append(_ca, _va...)
+	ret := _m.Called(_ca...)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RecordEvents")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, ...*model.MessageEvent) error); ok {
+		r0 = rf(ctx, events...)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewMessageRepository creates a new instance of MessageRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMessageRepository(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MessageRepository {
+	mock := &MessageRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
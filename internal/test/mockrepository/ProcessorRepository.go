@@ -0,0 +1,113 @@
+// Code generated by mockery v2.46.0. DO NOT EDIT.
+
+package mockrepository
+
+import (
+	context "context"
+	model "tenant/internal/model"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// ProcessorRepository is an autogenerated mock type for the ProcessorRepository type
+type ProcessorRepository struct {
+	mock.Mock
+}
+
+// DeleteProcessors provides a mock function with given fields: ctx, clientID
+func (_m *ProcessorRepository) DeleteProcessors(ctx context.Context, clientID string) error {
+	ret := _m.Called(ctx, clientID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteProcessors")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, clientID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetProcessors provides a mock function with given fields: ctx, clientID
+func (_m *ProcessorRepository) GetProcessors(ctx context.Context, clientID string) (*model.ProcessorChain, error) {
+	ret := _m.Called(ctx, clientID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetProcessors")
+	}
+
+	var r0 *model.ProcessorChain
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*model.ProcessorChain, error)); ok {
+		return rf(ctx, clientID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *model.ProcessorChain); ok {
+		r0 = rf(ctx, clientID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.ProcessorChain)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, clientID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SaveSinkMessage provides a mock function with given fields: ctx, message
+func (_m *ProcessorRepository) SaveSinkMessage(ctx context.Context, message *model.SinkMessage) error {
+	ret := _m.Called(ctx, message)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SaveSinkMessage")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *model.SinkMessage) error); ok {
+		r0 = rf(ctx, message)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SetProcessors provides a mock function with given fields: ctx, chain
+func (_m *ProcessorRepository) SetProcessors(ctx context.Context, chain *model.ProcessorChain) error {
+	ret := _m.Called(ctx, chain)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetProcessors")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *model.ProcessorChain) error); ok {
+		r0 = rf(ctx, chain)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewProcessorRepository creates a new instance of ProcessorRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewProcessorRepository(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *ProcessorRepository {
+	mock := &ProcessorRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,173 @@
+// Code generated by mockery v2.46.0. DO NOT EDIT.
+
+package mockrepository
+
+import (
+	context "context"
+	model "tenant/internal/model"
+
+	mock "github.com/stretchr/testify/mock"
+
+	time "time"
+)
+
+// ReplayRepository is an autogenerated mock type for the ReplayRepository type
+type ReplayRepository struct {
+	mock.Mock
+}
+
+// CancelJob provides a mock function with given fields: ctx, clientID, jobID
+func (_m *ReplayRepository) CancelJob(ctx context.Context, clientID string, jobID string) error {
+	ret := _m.Called(ctx, clientID, jobID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CancelJob")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) error); ok {
+		r0 = rf(ctx, clientID, jobID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// ClaimJob provides a mock function with given fields: ctx, workerID, staleAfter
+func (_m *ReplayRepository) ClaimJob(ctx context.Context, workerID string, staleAfter time.Duration) (*model.ReplayJob, error) {
+	ret := _m.Called(ctx, workerID, staleAfter)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ClaimJob")
+	}
+
+	var r0 *model.ReplayJob
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, time.Duration) (*model.ReplayJob, error)); ok {
+		return rf(ctx, workerID, staleAfter)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, time.Duration) *model.ReplayJob); ok {
+		r0 = rf(ctx, workerID, staleAfter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.ReplayJob)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, time.Duration) error); ok {
+		r1 = rf(ctx, workerID, staleAfter)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// CreateJob provides a mock function with given fields: ctx, job
+func (_m *ReplayRepository) CreateJob(ctx context.Context, job *model.ReplayJob) error {
+	ret := _m.Called(ctx, job)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateJob")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *model.ReplayJob) error); ok {
+		r0 = rf(ctx, job)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// FinishJob provides a mock function with given fields: ctx, job
+func (_m *ReplayRepository) FinishJob(ctx context.Context, job *model.ReplayJob) error {
+	ret := _m.Called(ctx, job)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FinishJob")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *model.ReplayJob) error); ok {
+		r0 = rf(ctx, job)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetJob provides a mock function with given fields: ctx, clientID, jobID
+func (_m *ReplayRepository) GetJob(ctx context.Context, clientID string, jobID string) (*model.ReplayJob, error) {
+	ret := _m.Called(ctx, clientID, jobID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetJob")
+	}
+
+	var r0 *model.ReplayJob
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) (*model.ReplayJob, error)); ok {
+		return rf(ctx, clientID, jobID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) *model.ReplayJob); ok {
+		r0 = rf(ctx, clientID, jobID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.ReplayJob)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, clientID, jobID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// UpdateProgress provides a mock function with given fields: ctx, job
+func (_m *ReplayRepository) UpdateProgress(ctx context.Context, job *model.ReplayJob) (bool, error) {
+	ret := _m.Called(ctx, job)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateProgress")
+	}
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *model.ReplayJob) (bool, error)); ok {
+		return rf(ctx, job)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *model.ReplayJob) bool); ok {
+		r0 = rf(ctx, job)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *model.ReplayJob) error); ok {
+		r1 = rf(ctx, job)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewReplayRepository creates a new instance of ReplayRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewReplayRepository(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *ReplayRepository {
+	mock := &ReplayRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
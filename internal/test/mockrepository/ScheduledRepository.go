@@ -0,0 +1,125 @@
+// Code generated by mockery v2.46.0. DO NOT EDIT.
+
+package mockrepository
+
+import (
+	context "context"
+	model "tenant/internal/model"
+
+	mock "github.com/stretchr/testify/mock"
+
+	time "time"
+)
+
+// ScheduledRepository is an autogenerated mock type for the ScheduledRepository type
+type ScheduledRepository struct {
+	mock.Mock
+}
+
+// CancelScheduled provides a mock function with given fields: ctx, clientID, messageID
+func (_m *ScheduledRepository) CancelScheduled(ctx context.Context, clientID string, messageID string) error {
+	ret := _m.Called(ctx, clientID, messageID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CancelScheduled")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) error); ok {
+		r0 = rf(ctx, clientID, messageID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// ListScheduled provides a mock function with given fields: ctx, clientID, limit
+func (_m *ScheduledRepository) ListScheduled(ctx context.Context, clientID string, limit int) ([]*model.ScheduledMessage, error) {
+	ret := _m.Called(ctx, clientID, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListScheduled")
+	}
+
+	var r0 []*model.ScheduledMessage
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, int) ([]*model.ScheduledMessage, error)); ok {
+		return rf(ctx, clientID, limit)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, int) []*model.ScheduledMessage); ok {
+		r0 = rf(ctx, clientID, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*model.ScheduledMessage)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, int) error); ok {
+		r1 = rf(ctx, clientID, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// PublishDue provides a mock function with given fields: ctx, now, limit, publish
+func (_m *ScheduledRepository) PublishDue(ctx context.Context, now time.Time, limit int, publish func([]*model.ScheduledMessage) []error) (int, error) {
+	ret := _m.Called(ctx, now, limit, publish)
+
+	if len(ret) == 0 {
+		panic("no return value specified for PublishDue")
+	}
+
+	var r0 int
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time, int, func([]*model.ScheduledMessage) []error) (int, error)); ok {
+		return rf(ctx, now, limit, publish)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time, int, func([]*model.ScheduledMessage) []error) int); ok {
+		r0 = rf(ctx, now, limit, publish)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, time.Time, int, func([]*model.ScheduledMessage) []error) error); ok {
+		r1 = rf(ctx, now, limit, publish)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Schedule provides a mock function with given fields: ctx, message
+func (_m *ScheduledRepository) Schedule(ctx context.Context, message *model.ScheduledMessage) error {
+	ret := _m.Called(ctx, message)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Schedule")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *model.ScheduledMessage) error); ok {
+		r0 = rf(ctx, message)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewScheduledRepository creates a new instance of ScheduledRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewScheduledRepository(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *ScheduledRepository {
+	mock := &ScheduledRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -62,6 +62,36 @@ func (_m *TenantRepository) GetTenantByClientID(ctx context.Context, clientID st
 	return r0, r1
 }
 
+// ListTenants provides a mock function with given fields: ctx
+func (_m *TenantRepository) ListTenants(ctx context.Context) ([]*model.Tenant, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListTenants")
+	}
+
+	var r0 []*model.Tenant
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) ([]*model.Tenant, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) []*model.Tenant); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*model.Tenant)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // SoftDeleteTenant provides a mock function with given fields: ctx, clientID
 func (_m *TenantRepository) SoftDeleteTenant(ctx context.Context, clientID string) error {
 	ret := _m.Called(ctx, clientID)
@@ -0,0 +1,269 @@
+// Code generated by mockery v2.46.0. DO NOT EDIT.
+
+package mockrepository
+
+import (
+	context "context"
+	model "tenant/internal/model"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// WebhookRepository is an autogenerated mock type for the WebhookRepository type
+type WebhookRepository struct {
+	mock.Mock
+}
+
+// CreateWebhook provides a mock function with given fields: ctx, webhook
+func (_m *WebhookRepository) CreateWebhook(ctx context.Context, webhook *model.Webhook) error {
+	ret := _m.Called(ctx, webhook)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateWebhook")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *model.Webhook) error); ok {
+		r0 = rf(ctx, webhook)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DeleteWebhook provides a mock function with given fields: ctx, clientID, webhookID
+func (_m *WebhookRepository) DeleteWebhook(ctx context.Context, clientID string, webhookID string) error {
+	ret := _m.Called(ctx, clientID, webhookID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteWebhook")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) error); ok {
+		r0 = rf(ctx, clientID, webhookID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetHealth provides a mock function with given fields: ctx, clientID, webhookID
+func (_m *WebhookRepository) GetHealth(ctx context.Context, clientID string, webhookID string) (*model.WebhookHealth, error) {
+	ret := _m.Called(ctx, clientID, webhookID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetHealth")
+	}
+
+	var r0 *model.WebhookHealth
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) (*model.WebhookHealth, error)); ok {
+		return rf(ctx, clientID, webhookID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) *model.WebhookHealth); ok {
+		r0 = rf(ctx, clientID, webhookID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.WebhookHealth)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, clientID, webhookID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetWebhook provides a mock function with given fields: ctx, clientID, webhookID
+func (_m *WebhookRepository) GetWebhook(ctx context.Context, clientID string, webhookID string) (*model.Webhook, error) {
+	ret := _m.Called(ctx, clientID, webhookID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetWebhook")
+	}
+
+	var r0 *model.Webhook
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) (*model.Webhook, error)); ok {
+		return rf(ctx, clientID, webhookID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) *model.Webhook); ok {
+		r0 = rf(ctx, clientID, webhookID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.Webhook)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, clientID, webhookID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListDelivered provides a mock function with given fields: ctx, messageID
+func (_m *WebhookRepository) ListDelivered(ctx context.Context, messageID string) ([]string, error) {
+	ret := _m.Called(ctx, messageID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListDelivered")
+	}
+
+	var r0 []string
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) ([]string, error)); ok {
+		return rf(ctx, messageID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) []string); ok {
+		r0 = rf(ctx, messageID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]string)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, messageID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListDeliveries provides a mock function with given fields: ctx, clientID, webhookID, limit
+func (_m *WebhookRepository) ListDeliveries(ctx context.Context, clientID string, webhookID string, limit int) ([]*model.WebhookDelivery, error) {
+	ret := _m.Called(ctx, clientID, webhookID, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListDeliveries")
+	}
+
+	var r0 []*model.WebhookDelivery
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, int) ([]*model.WebhookDelivery, error)); ok {
+		return rf(ctx, clientID, webhookID, limit)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, int) []*model.WebhookDelivery); ok {
+		r0 = rf(ctx, clientID, webhookID, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*model.WebhookDelivery)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, int) error); ok {
+		r1 = rf(ctx, clientID, webhookID, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListWebhooks provides a mock function with given fields: ctx, clientID
+func (_m *WebhookRepository) ListWebhooks(ctx context.Context, clientID string) ([]*model.Webhook, error) {
+	ret := _m.Called(ctx, clientID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListWebhooks")
+	}
+
+	var r0 []*model.Webhook
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) ([]*model.Webhook, error)); ok {
+		return rf(ctx, clientID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) []*model.Webhook); ok {
+		r0 = rf(ctx, clientID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*model.Webhook)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, clientID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RecordDelivery provides a mock function with given fields: ctx, delivery
+func (_m *WebhookRepository) RecordDelivery(ctx context.Context, delivery *model.WebhookDelivery) error {
+	ret := _m.Called(ctx, delivery)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RecordDelivery")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *model.WebhookDelivery) error); ok {
+		r0 = rf(ctx, delivery)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SaveHealth provides a mock function with given fields: ctx, health
+func (_m *WebhookRepository) SaveHealth(ctx context.Context, health *model.WebhookHealth) error {
+	ret := _m.Called(ctx, health)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SaveHealth")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *model.WebhookHealth) error); ok {
+		r0 = rf(ctx, health)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UpdateWebhook provides a mock function with given fields: ctx, webhook
+func (_m *WebhookRepository) UpdateWebhook(ctx context.Context, webhook *model.Webhook) error {
+	ret := _m.Called(ctx, webhook)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateWebhook")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *model.Webhook) error); ok {
+		r0 = rf(ctx, webhook)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewWebhookRepository creates a new instance of WebhookRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewWebhookRepository(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *WebhookRepository {
+	mock := &WebhookRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -4,6 +4,7 @@ package mockservice
 
 import (
 	context "context"
+	messaging "tenant/internal/service/messaging"
 
 	mock "github.com/stretchr/testify/mock"
 )
@@ -13,8 +14,26 @@ type Messagging struct {
 	mock.Mock
 }
 
+// CheckConsumers provides a mock function with given fields:
+func (_m *Messagging) CheckConsumers() error {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for CheckConsumers")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func() error); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // Consume provides a mock function with given fields: ctx, queueName, handler
-func (_m *Messagging) Consume(ctx context.Context, queueName string, handler func(string)) error {
+func (_m *Messagging) Consume(ctx context.Context, queueName string, handler messaging.Handler) error {
 	ret := _m.Called(ctx, queueName, handler)
 
 	if len(ret) == 0 {
@@ -22,7 +41,7 @@ func (_m *Messagging) Consume(ctx context.Context, queueName string, handler fun
 	}
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(context.Context, string, func(string)) error); ok {
+	if rf, ok := ret.Get(0).(func(context.Context, string, messaging.Handler) error); ok {
 		r0 = rf(ctx, queueName, handler)
 	} else {
 		r0 = ret.Error(0)
@@ -67,8 +86,44 @@ func (_m *Messagging) DeleteQueue(queueName string) error {
 	return r0
 }
 
+// IsConsuming provides a mock function with given fields: queueName
+func (_m *Messagging) IsConsuming(queueName string) bool {
+	ret := _m.Called(queueName)
+
+	if len(ret) == 0 {
+		panic("no return value specified for IsConsuming")
+	}
+
+	var r0 bool
+	if rf, ok := ret.Get(0).(func(string) bool); ok {
+		r0 = rf(queueName)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	return r0
+}
+
+// Ping provides a mock function with given fields:
+func (_m *Messagging) Ping() error {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for Ping")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func() error); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // Publish provides a mock function with given fields: ctx, queueName, message
-func (_m *Messagging) Publish(ctx context.Context, queueName string, message interface{}) error {
+func (_m *Messagging) Publish(ctx context.Context, queueName string, message messaging.Message) error {
 	ret := _m.Called(ctx, queueName, message)
 
 	if len(ret) == 0 {
@@ -76,7 +131,7 @@ func (_m *Messagging) Publish(ctx context.Context, queueName string, message int
 	}
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(context.Context, string, interface{}) error); ok {
+	if rf, ok := ret.Get(0).(func(context.Context, string, messaging.Message) error); ok {
 		r0 = rf(ctx, queueName, message)
 	} else {
 		r0 = ret.Error(0)
@@ -85,8 +140,28 @@ func (_m *Messagging) Publish(ctx context.Context, queueName string, message int
 	return r0
 }
 
+// PublishBatch provides a mock function with given fields: ctx, queueName, messages
+func (_m *Messagging) PublishBatch(ctx context.Context, queueName string, messages []messaging.Message) []error {
+	ret := _m.Called(ctx, queueName, messages)
+
+	if len(ret) == 0 {
+		panic("no return value specified for PublishBatch")
+	}
+
+	var r0 []error
+	if rf, ok := ret.Get(0).(func(context.Context, string, []messaging.Message) []error); ok {
+		r0 = rf(ctx, queueName, messages)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]error)
+		}
+	}
+
+	return r0
+}
+
 // StartQueue provides a mock function with given fields: ctx, queueName, handler
-func (_m *Messagging) StartQueue(ctx context.Context, queueName string, handler func(string)) error {
+func (_m *Messagging) StartQueue(ctx context.Context, queueName string, handler messaging.Handler) error {
 	ret := _m.Called(ctx, queueName, handler)
 
 	if len(ret) == 0 {
@@ -94,7 +169,7 @@ func (_m *Messagging) StartQueue(ctx context.Context, queueName string, handler
 	}
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(context.Context, string, func(string)) error); ok {
+	if rf, ok := ret.Get(0).(func(context.Context, string, messaging.Handler) error); ok {
 		r0 = rf(ctx, queueName, handler)
 	} else {
 		r0 = ret.Error(0)
@@ -103,6 +178,24 @@ func (_m *Messagging) StartQueue(ctx context.Context, queueName string, handler
 	return r0
 }
 
+// StopQueue provides a mock function with given fields: queueName
+func (_m *Messagging) StopQueue(queueName string) error {
+	ret := _m.Called(queueName)
+
+	if len(ret) == 0 {
+		panic("no return value specified for StopQueue")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string) error); ok {
+		r0 = rf(queueName)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // NewMessagging creates a new instance of Messagging. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewMessagging(t interface {
@@ -0,0 +1,59 @@
+// Code generated by mockery v2.46.0. DO NOT EDIT.
+
+package mockservice
+
+import (
+	context "context"
+	webhook "tenant/internal/service/webhook"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// Sender is an autogenerated mock type for the Sender type
+type Sender struct {
+	mock.Mock
+}
+
+// Send provides a mock function with given fields: ctx, req
+func (_m *Sender) Send(ctx context.Context, req *webhook.Request) (*webhook.Response, error) {
+	ret := _m.Called(ctx, req)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Send")
+	}
+
+	var r0 *webhook.Response
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *webhook.Request) (*webhook.Response, error)); ok {
+		return rf(ctx, req)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *webhook.Request) *webhook.Response); ok {
+		r0 = rf(ctx, req)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*webhook.Response)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *webhook.Request) error); ok {
+		r1 = rf(ctx, req)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewSender creates a new instance of Sender. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewSender(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *Sender {
+	mock := &Sender{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,186 @@
+// Code generated by mockery v2.46.0. DO NOT EDIT.
+
+package mockusecase
+
+import (
+	context "context"
+	model "tenant/internal/model"
+
+	mock "github.com/stretchr/testify/mock"
+
+	time "time"
+)
+
+// ArchiveUsecase is an autogenerated mock type for the ArchiveUsecase type
+type ArchiveUsecase struct {
+	mock.Mock
+}
+
+// GetRetention provides a mock function with given fields: ctx, clientID
+func (_m *ArchiveUsecase) GetRetention(ctx context.Context, clientID string) (*model.RetentionPolicy, error) {
+	ret := _m.Called(ctx, clientID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetRetention")
+	}
+
+	var r0 *model.RetentionPolicy
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*model.RetentionPolicy, error)); ok {
+		return rf(ctx, clientID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *model.RetentionPolicy); ok {
+		r0 = rf(ctx, clientID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.RetentionPolicy)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, clientID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Prune provides a mock function with given fields: ctx
+func (_m *ArchiveUsecase) Prune(ctx context.Context) (*model.PruneResult, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Prune")
+	}
+
+	var r0 *model.PruneResult
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) (*model.PruneResult, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) *model.PruneResult); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.PruneResult)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ResetRetention provides a mock function with given fields: ctx, clientID
+func (_m *ArchiveUsecase) ResetRetention(ctx context.Context, clientID string) (*model.RetentionPolicy, error) {
+	ret := _m.Called(ctx, clientID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ResetRetention")
+	}
+
+	var r0 *model.RetentionPolicy
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*model.RetentionPolicy, error)); ok {
+		return rf(ctx, clientID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *model.RetentionPolicy); ok {
+		r0 = rf(ctx, clientID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.RetentionPolicy)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, clientID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RunPruner provides a mock function with given fields: ctx, interval
+func (_m *ArchiveUsecase) RunPruner(ctx context.Context, interval time.Duration) {
+	_m.Called(ctx, interval)
+}
+
+// Search provides a mock function with given fields: ctx, query
+func (_m *ArchiveUsecase) Search(ctx context.Context, query *model.ArchiveQuery) (*model.ArchivePage, error) {
+	ret := _m.Called(ctx, query)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Search")
+	}
+
+	var r0 *model.ArchivePage
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *model.ArchiveQuery) (*model.ArchivePage, error)); ok {
+		return rf(ctx, query)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *model.ArchiveQuery) *model.ArchivePage); ok {
+		r0 = rf(ctx, query)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.ArchivePage)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *model.ArchiveQuery) error); ok {
+		r1 = rf(ctx, query)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SetRetention provides a mock function with given fields: ctx, clientID, retention
+func (_m *ArchiveUsecase) SetRetention(ctx context.Context, clientID string, retention time.Duration) (*model.RetentionPolicy, error) {
+	ret := _m.Called(ctx, clientID, retention)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetRetention")
+	}
+
+	var r0 *model.RetentionPolicy
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, time.Duration) (*model.RetentionPolicy, error)); ok {
+		return rf(ctx, clientID, retention)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, time.Duration) *model.RetentionPolicy); ok {
+		r0 = rf(ctx, clientID, retention)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.RetentionPolicy)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, time.Duration) error); ok {
+		r1 = rf(ctx, clientID, retention)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewArchiveUsecase creates a new instance of ArchiveUsecase. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewArchiveUsecase(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *ArchiveUsecase {
+	mock := &ArchiveUsecase{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
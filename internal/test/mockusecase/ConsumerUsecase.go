@@ -0,0 +1,122 @@
+// Code generated by mockery v2.46.0. DO NOT EDIT.
+
+package mockusecase
+
+import (
+	context "context"
+	model "tenant/internal/model"
+
+	mock "github.com/stretchr/testify/mock"
+
+	time "time"
+)
+
+// ConsumerUsecase is an autogenerated mock type for the ConsumerUsecase type
+type ConsumerUsecase struct {
+	mock.Mock
+}
+
+// Assignments provides a mock function with given fields: ctx
+func (_m *ConsumerUsecase) Assignments(ctx context.Context) (*model.Assignments, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Assignments")
+	}
+
+	var r0 *model.Assignments
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) (*model.Assignments, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) *model.Assignments); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.Assignments)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ConsumedTenants provides a mock function with given fields:
+func (_m *ConsumerUsecase) ConsumedTenants() []string {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for ConsumedTenants")
+	}
+
+	var r0 []string
+	if rf, ok := ret.Get(0).(func() []string); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]string)
+		}
+	}
+
+	return r0
+}
+
+// Resync provides a mock function with given fields:
+func (_m *ConsumerUsecase) Resync() {
+	_m.Called()
+}
+
+// Run provides a mock function with given fields: ctx, interval
+func (_m *ConsumerUsecase) Run(ctx context.Context, interval time.Duration) error {
+	ret := _m.Called(ctx, interval)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Run")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, time.Duration) error); ok {
+		r0 = rf(ctx, interval)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Sync provides a mock function with given fields: ctx
+func (_m *ConsumerUsecase) Sync(ctx context.Context) error {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Sync")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context) error); ok {
+		r0 = rf(ctx)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewConsumerUsecase creates a new instance of ConsumerUsecase. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewConsumerUsecase(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *ConsumerUsecase {
+	mock := &ConsumerUsecase{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
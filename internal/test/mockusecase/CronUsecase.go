@@ -0,0 +1,202 @@
+// Code generated by mockery v2.46.0. DO NOT EDIT.
+
+package mockusecase
+
+import (
+	context "context"
+	model "tenant/internal/model"
+
+	mock "github.com/stretchr/testify/mock"
+
+	time "time"
+)
+
+// CronUsecase is an autogenerated mock type for the CronUsecase type
+type CronUsecase struct {
+	mock.Mock
+}
+
+// CreateSchedule provides a mock function with given fields: ctx, schedule
+func (_m *CronUsecase) CreateSchedule(ctx context.Context, schedule *model.CronSchedule) (*model.CronSchedule, error) {
+	ret := _m.Called(ctx, schedule)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateSchedule")
+	}
+
+	var r0 *model.CronSchedule
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *model.CronSchedule) (*model.CronSchedule, error)); ok {
+		return rf(ctx, schedule)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *model.CronSchedule) *model.CronSchedule); ok {
+		r0 = rf(ctx, schedule)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.CronSchedule)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *model.CronSchedule) error); ok {
+		r1 = rf(ctx, schedule)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// DeleteSchedule provides a mock function with given fields: ctx, clientID, scheduleID
+func (_m *CronUsecase) DeleteSchedule(ctx context.Context, clientID string, scheduleID string) error {
+	ret := _m.Called(ctx, clientID, scheduleID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteSchedule")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) error); ok {
+		r0 = rf(ctx, clientID, scheduleID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetSchedule provides a mock function with given fields: ctx, clientID, scheduleID
+func (_m *CronUsecase) GetSchedule(ctx context.Context, clientID string, scheduleID string) (*model.CronSchedule, error) {
+	ret := _m.Called(ctx, clientID, scheduleID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetSchedule")
+	}
+
+	var r0 *model.CronSchedule
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) (*model.CronSchedule, error)); ok {
+		return rf(ctx, clientID, scheduleID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) *model.CronSchedule); ok {
+		r0 = rf(ctx, clientID, scheduleID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.CronSchedule)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, clientID, scheduleID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListSchedules provides a mock function with given fields: ctx, clientID
+func (_m *CronUsecase) ListSchedules(ctx context.Context, clientID string) ([]*model.CronSchedule, error) {
+	ret := _m.Called(ctx, clientID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListSchedules")
+	}
+
+	var r0 []*model.CronSchedule
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) ([]*model.CronSchedule, error)); ok {
+		return rf(ctx, clientID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) []*model.CronSchedule); ok {
+		r0 = rf(ctx, clientID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*model.CronSchedule)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, clientID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RunDue provides a mock function with given fields: ctx
+func (_m *CronUsecase) RunDue(ctx context.Context) (int, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RunDue")
+	}
+
+	var r0 int
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) (int, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) int); ok {
+		r0 = rf(ctx)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RunScheduler provides a mock function with given fields: ctx, interval
+func (_m *CronUsecase) RunScheduler(ctx context.Context, interval time.Duration) {
+	_m.Called(ctx, interval)
+}
+
+// UpdateSchedule provides a mock function with given fields: ctx, schedule
+func (_m *CronUsecase) UpdateSchedule(ctx context.Context, schedule *model.CronSchedule) (*model.CronSchedule, error) {
+	ret := _m.Called(ctx, schedule)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateSchedule")
+	}
+
+	var r0 *model.CronSchedule
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *model.CronSchedule) (*model.CronSchedule, error)); ok {
+		return rf(ctx, schedule)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *model.CronSchedule) *model.CronSchedule); ok {
+		r0 = rf(ctx, schedule)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.CronSchedule)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *model.CronSchedule) error); ok {
+		r1 = rf(ctx, schedule)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewCronUsecase creates a new instance of CronUsecase. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewCronUsecase(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *CronUsecase {
+	mock := &CronUsecase{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,59 @@
+// Code generated by mockery v2.46.0. DO NOT EDIT.
+
+package mockusecase
+
+import (
+	context "context"
+	model "tenant/internal/model"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// MessageUsecase is an autogenerated mock type for the MessageUsecase type
+type MessageUsecase struct {
+	mock.Mock
+}
+
+// GetMessage provides a mock function with given fields: ctx, clientID, messageID
+func (_m *MessageUsecase) GetMessage(ctx context.Context, clientID string, messageID string) (*model.Message, error) {
+	ret := _m.Called(ctx, clientID, messageID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetMessage")
+	}
+
+	var r0 *model.Message
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) (*model.Message, error)); ok {
+		return rf(ctx, clientID, messageID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) *model.Message); ok {
+		r0 = rf(ctx, clientID, messageID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.Message)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, clientID, messageID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewMessageUsecase creates a new instance of MessageUsecase. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMessageUsecase(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MessageUsecase {
+	mock := &MessageUsecase{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,159 @@
+// Code generated by mockery v2.46.0. DO NOT EDIT.
+
+package mockusecase
+
+import (
+	context "context"
+	messaging "tenant/internal/service/messaging"
+
+	mock "github.com/stretchr/testify/mock"
+
+	model "tenant/internal/model"
+)
+
+// ProcessorUsecase is an autogenerated mock type for the ProcessorUsecase type
+type ProcessorUsecase struct {
+	mock.Mock
+}
+
+// GetProcessors provides a mock function with given fields: ctx, clientID
+func (_m *ProcessorUsecase) GetProcessors(ctx context.Context, clientID string) (*model.ProcessorChain, error) {
+	ret := _m.Called(ctx, clientID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetProcessors")
+	}
+
+	var r0 *model.ProcessorChain
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*model.ProcessorChain, error)); ok {
+		return rf(ctx, clientID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *model.ProcessorChain); ok {
+		r0 = rf(ctx, clientID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.ProcessorChain)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, clientID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListProcessorTypes provides a mock function with given fields:
+func (_m *ProcessorUsecase) ListProcessorTypes() []string {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListProcessorTypes")
+	}
+
+	var r0 []string
+	if rf, ok := ret.Get(0).(func() []string); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]string)
+		}
+	}
+
+	return r0
+}
+
+// Process provides a mock function with given fields: ctx, clientID, delivery
+func (_m *ProcessorUsecase) Process(ctx context.Context, clientID string, delivery *messaging.Delivery) error {
+	ret := _m.Called(ctx, clientID, delivery)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Process")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, *messaging.Delivery) error); ok {
+		r0 = rf(ctx, clientID, delivery)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// ResetProcessors provides a mock function with given fields: ctx, clientID
+func (_m *ProcessorUsecase) ResetProcessors(ctx context.Context, clientID string) (*model.ProcessorChain, error) {
+	ret := _m.Called(ctx, clientID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ResetProcessors")
+	}
+
+	var r0 *model.ProcessorChain
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*model.ProcessorChain, error)); ok {
+		return rf(ctx, clientID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *model.ProcessorChain); ok {
+		r0 = rf(ctx, clientID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.ProcessorChain)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, clientID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SetProcessors provides a mock function with given fields: ctx, clientID, specs
+func (_m *ProcessorUsecase) SetProcessors(ctx context.Context, clientID string, specs []model.ProcessorSpec) (*model.ProcessorChain, error) {
+	ret := _m.Called(ctx, clientID, specs)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetProcessors")
+	}
+
+	var r0 *model.ProcessorChain
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, []model.ProcessorSpec) (*model.ProcessorChain, error)); ok {
+		return rf(ctx, clientID, specs)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, []model.ProcessorSpec) *model.ProcessorChain); ok {
+		r0 = rf(ctx, clientID, specs)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.ProcessorChain)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, []model.ProcessorSpec) error); ok {
+		r1 = rf(ctx, clientID, specs)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewProcessorUsecase creates a new instance of ProcessorUsecase. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewProcessorUsecase(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *ProcessorUsecase {
+	mock := &ProcessorUsecase{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,156 @@
+// Code generated by mockery v2.46.0. DO NOT EDIT.
+
+package mockusecase
+
+import (
+	context "context"
+	model "tenant/internal/model"
+
+	mock "github.com/stretchr/testify/mock"
+
+	time "time"
+)
+
+// ReplayUsecase is an autogenerated mock type for the ReplayUsecase type
+type ReplayUsecase struct {
+	mock.Mock
+}
+
+// CancelReplay provides a mock function with given fields: ctx, clientID, jobID
+func (_m *ReplayUsecase) CancelReplay(ctx context.Context, clientID string, jobID string) (*model.ReplayJob, error) {
+	ret := _m.Called(ctx, clientID, jobID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CancelReplay")
+	}
+
+	var r0 *model.ReplayJob
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) (*model.ReplayJob, error)); ok {
+		return rf(ctx, clientID, jobID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) *model.ReplayJob); ok {
+		r0 = rf(ctx, clientID, jobID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.ReplayJob)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, clientID, jobID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// CreateReplay provides a mock function with given fields: ctx, job
+func (_m *ReplayUsecase) CreateReplay(ctx context.Context, job *model.ReplayJob) (*model.ReplayJob, error) {
+	ret := _m.Called(ctx, job)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateReplay")
+	}
+
+	var r0 *model.ReplayJob
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *model.ReplayJob) (*model.ReplayJob, error)); ok {
+		return rf(ctx, job)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *model.ReplayJob) *model.ReplayJob); ok {
+		r0 = rf(ctx, job)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.ReplayJob)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *model.ReplayJob) error); ok {
+		r1 = rf(ctx, job)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetReplay provides a mock function with given fields: ctx, clientID, jobID
+func (_m *ReplayUsecase) GetReplay(ctx context.Context, clientID string, jobID string) (*model.ReplayJob, error) {
+	ret := _m.Called(ctx, clientID, jobID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetReplay")
+	}
+
+	var r0 *model.ReplayJob
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) (*model.ReplayJob, error)); ok {
+		return rf(ctx, clientID, jobID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) *model.ReplayJob); ok {
+		r0 = rf(ctx, clientID, jobID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.ReplayJob)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, clientID, jobID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Replay provides a mock function with given fields: ctx, job, progress
+func (_m *ReplayUsecase) Replay(ctx context.Context, job *model.ReplayJob, progress func(*model.ReplayJob)) (*model.ReplayJob, error) {
+	ret := _m.Called(ctx, job, progress)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Replay")
+	}
+
+	var r0 *model.ReplayJob
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *model.ReplayJob, func(*model.ReplayJob)) (*model.ReplayJob, error)); ok {
+		return rf(ctx, job, progress)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *model.ReplayJob, func(*model.ReplayJob)) *model.ReplayJob); ok {
+		r0 = rf(ctx, job, progress)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.ReplayJob)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *model.ReplayJob, func(*model.ReplayJob)) error); ok {
+		r1 = rf(ctx, job, progress)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RunReplays provides a mock function with given fields: ctx, interval
+func (_m *ReplayUsecase) RunReplays(ctx context.Context, interval time.Duration) {
+	_m.Called(ctx, interval)
+}
+
+// NewReplayUsecase creates a new instance of ReplayUsecase. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewReplayUsecase(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *ReplayUsecase {
+	mock := &ReplayUsecase{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,112 @@
+// Code generated by mockery v2.46.0. DO NOT EDIT.
+
+package mockusecase
+
+import (
+	context "context"
+	model "tenant/internal/model"
+
+	mock "github.com/stretchr/testify/mock"
+
+	time "time"
+)
+
+// ScheduledUsecase is an autogenerated mock type for the ScheduledUsecase type
+type ScheduledUsecase struct {
+	mock.Mock
+}
+
+// CancelScheduled provides a mock function with given fields: ctx, clientID, messageID
+func (_m *ScheduledUsecase) CancelScheduled(ctx context.Context, clientID string, messageID string) error {
+	ret := _m.Called(ctx, clientID, messageID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CancelScheduled")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) error); ok {
+		r0 = rf(ctx, clientID, messageID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// ListScheduled provides a mock function with given fields: ctx, clientID, limit
+func (_m *ScheduledUsecase) ListScheduled(ctx context.Context, clientID string, limit int) ([]*model.ScheduledMessage, error) {
+	ret := _m.Called(ctx, clientID, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListScheduled")
+	}
+
+	var r0 []*model.ScheduledMessage
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, int) ([]*model.ScheduledMessage, error)); ok {
+		return rf(ctx, clientID, limit)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, int) []*model.ScheduledMessage); ok {
+		r0 = rf(ctx, clientID, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*model.ScheduledMessage)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, int) error); ok {
+		r1 = rf(ctx, clientID, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// PublishDue provides a mock function with given fields: ctx
+func (_m *ScheduledUsecase) PublishDue(ctx context.Context) (int, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for PublishDue")
+	}
+
+	var r0 int
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) (int, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) int); ok {
+		r0 = rf(ctx)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RunScheduler provides a mock function with given fields: ctx, interval
+func (_m *ScheduledUsecase) RunScheduler(ctx context.Context, interval time.Duration) {
+	_m.Called(ctx, interval)
+}
+
+// NewScheduledUsecase creates a new instance of ScheduledUsecase. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewScheduledUsecase(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *ScheduledUsecase {
+	mock := &ScheduledUsecase{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
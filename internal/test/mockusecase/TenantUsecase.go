@@ -92,22 +92,62 @@ func (_m *TenantUsecase) GetTenant(ctx context.Context, clientID string) (*model
 	return r0, r1
 }
 
+// ProcessBatch provides a mock function with given fields: ctx, clientID, payloads
+func (_m *TenantUsecase) ProcessBatch(ctx context.Context, clientID string, payloads []interface{}) (*model.BatchResult, error) {
+	ret := _m.Called(ctx, clientID, payloads)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ProcessBatch")
+	}
+
+	var r0 *model.BatchResult
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, []interface{}) (*model.BatchResult, error)); ok {
+		return rf(ctx, clientID, payloads)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, []interface{}) *model.BatchResult); ok {
+		r0 = rf(ctx, clientID, payloads)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.BatchResult)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, []interface{}) error); ok {
+		r1 = rf(ctx, clientID, payloads)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // ProcessPayload provides a mock function with given fields: ctx, clientID, payload
-func (_m *TenantUsecase) ProcessPayload(ctx context.Context, clientID string, payload interface{}) error {
+func (_m *TenantUsecase) ProcessPayload(ctx context.Context, clientID string, payload *model.Payload) (string, error) {
 	ret := _m.Called(ctx, clientID, payload)
 
 	if len(ret) == 0 {
 		panic("no return value specified for ProcessPayload")
 	}
 
-	var r0 error
-	if rf, ok := ret.Get(0).(func(context.Context, string, interface{}) error); ok {
+	var r0 string
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, *model.Payload) (string, error)); ok {
+		return rf(ctx, clientID, payload)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, *model.Payload) string); ok {
 		r0 = rf(ctx, clientID, payload)
 	} else {
-		r0 = ret.Error(0)
+		r0 = ret.Get(0).(string)
 	}
 
-	return r0
+	if rf, ok := ret.Get(1).(func(context.Context, string, *model.Payload) error); ok {
+		r1 = rf(ctx, clientID, payload)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
 }
 
 // NewTenantUsecase creates a new instance of TenantUsecase. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
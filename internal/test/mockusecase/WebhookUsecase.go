@@ -0,0 +1,247 @@
+// Code generated by mockery v2.46.0. DO NOT EDIT.
+
+package mockusecase
+
+import (
+	context "context"
+	messaging "tenant/internal/service/messaging"
+
+	mock "github.com/stretchr/testify/mock"
+
+	model "tenant/internal/model"
+)
+
+// WebhookUsecase is an autogenerated mock type for the WebhookUsecase type
+type WebhookUsecase struct {
+	mock.Mock
+}
+
+// CreateWebhook provides a mock function with given fields: ctx, hook
+func (_m *WebhookUsecase) CreateWebhook(ctx context.Context, hook *model.Webhook) (*model.Webhook, error) {
+	ret := _m.Called(ctx, hook)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateWebhook")
+	}
+
+	var r0 *model.Webhook
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *model.Webhook) (*model.Webhook, error)); ok {
+		return rf(ctx, hook)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *model.Webhook) *model.Webhook); ok {
+		r0 = rf(ctx, hook)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.Webhook)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *model.Webhook) error); ok {
+		r1 = rf(ctx, hook)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// DeleteWebhook provides a mock function with given fields: ctx, clientID, webhookID
+func (_m *WebhookUsecase) DeleteWebhook(ctx context.Context, clientID string, webhookID string) error {
+	ret := _m.Called(ctx, clientID, webhookID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteWebhook")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) error); ok {
+		r0 = rf(ctx, clientID, webhookID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Deliver provides a mock function with given fields: ctx, clientID, delivery
+func (_m *WebhookUsecase) Deliver(ctx context.Context, clientID string, delivery *messaging.Delivery) error {
+	ret := _m.Called(ctx, clientID, delivery)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Deliver")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, *messaging.Delivery) error); ok {
+		r0 = rf(ctx, clientID, delivery)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetHealth provides a mock function with given fields: ctx, clientID, webhookID
+func (_m *WebhookUsecase) GetHealth(ctx context.Context, clientID string, webhookID string) (*model.WebhookHealth, error) {
+	ret := _m.Called(ctx, clientID, webhookID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetHealth")
+	}
+
+	var r0 *model.WebhookHealth
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) (*model.WebhookHealth, error)); ok {
+		return rf(ctx, clientID, webhookID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) *model.WebhookHealth); ok {
+		r0 = rf(ctx, clientID, webhookID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.WebhookHealth)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, clientID, webhookID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetWebhook provides a mock function with given fields: ctx, clientID, webhookID
+func (_m *WebhookUsecase) GetWebhook(ctx context.Context, clientID string, webhookID string) (*model.Webhook, error) {
+	ret := _m.Called(ctx, clientID, webhookID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetWebhook")
+	}
+
+	var r0 *model.Webhook
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) (*model.Webhook, error)); ok {
+		return rf(ctx, clientID, webhookID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) *model.Webhook); ok {
+		r0 = rf(ctx, clientID, webhookID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.Webhook)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, clientID, webhookID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListDeliveries provides a mock function with given fields: ctx, clientID, webhookID, limit
+func (_m *WebhookUsecase) ListDeliveries(ctx context.Context, clientID string, webhookID string, limit int) ([]*model.WebhookDelivery, error) {
+	ret := _m.Called(ctx, clientID, webhookID, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListDeliveries")
+	}
+
+	var r0 []*model.WebhookDelivery
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, int) ([]*model.WebhookDelivery, error)); ok {
+		return rf(ctx, clientID, webhookID, limit)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, int) []*model.WebhookDelivery); ok {
+		r0 = rf(ctx, clientID, webhookID, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*model.WebhookDelivery)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, int) error); ok {
+		r1 = rf(ctx, clientID, webhookID, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListWebhooks provides a mock function with given fields: ctx, clientID
+func (_m *WebhookUsecase) ListWebhooks(ctx context.Context, clientID string) ([]*model.Webhook, error) {
+	ret := _m.Called(ctx, clientID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListWebhooks")
+	}
+
+	var r0 []*model.Webhook
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) ([]*model.Webhook, error)); ok {
+		return rf(ctx, clientID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) []*model.Webhook); ok {
+		r0 = rf(ctx, clientID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*model.Webhook)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, clientID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// UpdateWebhook provides a mock function with given fields: ctx, hook
+func (_m *WebhookUsecase) UpdateWebhook(ctx context.Context, hook *model.Webhook) (*model.Webhook, error) {
+	ret := _m.Called(ctx, hook)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateWebhook")
+	}
+
+	var r0 *model.Webhook
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *model.Webhook) (*model.Webhook, error)); ok {
+		return rf(ctx, hook)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *model.Webhook) *model.Webhook); ok {
+		r0 = rf(ctx, hook)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.Webhook)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *model.Webhook) error); ok {
+		r1 = rf(ctx, hook)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewWebhookUsecase creates a new instance of WebhookUsecase. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewWebhookUsecase(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *WebhookUsecase {
+	mock := &WebhookUsecase{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,188 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"tenant/internal/model"
+	"tenant/internal/repository"
+	"tenant/pkg/derrors"
+	"tenant/pkg/logger"
+
+	"github.com/sirupsen/logrus"
+)
+
+// defaultArchiveSearchLimit is the page size of searches that do not set one
+const defaultArchiveSearchLimit = 50
+
+type ArchiveUsecase interface {
+	Search(ctx context.Context, query *model.ArchiveQuery) (*model.ArchivePage, error)
+	GetRetention(ctx context.Context, clientID string) (*model.RetentionPolicy, error)
+	SetRetention(ctx context.Context, clientID string, retention time.Duration) (*model.RetentionPolicy, error)
+	ResetRetention(ctx context.Context, clientID string) (*model.RetentionPolicy, error)
+	Prune(ctx context.Context) (*model.PruneResult, error)
+	RunPruner(ctx context.Context, interval time.Duration)
+}
+
+// ArchiveConfig tunes the message archive
+type ArchiveConfig struct {
+	// Retention is how long the messages of tenants without a retention
+	// policy are kept
+	Retention time.Duration
+
+	// PartitionsAhead is how many monthly partitions are created ahead of
+	// the current month
+	PartitionsAhead int
+}
+
+type archiveUsecase struct {
+	repo        repository.ArchiveRepository
+	tenants     repository.TenantRepository
+	idempotency repository.IdempotencyRepository
+	log         *logrus.Logger
+
+	retention       time.Duration
+	partitionsAhead int
+}
+
+// NewArchiveUsecase initializes a new archive usecase
+func NewArchiveUsecase(repo repository.ArchiveRepository, tenants repository.TenantRepository, idempotency repository.IdempotencyRepository, log *logrus.Logger, cfg ArchiveConfig) ArchiveUsecase {
+	if cfg.Retention <= 0 {
+		cfg.Retention = 30 * 24 * time.Hour
+	}
+	if cfg.PartitionsAhead <= 0 {
+		cfg.PartitionsAhead = 1
+	}
+	return &archiveUsecase{
+		repo:            repo,
+		tenants:         tenants,
+		idempotency:     idempotency,
+		log:             log,
+		retention:       cfg.Retention,
+		partitionsAhead: cfg.PartitionsAhead,
+	}
+}
+
+// Search returns a page of the archived messages of a tenant, newest first
+func (s *archiveUsecase) Search(ctx context.Context, query *model.ArchiveQuery) (page *model.ArchivePage, err error) {
+	defer derrors.Wrap(&err, "Search(%q)", query.ClientID)
+
+	if _, err := s.tenants.GetTenantByClientID(ctx, query.ClientID); err != nil {
+		return nil, err
+	}
+
+	// Fetch one more message to know whether there is a next page
+	limit := query.Limit
+	if limit <= 0 {
+		limit = defaultArchiveSearchLimit
+	}
+	paged := *query
+	paged.Limit = limit + 1
+	messages, err := s.repo.Search(ctx, &paged)
+	if err != nil {
+		return nil, err
+	}
+
+	page = &model.ArchivePage{Messages: messages}
+	if len(messages) > limit {
+		page.Messages = messages[:limit]
+		last := page.Messages[limit-1]
+		page.NextCursor = model.ArchiveCursor{PublishedAt: last.PublishedAt, MessageID: last.MessageID}.String()
+	}
+	return page, nil
+}
+
+// GetRetention returns how long the archived messages of a tenant are kept
+func (s *archiveUsecase) GetRetention(ctx context.Context, clientID string) (policy *model.RetentionPolicy, err error) {
+	defer derrors.Wrap(&err, "GetRetention(%q)", clientID)
+
+	if _, err := s.tenants.GetTenantByClientID(ctx, clientID); err != nil {
+		return nil, err
+	}
+
+	policy, err = s.repo.GetRetention(ctx, clientID)
+	if derrors.IsErrCode(err, derrors.NotFound) {
+		return s.defaultRetention(clientID), nil
+	}
+	return policy, err
+}
+
+// SetRetention overrides the default retention of a tenant
+func (s *archiveUsecase) SetRetention(ctx context.Context, clientID string, retention time.Duration) (policy *model.RetentionPolicy, err error) {
+	defer derrors.Wrap(&err, "SetRetention(%q)", clientID)
+
+	if retention < time.Second {
+		return nil, derrors.New(derrors.InvalidArgument, "retention must be at least 1s")
+	}
+	if _, err := s.tenants.GetTenantByClientID(ctx, clientID); err != nil {
+		return nil, err
+	}
+
+	policy = &model.RetentionPolicy{ClientID: clientID, Retention: retention.Truncate(time.Second)}
+	if err := s.repo.SetRetention(ctx, policy); err != nil {
+		return nil, err
+	}
+	return policy, nil
+}
+
+// ResetRetention moves a tenant back to the default retention
+func (s *archiveUsecase) ResetRetention(ctx context.Context, clientID string) (policy *model.RetentionPolicy, err error) {
+	defer derrors.Wrap(&err, "ResetRetention(%q)", clientID)
+
+	if _, err := s.tenants.GetTenantByClientID(ctx, clientID); err != nil {
+		return nil, err
+	}
+	if err := s.repo.DeleteRetention(ctx, clientID); err != nil {
+		return nil, err
+	}
+	return s.defaultRetention(clientID), nil
+}
+
+// Prune removes the archived messages, message statuses and idempotency keys
+// that expired
+func (s *archiveUsecase) Prune(ctx context.Context) (result *model.PruneResult, err error) {
+	defer derrors.Wrap(&err, "Prune")
+
+	result, err = s.repo.Prune(ctx, time.Now(), s.retention, s.partitionsAhead)
+	if err != nil {
+		return nil, err
+	}
+	if result.Skipped {
+		return result, nil
+	}
+
+	result.IdempotencyKeys, err = s.idempotency.DeleteExpired(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// RunPruner prunes immediately, then every interval until ctx is cancelled
+func (s *archiveUsecase) RunPruner(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		result, err := s.Prune(ctx)
+		switch {
+		case err != nil:
+			logger.WithContext(ctx, s.log).Errorf("failed to prune archive: %v", err)
+		case result.Skipped:
+			logger.WithContext(ctx, s.log).Debug("archive pruned by another instance")
+		default:
+			logger.WithContext(ctx, s.log).Infof("Pruned %d archived messages, %d message statuses, %d webhook deliveries, %d idempotency keys and %d partitions",
+				result.ArchivedMessages, result.Messages, result.WebhookDeliveries, result.IdempotencyKeys, len(result.DroppedPartitions))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *archiveUsecase) defaultRetention(clientID string) *model.RetentionPolicy {
+	return &model.RetentionPolicy{ClientID: clientID, Retention: s.retention, Default: true}
+}
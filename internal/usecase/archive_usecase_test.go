@@ -0,0 +1,178 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"tenant/internal/model"
+	"tenant/internal/test/mockrepository"
+	"tenant/pkg/derrors"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestSearchArchive(t *testing.T) {
+	ctx := context.Background()
+	publishedAt := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+
+	// Each case starts with fresh mocks, the expectations capture the variables
+	var mockArchive *mockrepository.ArchiveRepository
+	var mockRepo *mockrepository.TenantRepository
+
+	var testCases = []struct {
+		caseName     string
+		query        *model.ArchiveQuery
+		expectations func()
+		results      func(page *model.ArchivePage, err error)
+	}{
+		{
+			caseName: "Search_NextPage",
+			query:    &model.ArchiveQuery{ClientID: "test-client", Limit: 2},
+			expectations: func() {
+				mockRepo.On("GetTenantByClientID", mock.Anything, "test-client").Return(&model.Tenant{ClientID: "test-client"}, nil)
+				mockArchive.On("Search", mock.Anything, mock.MatchedBy(func(query *model.ArchiveQuery) bool {
+					return query.Limit == 3
+				})).Return([]*model.ArchivedMessage{
+					{MessageID: "msg-3", PublishedAt: publishedAt},
+					{MessageID: "msg-2", PublishedAt: publishedAt},
+					{MessageID: "msg-1", PublishedAt: publishedAt},
+				}, nil)
+			},
+			results: func(page *model.ArchivePage, err error) {
+				assert.Nil(t, err)
+				assert.Len(t, page.Messages, 2)
+
+				cursor, err := model.ParseArchiveCursor(page.NextCursor)
+				assert.Nil(t, err)
+				assert.Equal(t, "msg-2", cursor.MessageID)
+				assert.True(t, publishedAt.Equal(cursor.PublishedAt))
+			},
+		},
+		{
+			caseName: "Search_LastPage",
+			query:    &model.ArchiveQuery{ClientID: "test-client"},
+			expectations: func() {
+				mockRepo.On("GetTenantByClientID", mock.Anything, "test-client").Return(&model.Tenant{ClientID: "test-client"}, nil)
+				mockArchive.On("Search", mock.Anything, mock.MatchedBy(func(query *model.ArchiveQuery) bool {
+					return query.Limit == defaultArchiveSearchLimit+1
+				})).Return([]*model.ArchivedMessage{{MessageID: "msg-1", PublishedAt: publishedAt}}, nil)
+			},
+			results: func(page *model.ArchivePage, err error) {
+				assert.Nil(t, err)
+				assert.Len(t, page.Messages, 1)
+				assert.Empty(t, page.NextCursor)
+			},
+		},
+		{
+			caseName: "Search_TenantNotFound",
+			query:    &model.ArchiveQuery{ClientID: "missing-client"},
+			expectations: func() {
+				mockRepo.On("GetTenantByClientID", mock.Anything, "missing-client").Return(nil, derrors.New(derrors.NotFound, "tenant not found"))
+			},
+			results: func(page *model.ArchivePage, err error) {
+				assert.Nil(t, page)
+				assert.True(t, derrors.IsErrCode(err, derrors.NotFound))
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.caseName, func(t *testing.T) {
+			mockArchive = new(mockrepository.ArchiveRepository)
+			mockRepo = new(mockrepository.TenantRepository)
+			archiveUsecase := NewArchiveUsecase(mockArchive, mockRepo, new(mockrepository.IdempotencyRepository), logrus.New(), ArchiveConfig{})
+
+			testCase.expectations()
+			page, err := archiveUsecase.Search(ctx, testCase.query)
+			testCase.results(page, err)
+
+			mockRepo.AssertExpectations(t)
+			mockArchive.AssertExpectations(t)
+		})
+	}
+}
+
+func TestGetRetention(t *testing.T) {
+	ctx := context.Background()
+	mockArchive := new(mockrepository.ArchiveRepository)
+	mockRepo := new(mockrepository.TenantRepository)
+	archiveUsecase := NewArchiveUsecase(mockArchive, mockRepo, new(mockrepository.IdempotencyRepository), logrus.New(), ArchiveConfig{Retention: 72 * time.Hour})
+
+	mockRepo.On("GetTenantByClientID", mock.Anything, mock.Anything).Return(&model.Tenant{}, nil)
+	mockArchive.On("GetRetention", mock.Anything, "custom-client").Return(&model.RetentionPolicy{ClientID: "custom-client", Retention: time.Hour}, nil).Once()
+	mockArchive.On("GetRetention", mock.Anything, "default-client").Return(nil, derrors.New(derrors.NotFound, "no rows")).Once()
+
+	policy, err := archiveUsecase.GetRetention(ctx, "custom-client")
+	assert.Nil(t, err)
+	assert.Equal(t, time.Hour, policy.Retention)
+	assert.False(t, policy.Default)
+
+	policy, err = archiveUsecase.GetRetention(ctx, "default-client")
+	assert.Nil(t, err)
+	assert.Equal(t, 72*time.Hour, policy.Retention)
+	assert.True(t, policy.Default)
+
+	_, err = archiveUsecase.SetRetention(ctx, "custom-client", time.Millisecond)
+	assert.True(t, derrors.IsErrCode(err, derrors.InvalidArgument))
+}
+
+func TestPruneArchive(t *testing.T) {
+	ctx := context.Background()
+
+	var testCases = []struct {
+		caseName     string
+		expectations func(mockArchive *mockrepository.ArchiveRepository, mockIdempotency *mockrepository.IdempotencyRepository)
+		results      func(result *model.PruneResult, err error)
+	}{
+		{
+			caseName: "Prune_Success",
+			expectations: func(mockArchive *mockrepository.ArchiveRepository, mockIdempotency *mockrepository.IdempotencyRepository) {
+				mockArchive.On("Prune", mock.Anything, mock.AnythingOfType("time.Time"), 30*24*time.Hour, 1).Return(&model.PruneResult{ArchivedMessages: 10}, nil).Once()
+				mockIdempotency.On("DeleteExpired", mock.Anything).Return(int64(4), nil).Once()
+			},
+			results: func(result *model.PruneResult, err error) {
+				assert.Nil(t, err)
+				assert.Equal(t, int64(10), result.ArchivedMessages)
+				assert.Equal(t, int64(4), result.IdempotencyKeys)
+			},
+		},
+		{
+			caseName: "Prune_SkippedWhileAnotherInstancePrunes",
+			expectations: func(mockArchive *mockrepository.ArchiveRepository, mockIdempotency *mockrepository.IdempotencyRepository) {
+				mockArchive.On("Prune", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(&model.PruneResult{Skipped: true}, nil).Once()
+			},
+			results: func(result *model.PruneResult, err error) {
+				assert.Nil(t, err)
+				assert.True(t, result.Skipped)
+			},
+		},
+		{
+			caseName: "Prune_Error",
+			expectations: func(mockArchive *mockrepository.ArchiveRepository, mockIdempotency *mockrepository.IdempotencyRepository) {
+				mockArchive.On("Prune", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil, errors.New("connection refused")).Once()
+			},
+			results: func(result *model.PruneResult, err error) {
+				assert.NotNil(t, err)
+				assert.Nil(t, result)
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.caseName, func(t *testing.T) {
+			mockArchive := new(mockrepository.ArchiveRepository)
+			mockIdempotency := new(mockrepository.IdempotencyRepository)
+			archiveUsecase := NewArchiveUsecase(mockArchive, new(mockrepository.TenantRepository), mockIdempotency, logrus.New(), ArchiveConfig{})
+
+			testCase.expectations(mockArchive, mockIdempotency)
+			result, err := archiveUsecase.Prune(ctx)
+			testCase.results(result, err)
+
+			mockArchive.AssertExpectations(t)
+			mockIdempotency.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,543 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"os"
+	"runtime/debug"
+	"slices"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"tenant/internal/model"
+	"tenant/internal/repository"
+	"tenant/internal/service/messaging"
+	"tenant/pkg/derrors"
+	"tenant/pkg/logger"
+	"tenant/pkg/metrics"
+
+	"github.com/sirupsen/logrus"
+)
+
+var consumerPaused = metrics.NewGauge("tenant_consumer_paused",
+	"Tenants whose consumption this worker paused while one of their webhooks is unavailable", "client_id")
+
+type ConsumerUsecase interface {
+	Sync(ctx context.Context) error
+	Run(ctx context.Context, interval time.Duration) error
+	Resync()
+	ConsumedTenants() []string
+	Assignments(ctx context.Context) (*model.Assignments, error)
+}
+
+// ConsumerConfig controls how tenants are spread across workers
+type ConsumerConfig struct {
+	// WorkerID identifies this worker in the lease table
+	WorkerID string
+
+	// Replicas is the maximum number of workers consuming each tenant queue
+	Replicas int
+
+	// LeaseTTL is how long a lease or heartbeat stays valid without renewal
+	LeaseTTL time.Duration
+
+	// MaxAttempts is how many times a message is processed before it is
+	// dead-lettered
+	MaxAttempts int
+}
+
+type consumerUsecase struct {
+	repo       repository.TenantRepository
+	leases     repository.LeaseRepository
+	messages   repository.MessageRepository
+	processors ProcessorUsecase
+	mq         messaging.Messagging
+	log        *logrus.Logger
+
+	worker      *model.Worker
+	replicas    int
+	leaseTTL    time.Duration
+	maxAttempts int
+
+	// running holds the client IDs whose queue this worker consumes
+	mu      sync.Mutex
+	running map[string]struct{}
+
+	// paused holds the client IDs whose consumption is held back, with the
+	// time it resumes. It has its own lock as consumers pause their tenant
+	// while Sync may be waiting for them to stop.
+	pauseMu sync.Mutex
+	paused  map[string]time.Time
+
+	// resync asks Run for a sync ahead of the next tick
+	resync chan struct{}
+}
+
+// NewConsumerUsecase initializes a new consumer usecase, consumed messages
+// go through the processor chain of their tenant
+func NewConsumerUsecase(repo repository.TenantRepository, leases repository.LeaseRepository, messages repository.MessageRepository, processors ProcessorUsecase, mq messaging.Messagging, log *logrus.Logger, cfg ConsumerConfig) ConsumerUsecase {
+	if cfg.Replicas <= 0 {
+		cfg.Replicas = 1
+	}
+	if cfg.LeaseTTL <= 0 {
+		cfg.LeaseTTL = 30 * time.Second
+	}
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = 3
+	}
+	hostname, _ := os.Hostname()
+
+	return &consumerUsecase{
+		repo:       repo,
+		leases:     leases,
+		messages:   messages,
+		processors: processors,
+		mq:         mq,
+		log:        log,
+		worker: &model.Worker{
+			ID:        cfg.WorkerID,
+			Hostname:  hostname,
+			StartedAt: time.Now(),
+		},
+		replicas:    cfg.Replicas,
+		leaseTTL:    cfg.LeaseTTL,
+		maxAttempts: cfg.MaxAttempts,
+		running:     make(map[string]struct{}),
+		paused:      make(map[string]time.Time),
+		resync:      make(chan struct{}, 1),
+	}
+}
+
+// Sync reconciles the running consumers with the tenants stored in Postgres.
+// Every tenant is assigned to at most Replicas of the live workers by
+// rendezvous hashing, so workers joining or leaving only move the tenants
+// they gain or lose. A worker consumes a tenant once it holds a lease on it,
+// and releases the lease as soon as the tenant is assigned elsewhere or
+// deleted. The consumer of a paused tenant is stopped until the pause ends,
+// the worker keeps its lease meanwhile.
+func (s *consumerUsecase) Sync(ctx context.Context) (err error) {
+	defer derrors.Wrap(&err, "Sync")
+
+	if err := s.leases.Heartbeat(ctx, s.worker, s.leaseTTL); err != nil {
+		return err
+	}
+
+	workers, err := s.leases.ListWorkers(ctx)
+	if err != nil {
+		return err
+	}
+	workerIDs := make([]string, 0, len(workers))
+	for _, worker := range workers {
+		workerIDs = append(workerIDs, worker.ID)
+	}
+
+	tenants, err := s.repo.ListTenants(ctx)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	desired := make(map[string]struct{}, len(tenants))
+	for _, tenant := range tenants {
+		clientID := tenant.ClientID
+		if !slices.Contains(assignWorkers(clientID, workerIDs, s.replicas), s.worker.ID) {
+			continue
+		}
+
+		acquired, err := s.leases.AcquireLease(ctx, clientID, s.worker.ID, s.leaseTTL, s.replicas)
+		if err != nil {
+			// Keep the current state, the lease is still valid until it expires
+			logger.WithContext(logger.WithClientID(ctx, clientID), s.log).Errorf("failed to acquire lease: %v", err)
+			if _, ok := s.running[clientID]; ok {
+				desired[clientID] = struct{}{}
+			} else if _, ok := s.pausedUntil(clientID); ok {
+				desired[clientID] = struct{}{}
+			}
+			continue
+		}
+		if !acquired {
+			// The previous holders have not released the tenant yet
+			continue
+		}
+		desired[clientID] = struct{}{}
+
+		if until, ok := s.pausedUntil(clientID); ok {
+			if _, ok := s.running[clientID]; !ok {
+				continue
+			}
+			if err := s.mq.StopQueue(processQueueName(clientID)); err != nil {
+				logger.WithContext(logger.WithClientID(ctx, clientID), s.log).Errorf("failed to stop consumer: %v", err)
+				continue
+			}
+			delete(s.running, clientID)
+			logger.WithContext(logger.WithClientID(ctx, clientID), s.log).Warnf("Paused consumer until %s", until.Format(time.RFC3339))
+			continue
+		}
+
+		if s.mq.IsConsuming(processQueueName(clientID)) {
+			continue
+		}
+		if err := s.startConsumer(clientID); err != nil {
+			// Keep going, the next sync retries the tenants that failed
+			logger.WithContext(logger.WithClientID(ctx, clientID), s.log).Errorf("failed to start consumer: %v", err)
+			continue
+		}
+		s.running[clientID] = struct{}{}
+	}
+
+	for clientID := range s.running {
+		if _, ok := desired[clientID]; ok {
+			continue
+		}
+		if err := s.mq.StopQueue(processQueueName(clientID)); err != nil {
+			logger.WithContext(logger.WithClientID(ctx, clientID), s.log).Errorf("failed to stop consumer: %v", err)
+			continue
+		}
+		delete(s.running, clientID)
+		if err := s.leases.ReleaseLease(ctx, clientID, s.worker.ID); err != nil {
+			logger.WithContext(logger.WithClientID(ctx, clientID), s.log).Errorf("failed to release lease: %v", err)
+		}
+		logger.WithContext(logger.WithClientID(ctx, clientID), s.log).Info("Stopped consumer of unassigned tenant")
+	}
+
+	// Paused tenants have no running consumer but still hold a lease
+	for _, clientID := range s.pausedTenants() {
+		if _, ok := desired[clientID]; ok {
+			continue
+		}
+		s.resume(clientID)
+		if err := s.leases.ReleaseLease(ctx, clientID, s.worker.ID); err != nil {
+			logger.WithContext(logger.WithClientID(ctx, clientID), s.log).Errorf("failed to release lease: %v", err)
+		}
+	}
+
+	return nil
+}
+
+// Run syncs the consumers immediately, then on every Resync and every
+// interval until ctx is cancelled, at which point every consumer is stopped
+// and the leases of this worker are released. The interval sync keeps leases
+// renewed and catches up on changes when tenant events are not delivered.
+func (s *consumerUsecase) Run(ctx context.Context, interval time.Duration) error {
+	logger.WithContext(ctx, s.log).Infof("Worker %s consuming with %d replicas per tenant", s.worker.ID, s.replicas)
+
+	if err := s.Sync(ctx); err != nil {
+		return err
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			s.stopAll()
+			return nil
+		case <-ticker.C:
+		case <-s.resync:
+		}
+
+		if err := s.Sync(ctx); err != nil {
+			logger.WithContext(ctx, s.log).Errorf("failed to sync consumers: %v", err)
+		}
+	}
+}
+
+// Resync schedules a sync without waiting for the next interval. It never
+// blocks, requests made while one is already pending are merged.
+func (s *consumerUsecase) Resync() {
+	select {
+	case s.resync <- struct{}{}:
+	default:
+	}
+}
+
+// ConsumedTenants returns the client IDs whose queue is consumed by this worker
+func (s *consumerUsecase) ConsumedTenants() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	clientIDs := make([]string, 0, len(s.running))
+	for clientID := range s.running {
+		clientIDs = append(clientIDs, clientID)
+	}
+	return clientIDs
+}
+
+// Assignments returns the live workers and the tenant leases they hold
+func (s *consumerUsecase) Assignments(ctx context.Context) (assignments *model.Assignments, err error) {
+	defer derrors.Wrap(&err, "Assignments")
+
+	workers, err := s.leases.ListWorkers(ctx)
+	if err != nil {
+		return nil, err
+	}
+	leases, err := s.leases.ListLeases(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &model.Assignments{Workers: workers, Leases: leases}, nil
+}
+
+func (s *consumerUsecase) stopAll() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for clientID := range s.running {
+		if err := s.mq.StopQueue(processQueueName(clientID)); err != nil {
+			s.log.Errorf("failed to stop consumer for tenant %s: %v", clientID, err)
+		}
+		delete(s.running, clientID)
+	}
+
+	// The run context is already cancelled
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := s.leases.ReleaseWorker(ctx, s.worker.ID); err != nil {
+		s.log.Errorf("failed to release leases of worker %s: %v", s.worker.ID, err)
+	}
+}
+
+// startConsumer starts the consumer of a tenant queue. The consumer outlives
+// the caller, so it must not inherit its cancellation or request ID.
+func (s *consumerUsecase) startConsumer(clientID string) error {
+	consumerCtx := logger.WithClientID(context.Background(), clientID)
+
+	return s.mq.StartQueue(consumerCtx, processQueueName(clientID), func(ctx context.Context, delivery *messaging.Delivery) error {
+		return s.handleDelivery(ctx, clientID, delivery)
+	})
+}
+
+// handleDelivery processes a message of a tenant and records its status
+// transitions. A failed message is republished with its attempt count
+// increased until MaxAttempts, then moved to the dead letter queue of the
+// tenant. Permanent failures are dead-lettered right away. The returned
+// error asks for the delivery to be requeued, when the message could not be
+// republished or the tenant is paused.
+func (s *consumerUsecase) handleDelivery(ctx context.Context, clientID string, delivery *messaging.Delivery) error {
+	if until, ok := s.pausedUntil(clientID); ok {
+		return fmt.Errorf("tenant %s is paused until %s", clientID, until.Format(time.RFC3339))
+	}
+	s.recordEvent(ctx, delivery, model.MessageDelivered, "")
+
+	err := s.process(ctx, clientID, delivery)
+	// Recording the outcome must not be cut short by the handler timeout
+	ctx = context.WithoutCancel(ctx)
+	if err == nil {
+		s.recordEvent(ctx, delivery, model.MessageProcessed, "")
+		return nil
+	}
+	if until, ok := pausedUntil(err); ok {
+		// Not the message's fault, the attempt does not count
+		s.pauseTenant(clientID, until)
+		logger.WithContext(ctx, s.log).Warnf("Paused tenant until %s, message %s requeued: %v", until.Format(time.RFC3339), delivery.MessageID, err)
+		return err
+	}
+	s.recordEvent(ctx, delivery, model.MessageFailed, err.Error())
+
+	if delivery.Attempt < s.maxAttempts && !isPermanent(err) {
+		retry := retryMessage(delivery, err)
+		retry.Headers[messaging.HeaderAttempt] = strconv.Itoa(delivery.Attempt + 1)
+		if err := s.mq.Publish(ctx, processQueueName(clientID), retry); err != nil {
+			return fmt.Errorf("failed to republish message %s: %w", delivery.MessageID, err)
+		}
+		logger.WithContext(ctx, s.log).Warnf("Message %s failed attempt %d of %d: %v", delivery.MessageID, delivery.Attempt, s.maxAttempts, err)
+		return nil
+	}
+
+	if err := s.mq.Publish(ctx, deadLetterQueueName(clientID), retryMessage(delivery, err)); err != nil {
+		return fmt.Errorf("failed to dead-letter message %s: %w", delivery.MessageID, err)
+	}
+	s.recordEvent(ctx, delivery, model.MessageDeadLettered, err.Error())
+	logger.WithContext(ctx, s.log).Errorf("Message %s dead-lettered after %d attempts: %v", delivery.MessageID, delivery.Attempt, err)
+	return nil
+}
+
+// process handles the payload of a message. A panic is a permanent failure,
+// so the message is dead-lettered instead of being dropped or requeued
+// forever.
+func (s *consumerUsecase) process(ctx context.Context, clientID string, delivery *messaging.Delivery) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			panicErr := &messaging.PanicError{Value: r, Stack: debug.Stack()}
+			logger.WithContext(ctx, s.log).WithField("stack", string(panicErr.Stack)).
+				Errorf("recovered from panic processing message %s: %v", delivery.MessageID, r)
+			err = permanent(panicErr)
+		}
+	}()
+	return s.processors.Process(ctx, clientID, delivery)
+}
+
+// recordEvent records a status transition of a delivered message. Tracking
+// is best effort, it never fails the processing of the message.
+func (s *consumerUsecase) recordEvent(ctx context.Context, delivery *messaging.Delivery, status, detail string) {
+	if delivery.MessageID == "" {
+		return
+	}
+	err := s.messages.RecordEvents(ctx, &model.MessageEvent{
+		MessageID: delivery.MessageID,
+		Status:    status,
+		Attempt:   delivery.Attempt,
+		Detail:    detail,
+	})
+	if err != nil {
+		logger.WithContext(ctx, s.log).Errorf("failed to record message %s as %s: %v", delivery.MessageID, status, err)
+	}
+}
+
+// pauseTenant holds back the consumption of a tenant until a time. The
+// consumer is stopped right away, so the broker does not redeliver the
+// requeued messages to it, and started again by the sync following the
+// pause.
+func (s *consumerUsecase) pauseTenant(clientID string, until time.Time) {
+	s.pauseMu.Lock()
+	if until.After(s.paused[clientID]) {
+		s.paused[clientID] = until
+	}
+	s.pauseMu.Unlock()
+	consumerPaused.Set(1, clientID)
+
+	// Deliveries already received are handled before the consumer stops,
+	// they are requeued as the tenant is paused
+	if err := s.mq.StopQueue(processQueueName(clientID)); err != nil {
+		s.log.Errorf("failed to stop consumer of paused tenant %s: %v", clientID, err)
+	}
+
+	time.AfterFunc(time.Until(until), s.Resync)
+	s.Resync()
+}
+
+// pausedUntil returns when the pause of a tenant ends, forgetting pauses
+// that already ended
+func (s *consumerUsecase) pausedUntil(clientID string) (time.Time, bool) {
+	s.pauseMu.Lock()
+	until, ok := s.paused[clientID]
+	s.pauseMu.Unlock()
+	if !ok {
+		return time.Time{}, false
+	}
+	if !time.Now().Before(until) {
+		s.resume(clientID)
+		return time.Time{}, false
+	}
+	return until, true
+}
+
+func (s *consumerUsecase) pausedTenants() []string {
+	s.pauseMu.Lock()
+	defer s.pauseMu.Unlock()
+
+	clientIDs := make([]string, 0, len(s.paused))
+	for clientID := range s.paused {
+		clientIDs = append(clientIDs, clientID)
+	}
+	return clientIDs
+}
+
+func (s *consumerUsecase) resume(clientID string) {
+	s.pauseMu.Lock()
+	delete(s.paused, clientID)
+	s.pauseMu.Unlock()
+	consumerPaused.Delete(clientID)
+}
+
+// pausedError is a processing failure that holds back the messages of a
+// tenant until a time
+type pausedError struct {
+	err   error
+	until time.Time
+}
+
+func (e *pausedError) Error() string { return e.err.Error() }
+func (e *pausedError) Unwrap() error { return e.err }
+
+// pause marks err as a failure that pauses the tenant until a time, its
+// message is requeued without counting the attempt
+func pause(err error, until time.Time) error {
+	return &pausedError{err: err, until: until}
+}
+
+func pausedUntil(err error) (time.Time, bool) {
+	var pausedErr *pausedError
+	if errors.As(err, &pausedErr) {
+		return pausedErr.until, true
+	}
+	return time.Time{}, false
+}
+
+// permanentError marks a processing failure that retrying cannot fix
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
+// permanent marks err as a failure that is dead-lettered without retrying
+func permanent(err error) error {
+	return &permanentError{err: err}
+}
+
+func isPermanent(err error) bool {
+	var permanentErr *permanentError
+	return errors.As(err, &permanentErr)
+}
+
+// retryMessage rebuilds a delivered message for publishing, keeping its ID
+// and AMQP properties and recording why it failed
+func retryMessage(delivery *messaging.Delivery, cause error) messaging.Message {
+	headers := make(map[string]string, len(delivery.Headers)+1)
+	for key, value := range delivery.Headers {
+		headers[key] = value
+	}
+	headers[messaging.HeaderError] = cause.Error()
+
+	return messaging.Message{
+		ID:            delivery.MessageID,
+		Body:          json.RawMessage(delivery.Body),
+		Headers:       headers,
+		CorrelationID: delivery.CorrelationID,
+		ContentType:   delivery.ContentType,
+		Priority:      delivery.Priority,
+	}
+}
+
+// assignWorkers picks the n workers with the highest rendezvous hash for a
+// tenant. Every worker computes the same result from the same worker list.
+func assignWorkers(clientID string, workerIDs []string, n int) []string {
+	type scored struct {
+		id    string
+		score uint64
+	}
+
+	scores := make([]scored, 0, len(workerIDs))
+	for _, id := range workerIDs {
+		h := fnv.New64a()
+		h.Write([]byte(clientID))
+		h.Write([]byte{0})
+		h.Write([]byte(id))
+		scores = append(scores, scored{id: id, score: h.Sum64()})
+	}
+	sort.Slice(scores, func(i, j int) bool {
+		if scores[i].score != scores[j].score {
+			return scores[i].score > scores[j].score
+		}
+		return scores[i].id < scores[j].id
+	})
+
+	if n > len(scores) {
+		n = len(scores)
+	}
+	assigned := make([]string, 0, n)
+	for _, s := range scores[:n] {
+		assigned = append(assigned, s.id)
+	}
+	return assigned
+}
@@ -0,0 +1,401 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"tenant/internal/model"
+	"tenant/internal/service/messaging"
+	"tenant/internal/test/mockrepository"
+	"tenant/internal/test/mockservice"
+	"tenant/internal/test/mockusecase"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestSyncConsumers(t *testing.T) {
+	type params struct {
+		workers []string
+		running []string
+		paused  map[string]time.Time
+	}
+
+	ctx := context.Background()
+	// Each case starts with fresh mocks, the expectations capture the variables
+	var mockRepo *mockrepository.TenantRepository
+	var mockLeases *mockrepository.LeaseRepository
+	var mockMQ *mockservice.Messagging
+
+	var testCases = []struct {
+		caseName     string
+		params       params
+		expectations func(params params)
+		results      func(consumed []string, err error)
+	}{
+		{
+			caseName: "Sync_StartsNewTenants",
+			params: params{
+				workers: []string{"worker-1"},
+			},
+			expectations: func(params params) {
+				mockRepo.On("ListTenants", mock.Anything).Return([]*model.Tenant{{ClientID: "client-a"}, {ClientID: "client-b"}}, nil)
+				mockLeases.On("AcquireLease", mock.Anything, "client-a", "worker-1", mock.Anything, 1).Return(true, nil)
+				mockLeases.On("AcquireLease", mock.Anything, "client-b", "worker-1", mock.Anything, 1).Return(true, nil)
+				mockMQ.On("IsConsuming", "client-a.process").Return(true)
+				mockMQ.On("IsConsuming", "client-b.process").Return(false)
+				mockMQ.On("StartQueue", mock.Anything, "client-b.process", mock.Anything).Return(nil)
+			},
+			results: func(consumed []string, err error) {
+				assert.Nil(t, err)
+				assert.ElementsMatch(t, []string{"client-b"}, consumed)
+			},
+		},
+		{
+			caseName: "Sync_StopsDeletedTenants",
+			params: params{
+				workers: []string{"worker-1"},
+				running: []string{"client-a", "client-deleted"},
+			},
+			expectations: func(params params) {
+				mockRepo.On("ListTenants", mock.Anything).Return([]*model.Tenant{{ClientID: "client-a"}}, nil)
+				mockLeases.On("AcquireLease", mock.Anything, "client-a", "worker-1", mock.Anything, 1).Return(true, nil)
+				mockMQ.On("IsConsuming", "client-a.process").Return(true)
+				mockMQ.On("StopQueue", "client-deleted.process").Return(nil)
+				mockLeases.On("ReleaseLease", mock.Anything, "client-deleted", "worker-1").Return(nil)
+			},
+			results: func(consumed []string, err error) {
+				assert.Nil(t, err)
+				assert.ElementsMatch(t, []string{"client-a"}, consumed)
+			},
+		},
+		{
+			caseName: "Sync_WaitsForLeaseHolders",
+			params: params{
+				workers: []string{"worker-1"},
+			},
+			expectations: func(params params) {
+				mockRepo.On("ListTenants", mock.Anything).Return([]*model.Tenant{{ClientID: "client-a"}}, nil)
+				mockLeases.On("AcquireLease", mock.Anything, "client-a", "worker-1", mock.Anything, 1).Return(false, nil)
+			},
+			results: func(consumed []string, err error) {
+				assert.Nil(t, err)
+				assert.Empty(t, consumed)
+			},
+		},
+		{
+			caseName: "Sync_HandsOverReassignedTenants",
+			params: params{
+				workers: []string{"worker-1", "worker-2"},
+				running: []string{"client-a", "client-c"},
+			},
+			expectations: func(params params) {
+				// With both workers live, client-a hashes to worker-2 and client-c to worker-1
+				mockRepo.On("ListTenants", mock.Anything).Return([]*model.Tenant{{ClientID: "client-a"}, {ClientID: "client-c"}}, nil)
+				mockLeases.On("AcquireLease", mock.Anything, "client-c", "worker-1", mock.Anything, 1).Return(true, nil)
+				mockMQ.On("IsConsuming", "client-c.process").Return(true)
+				mockMQ.On("StopQueue", "client-a.process").Return(nil)
+				mockLeases.On("ReleaseLease", mock.Anything, "client-a", "worker-1").Return(nil)
+			},
+			results: func(consumed []string, err error) {
+				assert.Nil(t, err)
+				assert.ElementsMatch(t, []string{"client-c"}, consumed)
+			},
+		},
+		{
+			caseName: "Sync_StopsPausedTenants",
+			params: params{
+				workers: []string{"worker-1"},
+				running: []string{"client-a"},
+				paused:  map[string]time.Time{"client-a": time.Now().Add(time.Minute)},
+			},
+			expectations: func(params params) {
+				// The lease is kept while the tenant is paused
+				mockRepo.On("ListTenants", mock.Anything).Return([]*model.Tenant{{ClientID: "client-a"}}, nil)
+				mockLeases.On("AcquireLease", mock.Anything, "client-a", "worker-1", mock.Anything, 1).Return(true, nil)
+				mockMQ.On("StopQueue", "client-a.process").Return(nil)
+			},
+			results: func(consumed []string, err error) {
+				assert.Nil(t, err)
+				assert.Empty(t, consumed)
+			},
+		},
+		{
+			caseName: "Sync_ResumesTenantsAfterPause",
+			params: params{
+				workers: []string{"worker-1"},
+				paused:  map[string]time.Time{"client-a": time.Now().Add(-time.Second)},
+			},
+			expectations: func(params params) {
+				mockRepo.On("ListTenants", mock.Anything).Return([]*model.Tenant{{ClientID: "client-a"}}, nil)
+				mockLeases.On("AcquireLease", mock.Anything, "client-a", "worker-1", mock.Anything, 1).Return(true, nil)
+				mockMQ.On("IsConsuming", "client-a.process").Return(false)
+				mockMQ.On("StartQueue", mock.Anything, "client-a.process", mock.Anything).Return(nil)
+			},
+			results: func(consumed []string, err error) {
+				assert.Nil(t, err)
+				assert.ElementsMatch(t, []string{"client-a"}, consumed)
+			},
+		},
+		{
+			caseName: "Sync_ReleasesPausedTenantsReassigned",
+			params: params{
+				workers: []string{"worker-1"},
+				paused:  map[string]time.Time{"client-deleted": time.Now().Add(time.Minute)},
+			},
+			expectations: func(params params) {
+				mockRepo.On("ListTenants", mock.Anything).Return([]*model.Tenant{}, nil)
+				mockLeases.On("ReleaseLease", mock.Anything, "client-deleted", "worker-1").Return(nil)
+			},
+			results: func(consumed []string, err error) {
+				assert.Nil(t, err)
+				assert.Empty(t, consumed)
+			},
+		},
+		{
+			caseName: "Sync_FailToStartQueue",
+			params: params{
+				workers: []string{"worker-1"},
+			},
+			expectations: func(params params) {
+				mockRepo.On("ListTenants", mock.Anything).Return([]*model.Tenant{{ClientID: "client-a"}}, nil)
+				mockLeases.On("AcquireLease", mock.Anything, "client-a", "worker-1", mock.Anything, 1).Return(true, nil)
+				mockMQ.On("IsConsuming", "client-a.process").Return(false)
+				mockMQ.On("StartQueue", mock.Anything, "client-a.process", mock.Anything).Return(errors.New("channel closed"))
+			},
+			results: func(consumed []string, err error) {
+				assert.Nil(t, err)
+				assert.Empty(t, consumed)
+			},
+		},
+		{
+			caseName: "Sync_FailToListTenants",
+			params: params{
+				workers: []string{"worker-1"},
+			},
+			expectations: func(params params) {
+				mockRepo.On("ListTenants", mock.Anything).Return(nil, errors.New("connection refused"))
+			},
+			results: func(consumed []string, err error) {
+				assert.NotNil(t, err)
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.caseName, func(t *testing.T) {
+			mockRepo, mockMQ = mockInit()
+			mockLeases = new(mockrepository.LeaseRepository)
+
+			consumer := NewConsumerUsecase(mockRepo, mockLeases, new(mockrepository.MessageRepository), new(mockusecase.ProcessorUsecase), mockMQ, logrus.New(), ConsumerConfig{WorkerID: "worker-1"}).(*consumerUsecase)
+			for _, clientID := range testCase.params.running {
+				consumer.running[clientID] = struct{}{}
+			}
+			for clientID, until := range testCase.params.paused {
+				consumer.paused[clientID] = until
+			}
+
+			workers := make([]*model.Worker, 0, len(testCase.params.workers))
+			for _, id := range testCase.params.workers {
+				workers = append(workers, &model.Worker{ID: id})
+			}
+			mockLeases.On("Heartbeat", mock.Anything, mock.AnythingOfType("*model.Worker"), mock.Anything).Return(nil)
+			mockLeases.On("ListWorkers", mock.Anything).Return(workers, nil)
+
+			testCase.expectations(testCase.params)
+			err := consumer.Sync(ctx)
+			testCase.results(consumer.ConsumedTenants(), err)
+
+			mockRepo.AssertExpectations(t)
+			mockLeases.AssertExpectations(t)
+			mockMQ.AssertExpectations(t)
+		})
+	}
+}
+
+func TestAssignWorkers(t *testing.T) {
+	workers := []string{"worker-1", "worker-2", "worker-3", "worker-4"}
+
+	assigned := assignWorkers("client-a", workers, 2)
+	assert.Len(t, assigned, 2)
+	assert.Equal(t, assigned, assignWorkers("client-a", []string{"worker-4", "worker-3", "worker-2", "worker-1"}, 2))
+
+	assert.Len(t, assignWorkers("client-a", workers, 10), len(workers))
+	assert.Empty(t, assignWorkers("client-a", nil, 1))
+
+	// Removing a worker only moves the tenants that worker held
+	for _, clientID := range []string{"client-a", "client-b", "client-c", "client-d", "client-e"} {
+		owner := assignWorkers(clientID, workers, 1)[0]
+		if owner == "worker-4" {
+			continue
+		}
+		assert.Equal(t, owner, assignWorkers(clientID, workers[:3], 1)[0])
+	}
+}
+
+func TestResyncConsumers(t *testing.T) {
+	mockRepo, mockMQ := mockInit()
+	consumer := NewConsumerUsecase(mockRepo, new(mockrepository.LeaseRepository), new(mockrepository.MessageRepository), new(mockusecase.ProcessorUsecase), mockMQ, logrus.New(), ConsumerConfig{}).(*consumerUsecase)
+
+	// Pending requests are merged and never block the caller
+	consumer.Resync()
+	consumer.Resync()
+	assert.Len(t, consumer.resync, 1)
+}
+
+func TestHandleDelivery(t *testing.T) {
+	ctx := context.Background()
+	mockRepo, mockMQ := mockInit()
+	mockMessages := new(mockrepository.MessageRepository)
+	mockProcessors := new(mockusecase.ProcessorUsecase)
+	consumer := NewConsumerUsecase(mockRepo, new(mockrepository.LeaseRepository), mockMessages, mockProcessors, mockMQ, logrus.New(), ConsumerConfig{}).(*consumerUsecase)
+
+	delivery := &messaging.Delivery{MessageID: "msg-1", Body: []byte(`"payload"`), Attempt: 1}
+	mockProcessors.On("Process", mock.Anything, "client-a", delivery).Return(nil).Once()
+	mockMessages.On("RecordEvents", mock.Anything, &model.MessageEvent{MessageID: "msg-1", Status: model.MessageDelivered, Attempt: 1}).Return(nil).Once()
+	// Tracking is best effort, the message is still acked
+	mockMessages.On("RecordEvents", mock.Anything, &model.MessageEvent{MessageID: "msg-1", Status: model.MessageProcessed, Attempt: 1}).Return(errors.New("connection refused")).Once()
+
+	assert.Nil(t, consumer.handleDelivery(ctx, "client-a", delivery))
+	mockProcessors.AssertExpectations(t)
+	mockMessages.AssertExpectations(t)
+	mockMQ.AssertExpectations(t)
+}
+
+func TestHandleFailedDelivery(t *testing.T) {
+	ctx := context.Background()
+
+	var testCases = []struct {
+		caseName     string
+		attempt      int
+		err          error
+		panics       bool
+		expectations func(mockMQ *mockservice.Messagging)
+	}{
+		{
+			caseName: "Failed_Retried",
+			attempt:  1,
+			err:      errors.New("webhook responded 503"),
+			expectations: func(mockMQ *mockservice.Messagging) {
+				mockMQ.On("Publish", mock.Anything, "client-a.process", mock.MatchedBy(func(message messaging.Message) bool {
+					return message.ID == "msg-1" && message.Headers[messaging.HeaderAttempt] == "2"
+				})).Return(nil).Once()
+			},
+		},
+		{
+			caseName: "Failed_DeadLetteredAfterLastAttempt",
+			attempt:  3,
+			err:      errors.New("webhook responded 503"),
+			expectations: func(mockMQ *mockservice.Messagging) {
+				mockMQ.On("Publish", mock.Anything, "client-a.dlq", mock.Anything).Return(nil).Once()
+			},
+		},
+		{
+			caseName: "Failed_PanicDeadLettered",
+			attempt:  1,
+			panics:   true,
+			expectations: func(mockMQ *mockservice.Messagging) {
+				mockMQ.On("Publish", mock.Anything, "client-a.dlq", mock.MatchedBy(func(message messaging.Message) bool {
+					return message.ID == "msg-1" && message.Headers[messaging.HeaderError] == "handler panicked: assignment to entry in nil map"
+				})).Return(nil).Once()
+			},
+		},
+		{
+			caseName: "Failed_PermanentDeadLetteredRightAway",
+			attempt:  1,
+			err:      permanent(errors.New("webhook responded 400")),
+			expectations: func(mockMQ *mockservice.Messagging) {
+				mockMQ.On("Publish", mock.Anything, "client-a.dlq", mock.MatchedBy(func(message messaging.Message) bool {
+					return message.Headers[messaging.HeaderError] == "webhook responded 400"
+				})).Return(nil).Once()
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.caseName, func(t *testing.T) {
+			mockRepo, mockMQ := mockInit()
+			mockMessages := new(mockrepository.MessageRepository)
+			mockProcessors := new(mockusecase.ProcessorUsecase)
+			consumer := NewConsumerUsecase(mockRepo, new(mockrepository.LeaseRepository), mockMessages, mockProcessors, mockMQ, logrus.New(), ConsumerConfig{MaxAttempts: 3}).(*consumerUsecase)
+
+			delivery := &messaging.Delivery{MessageID: "msg-1", Body: []byte(`"payload"`), Attempt: testCase.attempt}
+			call := mockProcessors.On("Process", mock.Anything, "client-a", delivery).Return(testCase.err).Once()
+			if testCase.panics {
+				call.Run(func(args mock.Arguments) {
+					panic("assignment to entry in nil map")
+				})
+			}
+			mockMessages.On("RecordEvents", mock.Anything, mock.Anything).Return(nil)
+			testCase.expectations(mockMQ)
+
+			assert.Nil(t, consumer.handleDelivery(ctx, "client-a", delivery))
+			mockProcessors.AssertExpectations(t)
+			mockMQ.AssertExpectations(t)
+			if testCase.panics {
+				mockMessages.AssertCalled(t, "RecordEvents", mock.Anything, mock.MatchedBy(func(event *model.MessageEvent) bool {
+					return event.Status == model.MessageDeadLettered
+				}))
+			}
+		})
+	}
+}
+
+func TestHandlePausedDelivery(t *testing.T) {
+	ctx := context.Background()
+	mockRepo, mockMQ := mockInit()
+	mockMessages := new(mockrepository.MessageRepository)
+	mockProcessors := new(mockusecase.ProcessorUsecase)
+	consumer := NewConsumerUsecase(mockRepo, new(mockrepository.LeaseRepository), mockMessages, mockProcessors, mockMQ, logrus.New(), ConsumerConfig{MaxAttempts: 3}).(*consumerUsecase)
+
+	until := time.Now().Add(time.Minute)
+	delivery := &messaging.Delivery{MessageID: "msg-1", Body: []byte(`"payload"`), Attempt: 3}
+	mockProcessors.On("Process", mock.Anything, "client-a", delivery).Return(pause(errors.New("webhook hook-1 is unavailable"), until)).Once()
+	mockMessages.On("RecordEvents", mock.Anything, mock.Anything).Return(nil).Once()
+	// The consumer stops at once instead of receiving the requeued message again
+	mockMQ.On("StopQueue", "client-a.process").Return(nil).Once()
+
+	// The message is requeued without counting the attempt or dead-lettering
+	assert.NotNil(t, consumer.handleDelivery(ctx, "client-a", delivery))
+	paused, ok := consumer.pausedUntil("client-a")
+	assert.True(t, ok)
+	assert.Equal(t, until, paused)
+
+	// Messages received until the consumer stops are requeued untouched
+	assert.NotNil(t, consumer.handleDelivery(ctx, "client-a", &messaging.Delivery{MessageID: "msg-2", Attempt: 1}))
+
+	mockProcessors.AssertExpectations(t)
+	mockMessages.AssertExpectations(t)
+	mockMQ.AssertExpectations(t)
+}
+
+func TestRetryMessage(t *testing.T) {
+	delivery := &messaging.Delivery{
+		MessageID:     "msg-1",
+		CorrelationID: "order-42",
+		ContentType:   "application/json",
+		Priority:      5,
+		Headers:       map[string]string{"x-source": "test", messaging.HeaderAttempt: "2"},
+		Body:          []byte(`{"id":1}`),
+		Attempt:       2,
+	}
+
+	message := retryMessage(delivery, errors.New("boom"))
+	assert.Equal(t, "msg-1", message.ID)
+	assert.Equal(t, "order-42", message.CorrelationID)
+	assert.Equal(t, "application/json", message.ContentType)
+	assert.Equal(t, uint8(5), message.Priority)
+	assert.Equal(t, "test", message.Headers["x-source"])
+	assert.Equal(t, "boom", message.Headers[messaging.HeaderError])
+
+	// The body is republished as is, not encoded again
+	body, err := json.Marshal(message.Body)
+	assert.Nil(t, err)
+	assert.JSONEq(t, `{"id":1}`, string(body))
+
+	// The delivered headers are left untouched
+	assert.NotContains(t, delivery.Headers, messaging.HeaderError)
+}
@@ -0,0 +1,361 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"text/template"
+	"time"
+
+	"tenant/internal/model"
+	"tenant/internal/repository"
+	"tenant/internal/service/messaging"
+	"tenant/pkg/derrors"
+	"tenant/pkg/logger"
+
+	"github.com/robfig/cron/v3"
+	"github.com/segmentio/ksuid"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// cronLeaderRole is the leader lease held by the worker running schedules
+	cronLeaderRole = "cron_scheduler"
+
+	// cronBatchSize is how many due schedules are run at once
+	cronBatchSize = 100
+)
+
+type CronUsecase interface {
+	CreateSchedule(ctx context.Context, schedule *model.CronSchedule) (*model.CronSchedule, error)
+	GetSchedule(ctx context.Context, clientID, scheduleID string) (*model.CronSchedule, error)
+	ListSchedules(ctx context.Context, clientID string) ([]*model.CronSchedule, error)
+	UpdateSchedule(ctx context.Context, schedule *model.CronSchedule) (*model.CronSchedule, error)
+	DeleteSchedule(ctx context.Context, clientID, scheduleID string) error
+	RunDue(ctx context.Context) (int, error)
+	RunScheduler(ctx context.Context, interval time.Duration)
+}
+
+// CronConfig tunes the cron scheduler
+type CronConfig struct {
+	// WorkerID identifies this process in the leader election
+	WorkerID string
+
+	// LeaderTTL hands the scheduler over to another worker once its leader
+	// stopped renewing its lease for this long
+	LeaderTTL time.Duration
+}
+
+type cronUsecase struct {
+	repo    repository.CronRepository
+	leases  repository.LeaseRepository
+	tenants repository.TenantRepository
+	tenant  TenantUsecase
+	log     *logrus.Logger
+
+	workerID  string
+	leaderTTL time.Duration
+}
+
+// NewCronUsecase initializes a new cron usecase, due schedules are published
+// through tenant
+func NewCronUsecase(repo repository.CronRepository, leases repository.LeaseRepository, tenants repository.TenantRepository, tenant TenantUsecase, log *logrus.Logger, cfg CronConfig) CronUsecase {
+	if cfg.LeaderTTL <= 0 {
+		cfg.LeaderTTL = 30 * time.Second
+	}
+	return &cronUsecase{
+		repo:      repo,
+		leases:    leases,
+		tenants:   tenants,
+		tenant:    tenant,
+		log:       log,
+		workerID:  cfg.WorkerID,
+		leaderTTL: cfg.LeaderTTL,
+	}
+}
+
+// CreateSchedule validates and stores a new schedule of a tenant
+func (s *cronUsecase) CreateSchedule(ctx context.Context, schedule *model.CronSchedule) (_ *model.CronSchedule, err error) {
+	defer derrors.Wrap(&err, "CreateSchedule(%q)", schedule.ClientID)
+
+	if _, err := s.tenants.GetTenantByClientID(ctx, schedule.ClientID); err != nil {
+		return nil, err
+	}
+	if err := prepareSchedule(schedule, time.Now()); err != nil {
+		return nil, err
+	}
+
+	schedule.ID = ksuid.New().String()
+	if err := s.repo.CreateSchedule(ctx, schedule); err != nil {
+		if derrors.IsErrCode(err, derrors.Duplicate) {
+			return nil, derrors.New(derrors.Duplicate, "a schedule named %q already exists", schedule.Name)
+		}
+		return nil, err
+	}
+	return schedule, nil
+}
+
+func (s *cronUsecase) GetSchedule(ctx context.Context, clientID, scheduleID string) (schedule *model.CronSchedule, err error) {
+	defer derrors.Wrap(&err, "GetSchedule(%q, %q)", clientID, scheduleID)
+
+	schedule, err = s.repo.GetSchedule(ctx, clientID, scheduleID)
+	if derrors.IsErrCode(err, derrors.NotFound) {
+		return nil, derrors.New(derrors.NotFound, "schedule not found")
+	}
+	return schedule, err
+}
+
+func (s *cronUsecase) ListSchedules(ctx context.Context, clientID string) (schedules []*model.CronSchedule, err error) {
+	defer derrors.Wrap(&err, "ListSchedules(%q)", clientID)
+
+	if _, err := s.tenants.GetTenantByClientID(ctx, clientID); err != nil {
+		return nil, err
+	}
+	return s.repo.ListSchedules(ctx, clientID)
+}
+
+// UpdateSchedule replaces the definition of a schedule, its next run is
+// computed again from now
+func (s *cronUsecase) UpdateSchedule(ctx context.Context, schedule *model.CronSchedule) (_ *model.CronSchedule, err error) {
+	defer derrors.Wrap(&err, "UpdateSchedule(%q, %q)", schedule.ClientID, schedule.ID)
+
+	current, err := s.GetSchedule(ctx, schedule.ClientID, schedule.ID)
+	if err != nil {
+		return nil, err
+	}
+	if err := prepareSchedule(schedule, time.Now()); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.UpdateSchedule(ctx, schedule); err != nil {
+		if derrors.IsErrCode(err, derrors.Duplicate) {
+			return nil, derrors.New(derrors.Duplicate, "a schedule named %q already exists", schedule.Name)
+		}
+		return nil, err
+	}
+	schedule.LastRunAt, schedule.LastMessageID, schedule.LastError = current.LastRunAt, current.LastMessageID, current.LastError
+	return schedule, nil
+}
+
+func (s *cronUsecase) DeleteSchedule(ctx context.Context, clientID, scheduleID string) (err error) {
+	defer derrors.Wrap(&err, "DeleteSchedule(%q, %q)", clientID, scheduleID)
+
+	err = s.repo.DeleteSchedule(ctx, clientID, scheduleID)
+	if derrors.IsErrCode(err, derrors.NotFound) {
+		return derrors.New(derrors.NotFound, "schedule not found")
+	}
+	return err
+}
+
+// RunDue publishes the payloads of the schedules that are due and returns
+// how many were published. Runs missed while no scheduler was running are
+// skipped, a schedule only runs once for its most overdue time.
+func (s *cronUsecase) RunDue(ctx context.Context) (published int, err error) {
+	defer derrors.Wrap(&err, "RunDue")
+
+	now := time.Now()
+	schedules, err := s.repo.ListDue(ctx, now, cronBatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, schedule := range schedules {
+		dueAt := *schedule.NextRunAt
+		runCtx := logger.WithClientID(ctx, schedule.ClientID)
+
+		messageID, err := s.run(runCtx, schedule, dueAt)
+		schedule.LastRunAt = &now
+		schedule.LastMessageID, schedule.LastError = messageID, ""
+		if err != nil {
+			schedule.LastError = err.Error()
+			logger.WithContext(runCtx, s.log).Errorf("schedule %s failed: %v", schedule.ID, err)
+		} else {
+			published++
+		}
+
+		// The schedule was valid when stored, a failure here leaves it
+		// without a next run rather than running it in a loop
+		next, _ := nextRun(schedule, now)
+		schedule.NextRunAt = next
+		if err := s.repo.RecordRun(ctx, schedule, dueAt); err != nil {
+			return published, err
+		}
+	}
+	return published, nil
+}
+
+// run publishes the payload of a schedule for its run due at dueAt. The
+// idempotency key makes a run retried by another leader publish only once.
+func (s *cronUsecase) run(ctx context.Context, schedule *model.CronSchedule, dueAt time.Time) (string, error) {
+	body, err := renderPayload(schedule.Payload, model.CronRun{
+		ScheduleID:  schedule.ID,
+		Name:        schedule.Name,
+		ClientID:    schedule.ClientID,
+		ScheduledAt: dueAt.UTC().Format(time.RFC3339),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	headers := make(map[string]string, len(schedule.Headers)+1)
+	for key, value := range schedule.Headers {
+		headers[key] = value
+	}
+	headers[messaging.HeaderScheduleID] = schedule.ID
+
+	return s.tenant.ProcessPayload(ctx, schedule.ClientID, &model.Payload{
+		Body:           body,
+		Headers:        headers,
+		IdempotencyKey: fmt.Sprintf("cron:%s:%d", schedule.ID, dueAt.Unix()),
+	})
+}
+
+// RunScheduler runs the due schedules every interval until ctx is cancelled,
+// while this worker is the elected leader
+func (s *cronUsecase) RunScheduler(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	var leader bool
+	defer func() {
+		if !leader {
+			return
+		}
+		// Let another worker take over right away
+		releaseCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := s.leases.ReleaseLeadership(releaseCtx, cronLeaderRole, s.workerID); err != nil {
+			logger.WithContext(ctx, s.log).Errorf("failed to release cron scheduler leadership: %v", err)
+		}
+	}()
+
+	for {
+		acquired, err := s.leases.AcquireLeadership(ctx, cronLeaderRole, s.workerID, s.leaderTTL)
+		if err != nil {
+			logger.WithContext(ctx, s.log).Errorf("failed to acquire cron scheduler leadership: %v", err)
+		}
+		if acquired != leader {
+			leader = acquired
+			if leader {
+				logger.WithContext(ctx, s.log).Info("Running cron schedules as the elected leader")
+			} else {
+				logger.WithContext(ctx, s.log).Info("Lost cron scheduler leadership")
+			}
+		}
+
+		if leader {
+			if published, err := s.RunDue(ctx); err != nil {
+				logger.WithContext(ctx, s.log).Errorf("failed to run cron schedules: %v", err)
+			} else if published > 0 {
+				logger.WithContext(ctx, s.log).Infof("Published %d scheduled payloads", published)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// prepareSchedule validates a schedule and computes its next run after now
+func prepareSchedule(schedule *model.CronSchedule, now time.Time) error {
+	schedule.Name = strings.TrimSpace(schedule.Name)
+	if schedule.Name == "" || len(schedule.Name) > 255 {
+		return derrors.New(derrors.InvalidArgument, "a schedule needs a name of at most 255 characters")
+	}
+	if schedule.Timezone == "" {
+		schedule.Timezone = "UTC"
+	}
+	if len(schedule.Payload) == 0 || !json.Valid(schedule.Payload) {
+		return derrors.New(derrors.InvalidArgument, "a schedule needs a JSON payload")
+	}
+	if _, err := renderPayload(schedule.Payload, model.CronRun{}); err != nil {
+		return err
+	}
+
+	next, err := nextRun(schedule, now)
+	if err != nil {
+		return err
+	}
+	schedule.NextRunAt = next
+	return nil
+}
+
+// nextRun returns the first run of an enabled schedule after now, nil for a
+// disabled one
+func nextRun(schedule *model.CronSchedule, now time.Time) (*time.Time, error) {
+	location, err := time.LoadLocation(schedule.Timezone)
+	if err != nil {
+		return nil, derrors.New(derrors.InvalidArgument, "unknown timezone %q", schedule.Timezone)
+	}
+	// The timezone is a field of its own
+	if strings.HasPrefix(schedule.Expression, "TZ=") || strings.HasPrefix(schedule.Expression, "CRON_TZ=") {
+		return nil, derrors.New(derrors.InvalidArgument, "set the timezone of a schedule with its timezone field")
+	}
+	parsed, err := cron.ParseStandard(schedule.Expression)
+	if err != nil {
+		return nil, derrors.New(derrors.InvalidArgument, "invalid cron expression %q: %v", schedule.Expression, err)
+	}
+	if !schedule.Enabled {
+		return nil, nil
+	}
+
+	next := parsed.Next(now.In(location))
+	if next.IsZero() {
+		return nil, derrors.New(derrors.InvalidArgument, "cron expression %q never fires", schedule.Expression)
+	}
+	return &next, nil
+}
+
+// renderPayload decodes a payload template and renders its string values
+// with run
+func renderPayload(payload json.RawMessage, run model.CronRun) (interface{}, error) {
+	decoder := json.NewDecoder(bytes.NewReader(payload))
+	decoder.UseNumber()
+	var body interface{}
+	if err := decoder.Decode(&body); err != nil {
+		return nil, derrors.WrapStack(err, derrors.InvalidArgument, "invalid payload template")
+	}
+	return renderValue(body, run)
+}
+
+func renderValue(value interface{}, run model.CronRun) (interface{}, error) {
+	switch v := value.(type) {
+	case string:
+		if !strings.Contains(v, "{{") {
+			return v, nil
+		}
+		tmpl, err := template.New("payload").Option("missingkey=error").Parse(v)
+		if err != nil {
+			return nil, derrors.WrapStack(err, derrors.InvalidArgument, "invalid payload template %q", v)
+		}
+		var out strings.Builder
+		if err := tmpl.Execute(&out, run); err != nil {
+			return nil, derrors.WrapStack(err, derrors.InvalidArgument, "invalid payload template %q", v)
+		}
+		return out.String(), nil
+	case map[string]interface{}:
+		for key, item := range v {
+			rendered, err := renderValue(item, run)
+			if err != nil {
+				return nil, err
+			}
+			v[key] = rendered
+		}
+		return v, nil
+	case []interface{}:
+		for i, item := range v {
+			rendered, err := renderValue(item, run)
+			if err != nil {
+				return nil, err
+			}
+			v[i] = rendered
+		}
+		return v, nil
+	}
+	return value, nil
+}
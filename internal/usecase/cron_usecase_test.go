@@ -0,0 +1,147 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"tenant/internal/model"
+	"tenant/internal/service/messaging"
+	"tenant/internal/test/mockrepository"
+	"tenant/internal/test/mockusecase"
+	"tenant/pkg/derrors"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestPrepareSchedule(t *testing.T) {
+	now := time.Date(2026, 10, 19, 12, 3, 0, 0, time.UTC)
+
+	var testCases = []struct {
+		caseName string
+		schedule *model.CronSchedule
+		results  func(schedule *model.CronSchedule, err error)
+	}{
+		{
+			caseName: "PrepareSchedule_Timezone",
+			schedule: &model.CronSchedule{Name: "nightly", Expression: "0 2 * * *", Timezone: "Asia/Jakarta", Payload: json.RawMessage(`{"job":"{{.Name}}"}`), Enabled: true},
+			results: func(schedule *model.CronSchedule, err error) {
+				assert.Nil(t, err)
+				// 02:00 in Jakarta is 19:00 UTC the day before
+				assert.True(t, schedule.NextRunAt.Equal(time.Date(2026, 10, 19, 19, 0, 0, 0, time.UTC)))
+			},
+		},
+		{
+			caseName: "PrepareSchedule_DefaultTimezone",
+			schedule: &model.CronSchedule{Name: "heartbeat", Expression: "*/5 * * * *", Payload: json.RawMessage(`"ping"`), Enabled: true},
+			results: func(schedule *model.CronSchedule, err error) {
+				assert.Nil(t, err)
+				assert.Equal(t, "UTC", schedule.Timezone)
+				assert.True(t, schedule.NextRunAt.Equal(time.Date(2026, 10, 19, 12, 5, 0, 0, time.UTC)))
+			},
+		},
+		{
+			caseName: "PrepareSchedule_Disabled",
+			schedule: &model.CronSchedule{Name: "heartbeat", Expression: "@every 1m", Payload: json.RawMessage(`{}`)},
+			results: func(schedule *model.CronSchedule, err error) {
+				assert.Nil(t, err)
+				assert.Nil(t, schedule.NextRunAt)
+			},
+		},
+		{
+			caseName: "PrepareSchedule_InvalidExpression",
+			schedule: &model.CronSchedule{Name: "heartbeat", Expression: "every minute", Payload: json.RawMessage(`{}`), Enabled: true},
+			results: func(schedule *model.CronSchedule, err error) {
+				assert.True(t, derrors.IsErrCode(err, derrors.InvalidArgument))
+			},
+		},
+		{
+			caseName: "PrepareSchedule_TimezoneInExpression",
+			schedule: &model.CronSchedule{Name: "heartbeat", Expression: "CRON_TZ=Asia/Tokyo 0 * * * *", Payload: json.RawMessage(`{}`), Enabled: true},
+			results: func(schedule *model.CronSchedule, err error) {
+				assert.True(t, derrors.IsErrCode(err, derrors.InvalidArgument))
+			},
+		},
+		{
+			caseName: "PrepareSchedule_UnknownTimezone",
+			schedule: &model.CronSchedule{Name: "heartbeat", Expression: "@hourly", Timezone: "Mars/Olympus", Payload: json.RawMessage(`{}`), Enabled: true},
+			results: func(schedule *model.CronSchedule, err error) {
+				assert.True(t, derrors.IsErrCode(err, derrors.InvalidArgument))
+			},
+		},
+		{
+			caseName: "PrepareSchedule_UnknownTemplateField",
+			schedule: &model.CronSchedule{Name: "heartbeat", Expression: "@hourly", Payload: json.RawMessage(`{"at":"{{.Tomorrow}}"}`), Enabled: true},
+			results: func(schedule *model.CronSchedule, err error) {
+				assert.True(t, derrors.IsErrCode(err, derrors.InvalidArgument))
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.caseName, func(t *testing.T) {
+			err := prepareSchedule(testCase.schedule, now)
+			testCase.results(testCase.schedule, err)
+		})
+	}
+}
+
+func TestRunDue(t *testing.T) {
+	ctx := context.Background()
+	mockCron := new(mockrepository.CronRepository)
+	mockTenant := new(mockusecase.TenantUsecase)
+	cronUsecase := NewCronUsecase(mockCron, nil, nil, mockTenant, logrus.New(), CronConfig{})
+
+	dueAt := time.Now().Add(-time.Minute).Truncate(time.Minute)
+	due := []*model.CronSchedule{
+		{ID: "sched-1", ClientID: "test-client", Name: "heartbeat", Expression: "* * * * *", Timezone: "UTC", Payload: json.RawMessage(`{"at":"{{.ScheduledAt}}","n":1}`), Headers: map[string]string{"x-source": "cron"}, Enabled: true, NextRunAt: &dueAt},
+		{ID: "sched-2", ClientID: "test-client", Name: "report", Expression: "@hourly", Timezone: "UTC", Payload: json.RawMessage(`"report"`), Enabled: true, NextRunAt: &dueAt},
+	}
+
+	mockCron.On("ListDue", mock.Anything, mock.AnythingOfType("time.Time"), cronBatchSize).Return(due, nil).Once()
+	mockTenant.On("ProcessPayload", mock.Anything, "test-client", mock.MatchedBy(func(payload *model.Payload) bool {
+		body, _ := json.Marshal(payload.Body)
+		return string(body) == `{"at":"`+dueAt.UTC().Format(time.RFC3339)+`","n":1}` &&
+			payload.Headers["x-source"] == "cron" && payload.Headers[messaging.HeaderScheduleID] == "sched-1" &&
+			payload.IdempotencyKey != ""
+	})).Return("msg-1", nil).Once()
+	mockTenant.On("ProcessPayload", mock.Anything, "test-client", mock.MatchedBy(func(payload *model.Payload) bool {
+		return payload.Headers[messaging.HeaderScheduleID] == "sched-2"
+	})).Return("", errors.New("broker unavailable")).Once()
+	mockCron.On("RecordRun", mock.Anything, mock.MatchedBy(func(schedule *model.CronSchedule) bool {
+		return schedule.ID == "sched-1" && schedule.LastMessageID == "msg-1" && schedule.LastError == "" && schedule.NextRunAt.After(dueAt)
+	}), dueAt).Return(nil).Once()
+	mockCron.On("RecordRun", mock.Anything, mock.MatchedBy(func(schedule *model.CronSchedule) bool {
+		return schedule.ID == "sched-2" && schedule.LastError == "broker unavailable"
+	}), dueAt).Return(nil).Once()
+
+	published, err := cronUsecase.RunDue(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, published)
+
+	mockCron.AssertExpectations(t)
+	mockTenant.AssertExpectations(t)
+}
+
+func TestRunCronScheduler(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	mockCron := new(mockrepository.CronRepository)
+	mockLeases := new(mockrepository.LeaseRepository)
+	cronUsecase := NewCronUsecase(mockCron, mockLeases, nil, nil, logrus.New(), CronConfig{WorkerID: "worker-1"})
+
+	// Only the leader runs the schedules, and it steps down when stopped
+	mockLeases.On("AcquireLeadership", mock.Anything, cronLeaderRole, "worker-1", 30*time.Second).Return(false, nil).Once()
+	mockLeases.On("AcquireLeadership", mock.Anything, cronLeaderRole, "worker-1", 30*time.Second).Return(true, nil).Once()
+	mockCron.On("ListDue", mock.Anything, mock.Anything, cronBatchSize).Return([]*model.CronSchedule{}, nil).Run(func(mock.Arguments) {
+		cancel()
+	}).Once()
+	mockLeases.On("ReleaseLeadership", mock.Anything, cronLeaderRole, "worker-1").Return(nil).Once()
+
+	cronUsecase.RunScheduler(ctx, time.Millisecond)
+
+	mockCron.AssertExpectations(t)
+	mockLeases.AssertExpectations(t)
+}
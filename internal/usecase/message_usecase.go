@@ -0,0 +1,31 @@
+package usecase
+
+import (
+	"context"
+	"tenant/internal/model"
+	"tenant/internal/repository"
+	"tenant/pkg/derrors"
+
+	"github.com/sirupsen/logrus"
+)
+
+type MessageUsecase interface {
+	GetMessage(ctx context.Context, clientID, messageID string) (*model.Message, error)
+}
+
+type messageUsecase struct {
+	repo repository.MessageRepository
+	log  *logrus.Logger
+}
+
+// NewMessageUsecase initializes a new message usecase
+func NewMessageUsecase(repo repository.MessageRepository, log *logrus.Logger) MessageUsecase {
+	return &messageUsecase{repo: repo, log: log}
+}
+
+// GetMessage returns a message of a tenant with its status timeline
+func (s *messageUsecase) GetMessage(ctx context.Context, clientID, messageID string) (message *model.Message, err error) {
+	defer derrors.Wrap(&err, "GetMessage(%q, %q)", clientID, messageID)
+
+	return s.repo.GetMessage(ctx, clientID, messageID)
+}
@@ -0,0 +1,130 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"tenant/internal/model"
+	"tenant/internal/service/messaging"
+	"tenant/pkg/derrors"
+)
+
+// maxProcessors bounds the length of the processor chain of a tenant
+const maxProcessors = 10
+
+// Processor handles a message consumed from the queue of a tenant. A failed
+// message is retried, so processors must tolerate seeing a message again.
+type Processor interface {
+	Process(ctx context.Context, clientID string, delivery *messaging.Delivery) error
+}
+
+// ProcessorFunc adapts a function to a Processor
+type ProcessorFunc func(ctx context.Context, clientID string, delivery *messaging.Delivery) error
+
+func (f ProcessorFunc) Process(ctx context.Context, clientID string, delivery *messaging.Delivery) error {
+	return f(ctx, clientID, delivery)
+}
+
+// ProcessorFactory builds the processor of a tenant from its options, it
+// returns an InvalidArgument error when the options are invalid
+type ProcessorFactory func(clientID string, options json.RawMessage) (Processor, error)
+
+// ProcessorRegistry holds the processor types tenants may select
+type ProcessorRegistry struct {
+	mu        sync.RWMutex
+	factories map[string]ProcessorFactory
+}
+
+// NewProcessorRegistry creates an empty registry
+func NewProcessorRegistry() *ProcessorRegistry {
+	return &ProcessorRegistry{factories: make(map[string]ProcessorFactory)}
+}
+
+// Register adds a processor type, registering a type twice is a programming
+// error
+func (r *ProcessorRegistry) Register(name string, factory ProcessorFactory) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.factories[name]; ok {
+		panic(fmt.Sprintf("processor %q registered twice", name))
+	}
+	r.factories[name] = factory
+}
+
+// Types returns the registered processor types, sorted
+func (r *ProcessorRegistry) Types() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.typesLocked()
+}
+
+// Build creates the processor chain of a tenant, running specs in order
+func (r *ProcessorRegistry) Build(clientID string, specs []model.ProcessorSpec) (Processor, error) {
+	if len(specs) == 0 || len(specs) > maxProcessors {
+		return nil, derrors.New(derrors.InvalidArgument, "a tenant needs between 1 and %d processors", maxProcessors)
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	chain := make(processorChain, 0, len(specs))
+	for i, spec := range specs {
+		factory, ok := r.factories[spec.Type]
+		if !ok {
+			return nil, derrors.New(derrors.InvalidArgument, "processors[%d]: unknown type %q, expected one of %s", i, spec.Type, strings.Join(r.typesLocked(), ", "))
+		}
+		processor, err := factory(clientID, spec.Options)
+		if err != nil {
+			return nil, fmt.Errorf("processors[%d] (%s): %w", i, spec.Type, err)
+		}
+		chain = append(chain, namedProcessor{name: spec.Type, Processor: processor})
+	}
+	return chain, nil
+}
+
+func (r *ProcessorRegistry) typesLocked() []string {
+	types := make([]string, 0, len(r.factories))
+	for name := range r.factories {
+		types = append(types, name)
+	}
+	sort.Strings(types)
+	return types
+}
+
+type namedProcessor struct {
+	name string
+	Processor
+}
+
+// processorChain runs processors in order and stops at the first failure,
+// the failed message is retried from the start of the chain
+type processorChain []namedProcessor
+
+func (c processorChain) Process(ctx context.Context, clientID string, delivery *messaging.Delivery) error {
+	for _, processor := range c {
+		if err := processor.Process(ctx, clientID, delivery); err != nil {
+			return fmt.Errorf("processor %s: %w", processor.name, err)
+		}
+	}
+	return nil
+}
+
+// decodeProcessorOptions decodes the options of a processor into v,
+// rejecting unknown fields. Empty options leave v untouched.
+func decodeProcessorOptions(options json.RawMessage, v interface{}) error {
+	if len(options) == 0 || string(options) == "null" {
+		return nil
+	}
+	decoder := json.NewDecoder(bytes.NewReader(options))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(v); err != nil {
+		return derrors.New(derrors.InvalidArgument, "invalid options: %v", err)
+	}
+	return nil
+}
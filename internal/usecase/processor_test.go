@@ -0,0 +1,264 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"tenant/internal/model"
+	"tenant/internal/service/messaging"
+	"tenant/internal/test/mockusecase"
+	"tenant/pkg/derrors"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestBuildProcessors(t *testing.T) {
+	var calls []string
+	recorder := func(name string, err error) ProcessorFactory {
+		return func(clientID string, options json.RawMessage) (Processor, error) {
+			return ProcessorFunc(func(ctx context.Context, clientID string, delivery *messaging.Delivery) error {
+				calls = append(calls, name)
+				return err
+			}), nil
+		}
+	}
+
+	registry := NewProcessorRegistry()
+	registry.Register("first", recorder("first", nil))
+	registry.Register("failing", recorder("failing", errors.New("disk full")))
+	registry.Register("last", recorder("last", nil))
+	registry.Register(model.ProcessorLog, LogProcessor(logrus.New()))
+	assert.Equal(t, []string{"failing", "first", "last", model.ProcessorLog}, registry.Types())
+	assert.Panics(t, func() { registry.Register("first", recorder("first", nil)) })
+
+	var testCases = []struct {
+		caseName string
+		specs    []model.ProcessorSpec
+		results  func(processor Processor, err error)
+	}{
+		{
+			caseName: "Build_RunsInOrder",
+			specs:    []model.ProcessorSpec{{Type: "last"}, {Type: "first"}, {Type: model.ProcessorLog}},
+			results: func(processor Processor, err error) {
+				assert.Nil(t, err)
+				assert.Nil(t, processor.Process(context.Background(), "client-a", &messaging.Delivery{MessageID: "msg-1"}))
+				assert.Equal(t, []string{"last", "first"}, calls)
+			},
+		},
+		{
+			caseName: "Build_StopsAtFirstFailure",
+			specs:    []model.ProcessorSpec{{Type: "first"}, {Type: "failing"}, {Type: "last"}},
+			results: func(processor Processor, err error) {
+				assert.Nil(t, err)
+				err = processor.Process(context.Background(), "client-a", &messaging.Delivery{MessageID: "msg-1"})
+				assert.EqualError(t, err, "processor failing: disk full")
+				assert.Equal(t, []string{"first", "failing"}, calls)
+			},
+		},
+		{
+			caseName: "Build_UnknownType",
+			specs:    []model.ProcessorSpec{{Type: "first"}, {Type: "kafka"}},
+			results: func(processor Processor, err error) {
+				assert.True(t, derrors.IsErrCode(err, derrors.InvalidArgument))
+				assert.ErrorContains(t, err, `processors[1]: unknown type "kafka"`)
+			},
+		},
+		{
+			caseName: "Build_InvalidOptions",
+			specs:    []model.ProcessorSpec{{Type: model.ProcessorLog, Options: json.RawMessage(`{"level":"debug"}`)}},
+			results: func(processor Processor, err error) {
+				assert.True(t, derrors.IsErrCode(err, derrors.InvalidArgument))
+			},
+		},
+		{
+			caseName: "Build_Empty",
+			specs:    nil,
+			results: func(processor Processor, err error) {
+				assert.True(t, derrors.IsErrCode(err, derrors.InvalidArgument))
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.caseName, func(t *testing.T) {
+			calls = nil
+			processor, err := registry.Build("client-a", testCase.specs)
+			testCase.results(processor, err)
+		})
+	}
+}
+
+func TestFileProcessor(t *testing.T) {
+	dir := t.TempDir()
+	factory := FileProcessor(dir)
+
+	_, err := factory("client-a", json.RawMessage(`{"file":"../client-b/messages.ndjson"}`))
+	assert.True(t, derrors.IsErrCode(err, derrors.InvalidArgument))
+
+	processor, err := factory("client-a", json.RawMessage(`{"file":"orders.ndjson"}`))
+	assert.Nil(t, err)
+	assert.Nil(t, processor.Process(context.Background(), "client-a", &messaging.Delivery{MessageID: "msg-1", Body: []byte(`{"id":1}`)}))
+	assert.Nil(t, processor.Process(context.Background(), "client-a", &messaging.Delivery{MessageID: "msg-2", Body: []byte(`plain text`)}))
+
+	content, err := os.ReadFile(filepath.Join(dir, "client-a", "orders.ndjson"))
+	assert.Nil(t, err)
+	lines := strings.Split(strings.TrimSpace(string(content)), "\n")
+	assert.Len(t, lines, 2)
+
+	var message model.SinkMessage
+	assert.Nil(t, json.Unmarshal([]byte(lines[0]), &message))
+	assert.Equal(t, "msg-1", message.MessageID)
+	assert.JSONEq(t, `{"id":1}`, string(message.Payload))
+	assert.Nil(t, json.Unmarshal([]byte(lines[1]), &message))
+	assert.JSONEq(t, `"plain text"`, string(message.Payload))
+}
+
+func TestForwardProcessor(t *testing.T) {
+	ctx := context.Background()
+	delivery := &messaging.Delivery{
+		MessageID:     "msg-1",
+		CorrelationID: "order-42",
+		Headers:       map[string]string{"x-source": "test", messaging.HeaderAttempt: "2", messaging.HeaderError: "timeout"},
+		Body:          []byte(`{"id":1}`),
+		Attempt:       2,
+	}
+
+	var testCases = []struct {
+		caseName     string
+		options      string
+		headers      map[string]string
+		expectations func(mockTenant *mockusecase.TenantUsecase)
+		results      func(buildErr, err error)
+	}{
+		{
+			caseName: "Forward_Success",
+			options:  `{"client_id":"client-b"}`,
+			expectations: func(mockTenant *mockusecase.TenantUsecase) {
+				mockTenant.On("ProcessPayload", mock.Anything, "client-b", mock.MatchedBy(func(payload *model.Payload) bool {
+					body, _ := json.Marshal(payload.Body)
+					return string(body) == `{"id":1}` &&
+						payload.CorrelationID == "order-42" &&
+						payload.IdempotencyKey == "forward:client-a:msg-1" &&
+						payload.Headers["x-source"] == "test" &&
+						payload.Headers[messaging.HeaderForwardedFrom] == "client-a" &&
+						payload.Headers[messaging.HeaderForwardedMessageID] == "msg-1" &&
+						payload.Headers[messaging.HeaderForwardedPath] == "client-a" &&
+						payload.Headers[messaging.HeaderAttempt] == "" &&
+						payload.Headers[messaging.HeaderError] == ""
+				})).Return("msg-2", nil).Once()
+			},
+			results: func(buildErr, err error) {
+				assert.Nil(t, buildErr)
+				assert.Nil(t, err)
+			},
+		},
+		{
+			caseName: "Forward_TargetDeletedIsPermanent",
+			options:  `{"client_id":"client-b"}`,
+			expectations: func(mockTenant *mockusecase.TenantUsecase) {
+				mockTenant.On("ProcessPayload", mock.Anything, "client-b", mock.Anything).Return("", derrors.New(derrors.NotFound, "tenant not found")).Once()
+			},
+			results: func(buildErr, err error) {
+				assert.Nil(t, buildErr)
+				assert.True(t, isPermanent(err))
+			},
+		},
+		{
+			caseName: "Forward_BrokerDownIsRetried",
+			options:  `{"client_id":"client-b"}`,
+			expectations: func(mockTenant *mockusecase.TenantUsecase) {
+				mockTenant.On("ProcessPayload", mock.Anything, "client-b", mock.Anything).Return("", derrors.New(derrors.Unavailable, "connection closed")).Once()
+			},
+			results: func(buildErr, err error) {
+				assert.Nil(t, buildErr)
+				assert.NotNil(t, err)
+				assert.False(t, isPermanent(err))
+			},
+		},
+		{
+			caseName:     "Forward_LoopBackToSender",
+			options:      `{"client_id":"client-b"}`,
+			headers:      map[string]string{messaging.HeaderForwardedFrom: "client-b", messaging.HeaderForwardedPath: "client-b"},
+			expectations: func(mockTenant *mockusecase.TenantUsecase) {},
+			results: func(buildErr, err error) {
+				assert.Nil(t, buildErr)
+				assert.True(t, isPermanent(err))
+				assert.ErrorContains(t, err, "forwarding loop")
+			},
+		},
+		{
+			caseName:     "Forward_LoopThroughOtherTenants",
+			options:      `{"client_id":"client-b"}`,
+			headers:      map[string]string{messaging.HeaderForwardedFrom: "client-c", messaging.HeaderForwardedPath: "client-b,client-c"},
+			expectations: func(mockTenant *mockusecase.TenantUsecase) {},
+			results: func(buildErr, err error) {
+				assert.True(t, isPermanent(err))
+			},
+		},
+		{
+			caseName: "Forward_ChainExtended",
+			options:  `{"client_id":"client-d"}`,
+			headers:  map[string]string{messaging.HeaderForwardedFrom: "client-c", messaging.HeaderForwardedPath: "client-b,client-c"},
+			expectations: func(mockTenant *mockusecase.TenantUsecase) {
+				mockTenant.On("ProcessPayload", mock.Anything, "client-d", mock.MatchedBy(func(payload *model.Payload) bool {
+					return payload.Headers[messaging.HeaderForwardedPath] == "client-b,client-c,client-a"
+				})).Return("msg-2", nil).Once()
+			},
+			results: func(buildErr, err error) {
+				assert.Nil(t, err)
+			},
+		},
+		{
+			caseName:     "Forward_TooManyHops",
+			options:      `{"client_id":"client-z"}`,
+			headers:      map[string]string{messaging.HeaderForwardedPath: "c1,c2,c3,c4,c5,c6,c7,c8"},
+			expectations: func(mockTenant *mockusecase.TenantUsecase) {},
+			results: func(buildErr, err error) {
+				assert.True(t, isPermanent(err))
+				assert.ErrorContains(t, err, "forwarded 8 times")
+			},
+		},
+		{
+			caseName:     "Forward_ToItself",
+			options:      `{"client_id":"client-a"}`,
+			expectations: func(mockTenant *mockusecase.TenantUsecase) {},
+			results: func(buildErr, err error) {
+				assert.True(t, derrors.IsErrCode(buildErr, derrors.InvalidArgument))
+			},
+		},
+		{
+			caseName:     "Forward_WithoutTarget",
+			options:      `{}`,
+			expectations: func(mockTenant *mockusecase.TenantUsecase) {},
+			results: func(buildErr, err error) {
+				assert.True(t, derrors.IsErrCode(buildErr, derrors.InvalidArgument))
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.caseName, func(t *testing.T) {
+			mockTenant := new(mockusecase.TenantUsecase)
+			testCase.expectations(mockTenant)
+
+			processor, buildErr := ForwardProcessor(mockTenant)("client-a", json.RawMessage(testCase.options))
+			var err error
+			if buildErr == nil {
+				delivery := *delivery
+				if testCase.headers != nil {
+					delivery.Headers = testCase.headers
+				}
+				err = processor.Process(ctx, "client-a", &delivery)
+			}
+			testCase.results(buildErr, err)
+
+			mockTenant.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,170 @@
+package usecase
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"tenant/internal/model"
+	"tenant/internal/repository"
+	"tenant/internal/service/messaging"
+	"tenant/pkg/derrors"
+
+	"github.com/sirupsen/logrus"
+)
+
+// DefaultProcessors is the processor chain of tenants that did not select
+// one
+var DefaultProcessors = []model.ProcessorSpec{{Type: model.ProcessorWebhook}}
+
+type ProcessorUsecase interface {
+	ListProcessorTypes() []string
+	GetProcessors(ctx context.Context, clientID string) (*model.ProcessorChain, error)
+	SetProcessors(ctx context.Context, clientID string, specs []model.ProcessorSpec) (*model.ProcessorChain, error)
+	ResetProcessors(ctx context.Context, clientID string) (*model.ProcessorChain, error)
+	Process(ctx context.Context, clientID string, delivery *messaging.Delivery) error
+}
+
+// ProcessorConfig tunes the processing of consumed messages
+type ProcessorConfig struct {
+	// CacheTTL is how long the processor chain of a tenant is reused before
+	// it is loaded again, so changes made on another instance apply within
+	// this delay
+	CacheTTL time.Duration
+}
+
+type processorUsecase struct {
+	repo     repository.ProcessorRepository
+	tenants  repository.TenantRepository
+	registry *ProcessorRegistry
+	log      *logrus.Logger
+	cacheTTL time.Duration
+
+	// chains caches the processor chain of each tenant
+	mu     sync.Mutex
+	chains map[string]cachedChain
+}
+
+type cachedChain struct {
+	processor Processor
+	expiresAt time.Time
+}
+
+// NewProcessorUsecase initializes a new processor usecase, tenants select
+// their processors among the types of registry
+func NewProcessorUsecase(repo repository.ProcessorRepository, tenants repository.TenantRepository, registry *ProcessorRegistry, log *logrus.Logger, cfg ProcessorConfig) ProcessorUsecase {
+	if cfg.CacheTTL <= 0 {
+		cfg.CacheTTL = 30 * time.Second
+	}
+	return &processorUsecase{
+		repo:     repo,
+		tenants:  tenants,
+		registry: registry,
+		log:      log,
+		cacheTTL: cfg.CacheTTL,
+		chains:   make(map[string]cachedChain),
+	}
+}
+
+// ListProcessorTypes returns the processor types tenants may select
+func (s *processorUsecase) ListProcessorTypes() []string {
+	return s.registry.Types()
+}
+
+// GetProcessors returns the processor chain of a tenant
+func (s *processorUsecase) GetProcessors(ctx context.Context, clientID string) (chain *model.ProcessorChain, err error) {
+	defer derrors.Wrap(&err, "GetProcessors(%q)", clientID)
+
+	if _, err := s.tenants.GetTenantByClientID(ctx, clientID); err != nil {
+		return nil, err
+	}
+	return s.getProcessors(ctx, clientID)
+}
+
+// SetProcessors replaces the processor chain of a tenant, every processor
+// is checked to build before it is stored
+func (s *processorUsecase) SetProcessors(ctx context.Context, clientID string, specs []model.ProcessorSpec) (chain *model.ProcessorChain, err error) {
+	defer derrors.Wrap(&err, "SetProcessors(%q)", clientID)
+
+	if _, err := s.registry.Build(clientID, specs); err != nil {
+		return nil, err
+	}
+	if _, err := s.tenants.GetTenantByClientID(ctx, clientID); err != nil {
+		return nil, err
+	}
+
+	chain = &model.ProcessorChain{ClientID: clientID, Processors: specs}
+	if err := s.repo.SetProcessors(ctx, chain); err != nil {
+		return nil, err
+	}
+	s.invalidate(clientID)
+	return chain, nil
+}
+
+// ResetProcessors moves a tenant back to the default processor chain
+func (s *processorUsecase) ResetProcessors(ctx context.Context, clientID string) (chain *model.ProcessorChain, err error) {
+	defer derrors.Wrap(&err, "ResetProcessors(%q)", clientID)
+
+	if _, err := s.tenants.GetTenantByClientID(ctx, clientID); err != nil {
+		return nil, err
+	}
+	if err := s.repo.DeleteProcessors(ctx, clientID); err != nil {
+		return nil, err
+	}
+	s.invalidate(clientID)
+	return s.defaultProcessors(clientID), nil
+}
+
+// Process runs the processor chain of a tenant on a consumed message
+func (s *processorUsecase) Process(ctx context.Context, clientID string, delivery *messaging.Delivery) error {
+	processor, err := s.chain(ctx, clientID)
+	if err != nil {
+		return err
+	}
+	return processor.Process(ctx, clientID, delivery)
+}
+
+// chain returns the processor chain of a tenant, built again once its cache
+// entry expired
+func (s *processorUsecase) chain(ctx context.Context, clientID string) (Processor, error) {
+	now := time.Now()
+	s.mu.Lock()
+	cached, ok := s.chains[clientID]
+	s.mu.Unlock()
+	if ok && now.Before(cached.expiresAt) {
+		return cached.processor, nil
+	}
+
+	chain, err := s.getProcessors(ctx, clientID)
+	if err != nil {
+		return nil, err
+	}
+	processor, err := s.registry.Build(clientID, chain.Processors)
+	if err != nil {
+		// The chain was stored by an instance knowing other processor types
+		return nil, derrors.WrapStack(err, derrors.Unavailable, "failed to build processors of tenant %s", clientID)
+	}
+
+	s.mu.Lock()
+	s.chains[clientID] = cachedChain{processor: processor, expiresAt: now.Add(s.cacheTTL)}
+	s.mu.Unlock()
+	return processor, nil
+}
+
+func (s *processorUsecase) getProcessors(ctx context.Context, clientID string) (*model.ProcessorChain, error) {
+	chain, err := s.repo.GetProcessors(ctx, clientID)
+	if derrors.IsErrCode(err, derrors.NotFound) {
+		return s.defaultProcessors(clientID), nil
+	}
+	return chain, err
+}
+
+func (s *processorUsecase) defaultProcessors(clientID string) *model.ProcessorChain {
+	return &model.ProcessorChain{ClientID: clientID, Processors: DefaultProcessors, Default: true}
+}
+
+func (s *processorUsecase) invalidate(clientID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.chains, clientID)
+}
@@ -0,0 +1,115 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"tenant/internal/model"
+	"tenant/internal/service/messaging"
+	"tenant/internal/test/mockrepository"
+	"tenant/pkg/derrors"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestProcessWithTenantProcessors(t *testing.T) {
+	ctx := context.Background()
+	mockRepo := new(mockrepository.ProcessorRepository)
+
+	var calls []string
+	registry := NewProcessorRegistry()
+	for _, name := range []string{model.ProcessorWebhook, model.ProcessorPostgres} {
+		name := name
+		registry.Register(name, func(clientID string, options json.RawMessage) (Processor, error) {
+			return ProcessorFunc(func(ctx context.Context, clientID string, delivery *messaging.Delivery) error {
+				calls = append(calls, clientID+":"+name)
+				return nil
+			}), nil
+		})
+	}
+	processorUsecase := NewProcessorUsecase(mockRepo, new(mockrepository.TenantRepository), registry, logrus.New(), ProcessorConfig{})
+
+	// Tenants without processors use the default chain, loaded once
+	mockRepo.On("GetProcessors", mock.Anything, "client-a").Return(nil, derrors.New(derrors.NotFound, "not found")).Once()
+	mockRepo.On("GetProcessors", mock.Anything, "client-b").Return(&model.ProcessorChain{
+		ClientID:   "client-b",
+		Processors: []model.ProcessorSpec{{Type: model.ProcessorPostgres}, {Type: model.ProcessorWebhook}},
+	}, nil).Once()
+
+	delivery := &messaging.Delivery{MessageID: "msg-1"}
+	assert.Nil(t, processorUsecase.Process(ctx, "client-a", delivery))
+	assert.Nil(t, processorUsecase.Process(ctx, "client-a", delivery))
+	assert.Nil(t, processorUsecase.Process(ctx, "client-b", delivery))
+	assert.Equal(t, []string{"client-a:webhook", "client-a:webhook", "client-b:postgres", "client-b:webhook"}, calls)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestSetProcessors(t *testing.T) {
+	ctx := context.Background()
+
+	registry := NewProcessorRegistry()
+	registry.Register(model.ProcessorLog, LogProcessor(logrus.New()))
+	registry.Register(model.ProcessorForward, ForwardProcessor(nil))
+
+	var testCases = []struct {
+		caseName     string
+		specs        []model.ProcessorSpec
+		expectations func(mockRepo *mockrepository.ProcessorRepository, mockTenants *mockrepository.TenantRepository)
+		results      func(chain *model.ProcessorChain, err error)
+	}{
+		{
+			caseName: "SetProcessors_Success",
+			specs: []model.ProcessorSpec{
+				{Type: model.ProcessorLog},
+				{Type: model.ProcessorForward, Options: json.RawMessage(`{"client_id":"client-b"}`)},
+			},
+			expectations: func(mockRepo *mockrepository.ProcessorRepository, mockTenants *mockrepository.TenantRepository) {
+				mockTenants.On("GetTenantByClientID", mock.Anything, "client-a").Return(&model.Tenant{ClientID: "client-a"}, nil).Once()
+				mockRepo.On("SetProcessors", mock.Anything, mock.MatchedBy(func(chain *model.ProcessorChain) bool {
+					return chain.ClientID == "client-a" && len(chain.Processors) == 2
+				})).Return(nil).Once()
+			},
+			results: func(chain *model.ProcessorChain, err error) {
+				assert.Nil(t, err)
+				assert.False(t, chain.Default)
+			},
+		},
+		{
+			caseName: "SetProcessors_InvalidOptions",
+			specs:    []model.ProcessorSpec{{Type: model.ProcessorForward, Options: json.RawMessage(`{"tenant":"client-b"}`)}},
+			expectations: func(mockRepo *mockrepository.ProcessorRepository, mockTenants *mockrepository.TenantRepository) {
+			},
+			results: func(chain *model.ProcessorChain, err error) {
+				assert.True(t, derrors.IsErrCode(err, derrors.InvalidArgument))
+			},
+		},
+		{
+			caseName: "SetProcessors_TenantNotFound",
+			specs:    []model.ProcessorSpec{{Type: model.ProcessorLog}},
+			expectations: func(mockRepo *mockrepository.ProcessorRepository, mockTenants *mockrepository.TenantRepository) {
+				mockTenants.On("GetTenantByClientID", mock.Anything, "client-a").Return(nil, derrors.New(derrors.NotFound, "tenant not found")).Once()
+			},
+			results: func(chain *model.ProcessorChain, err error) {
+				assert.True(t, derrors.IsErrCode(err, derrors.NotFound))
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.caseName, func(t *testing.T) {
+			mockRepo := new(mockrepository.ProcessorRepository)
+			mockTenants := new(mockrepository.TenantRepository)
+			processorUsecase := NewProcessorUsecase(mockRepo, mockTenants, registry, logrus.New(), ProcessorConfig{})
+
+			testCase.expectations(mockRepo, mockTenants)
+			chain, err := processorUsecase.SetProcessors(ctx, "client-a", testCase.specs)
+			testCase.results(chain, err)
+
+			mockRepo.AssertExpectations(t)
+			mockTenants.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,200 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"tenant/internal/model"
+	"tenant/internal/repository"
+	"tenant/internal/service/messaging"
+	"tenant/pkg/derrors"
+	"tenant/pkg/logger"
+
+	"github.com/sirupsen/logrus"
+)
+
+// defaultSinkFile is the file the file processor appends to when its
+// options do not name one
+const defaultSinkFile = "messages.ndjson"
+
+// LogProcessor logs every message. It takes no options.
+func LogProcessor(log *logrus.Logger) ProcessorFactory {
+	return func(clientID string, options json.RawMessage) (Processor, error) {
+		if err := decodeProcessorOptions(options, &struct{}{}); err != nil {
+			return nil, err
+		}
+		return ProcessorFunc(func(ctx context.Context, clientID string, delivery *messaging.Delivery) error {
+			logger.WithContext(ctx, log).Infof("Processing message %s of %d bytes, attempt %d", delivery.MessageID, len(delivery.Body), delivery.Attempt)
+			return nil
+		}), nil
+	}
+}
+
+// WebhookProcessor delivers every message to the webhooks of the tenant. It
+// takes no options.
+func WebhookProcessor(webhooks WebhookUsecase) ProcessorFactory {
+	return func(clientID string, options json.RawMessage) (Processor, error) {
+		if err := decodeProcessorOptions(options, &struct{}{}); err != nil {
+			return nil, err
+		}
+		return ProcessorFunc(webhooks.Deliver), nil
+	}
+}
+
+// PostgresProcessor stores every message in the sink_messages table, once.
+// It takes no options.
+func PostgresProcessor(repo repository.ProcessorRepository) ProcessorFactory {
+	return func(clientID string, options json.RawMessage) (Processor, error) {
+		if err := decodeProcessorOptions(options, &struct{}{}); err != nil {
+			return nil, err
+		}
+		return ProcessorFunc(func(ctx context.Context, clientID string, delivery *messaging.Delivery) error {
+			return repo.SaveSinkMessage(ctx, newSinkMessage(clientID, delivery))
+		}), nil
+	}
+}
+
+// FileProcessorOptions names the file the messages of a tenant are appended
+// to, within the directory of the tenant
+type FileProcessorOptions struct {
+	File string `json:"file"`
+}
+
+// FileProcessor appends every message as a JSON line to a file in
+// dir/<clientID>. A retried message may be appended again.
+func FileProcessor(dir string) ProcessorFactory {
+	// Serializes the writes of every tenant, lines must not interleave
+	var mu sync.Mutex
+
+	return func(clientID string, options json.RawMessage) (Processor, error) {
+		opts := FileProcessorOptions{File: defaultSinkFile}
+		if err := decodeProcessorOptions(options, &opts); err != nil {
+			return nil, err
+		}
+		if opts.File == "" || opts.File == "." || opts.File == ".." || filepath.Base(opts.File) != opts.File {
+			return nil, derrors.New(derrors.InvalidArgument, "file must be a file name without directories")
+		}
+		path := filepath.Join(dir, clientID, opts.File)
+
+		return ProcessorFunc(func(ctx context.Context, clientID string, delivery *messaging.Delivery) error {
+			line, err := json.Marshal(newSinkMessage(clientID, delivery))
+			if err != nil {
+				return derrors.WrapStack(err, derrors.Unknown, "failed to encode message %s", delivery.MessageID)
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+				return derrors.WrapStack(err, derrors.Unknown, "failed to create directory of %s", path)
+			}
+			f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+			if err != nil {
+				return derrors.WrapStack(err, derrors.Unknown, "failed to open %s", path)
+			}
+			if _, err := f.Write(append(line, '\n')); err != nil {
+				f.Close()
+				return derrors.WrapStack(err, derrors.Unknown, "failed to write to %s", path)
+			}
+			return f.Close()
+		}), nil
+	}
+}
+
+// ForwardProcessorOptions selects the tenant messages are forwarded to
+type ForwardProcessorOptions struct {
+	ClientID string `json:"client_id"`
+}
+
+// maxForwardHops bounds how many times a message is forwarded between tenants
+const maxForwardHops = 8
+
+// ForwardProcessor publishes every message to the queue of another tenant,
+// with the headers of the original message. A retried message is only
+// forwarded once. A message that already went through the target tenant, or
+// was forwarded maxForwardHops times, fails permanently instead of looping
+// between tenants.
+func ForwardProcessor(tenants TenantUsecase) ProcessorFactory {
+	return func(clientID string, options json.RawMessage) (Processor, error) {
+		var opts ForwardProcessorOptions
+		if err := decodeProcessorOptions(options, &opts); err != nil {
+			return nil, err
+		}
+		if opts.ClientID == "" {
+			return nil, derrors.New(derrors.InvalidArgument, "client_id is required")
+		}
+		if opts.ClientID == clientID {
+			return nil, derrors.New(derrors.InvalidArgument, "a tenant cannot forward messages to itself")
+		}
+
+		return ProcessorFunc(func(ctx context.Context, clientID string, delivery *messaging.Delivery) error {
+			var path []string
+			if value := delivery.Headers[messaging.HeaderForwardedPath]; value != "" {
+				path = strings.Split(value, ",")
+			}
+			if slices.Contains(path, opts.ClientID) || delivery.Headers[messaging.HeaderForwardedFrom] == opts.ClientID {
+				return permanent(derrors.New(derrors.InvalidArgument, "forwarding loop: message was already forwarded by %s", opts.ClientID))
+			}
+			if len(path) >= maxForwardHops {
+				return permanent(derrors.New(derrors.InvalidArgument, "message was forwarded %d times already", len(path)))
+			}
+			path = append(path, clientID)
+
+			headers := make(map[string]string, len(delivery.Headers)+3)
+			for key, value := range delivery.Headers {
+				headers[key] = value
+			}
+			// Retries of the original message are not retries of the forwarded one
+			delete(headers, messaging.HeaderAttempt)
+			delete(headers, messaging.HeaderError)
+			headers[messaging.HeaderForwardedFrom] = clientID
+			headers[messaging.HeaderForwardedMessageID] = delivery.MessageID
+			headers[messaging.HeaderForwardedPath] = strings.Join(path, ",")
+
+			_, err := tenants.ProcessPayload(ctx, opts.ClientID, &model.Payload{
+				Body:           json.RawMessage(sinkPayload(delivery.Body)),
+				Headers:        headers,
+				CorrelationID:  delivery.CorrelationID,
+				ContentType:    delivery.ContentType,
+				Priority:       delivery.Priority,
+				IdempotencyKey: "forward:" + clientID + ":" + delivery.MessageID,
+			})
+			if derrors.IsErrCode(err, derrors.NotFound) || derrors.IsErrCode(err, derrors.InvalidArgument) {
+				// The target tenant was deleted, retrying will not bring it back
+				return permanent(err)
+			}
+			return err
+		}), nil
+	}
+}
+
+// newSinkMessage describes a consumed message for the sinks
+func newSinkMessage(clientID string, delivery *messaging.Delivery) *model.SinkMessage {
+	contentType := delivery.ContentType
+	if contentType == "" {
+		contentType = messaging.DefaultContentType
+	}
+	return &model.SinkMessage{
+		MessageID:     delivery.MessageID,
+		ClientID:      clientID,
+		CorrelationID: delivery.CorrelationID,
+		ContentType:   contentType,
+		Headers:       delivery.Headers,
+		Payload:       sinkPayload(delivery.Body),
+		ReceivedAt:    time.Now(),
+	}
+}
+
+// sinkPayload returns a JSON body as is, and any other body as a JSON string
+func sinkPayload(body []byte) json.RawMessage {
+	if json.Valid(body) {
+		return body
+	}
+	encoded, _ := json.Marshal(string(body))
+	return encoded
+}
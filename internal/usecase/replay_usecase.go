@@ -0,0 +1,346 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"tenant/internal/model"
+	"tenant/internal/repository"
+	"tenant/internal/service/messaging"
+	"tenant/pkg/derrors"
+	"tenant/pkg/logger"
+
+	"github.com/segmentio/ksuid"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/time/rate"
+)
+
+const (
+	// replayPageSize is how many archived messages are read at once
+	replayPageSize = 500
+
+	// maxReplayMessageIDs bounds the message IDs of a single replay
+	maxReplayMessageIDs = 1000
+)
+
+type ReplayUsecase interface {
+	CreateReplay(ctx context.Context, job *model.ReplayJob) (*model.ReplayJob, error)
+	Replay(ctx context.Context, job *model.ReplayJob, progress func(*model.ReplayJob)) (*model.ReplayJob, error)
+	GetReplay(ctx context.Context, clientID, jobID string) (*model.ReplayJob, error)
+	CancelReplay(ctx context.Context, clientID, jobID string) (*model.ReplayJob, error)
+	RunReplays(ctx context.Context, interval time.Duration)
+}
+
+// ReplayConfig tunes the replay of archived messages
+type ReplayConfig struct {
+	// WorkerID identifies this process as the runner of a job
+	WorkerID string
+
+	// Rate is the messages per second of jobs that do not set one, MaxRate
+	// bounds the rate a job may ask for
+	Rate    float64
+	MaxRate float64
+
+	// StaleAfter hands a running job over to another worker once its
+	// worker stopped reporting progress for this long
+	StaleAfter time.Duration
+}
+
+type replayUsecase struct {
+	repo     repository.ReplayRepository
+	archive  repository.ArchiveRepository
+	tenants  repository.TenantRepository
+	messages repository.MessageRepository
+	mq       messaging.Messagging
+	log      *logrus.Logger
+
+	workerID   string
+	rate       float64
+	maxRate    float64
+	staleAfter time.Duration
+}
+
+// NewReplayUsecase initializes a new replay usecase
+func NewReplayUsecase(repo repository.ReplayRepository, archive repository.ArchiveRepository, tenants repository.TenantRepository, messages repository.MessageRepository, mq messaging.Messagging, log *logrus.Logger, cfg ReplayConfig) ReplayUsecase {
+	if cfg.Rate <= 0 {
+		cfg.Rate = 100
+	}
+	if cfg.MaxRate < cfg.Rate {
+		cfg.MaxRate = cfg.Rate
+	}
+	if cfg.StaleAfter <= 0 {
+		cfg.StaleAfter = 30 * time.Second
+	}
+	return &replayUsecase{
+		repo:       repo,
+		archive:    archive,
+		tenants:    tenants,
+		messages:   messages,
+		mq:         mq,
+		log:        log,
+		workerID:   cfg.WorkerID,
+		rate:       cfg.Rate,
+		maxRate:    cfg.MaxRate,
+		staleAfter: cfg.StaleAfter,
+	}
+}
+
+// CreateReplay queues a replay job, which is run by the next available worker
+func (s *replayUsecase) CreateReplay(ctx context.Context, job *model.ReplayJob) (_ *model.ReplayJob, err error) {
+	defer derrors.Wrap(&err, "CreateReplay(%q)", job.ClientID)
+
+	job.Status = model.ReplayPending
+	if err := s.prepare(ctx, job); err != nil {
+		return nil, err
+	}
+	if err := s.repo.CreateJob(ctx, job); err != nil {
+		return nil, err
+	}
+
+	logger.WithContext(logger.WithClientID(ctx, job.ClientID), s.log).Infof("Replay %s of %d messages queued", job.ID, job.Total)
+	return job, nil
+}
+
+// Replay runs a replay job in the caller until it completes or ctx is
+// cancelled, calling progress after every published chunk. A job left
+// running when ctx is cancelled is resumed by a worker.
+func (s *replayUsecase) Replay(ctx context.Context, job *model.ReplayJob, progress func(*model.ReplayJob)) (_ *model.ReplayJob, err error) {
+	defer derrors.Wrap(&err, "Replay(%q)", job.ClientID)
+
+	job.Status = model.ReplayRunning
+	job.WorkerID = s.workerID
+	if err := s.prepare(ctx, job); err != nil {
+		return nil, err
+	}
+	if err := s.repo.CreateJob(ctx, job); err != nil {
+		return nil, err
+	}
+
+	if err := s.run(ctx, job, progress); err != nil {
+		return job, err
+	}
+	return job, nil
+}
+
+// GetReplay returns a replay job of a tenant and its progress
+func (s *replayUsecase) GetReplay(ctx context.Context, clientID, jobID string) (job *model.ReplayJob, err error) {
+	defer derrors.Wrap(&err, "GetReplay(%q, %q)", clientID, jobID)
+
+	job, err = s.repo.GetJob(ctx, clientID, jobID)
+	if derrors.IsErrCode(err, derrors.NotFound) {
+		return nil, derrors.New(derrors.NotFound, "replay job not found")
+	}
+	return job, err
+}
+
+// CancelReplay stops a pending or running replay job
+func (s *replayUsecase) CancelReplay(ctx context.Context, clientID, jobID string) (job *model.ReplayJob, err error) {
+	defer derrors.Wrap(&err, "CancelReplay(%q, %q)", clientID, jobID)
+
+	job, err = s.GetReplay(ctx, clientID, jobID)
+	if err != nil {
+		return nil, err
+	}
+	if job.Done() {
+		return nil, derrors.New(derrors.Conflict, "replay job is already %s", job.Status)
+	}
+	if err := s.repo.CancelJob(ctx, clientID, jobID); err != nil {
+		return nil, err
+	}
+	return s.GetReplay(ctx, clientID, jobID)
+}
+
+// RunReplays runs the queued replay jobs one at a time until ctx is
+// cancelled, looking for new jobs every interval
+func (s *replayUsecase) RunReplays(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		job, err := s.repo.ClaimJob(ctx, s.workerID, s.staleAfter)
+		switch {
+		case err == nil:
+			jobCtx := logger.WithClientID(ctx, job.ClientID)
+			logger.WithContext(jobCtx, s.log).Infof("Running replay %s", job.ID)
+			if err := s.run(jobCtx, job, nil); err != nil {
+				logger.WithContext(jobCtx, s.log).Errorf("replay %s failed: %v", job.ID, err)
+			}
+			// Look for the next job right away
+			continue
+		case !derrors.IsErrCode(err, derrors.NotFound):
+			logger.WithContext(ctx, s.log).Errorf("failed to claim replay job: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// prepare validates a new job and counts the messages it replays, a job
+// without an end replays the messages archived until now
+func (s *replayUsecase) prepare(ctx context.Context, job *model.ReplayJob) error {
+	if job.From == nil && len(job.MessageIDs) == 0 {
+		return derrors.New(derrors.InvalidArgument, "a replay needs a start time or message IDs")
+	}
+	if job.From != nil && job.To != nil && !job.To.After(*job.From) {
+		return derrors.New(derrors.InvalidArgument, "the end of a replay must be after its start")
+	}
+	if len(job.MessageIDs) > maxReplayMessageIDs {
+		return derrors.New(derrors.InvalidArgument, "a replay accepts at most %d message IDs", maxReplayMessageIDs)
+	}
+	if job.Rate == 0 {
+		job.Rate = s.rate
+	}
+	if job.Rate < 0 || job.Rate > s.maxRate {
+		return derrors.New(derrors.InvalidArgument, "the rate of a replay must be between 0 and %g messages per second", s.maxRate)
+	}
+
+	if _, err := s.tenants.GetTenantByClientID(ctx, job.ClientID); err != nil {
+		return err
+	}
+
+	if job.To == nil {
+		// Stop at the messages archived so far, live traffic would otherwise
+		// keep the replay from ever catching up
+		now := time.Now().UTC()
+		job.To = &now
+	}
+	total, err := s.archive.Count(ctx, replayQuery(job))
+	if err != nil {
+		return err
+	}
+	job.ID = ksuid.New().String()
+	job.Total = total
+	return nil
+}
+
+// run publishes the archived messages of a job, oldest first, resuming
+// after its cursor. The job is failed on errors other than the cancellation
+// of ctx.
+func (s *replayUsecase) run(ctx context.Context, job *model.ReplayJob, progress func(*model.ReplayJob)) error {
+	burst := min(max(int(job.Rate), 1), replayPageSize)
+	limiter := rate.NewLimiter(rate.Limit(job.Rate), burst)
+	query := replayQuery(job)
+	query.Limit = replayPageSize
+
+	for {
+		query.After = job.Cursor
+		messages, err := s.archive.Search(ctx, query)
+		if err != nil {
+			return s.fail(ctx, job, err)
+		}
+		if len(messages) == 0 {
+			job.Status = model.ReplayCompleted
+			return s.finish(ctx, job)
+		}
+
+		for len(messages) > 0 {
+			chunk := messages[:min(burst, len(messages))]
+			messages = messages[len(chunk):]
+
+			if err := limiter.WaitN(ctx, len(chunk)); err != nil {
+				// Left running, another worker resumes it once stale
+				return ctx.Err()
+			}
+			if err := s.publish(ctx, job, chunk); err != nil {
+				return s.fail(ctx, job, err)
+			}
+
+			last := chunk[len(chunk)-1]
+			job.Cursor = &model.ArchiveCursor{PublishedAt: last.PublishedAt, MessageID: last.MessageID}
+			running, err := s.repo.UpdateProgress(ctx, job)
+			if err != nil {
+				return s.fail(ctx, job, err)
+			}
+			if !running {
+				logger.WithContext(ctx, s.log).Infof("Replay %s stopped, it was cancelled or taken over", job.ID)
+				job.Status = model.ReplayCancelled
+				return nil
+			}
+			if progress != nil {
+				progress(job)
+			}
+		}
+	}
+}
+
+// publish republishes archived messages to the queue of their tenant, under
+// new message IDs and with headers pointing at the originals
+func (s *replayUsecase) publish(ctx context.Context, job *model.ReplayJob, archived []*model.ArchivedMessage) error {
+	messages := make([]messaging.Message, len(archived))
+	tracked := make([]*model.Message, len(archived))
+	for i, original := range archived {
+		headers := make(map[string]string, len(original.Headers)+2)
+		for key, value := range original.Headers {
+			headers[key] = value
+		}
+		headers[messaging.HeaderReplayOf] = original.MessageID
+		headers[messaging.HeaderReplayJob] = job.ID
+
+		messages[i] = messaging.Message{
+			ID:            ksuid.New().String(),
+			Body:          json.RawMessage(original.Payload),
+			Headers:       headers,
+			CorrelationID: original.CorrelationID,
+			ContentType:   original.ContentType,
+		}
+		tracked[i] = &model.Message{ID: messages[i].ID, ClientID: job.ClientID, CorrelationID: original.CorrelationID}
+	}
+
+	if err := s.messages.CreateMessages(ctx, tracked...); err != nil {
+		return err
+	}
+	errs := s.mq.PublishBatch(ctx, processQueueName(job.ClientID), messages)
+
+	events := make([]*model.MessageEvent, len(messages))
+	for i, message := range messages {
+		events[i] = &model.MessageEvent{MessageID: message.ID, Status: model.MessagePublished}
+		if errs[i] != nil {
+			events[i].Status, events[i].Detail = model.MessageFailed, errs[i].Error()
+			job.Failed++
+			continue
+		}
+		job.Published++
+	}
+	if err := s.messages.RecordEvents(ctx, events...); err != nil {
+		logger.WithContext(ctx, s.log).Errorf("failed to record %d message events: %v", len(events), err)
+	}
+	return nil
+}
+
+func (s *replayUsecase) fail(ctx context.Context, job *model.ReplayJob, cause error) error {
+	if ctx.Err() != nil {
+		// Left running, another worker resumes it once stale
+		return ctx.Err()
+	}
+	job.Status = model.ReplayFailed
+	job.Error = cause.Error()
+	if err := s.finish(ctx, job); err != nil {
+		logger.WithContext(ctx, s.log).Errorf("failed to record failure of replay %s: %v", job.ID, err)
+	}
+	return cause
+}
+
+func (s *replayUsecase) finish(ctx context.Context, job *model.ReplayJob) error {
+	if err := s.repo.FinishJob(ctx, job); err != nil {
+		return err
+	}
+	logger.WithContext(ctx, s.log).Infof("Replay %s %s: %d published, %d failed", job.ID, job.Status, job.Published, job.Failed)
+	return nil
+}
+
+// replayQuery selects the archived messages of a job, oldest first
+func replayQuery(job *model.ReplayJob) *model.ArchiveQuery {
+	query := &model.ArchiveQuery{ClientID: job.ClientID, MessageIDs: job.MessageIDs, Ascending: true}
+	if job.From != nil {
+		query.From = *job.From
+	}
+	if job.To != nil {
+		query.To = *job.To
+	}
+	return query
+}
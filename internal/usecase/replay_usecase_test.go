@@ -0,0 +1,196 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"tenant/internal/model"
+	"tenant/internal/service/messaging"
+	"tenant/internal/test/mockrepository"
+	"tenant/internal/test/mockservice"
+	"tenant/pkg/derrors"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestReplay(t *testing.T) {
+	ctx := context.Background()
+	from := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	publishedAt := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	archived := []*model.ArchivedMessage{
+		{MessageID: "msg-1", ClientID: "test-client", CorrelationID: "corr-1", ContentType: "application/json", Headers: map[string]string{"x-source": "billing"}, Payload: []byte(`{"n":1}`), PublishedAt: publishedAt},
+		{MessageID: "msg-2", ClientID: "test-client", ContentType: "application/json", Payload: []byte(`{"n":2}`), PublishedAt: publishedAt.Add(time.Second)},
+	}
+
+	// Each case starts with fresh mocks, the expectations capture the variables
+	var (
+		mockReplay   *mockrepository.ReplayRepository
+		mockArchive  *mockrepository.ArchiveRepository
+		mockRepo     *mockrepository.TenantRepository
+		mockMessages *mockrepository.MessageRepository
+		mockMQ       *mockservice.Messagging
+	)
+
+	var testCases = []struct {
+		caseName     string
+		job          *model.ReplayJob
+		expectations func()
+		results      func(job *model.ReplayJob, err error)
+	}{
+		{
+			caseName: "Replay_Completed",
+			job:      &model.ReplayJob{ClientID: "test-client", From: &from},
+			expectations: func() {
+				mockRepo.On("GetTenantByClientID", mock.Anything, "test-client").Return(&model.Tenant{ClientID: "test-client"}, nil).Once()
+				mockArchive.On("Count", mock.Anything, mock.MatchedBy(func(query *model.ArchiveQuery) bool {
+					return query.From.Equal(from) && !query.To.IsZero() && query.Ascending
+				})).Return(int64(2), nil).Once()
+				mockReplay.On("CreateJob", mock.Anything, mock.MatchedBy(func(job *model.ReplayJob) bool {
+					return job.Status == model.ReplayRunning && job.WorkerID == "cli" && job.Rate == 100 && job.Total == 2
+				})).Return(nil).Once()
+
+				mockArchive.On("Search", mock.Anything, mock.MatchedBy(func(query *model.ArchiveQuery) bool {
+					return query.After == nil && query.Limit == replayPageSize && !query.To.IsZero()
+				})).Return(archived, nil).Once()
+				mockMessages.On("CreateMessages", mock.Anything, mock.Anything, mock.Anything).Return(nil).Once()
+				mockMQ.On("PublishBatch", mock.Anything, "test-client.process", mock.MatchedBy(func(messages []messaging.Message) bool {
+					return len(messages) == 2 &&
+						messages[0].ID != "msg-1" && messages[0].CorrelationID == "corr-1" &&
+						messages[0].Headers["x-source"] == "billing" && messages[0].Headers[messaging.HeaderReplayOf] == "msg-1" &&
+						string(messages[1].Body.(json.RawMessage)) == `{"n":2}`
+				})).Return([]error{nil, errors.New("nacked")}).Once()
+				mockMessages.On("RecordEvents", mock.Anything, mock.Anything, mock.Anything).Return(nil).Once()
+				mockReplay.On("UpdateProgress", mock.Anything, mock.MatchedBy(func(job *model.ReplayJob) bool {
+					return job.Cursor.MessageID == "msg-2" && job.Published == 1 && job.Failed == 1
+				})).Return(true, nil).Once()
+
+				mockArchive.On("Search", mock.Anything, mock.MatchedBy(func(query *model.ArchiveQuery) bool {
+					return query.After != nil && query.After.MessageID == "msg-2"
+				})).Return([]*model.ArchivedMessage{}, nil).Once()
+				mockReplay.On("FinishJob", mock.Anything, mock.MatchedBy(func(job *model.ReplayJob) bool {
+					return job.Status == model.ReplayCompleted
+				})).Return(nil).Once()
+			},
+			results: func(job *model.ReplayJob, err error) {
+				assert.Nil(t, err)
+				assert.Equal(t, model.ReplayCompleted, job.Status)
+				assert.WithinDuration(t, time.Now(), *job.To, time.Minute)
+				assert.Equal(t, int64(1), job.Published)
+				assert.Equal(t, int64(1), job.Failed)
+			},
+		},
+		{
+			caseName: "Replay_Cancelled",
+			job:      &model.ReplayJob{ClientID: "test-client", MessageIDs: []string{"msg-1"}},
+			expectations: func() {
+				mockRepo.On("GetTenantByClientID", mock.Anything, "test-client").Return(&model.Tenant{ClientID: "test-client"}, nil).Once()
+				mockArchive.On("Count", mock.Anything, mock.Anything).Return(int64(1), nil).Once()
+				mockReplay.On("CreateJob", mock.Anything, mock.Anything).Return(nil).Once()
+				mockArchive.On("Search", mock.Anything, mock.Anything).Return(archived[:1], nil).Once()
+				mockMessages.On("CreateMessages", mock.Anything, mock.Anything).Return(nil).Once()
+				mockMQ.On("PublishBatch", mock.Anything, "test-client.process", mock.Anything).Return([]error{nil}).Once()
+				mockMessages.On("RecordEvents", mock.Anything, mock.Anything).Return(nil).Once()
+				mockReplay.On("UpdateProgress", mock.Anything, mock.Anything).Return(false, nil).Once()
+			},
+			results: func(job *model.ReplayJob, err error) {
+				assert.Nil(t, err)
+				assert.Equal(t, model.ReplayCancelled, job.Status)
+			},
+		},
+		{
+			caseName: "Replay_SearchFailed",
+			job:      &model.ReplayJob{ClientID: "test-client", From: &from},
+			expectations: func() {
+				mockRepo.On("GetTenantByClientID", mock.Anything, "test-client").Return(&model.Tenant{ClientID: "test-client"}, nil).Once()
+				mockArchive.On("Count", mock.Anything, mock.Anything).Return(int64(2), nil).Once()
+				mockReplay.On("CreateJob", mock.Anything, mock.Anything).Return(nil).Once()
+				mockArchive.On("Search", mock.Anything, mock.Anything).Return(nil, errors.New("connection reset")).Once()
+				mockReplay.On("FinishJob", mock.Anything, mock.MatchedBy(func(job *model.ReplayJob) bool {
+					return job.Status == model.ReplayFailed && job.Error == "connection reset"
+				})).Return(nil).Once()
+			},
+			results: func(job *model.ReplayJob, err error) {
+				assert.NotNil(t, err)
+				assert.Equal(t, model.ReplayFailed, job.Status)
+			},
+		},
+		{
+			caseName:     "Replay_NoSelection",
+			job:          &model.ReplayJob{ClientID: "test-client"},
+			expectations: func() {},
+			results: func(job *model.ReplayJob, err error) {
+				assert.Nil(t, job)
+				assert.True(t, derrors.IsErrCode(err, derrors.InvalidArgument))
+			},
+		},
+		{
+			caseName:     "Replay_RateTooHigh",
+			job:          &model.ReplayJob{ClientID: "test-client", From: &from, Rate: 5000},
+			expectations: func() {},
+			results: func(job *model.ReplayJob, err error) {
+				assert.Nil(t, job)
+				assert.True(t, derrors.IsErrCode(err, derrors.InvalidArgument))
+			},
+		},
+		{
+			caseName: "Replay_TenantNotFound",
+			job:      &model.ReplayJob{ClientID: "missing-client", From: &from},
+			expectations: func() {
+				mockRepo.On("GetTenantByClientID", mock.Anything, "missing-client").Return(nil, derrors.New(derrors.NotFound, "tenant not found")).Once()
+			},
+			results: func(job *model.ReplayJob, err error) {
+				assert.Nil(t, job)
+				assert.True(t, derrors.IsErrCode(err, derrors.NotFound))
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.caseName, func(t *testing.T) {
+			mockReplay = new(mockrepository.ReplayRepository)
+			mockArchive = new(mockrepository.ArchiveRepository)
+			mockRepo = new(mockrepository.TenantRepository)
+			mockMessages = new(mockrepository.MessageRepository)
+			mockMQ = new(mockservice.Messagging)
+			replayUsecase := NewReplayUsecase(mockReplay, mockArchive, mockRepo, mockMessages, mockMQ, logrus.New(), ReplayConfig{WorkerID: "cli", MaxRate: 1000})
+
+			testCase.expectations()
+			job, err := replayUsecase.Replay(ctx, testCase.job, nil)
+			testCase.results(job, err)
+
+			mockReplay.AssertExpectations(t)
+			mockArchive.AssertExpectations(t)
+			mockRepo.AssertExpectations(t)
+			mockMessages.AssertExpectations(t)
+			mockMQ.AssertExpectations(t)
+		})
+	}
+}
+
+func TestCancelReplay(t *testing.T) {
+	ctx := context.Background()
+	mockReplay := new(mockrepository.ReplayRepository)
+	replayUsecase := NewReplayUsecase(mockReplay, nil, nil, nil, nil, logrus.New(), ReplayConfig{})
+
+	mockReplay.On("GetJob", mock.Anything, "test-client", "job-1").Return(&model.ReplayJob{ID: "job-1", Status: model.ReplayRunning}, nil).Once()
+	mockReplay.On("CancelJob", mock.Anything, "test-client", "job-1").Return(nil).Once()
+	mockReplay.On("GetJob", mock.Anything, "test-client", "job-1").Return(&model.ReplayJob{ID: "job-1", Status: model.ReplayCancelled}, nil).Once()
+
+	job, err := replayUsecase.CancelReplay(ctx, "test-client", "job-1")
+	assert.Nil(t, err)
+	assert.Equal(t, model.ReplayCancelled, job.Status)
+
+	mockReplay.On("GetJob", mock.Anything, "test-client", "job-2").Return(&model.ReplayJob{ID: "job-2", Status: model.ReplayCompleted}, nil).Once()
+	_, err = replayUsecase.CancelReplay(ctx, "test-client", "job-2")
+	assert.True(t, derrors.IsErrCode(err, derrors.Conflict))
+
+	mockReplay.On("GetJob", mock.Anything, "test-client", "job-3").Return(nil, derrors.New(derrors.NotFound, "no rows")).Once()
+	_, err = replayUsecase.CancelReplay(ctx, "test-client", "job-3")
+	assert.True(t, derrors.IsErrCode(err, derrors.NotFound))
+
+	mockReplay.AssertExpectations(t)
+}
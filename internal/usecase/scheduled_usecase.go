@@ -0,0 +1,229 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"tenant/internal/model"
+	"tenant/internal/repository"
+	"tenant/internal/service/messaging"
+	"tenant/pkg/derrors"
+	"tenant/pkg/logger"
+
+	"github.com/sirupsen/logrus"
+)
+
+// defaultScheduledListLimit is the number of scheduled payloads listed when
+// the request does not set one
+const defaultScheduledListLimit = 50
+
+type ScheduledUsecase interface {
+	ListScheduled(ctx context.Context, clientID string, limit int) ([]*model.ScheduledMessage, error)
+	CancelScheduled(ctx context.Context, clientID, messageID string) error
+	PublishDue(ctx context.Context) (int, error)
+	RunScheduler(ctx context.Context, interval time.Duration)
+}
+
+// ScheduledConfig tunes the publication of scheduled payloads
+type ScheduledConfig struct {
+	// BatchSize is how many due payloads an instance publishes at once
+	BatchSize int
+
+	// MaxAttempts is how many times the publication of a payload fails
+	// before it is marked failed and no longer published
+	MaxAttempts int
+
+	// Backoff delays a payload after its first failed publication, it
+	// doubles on every failure up to MaxBackoff
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+}
+
+type scheduledUsecase struct {
+	repo     repository.ScheduledRepository
+	tenants  repository.TenantRepository
+	messages repository.MessageRepository
+	archive  repository.ArchiveRepository
+	mq       messaging.Messagging
+	log      *logrus.Logger
+
+	batchSize   int
+	maxAttempts int
+	backoff     time.Duration
+	maxBackoff  time.Duration
+}
+
+// NewScheduledUsecase initializes a new scheduled usecase
+func NewScheduledUsecase(repo repository.ScheduledRepository, tenants repository.TenantRepository, messages repository.MessageRepository, archive repository.ArchiveRepository, mq messaging.Messagging, log *logrus.Logger, cfg ScheduledConfig) ScheduledUsecase {
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = 100
+	}
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = 10
+	}
+	if cfg.Backoff <= 0 {
+		cfg.Backoff = 5 * time.Second
+	}
+	if cfg.MaxBackoff < cfg.Backoff {
+		cfg.MaxBackoff = cfg.Backoff
+	}
+	return &scheduledUsecase{
+		repo:        repo,
+		tenants:     tenants,
+		messages:    messages,
+		archive:     archive,
+		mq:          mq,
+		log:         log,
+		batchSize:   cfg.BatchSize,
+		maxAttempts: cfg.MaxAttempts,
+		backoff:     cfg.Backoff,
+		maxBackoff:  cfg.MaxBackoff,
+	}
+}
+
+// ListScheduled returns the payloads of a tenant waiting for their delivery
+// time, earliest first
+func (s *scheduledUsecase) ListScheduled(ctx context.Context, clientID string, limit int) (messages []*model.ScheduledMessage, err error) {
+	defer derrors.Wrap(&err, "ListScheduled(%q)", clientID)
+
+	if _, err := s.tenants.GetTenantByClientID(ctx, clientID); err != nil {
+		return nil, err
+	}
+	if limit <= 0 {
+		limit = defaultScheduledListLimit
+	}
+	return s.repo.ListScheduled(ctx, clientID, limit)
+}
+
+// CancelScheduled drops a payload before its delivery time
+func (s *scheduledUsecase) CancelScheduled(ctx context.Context, clientID, messageID string) (err error) {
+	defer derrors.Wrap(&err, "CancelScheduled(%q, %q)", clientID, messageID)
+
+	err = s.repo.CancelScheduled(ctx, clientID, messageID)
+	if derrors.IsErrCode(err, derrors.NotFound) {
+		return derrors.New(derrors.NotFound, "scheduled message not found, it may already be published")
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := s.messages.RecordEvents(ctx, &model.MessageEvent{MessageID: messageID, Status: model.MessageCancelled}); err != nil {
+		logger.WithContext(ctx, s.log).Errorf("failed to record cancellation of message %s: %v", messageID, err)
+	}
+	return nil
+}
+
+// PublishDue publishes a batch of payloads whose delivery time passed and
+// returns how many were published
+func (s *scheduledUsecase) PublishDue(ctx context.Context) (published int, err error) {
+	defer derrors.Wrap(&err, "PublishDue")
+
+	return s.repo.PublishDue(ctx, time.Now(), s.batchSize, func(scheduled []*model.ScheduledMessage) []error {
+		return s.publish(ctx, scheduled)
+	})
+}
+
+// RunScheduler publishes due payloads every interval until ctx is
+// cancelled. Full batches are followed by the next one right away.
+func (s *scheduledUsecase) RunScheduler(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		published, err := s.PublishDue(ctx)
+		if err != nil {
+			logger.WithContext(ctx, s.log).Errorf("failed to publish scheduled messages: %v", err)
+		}
+		if published > 0 {
+			logger.WithContext(ctx, s.log).Infof("Published %d scheduled messages", published)
+		}
+		if err == nil && published == s.batchSize {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// publish publishes scheduled payloads to the queues of their tenants and
+// reports an error per payload. Failed payloads are delayed, or marked failed
+// after the last attempt.
+func (s *scheduledUsecase) publish(ctx context.Context, scheduled []*model.ScheduledMessage) []error {
+	errs := make([]error, len(scheduled))
+	now := time.Now()
+
+	// Publish the payloads of a tenant together, keeping their positions
+	byClient := make(map[string][]int)
+	for i, message := range scheduled {
+		byClient[message.ClientID] = append(byClient[message.ClientID], i)
+	}
+
+	var events []*model.MessageEvent
+	var archived []*model.ArchivedMessage
+	for clientID, positions := range byClient {
+		messages := make([]messaging.Message, len(positions))
+		for j, i := range positions {
+			messages[j] = messaging.Message{
+				ID:            scheduled[i].MessageID,
+				Body:          json.RawMessage(scheduled[i].Payload),
+				Headers:       scheduled[i].Headers,
+				CorrelationID: scheduled[i].CorrelationID,
+				ContentType:   scheduled[i].ContentType,
+				Priority:      scheduled[i].Priority,
+				Expiration:    scheduled[i].Expiration,
+			}
+		}
+
+		for j, err := range s.mq.PublishBatch(ctx, processQueueName(clientID), messages) {
+			i := positions[j]
+			if err != nil {
+				errs[i] = err
+				detail := s.retryLater(ctx, scheduled[i], err, now)
+				events = append(events, &model.MessageEvent{MessageID: messages[j].ID, Status: model.MessageFailed, Detail: detail})
+				continue
+			}
+			events = append(events, &model.MessageEvent{MessageID: messages[j].ID, Status: model.MessagePublished})
+			archived = append(archived, newArchivedMessage(clientID, messages[j], scheduled[i].Payload))
+		}
+	}
+
+	// The messages are already handed to the broker, failures are only logged
+	if err := s.messages.RecordEvents(ctx, events...); err != nil {
+		logger.WithContext(ctx, s.log).Errorf("failed to record %d message events: %v", len(events), err)
+	}
+	if len(archived) > 0 {
+		if err := s.archive.Archive(ctx, archived...); err != nil {
+			logger.WithContext(ctx, s.log).Errorf("failed to archive %d messages: %v", len(archived), err)
+		}
+	}
+	return errs
+}
+
+// retryLater delays a payload that failed to publish, or marks it failed once
+// it used all its attempts, and returns the detail of its failure event
+func (s *scheduledUsecase) retryLater(ctx context.Context, message *model.ScheduledMessage, err error, now time.Time) string {
+	attempts := message.Attempts + 1
+	if attempts >= s.maxAttempts {
+		message.FailedAt = &now
+		logger.WithContext(ctx, s.log).Errorf("giving up publishing scheduled message %s after %d attempts: %v", message.MessageID, attempts, err)
+		return fmt.Sprintf("gave up after %d attempts: %v", attempts, err)
+	}
+	message.DeliverAt = now.Add(s.retryDelay(attempts))
+	return err.Error()
+}
+
+// retryDelay returns how long a payload waits after its attempts-th failed
+// publication
+func (s *scheduledUsecase) retryDelay(attempts int) time.Duration {
+	delay := s.backoff
+	for i := 1; i < attempts && delay < s.maxBackoff; i++ {
+		delay *= 2
+	}
+	return min(delay, s.maxBackoff)
+}
@@ -0,0 +1,113 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"tenant/internal/model"
+	"tenant/internal/service/messaging"
+	"tenant/internal/test/mockrepository"
+	"tenant/internal/test/mockservice"
+	"tenant/pkg/derrors"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestPublishDue(t *testing.T) {
+	ctx := context.Background()
+	mockScheduled := new(mockrepository.ScheduledRepository)
+	mockMessages := new(mockrepository.MessageRepository)
+	mockArchive := new(mockrepository.ArchiveRepository)
+	mockMQ := new(mockservice.Messagging)
+	scheduledUsecase := NewScheduledUsecase(mockScheduled, nil, mockMessages, mockArchive, mockMQ, logrus.New(), ScheduledConfig{BatchSize: 10, Backoff: 5 * time.Second, MaxBackoff: time.Minute})
+
+	due := []*model.ScheduledMessage{
+		{MessageID: "msg-1", ClientID: "client-a", Payload: []byte(`{"n":1}`), Priority: 3},
+		{MessageID: "msg-2", ClientID: "client-b", Payload: []byte(`{"n":2}`)},
+		{MessageID: "msg-3", ClientID: "client-a", Payload: []byte(`{"n":3}`), Attempts: 2},
+		{MessageID: "msg-4", ClientID: "client-b", Payload: []byte(`{"n":4}`), Attempts: 9},
+	}
+
+	var errs []error
+	mockScheduled.On("PublishDue", mock.Anything, mock.AnythingOfType("time.Time"), 10, mock.Anything).
+		Run(func(args mock.Arguments) {
+			errs = args.Get(3).(func([]*model.ScheduledMessage) []error)(due)
+		}).Return(2, nil).Once()
+	mockMQ.On("PublishBatch", mock.Anything, "client-a.process", mock.MatchedBy(func(messages []messaging.Message) bool {
+		return len(messages) == 2 && messages[0].ID == "msg-1" && messages[0].Priority == 3 && messages[1].ID == "msg-3"
+	})).Return([]error{nil, errors.New("nacked")}).Once()
+	mockMQ.On("PublishBatch", mock.Anything, "client-b.process", mock.Anything).Return([]error{nil, errors.New("channel closed")}).Once()
+	mockMessages.On("RecordEvents", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil).Once()
+	mockArchive.On("Archive", mock.Anything, mock.Anything, mock.Anything).Return(nil).Once()
+
+	published, err := scheduledUsecase.PublishDue(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, published)
+	assert.Nil(t, errs[0])
+	assert.Nil(t, errs[1])
+	assert.EqualError(t, errs[2], "nacked")
+	assert.EqualError(t, errs[3], "channel closed")
+
+	// A failed payload is delayed, or marked failed after its last attempt
+	assert.WithinDuration(t, time.Now().Add(20*time.Second), due[2].DeliverAt, time.Second)
+	assert.Nil(t, due[2].FailedAt)
+	assert.True(t, due[3].DeliverAt.IsZero())
+	assert.NotNil(t, due[3].FailedAt)
+
+	mockScheduled.AssertExpectations(t)
+	mockMessages.AssertExpectations(t)
+	mockArchive.AssertExpectations(t)
+	mockMQ.AssertExpectations(t)
+}
+
+func TestScheduledRetryDelay(t *testing.T) {
+	scheduledUsecase := NewScheduledUsecase(nil, nil, nil, nil, nil, logrus.New(), ScheduledConfig{
+		Backoff:    5 * time.Second,
+		MaxBackoff: time.Minute,
+	}).(*scheduledUsecase)
+
+	assert.Equal(t, 5*time.Second, scheduledUsecase.retryDelay(1))
+	assert.Equal(t, 10*time.Second, scheduledUsecase.retryDelay(2))
+	assert.Equal(t, 40*time.Second, scheduledUsecase.retryDelay(4))
+	assert.Equal(t, time.Minute, scheduledUsecase.retryDelay(5))
+	assert.Equal(t, time.Minute, scheduledUsecase.retryDelay(100))
+}
+
+func TestCancelScheduled(t *testing.T) {
+	ctx := context.Background()
+	mockScheduled := new(mockrepository.ScheduledRepository)
+	mockMessages := new(mockrepository.MessageRepository)
+	scheduledUsecase := NewScheduledUsecase(mockScheduled, nil, mockMessages, nil, nil, logrus.New(), ScheduledConfig{})
+
+	mockScheduled.On("CancelScheduled", mock.Anything, "test-client", "msg-1").Return(nil).Once()
+	mockMessages.On("RecordEvents", mock.Anything, hasStatus(model.MessageCancelled)).Return(nil).Once()
+	assert.Nil(t, scheduledUsecase.CancelScheduled(ctx, "test-client", "msg-1"))
+
+	mockScheduled.On("CancelScheduled", mock.Anything, "test-client", "msg-2").Return(derrors.New(derrors.NotFound, "no scheduled message")).Once()
+	err := scheduledUsecase.CancelScheduled(ctx, "test-client", "msg-2")
+	assert.True(t, derrors.IsErrCode(err, derrors.NotFound))
+
+	mockScheduled.AssertExpectations(t)
+	mockMessages.AssertExpectations(t)
+}
+
+func TestListScheduled(t *testing.T) {
+	ctx := context.Background()
+	mockScheduled := new(mockrepository.ScheduledRepository)
+	mockRepo := new(mockrepository.TenantRepository)
+	scheduledUsecase := NewScheduledUsecase(mockScheduled, mockRepo, nil, nil, nil, logrus.New(), ScheduledConfig{})
+
+	mockRepo.On("GetTenantByClientID", mock.Anything, "test-client").Return(&model.Tenant{ClientID: "test-client"}, nil).Once()
+	mockScheduled.On("ListScheduled", mock.Anything, "test-client", defaultScheduledListLimit).
+		Return([]*model.ScheduledMessage{{MessageID: "msg-1", DeliverAt: time.Now().Add(time.Hour)}}, nil).Once()
+
+	messages, err := scheduledUsecase.ListScheduled(ctx, "test-client", 0)
+	assert.Nil(t, err)
+	assert.Len(t, messages, 1)
+
+	mockRepo.AssertExpectations(t)
+	mockScheduled.AssertExpectations(t)
+}
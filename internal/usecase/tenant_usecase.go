@@ -2,12 +2,16 @@ package usecase
 
 import (
 	"context"
-	"database/sql"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
 	"tenant/internal/model"
 	"tenant/internal/repository"
 	"tenant/internal/service/messaging"
 	"tenant/pkg/derrors"
+	"tenant/pkg/logger"
+	"time"
 
 	"github.com/segmentio/ksuid"
 	"github.com/sirupsen/logrus"
@@ -16,26 +20,72 @@ import (
 type TenantUsecase interface {
 	CreateTenant(ctx context.Context, name string) (tenant *model.Tenant, err error)
 	DeleteTenant(ctx context.Context, clientID string) error
-	ProcessPayload(ctx context.Context, clientID string, payload interface{}) error
+	ProcessPayload(ctx context.Context, clientID string, payload *model.Payload) (string, error)
+	ProcessBatch(ctx context.Context, clientID string, payloads []interface{}) (*model.BatchResult, error)
 	GetTenant(ctx context.Context, clientID string) (tenant *model.Tenant, err error)
 }
 
+// TenantConfig tunes the tenant usecase
+type TenantConfig struct {
+	// IdempotencyTTL is how long an Idempotency-Key is remembered
+	IdempotencyTTL time.Duration
+
+	// IdempotencyTimeout is how long an Idempotency-Key is held for a request
+	// that never completes, for example because the process crashed
+	IdempotencyTimeout time.Duration
+
+	// MaxDelay bounds how far in the future a payload may be scheduled
+	MaxDelay time.Duration
+}
+
 type tenantUsecase struct {
-	repo repository.TenantRepository
-	mq   messaging.Messagging
+	repo        repository.TenantRepository
+	idempotency repository.IdempotencyRepository
+	messages    repository.MessageRepository
+	archive     repository.ArchiveRepository
+	scheduled   repository.ScheduledRepository
+	mq          messaging.Messagging
+	log         *logrus.Logger
+
+	idempotencyTTL     time.Duration
+	idempotencyTimeout time.Duration
+	maxDelay           time.Duration
 }
 
 // NewTenantUsecase initializes a new tenant usecase
-func NewTenantUsecase(repo repository.TenantRepository, mq messaging.Messagging) TenantUsecase {
-	return &tenantUsecase{repo: repo, mq: mq}
+func NewTenantUsecase(repo repository.TenantRepository, idempotency repository.IdempotencyRepository, messages repository.MessageRepository, archive repository.ArchiveRepository, scheduled repository.ScheduledRepository, mq messaging.Messagging, log *logrus.Logger, cfg TenantConfig) TenantUsecase {
+	if cfg.IdempotencyTTL <= 0 {
+		cfg.IdempotencyTTL = 24 * time.Hour
+	}
+	if cfg.IdempotencyTimeout <= 0 {
+		cfg.IdempotencyTimeout = time.Minute
+	}
+	if cfg.MaxDelay <= 0 {
+		cfg.MaxDelay = 7 * 24 * time.Hour
+	}
+	return &tenantUsecase{
+		repo:               repo,
+		idempotency:        idempotency,
+		messages:           messages,
+		archive:            archive,
+		scheduled:          scheduled,
+		mq:                 mq,
+		log:                log,
+		idempotencyTTL:     cfg.IdempotencyTTL,
+		idempotencyTimeout: cfg.IdempotencyTimeout,
+		maxDelay:           cfg.MaxDelay,
+	}
 }
 
-// CreateTenant creates a new tenant and its associated RabbitMQ queue
+// CreateTenant creates a new tenant and its associated RabbitMQ queue. The
+// queue is consumed by the worker processes, which pick up the new tenant on
+// their next sync.
 func (s *tenantUsecase) CreateTenant(ctx context.Context, name string) (tenant *model.Tenant, err error) {
 	defer derrors.Wrap(&err, "CreateTenant(%q)", name)
 
 	// Generate a unique Client ID
 	clientID := ksuid.New().String()
+	ctx = logger.WithClientID(ctx, clientID)
 
 	// Prepare tenant model
 	tenant = &model.Tenant{
@@ -50,7 +100,7 @@ func (s *tenantUsecase) CreateTenant(ctx context.Context, name string) (tenant *
 	}
 
 	// Define queue name
-	queueName := fmt.Sprintf("%s.process", clientID)
+	queueName := processQueueName(clientID)
 
 	// Create RabbitMQ queue for the tenant
 	err = s.mq.CreateQueue(queueName)
@@ -63,32 +113,13 @@ func (s *tenantUsecase) CreateTenant(ctx context.Context, name string) (tenant *
 		return nil, err
 	}
 
-	// Start RabbitMQ consumer for the queue
-	err = s.mq.StartQueue(ctx, queueName, func(message string) {
-		// Log or process the message here
-		logrus.Infof("Processing message for tenant %s: %s\n", clientID, message)
-	})
-	if err != nil {
-		// Rollback tenant creation if starting consumer fails
-		err = s.repo.SoftDeleteTenant(ctx, clientID)
-		if err != nil {
-			return nil, err
-		}
-
-		err = s.mq.DeleteQueue(queueName)
-		if err != nil {
-			return nil, err
-		}
-
-		return nil, err
-	}
-
 	return tenant, nil
 }
 
-// DeleteTenant deletes a tenant and its associated RabbitMQ queue
+// DeleteTenant deletes a tenant and its associated RabbitMQ queues
 func (s *tenantUsecase) DeleteTenant(ctx context.Context, clientID string) (err error) {
 	defer derrors.Wrap(&err, "DeleteTenant(%q)", clientID)
+	ctx = logger.WithClientID(ctx, clientID)
 
 	// Soft delete tenant
 	err = s.repo.SoftDeleteTenant(ctx, clientID)
@@ -97,7 +128,7 @@ func (s *tenantUsecase) DeleteTenant(ctx context.Context, clientID string) (err
 	}
 
 	// Define queue name
-	queueName := fmt.Sprintf("%s.process", clientID)
+	queueName := processQueueName(clientID)
 
 	// Delete RabbitMQ queue
 	err = s.mq.DeleteQueue(queueName)
@@ -105,34 +136,273 @@ func (s *tenantUsecase) DeleteTenant(ctx context.Context, clientID string) (err
 		return err
 	}
 
-	return
+	// Delete the dead letter queue, it only exists once a message failed
+	return s.mq.DeleteQueue(deadLetterQueueName(clientID))
 }
 
-// ProcessPayload publishes a payload to the RabbitMQ queue of a specific tenant
-func (s *tenantUsecase) ProcessPayload(ctx context.Context, clientID string, payload interface{}) (err error) {
+// ProcessPayload publishes a payload to the RabbitMQ queue of a specific
+// tenant and returns the ID of the published message. A payload with a
+// future DeliverAt is stored until then instead. A payload with an
+// idempotency key already published returns the original message ID without
+// publishing it again.
+func (s *tenantUsecase) ProcessPayload(ctx context.Context, clientID string, payload *model.Payload) (messageID string, err error) {
 	defer derrors.Wrap(&err, "ProcessPayload(%q)", clientID)
+	ctx = logger.WithClientID(ctx, clientID)
+
+	scheduled := payload.DeliverAt.After(time.Now())
+	if scheduled && time.Until(payload.DeliverAt) > s.maxDelay {
+		return "", derrors.New(derrors.InvalidArgument, "payloads may be scheduled at most %s ahead", s.maxDelay)
+	}
 
-	// Validate tenant existence
+	// Validate tenant existence, a missing tenant is reported as NotFound
 	tenant, err := s.repo.GetTenantByClientID(ctx, clientID)
-	if err != nil && err != sql.ErrNoRows {
-		return err
+	if err != nil {
+		return "", err
 	}
 
-	if tenant == nil {
-		return derrors.New(derrors.NotFound, "tenant not found")
+	if payload.IdempotencyKey != "" {
+		messageID, err := s.reserveIdempotencyKey(ctx, clientID, payload)
+		if err != nil || messageID != "" {
+			return messageID, err
+		}
+	}
+
+	// Encode the body once, it is both published and archived
+	body, err := json.Marshal(payload.Body)
+	if err != nil {
+		s.releaseIdempotencyKey(ctx, clientID, payload)
+		return "", derrors.WrapStack(err, derrors.InvalidArgument, "failed to encode payload")
 	}
 
 	// Define queue name
-	queueName := fmt.Sprintf("%s.process", clientID)
+	queueName := processQueueName(clientID)
 
 	// Publish payload to the queue
-	err = s.mq.Publish(ctx, queueName, payload)
+	message := messaging.Message{
+		ID:            ksuid.New().String(),
+		Body:          json.RawMessage(body),
+		Headers:       payload.Headers,
+		CorrelationID: payload.CorrelationID,
+		ContentType:   payload.ContentType,
+		Priority:      payload.Priority,
+		Expiration:    payload.Expiration,
+	}
+
+	// Track the message before it can be consumed
+	err = s.messages.CreateMessages(ctx, &model.Message{ID: message.ID, ClientID: clientID, CorrelationID: payload.CorrelationID})
+	if err != nil {
+		s.releaseIdempotencyKey(ctx, clientID, payload)
+		return "", err
+	}
+
+	if scheduled {
+		return s.schedulePayload(ctx, clientID, payload, message, body)
+	}
+
+	err = s.mq.Publish(ctx, queueName, message)
 	if err != nil {
-		return derrors.New(derrors.Unknown, "failed to publish payload to queue %s for tenant %s: %v", queueName, tenant.Name, err)
+		s.recordEvents(ctx, &model.MessageEvent{MessageID: message.ID, Status: model.MessageFailed, Detail: err.Error()})
+		s.releaseIdempotencyKey(ctx, clientID, payload)
+		return "", derrors.HandleAMQPError(err, "failed to publish payload to queue %s for tenant %s", queueName, tenant.Name)
 	}
+	s.recordEvents(ctx, &model.MessageEvent{MessageID: message.ID, Status: model.MessagePublished})
+	s.archiveMessages(ctx, newArchivedMessage(clientID, message, body))
+	s.completeIdempotencyKey(ctx, clientID, payload, message.ID)
 
-	logrus.Infof("Payload successfully published to queue %s for tenant %s\n", queueName, tenant.Name)
-	return nil
+	logger.WithContext(ctx, s.log).Infof("Payload %s successfully published to queue %s for tenant %s", message.ID, queueName, tenant.Name)
+	return message.ID, nil
+}
+
+// schedulePayload stores a tracked message until the DeliverAt of its
+// payload, the scheduler publishes it then
+func (s *tenantUsecase) schedulePayload(ctx context.Context, clientID string, payload *model.Payload, message messaging.Message, body []byte) (string, error) {
+	err := s.scheduled.Schedule(ctx, &model.ScheduledMessage{
+		MessageID:     message.ID,
+		ClientID:      clientID,
+		Payload:       body,
+		Headers:       message.Headers,
+		CorrelationID: message.CorrelationID,
+		ContentType:   message.ContentType,
+		Priority:      message.Priority,
+		Expiration:    message.Expiration,
+		DeliverAt:     payload.DeliverAt,
+	})
+	if err != nil {
+		s.recordEvents(ctx, &model.MessageEvent{MessageID: message.ID, Status: model.MessageFailed, Detail: err.Error()})
+		s.releaseIdempotencyKey(ctx, clientID, payload)
+		return "", err
+	}
+	s.recordEvents(ctx, &model.MessageEvent{MessageID: message.ID, Status: model.MessageScheduled, Detail: payload.DeliverAt.UTC().Format(time.RFC3339)})
+	s.completeIdempotencyKey(ctx, clientID, payload, message.ID)
+
+	logger.WithContext(ctx, s.log).Infof("Payload %s scheduled for %s", message.ID, payload.DeliverAt.UTC().Format(time.RFC3339))
+	return message.ID, nil
+}
+
+// completeIdempotencyKey records the message accepted for the idempotency
+// key of payload. It runs even when the client is gone, as a client timing
+// out is the usual reason for a retry.
+func (s *tenantUsecase) completeIdempotencyKey(ctx context.Context, clientID string, payload *model.Payload, messageID string) {
+	if payload.IdempotencyKey == "" {
+		return
+	}
+	ctx = context.WithoutCancel(ctx)
+	if err := s.idempotency.Complete(ctx, clientID, payload.IdempotencyKey, messageID, s.idempotencyTTL); err != nil {
+		// The message is accepted, a retry with this key is reported as still in progress
+		logger.WithContext(ctx, s.log).Errorf("failed to record message %s for idempotency key %q: %v", messageID, payload.IdempotencyKey, err)
+	}
+}
+
+// releaseIdempotencyKey lets the client retry a payload that was not
+// published with the same idempotency key, even when the client is gone
+func (s *tenantUsecase) releaseIdempotencyKey(ctx context.Context, clientID string, payload *model.Payload) {
+	if payload.IdempotencyKey == "" {
+		return
+	}
+	ctx = context.WithoutCancel(ctx)
+	if err := s.idempotency.Release(ctx, clientID, payload.IdempotencyKey); err != nil {
+		logger.WithContext(ctx, s.log).Errorf("failed to release idempotency key %q: %v", payload.IdempotencyKey, err)
+	}
+}
+
+// recordEvents records status transitions of published messages. The
+// messages are already handed to the broker, so a failure is only logged and
+// the client going away does not cut the recording short.
+func (s *tenantUsecase) recordEvents(ctx context.Context, events ...*model.MessageEvent) {
+	ctx = context.WithoutCancel(ctx)
+	if err := s.messages.RecordEvents(ctx, events...); err != nil {
+		logger.WithContext(ctx, s.log).Errorf("failed to record %d message events: %v", len(events), err)
+	}
+}
+
+// archiveMessages keeps published messages for audits. The messages are
+// already handed to the broker, so a failure is only logged and the client
+// going away does not cut the archiving short.
+func (s *tenantUsecase) archiveMessages(ctx context.Context, messages ...*model.ArchivedMessage) {
+	ctx = context.WithoutCancel(ctx)
+	if err := s.archive.Archive(ctx, messages...); err != nil {
+		logger.WithContext(ctx, s.log).Errorf("failed to archive %d messages: %v", len(messages), err)
+	}
+}
+
+// newArchivedMessage describes a message published with the given encoded
+// body for the archive
+func newArchivedMessage(clientID string, message messaging.Message, body []byte) *model.ArchivedMessage {
+	contentType := message.ContentType
+	if contentType == "" {
+		contentType = messaging.DefaultContentType
+	}
+	return &model.ArchivedMessage{
+		MessageID:     message.ID,
+		ClientID:      clientID,
+		CorrelationID: message.CorrelationID,
+		ContentType:   contentType,
+		Headers:       message.Headers,
+		Payload:       body,
+		Size:          len(body),
+		PublishedAt:   time.Now(),
+	}
+}
+
+// reserveIdempotencyKey claims the idempotency key of payload. It returns the
+// ID of the message already published with this key, or an empty ID when the
+// caller holds the key and must publish.
+func (s *tenantUsecase) reserveIdempotencyKey(ctx context.Context, clientID string, payload *model.Payload) (string, error) {
+	hash, err := payloadHash(payload)
+	if err != nil {
+		return "", derrors.WrapStack(err, derrors.InvalidArgument, "failed to hash payload")
+	}
+
+	existing, err := s.idempotency.Reserve(ctx, &model.IdempotencyRecord{
+		ClientID:    clientID,
+		Key:         payload.IdempotencyKey,
+		RequestHash: hash,
+	}, s.idempotencyTimeout)
+	if err != nil {
+		return "", err
+	}
+	if existing == nil {
+		return "", nil
+	}
+
+	switch {
+	case existing.RequestHash != hash:
+		return "", derrors.New(derrors.InvalidArgument, "idempotency key %q was already used with a different payload", payload.IdempotencyKey)
+	case existing.MessageID == "":
+		return "", derrors.New(derrors.Conflict, "a request with idempotency key %q is still in progress", payload.IdempotencyKey)
+	}
+
+	logger.WithContext(ctx, s.log).Infof("Payload with idempotency key %q already published as %s", payload.IdempotencyKey, existing.MessageID)
+	return existing.MessageID, nil
+}
+
+// payloadHash fingerprints everything a client sends with a payload, so a
+// reused idempotency key can be told apart from a retry
+func payloadHash(payload *model.Payload) (string, error) {
+	b, err := json.Marshal(payload)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// ProcessBatch publishes payloads to the RabbitMQ queue of a specific tenant,
+// each with its own message ID. Only a missing tenant fails the whole batch,
+// payloads that could not be published are reported in the result.
+func (s *tenantUsecase) ProcessBatch(ctx context.Context, clientID string, payloads []interface{}) (result *model.BatchResult, err error) {
+	defer derrors.Wrap(&err, "ProcessBatch(%q)", clientID)
+	ctx = logger.WithClientID(ctx, clientID)
+
+	tenant, err := s.repo.GetTenantByClientID(ctx, clientID)
+	if err != nil {
+		return nil, err
+	}
+
+	queueName := processQueueName(clientID)
+
+	messages := make([]messaging.Message, len(payloads))
+	bodies := make([][]byte, len(payloads))
+	tracked := make([]*model.Message, len(payloads))
+	for i, payload := range payloads {
+		body, err := json.Marshal(payload)
+		if err != nil {
+			return nil, derrors.WrapStack(err, derrors.InvalidArgument, "failed to encode payload %d", i)
+		}
+		bodies[i] = body
+		messages[i] = messaging.Message{ID: ksuid.New().String(), Body: json.RawMessage(body)}
+		tracked[i] = &model.Message{ID: messages[i].ID, ClientID: clientID}
+	}
+
+	// Track the messages before they can be consumed
+	if err := s.messages.CreateMessages(ctx, tracked...); err != nil {
+		return nil, err
+	}
+	errs := s.mq.PublishBatch(ctx, queueName, messages)
+
+	result = &model.BatchResult{Results: make([]*model.BatchItemResult, 0, len(messages))}
+	events := make([]*model.MessageEvent, 0, len(messages))
+	archived := make([]*model.ArchivedMessage, 0, len(messages))
+	for i, message := range messages {
+		item := &model.BatchItemResult{Index: i, MessageID: message.ID, Status: model.BatchItemPublished}
+		event := &model.MessageEvent{MessageID: message.ID, Status: model.MessagePublished}
+		if errs[i] != nil {
+			err := derrors.HandleAMQPError(errs[i], "failed to publish payload %d", i)
+			item.Status = model.BatchItemFailed
+			item.Code = derrors.CodeOf(err).String()
+			item.Error = err.Error()
+			event.Status, event.Detail = model.MessageFailed, errs[i].Error()
+		} else {
+			archived = append(archived, newArchivedMessage(clientID, message, bodies[i]))
+		}
+		result.Add(item)
+		events = append(events, event)
+	}
+	s.recordEvents(ctx, events...)
+	s.archiveMessages(ctx, archived...)
+
+	logger.WithContext(ctx, s.log).Infof("Batch published to queue %s for tenant %s: %d published, %d failed", queueName, tenant.Name, result.Published, result.Failed)
+	return result, nil
 }
 
 // GetTenant retrieves a tenant by Client ID
@@ -143,3 +413,14 @@ func (s *tenantUsecase) GetTenant(ctx context.Context, clientID string) (tenant
 	}
 	return
 }
+
+// processQueueName returns the name of the queue payloads of a tenant are published to
+func processQueueName(clientID string) string {
+	return fmt.Sprintf("%s.process", clientID)
+}
+
+// deadLetterQueueName returns the name of the queue messages of a tenant are
+// moved to once they ran out of attempts
+func deadLetterQueueName(clientID string) string {
+	return fmt.Sprintf("%s.dlq", clientID)
+}
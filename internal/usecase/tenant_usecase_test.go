@@ -3,13 +3,18 @@ package usecase
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"tenant/internal/model"
+	"tenant/internal/service/messaging"
 	"tenant/internal/test/mockrepository"
 	"tenant/internal/test/mockservice"
+	"tenant/pkg/derrors"
 	"testing"
+	"time"
 
+	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
@@ -18,6 +23,19 @@ func mockInit() (*mockrepository.TenantRepository, *mockservice.Messagging) {
 	return new(mockrepository.TenantRepository), new(mockservice.Messagging)
 }
 
+// isBody reports whether message is published with the encoded body
+func isBody(message messaging.Message, body string) bool {
+	raw, ok := message.Body.(json.RawMessage)
+	return ok && string(raw) == body
+}
+
+// hasStatus matches a message event of the given status
+func hasStatus(status string) interface{} {
+	return mock.MatchedBy(func(event *model.MessageEvent) bool {
+		return event.MessageID != "" && event.Status == status
+	})
+}
+
 func TestCreateTenant(t *testing.T) {
 	type params struct {
 		tenantName string
@@ -25,7 +43,7 @@ func TestCreateTenant(t *testing.T) {
 
 	ctx := context.Background()
 	mockRepo, mockMQ := mockInit()
-	tenantUsecase := NewTenantUsecase(mockRepo, mockMQ)
+	tenantUsecase := NewTenantUsecase(mockRepo, new(mockrepository.IdempotencyRepository), new(mockrepository.MessageRepository), new(mockrepository.ArchiveRepository), new(mockrepository.ScheduledRepository), mockMQ, logrus.New(), TenantConfig{})
 
 	var testCases = []struct {
 		caseName     string
@@ -41,7 +59,6 @@ func TestCreateTenant(t *testing.T) {
 			expectations: func(params params) {
 				mockRepo.On("CreateTenant", mock.Anything, mock.AnythingOfType("*model.Tenant")).Return(nil)
 				mockMQ.On("CreateQueue", mock.Anything).Return(nil)
-				mockMQ.On("StartQueue", mock.Anything, mock.Anything, mock.Anything).Return(nil)
 			},
 			results: func(err error) {
 				assert.Nil(t, err)
@@ -81,7 +98,7 @@ func TestDeleteTenant(t *testing.T) {
 
 	ctx := context.Background()
 	mockRepo, mockMQ := mockInit()
-	tenantUsecase := NewTenantUsecase(mockRepo, mockMQ)
+	tenantUsecase := NewTenantUsecase(mockRepo, new(mockrepository.IdempotencyRepository), new(mockrepository.MessageRepository), new(mockrepository.ArchiveRepository), new(mockrepository.ScheduledRepository), mockMQ, logrus.New(), TenantConfig{})
 
 	var testCases = []struct {
 		caseName     string
@@ -97,6 +114,7 @@ func TestDeleteTenant(t *testing.T) {
 			expectations: func(params params) {
 				mockRepo.On("SoftDeleteTenant", mock.Anything, params.clientID).Return(nil)
 				mockMQ.On("DeleteQueue", fmt.Sprintf("%s.process", params.clientID)).Return(nil)
+				mockMQ.On("DeleteQueue", fmt.Sprintf("%s.dlq", params.clientID)).Return(nil)
 			},
 			results: func(err error) {
 				assert.Nil(t, err)
@@ -131,45 +149,129 @@ func TestDeleteTenant(t *testing.T) {
 func TestProcessPayload(t *testing.T) {
 	type params struct {
 		clientID string
-		payload  interface{}
+		payload  *model.Payload
 	}
 
 	ctx := context.Background()
 	mockRepo, mockMQ := mockInit()
-	tenantUsecase := NewTenantUsecase(mockRepo, mockMQ)
+	mockMessages := new(mockrepository.MessageRepository)
+	mockArchive := new(mockrepository.ArchiveRepository)
+	mockScheduled := new(mockrepository.ScheduledRepository)
+	tenantUsecase := NewTenantUsecase(mockRepo, new(mockrepository.IdempotencyRepository), mockMessages, mockArchive, mockScheduled, mockMQ, logrus.New(), TenantConfig{MaxDelay: 24 * time.Hour})
+	deliverAt := time.Now().Add(time.Hour)
 
 	var testCases = []struct {
 		caseName     string
 		params       params
 		expectations func(params params)
-		results      func(err error)
+		results      func(messageID string, err error)
 	}{
 		{
 			caseName: "ProcessPayload_Success",
 			params: params{
 				clientID: "test-client",
-				payload:  "test-payload",
+				payload: &model.Payload{
+					Body:          "test-payload",
+					Headers:       map[string]string{"x-source": "test"},
+					CorrelationID: "order-42",
+					Priority:      5,
+				},
 			},
 			expectations: func(params params) {
-				mockRepo.On("GetTenantByClientID", mock.Anything, params.clientID).Return(&model.Tenant{ClientID: params.clientID, Name: "Test Tenant"}, nil)
-				mockMQ.On("Publish", mock.Anything, fmt.Sprintf("%s.process", params.clientID), params.payload).Return(nil)
+				mockRepo.On("GetTenantByClientID", mock.Anything, params.clientID).Return(&model.Tenant{ClientID: params.clientID, Name: "Test Tenant"}, nil).Once()
+				mockMessages.On("CreateMessages", mock.Anything, mock.MatchedBy(func(message *model.Message) bool {
+					return message.ClientID == params.clientID && message.CorrelationID == "order-42"
+				})).Return(nil).Once()
+				mockMessages.On("RecordEvents", mock.Anything, hasStatus(model.MessagePublished)).Return(nil).Once()
+				mockArchive.On("Archive", mock.Anything, mock.MatchedBy(func(message *model.ArchivedMessage) bool {
+					return message.ClientID == params.clientID && string(message.Payload) == `"test-payload"` &&
+						message.Size == len(`"test-payload"`) && message.ContentType == messaging.DefaultContentType
+				})).Return(nil).Once()
+				mockMQ.On("Publish", mock.Anything, fmt.Sprintf("%s.process", params.clientID), mock.MatchedBy(func(message messaging.Message) bool {
+					return message.ID != "" && isBody(message, `"test-payload"`) && message.Headers["x-source"] == "test" &&
+						message.CorrelationID == "order-42" && message.Priority == 5
+				})).Return(nil).Once()
 			},
-			results: func(err error) {
+			results: func(messageID string, err error) {
 				assert.Nil(t, err)
+				assert.Len(t, messageID, 27)
 			},
 		},
 		{
-			caseName: "ProcessPayload_FailToPublish",
+			caseName: "ProcessPayload_Scheduled",
 			params: params{
 				clientID: "test-client",
-				payload:  "test-payload",
+				payload:  &model.Payload{Body: "test-payload", Headers: map[string]string{"x-source": "test"}, DeliverAt: deliverAt},
 			},
 			expectations: func(params params) {
-				mockRepo.On("GetTenantByClientID", mock.Anything, params.clientID).Return(&model.Tenant{ClientID: params.clientID, Name: "Test Tenant"}, nil)
-				mockMQ.On("Publish", mock.Anything, fmt.Sprintf("%s.process", params.clientID), params.payload).Return(errors.New("failed to publish"))
+				mockRepo.On("GetTenantByClientID", mock.Anything, params.clientID).Return(&model.Tenant{ClientID: params.clientID, Name: "Test Tenant"}, nil).Once()
+				mockMessages.On("CreateMessages", mock.Anything, mock.AnythingOfType("*model.Message")).Return(nil).Once()
+				mockScheduled.On("Schedule", mock.Anything, mock.MatchedBy(func(message *model.ScheduledMessage) bool {
+					return message.ClientID == params.clientID && string(message.Payload) == `"test-payload"` &&
+						message.Headers["x-source"] == "test" && message.DeliverAt.Equal(deliverAt)
+				})).Return(nil).Once()
+				mockMessages.On("RecordEvents", mock.Anything, hasStatus(model.MessageScheduled)).Return(nil).Once()
 			},
-			results: func(err error) {
+			results: func(messageID string, err error) {
 				assert.Nil(t, err)
+				assert.Len(t, messageID, 27)
+			},
+		},
+		{
+			caseName: "ProcessPayload_ScheduledTooFar",
+			params: params{
+				clientID: "test-client",
+				payload:  &model.Payload{Body: "test-payload", DeliverAt: time.Now().Add(48 * time.Hour)},
+			},
+			expectations: func(params params) {},
+			results: func(messageID string, err error) {
+				assert.True(t, derrors.IsErrCode(err, derrors.InvalidArgument))
+				assert.Empty(t, messageID)
+			},
+		},
+		{
+			caseName: "ProcessPayload_FailToPublish",
+			params: params{
+				clientID: "test-client",
+				payload:  &model.Payload{Body: "test-payload"},
+			},
+			expectations: func(params params) {
+				mockRepo.On("GetTenantByClientID", mock.Anything, params.clientID).Return(&model.Tenant{ClientID: params.clientID, Name: "Test Tenant"}, nil).Once()
+				mockMessages.On("CreateMessages", mock.Anything, mock.AnythingOfType("*model.Message")).Return(nil).Once()
+				mockMessages.On("RecordEvents", mock.Anything, hasStatus(model.MessageFailed)).Return(nil).Once()
+				mockMQ.On("Publish", mock.Anything, fmt.Sprintf("%s.process", params.clientID), mock.AnythingOfType("messaging.Message")).Return(errors.New("failed to publish")).Once()
+			},
+			results: func(messageID string, err error) {
+				assert.NotNil(t, err)
+				assert.Empty(t, messageID)
+			},
+		},
+		{
+			caseName: "ProcessPayload_FailToTrack",
+			params: params{
+				clientID: "test-client",
+				payload:  &model.Payload{Body: "test-payload"},
+			},
+			expectations: func(params params) {
+				mockRepo.On("GetTenantByClientID", mock.Anything, params.clientID).Return(&model.Tenant{ClientID: params.clientID, Name: "Test Tenant"}, nil).Once()
+				mockMessages.On("CreateMessages", mock.Anything, mock.AnythingOfType("*model.Message")).Return(derrors.New(derrors.Unknown, "connection refused")).Once()
+			},
+			results: func(messageID string, err error) {
+				assert.NotNil(t, err)
+				assert.Empty(t, messageID)
+			},
+		},
+		{
+			caseName: "ProcessPayload_TenantNotFound",
+			params: params{
+				clientID: "missing-client",
+				payload:  &model.Payload{Body: "test-payload"},
+			},
+			expectations: func(params params) {
+				mockRepo.On("GetTenantByClientID", mock.Anything, params.clientID).Return(nil, derrors.New(derrors.NotFound, "tenant not found"))
+			},
+			results: func(messageID string, err error) {
+				assert.True(t, derrors.IsErrCode(err, derrors.NotFound))
 			},
 		},
 	}
@@ -177,10 +279,13 @@ func TestProcessPayload(t *testing.T) {
 	for _, testCase := range testCases {
 		t.Run(testCase.caseName, func(t *testing.T) {
 			testCase.expectations(testCase.params)
-			err := tenantUsecase.ProcessPayload(ctx, testCase.params.clientID, testCase.params.payload)
-			testCase.results(err)
+			messageID, err := tenantUsecase.ProcessPayload(ctx, testCase.params.clientID, testCase.params.payload)
+			testCase.results(messageID, err)
 
 			mockRepo.AssertExpectations(t)
+			mockMessages.AssertExpectations(t)
+			mockArchive.AssertExpectations(t)
+			mockScheduled.AssertExpectations(t)
 			mockMQ.AssertExpectations(t)
 		})
 	}
@@ -192,7 +297,7 @@ func TestGetTenant(t *testing.T) {
 
 	ctx := context.Background()
 	mockRepo, mockMQ := mockInit()
-	tenantUsecase := NewTenantUsecase(mockRepo, mockMQ)
+	tenantUsecase := NewTenantUsecase(mockRepo, new(mockrepository.IdempotencyRepository), new(mockrepository.MessageRepository), new(mockrepository.ArchiveRepository), new(mockrepository.ScheduledRepository), mockMQ, logrus.New(), TenantConfig{})
 
 	var testCases = []struct {
 		caseName     string
@@ -253,3 +358,215 @@ func TestGetTenant(t *testing.T) {
 		})
 	}
 }
+
+func TestProcessBatch(t *testing.T) {
+	type params struct {
+		clientID string
+		payloads []interface{}
+	}
+
+	ctx := context.Background()
+	mockRepo, mockMQ := mockInit()
+	mockMessages := new(mockrepository.MessageRepository)
+	mockArchive := new(mockrepository.ArchiveRepository)
+	tenantUsecase := NewTenantUsecase(mockRepo, new(mockrepository.IdempotencyRepository), mockMessages, mockArchive, new(mockrepository.ScheduledRepository), mockMQ, logrus.New(), TenantConfig{})
+
+	var testCases = []struct {
+		caseName     string
+		params       params
+		expectations func(params params)
+		results      func(result *model.BatchResult, err error)
+	}{
+		{
+			caseName: "ProcessBatch_PartialFailure",
+			params: params{
+				clientID: "test-client",
+				payloads: []interface{}{"a", "b", "c"},
+			},
+			expectations: func(params params) {
+				mockRepo.On("GetTenantByClientID", mock.Anything, params.clientID).Return(&model.Tenant{ClientID: params.clientID, Name: "Test Tenant"}, nil).Once()
+				tracked := mock.AnythingOfType("*model.Message")
+				mockMessages.On("CreateMessages", mock.Anything, tracked, tracked, tracked).Return(nil).Once()
+				mockMessages.On("RecordEvents", mock.Anything, hasStatus(model.MessagePublished), hasStatus(model.MessageFailed), hasStatus(model.MessagePublished)).Return(nil).Once()
+				// Only the published payloads are archived
+				archived := mock.AnythingOfType("*model.ArchivedMessage")
+				mockArchive.On("Archive", mock.Anything, archived, archived).Return(nil).Once()
+				mockMQ.On("PublishBatch", mock.Anything, "test-client.process", mock.MatchedBy(func(messages []messaging.Message) bool {
+					return len(messages) == 3 && messages[0].ID != "" && messages[0].ID != messages[1].ID && isBody(messages[2], `"c"`)
+				})).Return([]error{nil, errors.New("message was nacked by the broker"), nil}).Once()
+			},
+			results: func(result *model.BatchResult, err error) {
+				assert.Nil(t, err)
+				assert.Equal(t, 2, result.Published)
+				assert.Equal(t, 1, result.Failed)
+				assert.Equal(t, model.BatchItemFailed, result.Results[1].Status)
+				assert.NotEmpty(t, result.Results[1].MessageID)
+				assert.Equal(t, 2, result.Results[2].Index)
+			},
+		},
+		{
+			caseName: "ProcessBatch_TenantNotFound",
+			params: params{
+				clientID: "missing-client",
+				payloads: []interface{}{"a"},
+			},
+			expectations: func(params params) {
+				mockRepo.On("GetTenantByClientID", mock.Anything, params.clientID).Return(nil, derrors.New(derrors.NotFound, "tenant not found")).Once()
+			},
+			results: func(result *model.BatchResult, err error) {
+				assert.Nil(t, result)
+				assert.True(t, derrors.IsErrCode(err, derrors.NotFound))
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.caseName, func(t *testing.T) {
+			testCase.expectations(testCase.params)
+			result, err := tenantUsecase.ProcessBatch(ctx, testCase.params.clientID, testCase.params.payloads)
+			testCase.results(result, err)
+
+			mockRepo.AssertExpectations(t)
+			mockMessages.AssertExpectations(t)
+			mockArchive.AssertExpectations(t)
+			mockMQ.AssertExpectations(t)
+		})
+	}
+}
+
+func TestProcessPayloadIdempotency(t *testing.T) {
+	ctx := context.Background()
+	payload := &model.Payload{Body: "test-payload", IdempotencyKey: "key-1"}
+	hash, err := payloadHash(payload)
+	assert.Nil(t, err)
+
+	var testCases = []struct {
+		caseName     string
+		expectations func(mockIdempotency *mockrepository.IdempotencyRepository, mockMQ *mockservice.Messagging)
+		results      func(messageID string, err error)
+	}{
+		{
+			caseName: "Idempotency_FirstRequest",
+			expectations: func(mockIdempotency *mockrepository.IdempotencyRepository, mockMQ *mockservice.Messagging) {
+				mockIdempotency.On("Reserve", mock.Anything, &model.IdempotencyRecord{ClientID: "test-client", Key: "key-1", RequestHash: hash}, time.Minute).Return(nil, nil).Once()
+				mockMQ.On("Publish", mock.Anything, "test-client.process", mock.AnythingOfType("messaging.Message")).Return(nil).Once()
+				mockIdempotency.On("Complete", mock.Anything, "test-client", "key-1", mock.AnythingOfType("string"), 24*time.Hour).Return(nil).Once()
+			},
+			results: func(messageID string, err error) {
+				assert.Nil(t, err)
+				assert.NotEmpty(t, messageID)
+			},
+		},
+		{
+			caseName: "Idempotency_Replay",
+			expectations: func(mockIdempotency *mockrepository.IdempotencyRepository, mockMQ *mockservice.Messagging) {
+				mockIdempotency.On("Reserve", mock.Anything, mock.Anything, mock.Anything).Return(&model.IdempotencyRecord{RequestHash: hash, MessageID: "msg-1"}, nil).Once()
+			},
+			results: func(messageID string, err error) {
+				assert.Nil(t, err)
+				assert.Equal(t, "msg-1", messageID)
+			},
+		},
+		{
+			caseName: "Idempotency_DifferentPayload",
+			expectations: func(mockIdempotency *mockrepository.IdempotencyRepository, mockMQ *mockservice.Messagging) {
+				mockIdempotency.On("Reserve", mock.Anything, mock.Anything, mock.Anything).Return(&model.IdempotencyRecord{RequestHash: "other", MessageID: "msg-1"}, nil).Once()
+			},
+			results: func(messageID string, err error) {
+				assert.True(t, derrors.IsErrCode(err, derrors.InvalidArgument))
+				assert.Empty(t, messageID)
+			},
+		},
+		{
+			caseName: "Idempotency_InProgress",
+			expectations: func(mockIdempotency *mockrepository.IdempotencyRepository, mockMQ *mockservice.Messagging) {
+				mockIdempotency.On("Reserve", mock.Anything, mock.Anything, mock.Anything).Return(&model.IdempotencyRecord{RequestHash: hash}, nil).Once()
+			},
+			results: func(messageID string, err error) {
+				assert.True(t, derrors.IsErrCode(err, derrors.Conflict))
+			},
+		},
+		{
+			caseName: "Idempotency_PublishFailsReleasesKey",
+			expectations: func(mockIdempotency *mockrepository.IdempotencyRepository, mockMQ *mockservice.Messagging) {
+				mockIdempotency.On("Reserve", mock.Anything, mock.Anything, mock.Anything).Return(nil, nil).Once()
+				mockMQ.On("Publish", mock.Anything, "test-client.process", mock.AnythingOfType("messaging.Message")).Return(errors.New("channel closed")).Once()
+				mockIdempotency.On("Release", mock.Anything, "test-client", "key-1").Return(nil).Once()
+			},
+			results: func(messageID string, err error) {
+				assert.NotNil(t, err)
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.caseName, func(t *testing.T) {
+			mockRepo, mockMQ := mockInit()
+			mockIdempotency := new(mockrepository.IdempotencyRepository)
+			mockMessages := new(mockrepository.MessageRepository)
+			mockArchive := new(mockrepository.ArchiveRepository)
+			tenantUsecase := NewTenantUsecase(mockRepo, mockIdempotency, mockMessages, mockArchive, new(mockrepository.ScheduledRepository), mockMQ, logrus.New(), TenantConfig{})
+
+			mockRepo.On("GetTenantByClientID", mock.Anything, "test-client").Return(&model.Tenant{ClientID: "test-client", Name: "Test Tenant"}, nil)
+			mockMessages.On("CreateMessages", mock.Anything, mock.Anything).Return(nil).Maybe()
+			mockMessages.On("RecordEvents", mock.Anything, mock.Anything).Return(nil).Maybe()
+			mockArchive.On("Archive", mock.Anything, mock.Anything).Return(nil).Maybe()
+			testCase.expectations(mockIdempotency, mockMQ)
+			messageID, err := tenantUsecase.ProcessPayload(ctx, "test-client", payload)
+			testCase.results(messageID, err)
+
+			mockIdempotency.AssertExpectations(t)
+			mockMQ.AssertExpectations(t)
+		})
+	}
+}
+
+func TestProcessPayloadIdempotencyClientGone(t *testing.T) {
+	// The client disconnected while the payload was being published
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	payload := &model.Payload{Body: "test-payload", IdempotencyKey: "key-1"}
+
+	mockRepo, mockMQ := mockInit()
+	mockIdempotency := new(mockrepository.IdempotencyRepository)
+	mockMessages := new(mockrepository.MessageRepository)
+	mockArchive := new(mockrepository.ArchiveRepository)
+	tenantUsecase := NewTenantUsecase(mockRepo, mockIdempotency, mockMessages, mockArchive, new(mockrepository.ScheduledRepository), mockMQ, logrus.New(), TenantConfig{})
+
+	live := mock.MatchedBy(func(ctx context.Context) bool { return ctx.Err() == nil })
+	mockRepo.On("GetTenantByClientID", mock.Anything, "test-client").Return(&model.Tenant{ClientID: "test-client", Name: "Test Tenant"}, nil)
+	mockIdempotency.On("Reserve", mock.Anything, mock.Anything, mock.Anything).Return(nil, nil).Once()
+	mockMessages.On("CreateMessages", mock.Anything, mock.Anything).Return(nil).Once()
+	mockMQ.On("Publish", mock.Anything, "test-client.process", mock.AnythingOfType("messaging.Message")).Return(nil).Once()
+	mockMessages.On("RecordEvents", mock.Anything, mock.Anything).Return(nil).Once()
+	mockArchive.On("Archive", mock.Anything, mock.Anything).Return(nil).Once()
+	mockIdempotency.On("Complete", live, "test-client", "key-1", mock.AnythingOfType("string"), 24*time.Hour).Return(nil).Once()
+
+	messageID, err := tenantUsecase.ProcessPayload(ctx, "test-client", payload)
+	assert.Nil(t, err)
+	assert.NotEmpty(t, messageID)
+	mockIdempotency.AssertExpectations(t)
+}
+
+func TestProcessPayloadArchivesAfterClientGone(t *testing.T) {
+	// The client disconnected once the broker confirmed the payload
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	mockRepo, mockMQ := mockInit()
+	mockMessages := new(mockrepository.MessageRepository)
+	mockArchive := new(mockrepository.ArchiveRepository)
+	tenantUsecase := NewTenantUsecase(mockRepo, nil, mockMessages, mockArchive, new(mockrepository.ScheduledRepository), mockMQ, logrus.New(), TenantConfig{})
+
+	live := mock.MatchedBy(func(ctx context.Context) bool { return ctx.Err() == nil })
+	mockRepo.On("GetTenantByClientID", mock.Anything, "test-client").Return(&model.Tenant{ClientID: "test-client", Name: "Test Tenant"}, nil)
+	mockMessages.On("CreateMessages", mock.Anything, mock.Anything).Return(nil).Once()
+	mockMQ.On("Publish", mock.Anything, "test-client.process", mock.AnythingOfType("messaging.Message")).Return(nil).Once()
+	mockMessages.On("RecordEvents", live, mock.Anything).Return(nil).Once()
+	mockArchive.On("Archive", live, mock.Anything).Return(nil).Once()
+
+	_, err := tenantUsecase.ProcessPayload(ctx, "test-client", &model.Payload{Body: "test-payload"})
+	assert.Nil(t, err)
+	mockMessages.AssertExpectations(t)
+	mockArchive.AssertExpectations(t)
+}
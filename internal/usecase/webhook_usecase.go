@@ -0,0 +1,493 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/netip"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"tenant/internal/model"
+	"tenant/internal/repository"
+	"tenant/internal/service/messaging"
+	"tenant/internal/service/webhook"
+	"tenant/pkg/breaker"
+	"tenant/pkg/derrors"
+	"tenant/pkg/logger"
+	"tenant/pkg/metrics"
+
+	"github.com/segmentio/ksuid"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// defaultWebhookDeliveries and maxWebhookDeliveries bound the delivery
+	// log returned at once
+	defaultWebhookDeliveries = 50
+	maxWebhookDeliveries     = 500
+
+	// webhookSecretPrefix starts the secrets generated for webhooks
+	webhookSecretPrefix = "whsec_"
+
+	// webhookHealthInterval is how often the health of a webhook is saved
+	// while its breaker keeps its state
+	webhookHealthInterval = 10 * time.Second
+)
+
+var (
+	webhookRequests = metrics.NewCounter("tenant_webhook_requests_total",
+		"Requests made to webhooks by result: success, client_error, failure or open when the breaker rejected it", "client_id", "webhook_id", "result")
+	webhookLatency = metrics.NewSummary("tenant_webhook_request_duration_seconds",
+		"Duration of the requests made to webhooks", "client_id", "webhook_id")
+	webhookBreakerState = metrics.NewGauge("tenant_webhook_breaker_state",
+		"Circuit breaker state of webhooks: 0 closed, 1 half-open, 2 open", "client_id", "webhook_id")
+	webhookConsecutiveFailures = metrics.NewGauge("tenant_webhook_consecutive_failures",
+		"Consecutive failed requests made to webhooks", "client_id", "webhook_id")
+)
+
+type WebhookUsecase interface {
+	CreateWebhook(ctx context.Context, hook *model.Webhook) (*model.Webhook, error)
+	GetWebhook(ctx context.Context, clientID, webhookID string) (*model.Webhook, error)
+	ListWebhooks(ctx context.Context, clientID string) ([]*model.Webhook, error)
+	UpdateWebhook(ctx context.Context, hook *model.Webhook) (*model.Webhook, error)
+	DeleteWebhook(ctx context.Context, clientID, webhookID string) error
+	GetHealth(ctx context.Context, clientID, webhookID string) (*model.WebhookHealth, error)
+	ListDeliveries(ctx context.Context, clientID, webhookID string, limit int) ([]*model.WebhookDelivery, error)
+	Deliver(ctx context.Context, clientID string, delivery *messaging.Delivery) error
+}
+
+// WebhookConfig tunes the delivery of messages to webhooks
+type WebhookConfig struct {
+	// Retries is how many times a failed request is sent again before the
+	// processing attempt of the message fails
+	Retries int
+
+	// Backoff is the delay before the first retry, it doubles on every
+	// retry up to MaxBackoff
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+
+	// WorkerID identifies this process in the reported health of webhooks
+	WorkerID string
+
+	// BreakerFailures is how many consecutive failed requests open the
+	// breaker of a webhook, zero never opens it. An open breaker holds back
+	// the messages of the tenant for BreakerCooldown, then lets one probe
+	// the webhook.
+	BreakerFailures int
+	BreakerCooldown time.Duration
+
+	// AllowPrivateNetworks accepts webhook URLs of loopback, private and
+	// link-local hosts, for local development only
+	AllowPrivateNetworks bool
+}
+
+type webhookUsecase struct {
+	repo    repository.WebhookRepository
+	tenants repository.TenantRepository
+	sender  webhook.Sender
+	log     *logrus.Logger
+
+	retries    int
+	backoff    time.Duration
+	maxBackoff time.Duration
+
+	workerID             string
+	breakers             *breaker.Set
+	allowPrivateNetworks bool
+
+	// saved is when the health of each webhook was last saved
+	mu    sync.Mutex
+	saved map[string]time.Time
+}
+
+// NewWebhookUsecase initializes a new webhook usecase
+func NewWebhookUsecase(repo repository.WebhookRepository, tenants repository.TenantRepository, sender webhook.Sender, log *logrus.Logger, cfg WebhookConfig) WebhookUsecase {
+	if cfg.Retries < 0 {
+		cfg.Retries = 0
+	}
+	if cfg.Backoff <= 0 {
+		cfg.Backoff = time.Second
+	}
+	if cfg.MaxBackoff < cfg.Backoff {
+		cfg.MaxBackoff = cfg.Backoff
+	}
+	return &webhookUsecase{
+		repo:       repo,
+		tenants:    tenants,
+		sender:     sender,
+		log:        log,
+		retries:    cfg.Retries,
+		backoff:    cfg.Backoff,
+		maxBackoff: cfg.MaxBackoff,
+		workerID:   cfg.WorkerID,
+		breakers:   breaker.NewSet(breaker.Config{Failures: cfg.BreakerFailures, Cooldown: cfg.BreakerCooldown}),
+
+		allowPrivateNetworks: cfg.AllowPrivateNetworks,
+		saved:                make(map[string]time.Time),
+	}
+}
+
+// CreateWebhook validates and stores a new webhook of a tenant. A secret is
+// generated when none is given, it is only returned here.
+func (s *webhookUsecase) CreateWebhook(ctx context.Context, hook *model.Webhook) (_ *model.Webhook, err error) {
+	defer derrors.Wrap(&err, "CreateWebhook(%q)", hook.ClientID)
+
+	if _, err := s.tenants.GetTenantByClientID(ctx, hook.ClientID); err != nil {
+		return nil, err
+	}
+	if err := validateWebhookURL(hook.URL, s.allowPrivateNetworks); err != nil {
+		return nil, err
+	}
+	if hook.Secret == "" {
+		if hook.Secret, err = generateWebhookSecret(); err != nil {
+			return nil, err
+		}
+	}
+
+	hook.ID = ksuid.New().String()
+	if err := s.repo.CreateWebhook(ctx, hook); err != nil {
+		if derrors.IsErrCode(err, derrors.Duplicate) {
+			return nil, derrors.New(derrors.Duplicate, "a webhook for %s already exists", hook.URL)
+		}
+		return nil, err
+	}
+	return hook, nil
+}
+
+// GetWebhook returns a webhook of a tenant without its secret
+func (s *webhookUsecase) GetWebhook(ctx context.Context, clientID, webhookID string) (_ *model.Webhook, err error) {
+	defer derrors.Wrap(&err, "GetWebhook(%q, %q)", clientID, webhookID)
+
+	hook, err := s.getWebhook(ctx, clientID, webhookID)
+	if err != nil {
+		return nil, err
+	}
+	hook.Secret = ""
+	return hook, nil
+}
+
+// ListWebhooks returns the webhooks of a tenant without their secret
+func (s *webhookUsecase) ListWebhooks(ctx context.Context, clientID string) (_ []*model.Webhook, err error) {
+	defer derrors.Wrap(&err, "ListWebhooks(%q)", clientID)
+
+	if _, err := s.tenants.GetTenantByClientID(ctx, clientID); err != nil {
+		return nil, err
+	}
+	hooks, err := s.repo.ListWebhooks(ctx, clientID)
+	if err != nil {
+		return nil, err
+	}
+	for _, hook := range hooks {
+		hook.Secret = ""
+	}
+	return hooks, nil
+}
+
+// UpdateWebhook replaces the URL and state of a webhook. Its secret is
+// rotated when a new one is given, and only returned in that case.
+func (s *webhookUsecase) UpdateWebhook(ctx context.Context, hook *model.Webhook) (_ *model.Webhook, err error) {
+	defer derrors.Wrap(&err, "UpdateWebhook(%q, %q)", hook.ClientID, hook.ID)
+
+	current, err := s.getWebhook(ctx, hook.ClientID, hook.ID)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateWebhookURL(hook.URL, s.allowPrivateNetworks); err != nil {
+		return nil, err
+	}
+	rotated := hook.Secret != ""
+	if !rotated {
+		hook.Secret = current.Secret
+	}
+
+	if err := s.repo.UpdateWebhook(ctx, hook); err != nil {
+		if derrors.IsErrCode(err, derrors.Duplicate) {
+			return nil, derrors.New(derrors.Duplicate, "a webhook for %s already exists", hook.URL)
+		}
+		return nil, err
+	}
+	if !rotated {
+		hook.Secret = ""
+	}
+	return hook, nil
+}
+
+func (s *webhookUsecase) DeleteWebhook(ctx context.Context, clientID, webhookID string) (err error) {
+	defer derrors.Wrap(&err, "DeleteWebhook(%q, %q)", clientID, webhookID)
+
+	err = s.repo.DeleteWebhook(ctx, clientID, webhookID)
+	if derrors.IsErrCode(err, derrors.NotFound) {
+		return derrors.New(derrors.NotFound, "webhook not found")
+	}
+	if err != nil {
+		return err
+	}
+
+	s.breakers.Remove(webhookID)
+	webhookBreakerState.Delete(clientID, webhookID)
+	webhookConsecutiveFailures.Delete(clientID, webhookID)
+	return nil
+}
+
+// GetHealth returns the circuit breaker of a webhook as last reported by a
+// worker, a webhook without reports is closed
+func (s *webhookUsecase) GetHealth(ctx context.Context, clientID, webhookID string) (_ *model.WebhookHealth, err error) {
+	defer derrors.Wrap(&err, "GetHealth(%q, %q)", clientID, webhookID)
+
+	if _, err := s.getWebhook(ctx, clientID, webhookID); err != nil {
+		return nil, err
+	}
+	health, err := s.repo.GetHealth(ctx, clientID, webhookID)
+	if derrors.IsErrCode(err, derrors.NotFound) {
+		return &model.WebhookHealth{WebhookID: webhookID, ClientID: clientID, State: model.WebhookClosed}, nil
+	}
+	return health, err
+}
+
+// ListDeliveries returns the latest requests made to a webhook, newest first
+func (s *webhookUsecase) ListDeliveries(ctx context.Context, clientID, webhookID string, limit int) (_ []*model.WebhookDelivery, err error) {
+	defer derrors.Wrap(&err, "ListDeliveries(%q, %q)", clientID, webhookID)
+
+	if limit < 0 || limit > maxWebhookDeliveries {
+		return nil, derrors.New(derrors.InvalidArgument, "limit must be between 1 and %d", maxWebhookDeliveries)
+	}
+	if limit == 0 {
+		limit = defaultWebhookDeliveries
+	}
+	if _, err := s.getWebhook(ctx, clientID, webhookID); err != nil {
+		return nil, err
+	}
+	return s.repo.ListDeliveries(ctx, clientID, webhookID, limit)
+}
+
+// Deliver posts a message to every enabled webhook of its tenant, retrying
+// failed requests with exponential backoff. Webhooks that already received
+// the message in a previous attempt are skipped. The returned error is
+// permanent when no webhook failed with an error worth retrying, and pauses
+// the tenant while the breaker of one of its webhooks is open.
+func (s *webhookUsecase) Deliver(ctx context.Context, clientID string, delivery *messaging.Delivery) error {
+	hooks, err := s.repo.ListWebhooks(ctx, clientID)
+	if err != nil {
+		return err
+	}
+	hooks = slices.DeleteFunc(hooks, func(hook *model.Webhook) bool { return !hook.Enabled })
+	if len(hooks) == 0 {
+		logger.WithContext(ctx, s.log).Infof("Processing message %s for tenant %s without webhooks: %s", delivery.MessageID, clientID, delivery.Body)
+		return nil
+	}
+
+	if delivery.Attempt > 1 || delivery.Redelivered {
+		delivered, err := s.repo.ListDelivered(ctx, delivery.MessageID)
+		if err != nil {
+			return err
+		}
+		hooks = slices.DeleteFunc(hooks, func(hook *model.Webhook) bool { return slices.Contains(delivered, hook.ID) })
+	}
+
+	// Deliver to every webhook or to none of them, rather than to the ones
+	// that are up only
+	for _, hook := range hooks {
+		if retryAt, ok := s.breakers.Get(hook.ID).Allow(); !ok {
+			webhookRequests.Inc(hook.ClientID, hook.ID, "open")
+			return pause(fmt.Errorf("webhook %s is unavailable", hook.ID), retryAt)
+		}
+	}
+
+	var errs []error
+	retryable := false
+	for _, hook := range hooks {
+		if err := s.deliver(ctx, hook, delivery); err != nil {
+			if _, ok := pausedUntil(err); ok {
+				return fmt.Errorf("webhook %s: %w", hook.ID, err)
+			}
+			errs = append(errs, fmt.Errorf("webhook %s: %w", hook.ID, err))
+			retryable = retryable || webhook.Retryable(err)
+		}
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	err = errors.Join(errs...)
+	if !retryable {
+		return permanent(err)
+	}
+	return err
+}
+
+// deliver posts a message to a webhook until it succeeds, fails with an
+// error that is not worth retrying, runs out of retries or opens the breaker
+// of the webhook, recording every request in the delivery log
+func (s *webhookUsecase) deliver(ctx context.Context, hook *model.Webhook, delivery *messaging.Delivery) error {
+	b := s.breakers.Get(hook.ID)
+
+	req := &webhook.Request{
+		URL:           hook.URL,
+		Secret:        hook.Secret,
+		MessageID:     delivery.MessageID,
+		CorrelationID: delivery.CorrelationID,
+		ContentType:   delivery.ContentType,
+		Attempt:       delivery.Attempt,
+		Headers:       delivery.Headers,
+		Body:          delivery.Body,
+	}
+
+	backoff := s.backoff
+	for retry := 0; ; retry++ {
+		start := time.Now()
+		resp, err := s.sender.Send(ctx, req)
+
+		record := &model.WebhookDelivery{
+			WebhookID: hook.ID,
+			ClientID:  hook.ClientID,
+			MessageID: delivery.MessageID,
+			Attempt:   delivery.Attempt,
+			Retry:     retry,
+			Duration:  time.Since(start),
+			Succeeded: err == nil,
+		}
+		if resp != nil {
+			record.StatusCode, record.Duration = resp.StatusCode, resp.Duration
+		}
+		if err != nil {
+			record.Error = err.Error()
+		}
+		s.recordDelivery(ctx, record)
+		if ctx.Err() != nil {
+			// Cancelled by this worker, the webhook is not to blame
+			return err
+		}
+		stats := s.recordOutcome(ctx, hook, b, record.Duration, err)
+
+		if stats.State == breaker.Open {
+			return pause(err, stats.RetryAt)
+		}
+		if err == nil || !webhook.Retryable(err) || retry >= s.retries {
+			return err
+		}
+		logger.WithContext(ctx, s.log).Warnf("Delivery of message %s to webhook %s failed, retrying in %s: %v", delivery.MessageID, hook.ID, backoff, err)
+
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, s.maxBackoff)
+	}
+}
+
+// recordDelivery adds a request to the delivery log. The log is best effort,
+// it never fails the delivery.
+func (s *webhookUsecase) recordDelivery(ctx context.Context, record *model.WebhookDelivery) {
+	if err := s.repo.RecordDelivery(ctx, record); err != nil {
+		logger.WithContext(ctx, s.log).Errorf("failed to record delivery of message %s to webhook %s: %v", record.MessageID, record.WebhookID, err)
+	}
+}
+
+// recordOutcome feeds the outcome of a request to the breaker and the
+// metrics of a webhook, and returns the breaker as the outcome left it. A
+// webhook answering with a client error is up.
+func (s *webhookUsecase) recordOutcome(ctx context.Context, hook *model.Webhook, b *breaker.Breaker, latency time.Duration, err error) breaker.Stats {
+	var transition breaker.Transition
+	switch {
+	case err == nil:
+		transition = b.Success(latency)
+		webhookRequests.Inc(hook.ClientID, hook.ID, "success")
+	case !webhook.Retryable(err):
+		transition = b.Success(latency)
+		webhookRequests.Inc(hook.ClientID, hook.ID, "client_error")
+	default:
+		transition = b.Failure(latency, err)
+		webhookRequests.Inc(hook.ClientID, hook.ID, "failure")
+	}
+	webhookLatency.Observe(latency.Seconds(), hook.ClientID, hook.ID)
+
+	stats := transition.Stats
+	webhookBreakerState.Set(float64(stats.State), hook.ClientID, hook.ID)
+	webhookConsecutiveFailures.Set(float64(stats.ConsecutiveFailures), hook.ClientID, hook.ID)
+
+	changed := transition.Changed()
+	switch {
+	case changed && stats.State == breaker.Open:
+		logger.WithContext(ctx, s.log).Warnf("Breaker of webhook %s opened after %d consecutive failures, retrying at %s", hook.ID, stats.ConsecutiveFailures, stats.RetryAt.Format(time.RFC3339))
+	case changed && stats.State == breaker.Closed:
+		logger.WithContext(ctx, s.log).Infof("Breaker of webhook %s closed", hook.ID)
+	}
+	s.saveHealth(ctx, hook, stats, changed)
+	return stats
+}
+
+// saveHealth reports the breaker of a webhook when it changed state, and
+// every webhookHealthInterval otherwise. The report is best effort.
+func (s *webhookUsecase) saveHealth(ctx context.Context, hook *model.Webhook, stats breaker.Stats, changed bool) {
+	now := time.Now()
+	s.mu.Lock()
+	if !changed && now.Sub(s.saved[hook.ID]) < webhookHealthInterval {
+		s.mu.Unlock()
+		return
+	}
+	s.saved[hook.ID] = now
+	s.mu.Unlock()
+
+	health := &model.WebhookHealth{
+		WebhookID:           hook.ID,
+		ClientID:            hook.ClientID,
+		WorkerID:            s.workerID,
+		State:               stats.State.String(),
+		ConsecutiveFailures: stats.ConsecutiveFailures,
+		Successes:           stats.Successes,
+		Failures:            stats.Failures,
+		LastLatency:         stats.LastLatency,
+		AvgLatency:          stats.AvgLatency,
+		LastError:           stats.LastError,
+	}
+	if !stats.OpenedAt.IsZero() {
+		health.OpenedAt, health.RetryAt = &stats.OpenedAt, &stats.RetryAt
+	}
+	if err := s.repo.SaveHealth(ctx, health); err != nil {
+		logger.WithContext(ctx, s.log).Errorf("failed to save health of webhook %s: %v", hook.ID, err)
+	}
+}
+
+func (s *webhookUsecase) getWebhook(ctx context.Context, clientID, webhookID string) (*model.Webhook, error) {
+	hook, err := s.repo.GetWebhook(ctx, clientID, webhookID)
+	if derrors.IsErrCode(err, derrors.NotFound) {
+		return nil, derrors.New(derrors.NotFound, "webhook not found")
+	}
+	return hook, err
+}
+
+// validateWebhookURL accepts absolute http and https URLs. Unless private
+// networks are allowed, it refuses localhost and the addresses the sender
+// would refuse to connect to, hostnames are checked again when dialed.
+func validateWebhookURL(rawURL string, allowPrivateNetworks bool) error {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host == "" || (u.Scheme != "http" && u.Scheme != "https") {
+		return derrors.New(derrors.InvalidArgument, "webhook URL must be an absolute http or https URL")
+	}
+	if allowPrivateNetworks {
+		return nil
+	}
+	host := strings.TrimSuffix(strings.ToLower(u.Hostname()), ".")
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return derrors.New(derrors.InvalidArgument, "webhook URL must not point to a private address")
+	}
+	if ip, err := netip.ParseAddr(host); err == nil && webhook.IsBlockedIP(ip) {
+		return derrors.New(derrors.InvalidArgument, "webhook URL must not point to a private address")
+	}
+	return nil
+}
+
+func generateWebhookSecret() (string, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", derrors.WrapStack(err, derrors.Unknown, "failed to generate webhook secret")
+	}
+	return webhookSecretPrefix + hex.EncodeToString(secret), nil
+}
@@ -0,0 +1,316 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"tenant/internal/model"
+	"tenant/internal/service/messaging"
+	"tenant/internal/service/webhook"
+	"tenant/internal/test/mockrepository"
+	"tenant/internal/test/mockservice"
+	"tenant/pkg/derrors"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestCreateWebhook(t *testing.T) {
+	ctx := context.Background()
+
+	var testCases = []struct {
+		caseName     string
+		hook         *model.Webhook
+		expectations func(mockRepo *mockrepository.WebhookRepository)
+		results      func(hook *model.Webhook, err error)
+	}{
+		{
+			caseName: "CreateWebhook_GeneratesSecret",
+			hook:     &model.Webhook{ClientID: "client-a", URL: "https://example.com/hook", Enabled: true},
+			expectations: func(mockRepo *mockrepository.WebhookRepository) {
+				mockRepo.On("CreateWebhook", mock.Anything, mock.MatchedBy(func(hook *model.Webhook) bool {
+					return hook.ID != "" && strings.HasPrefix(hook.Secret, webhookSecretPrefix)
+				})).Return(nil).Once()
+			},
+			results: func(hook *model.Webhook, err error) {
+				assert.Nil(t, err)
+				assert.Len(t, hook.Secret, len(webhookSecretPrefix)+64)
+			},
+		},
+		{
+			caseName: "CreateWebhook_KeepsSecret",
+			hook:     &model.Webhook{ClientID: "client-a", URL: "http://hooks.example.com:9000/hook", Secret: "my-secret"},
+			expectations: func(mockRepo *mockrepository.WebhookRepository) {
+				mockRepo.On("CreateWebhook", mock.Anything, mock.Anything).Return(nil).Once()
+			},
+			results: func(hook *model.Webhook, err error) {
+				assert.Nil(t, err)
+				assert.Equal(t, "my-secret", hook.Secret)
+			},
+		},
+		{
+			caseName:     "CreateWebhook_InvalidURL",
+			hook:         &model.Webhook{ClientID: "client-a", URL: "ftp://example.com/hook"},
+			expectations: func(mockRepo *mockrepository.WebhookRepository) {},
+			results: func(hook *model.Webhook, err error) {
+				assert.Nil(t, hook)
+				assert.True(t, derrors.IsErrCode(err, derrors.InvalidArgument))
+			},
+		},
+		{
+			caseName:     "CreateWebhook_Localhost",
+			hook:         &model.Webhook{ClientID: "client-a", URL: "http://localhost:9000/hook"},
+			expectations: func(mockRepo *mockrepository.WebhookRepository) {},
+			results: func(hook *model.Webhook, err error) {
+				assert.True(t, derrors.IsErrCode(err, derrors.InvalidArgument))
+			},
+		},
+		{
+			caseName:     "CreateWebhook_MetadataAddress",
+			hook:         &model.Webhook{ClientID: "client-a", URL: "http://169.254.169.254/latest/meta-data"},
+			expectations: func(mockRepo *mockrepository.WebhookRepository) {},
+			results: func(hook *model.Webhook, err error) {
+				assert.True(t, derrors.IsErrCode(err, derrors.InvalidArgument))
+			},
+		},
+		{
+			caseName:     "CreateWebhook_PrivateIPv6Address",
+			hook:         &model.Webhook{ClientID: "client-a", URL: "https://[fd00::1]/hook"},
+			expectations: func(mockRepo *mockrepository.WebhookRepository) {},
+			results: func(hook *model.Webhook, err error) {
+				assert.True(t, derrors.IsErrCode(err, derrors.InvalidArgument))
+			},
+		},
+		{
+			caseName: "CreateWebhook_Duplicate",
+			hook:     &model.Webhook{ClientID: "client-a", URL: "https://example.com/hook"},
+			expectations: func(mockRepo *mockrepository.WebhookRepository) {
+				mockRepo.On("CreateWebhook", mock.Anything, mock.Anything).Return(derrors.New(derrors.Duplicate, "unique violation")).Once()
+			},
+			results: func(hook *model.Webhook, err error) {
+				assert.Nil(t, hook)
+				assert.True(t, derrors.IsErrCode(err, derrors.Duplicate))
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.caseName, func(t *testing.T) {
+			mockRepo := new(mockrepository.WebhookRepository)
+			mockTenants := new(mockrepository.TenantRepository)
+			mockTenants.On("GetTenantByClientID", mock.Anything, "client-a").Return(&model.Tenant{ClientID: "client-a"}, nil)
+			webhookUsecase := NewWebhookUsecase(mockRepo, mockTenants, new(mockservice.Sender), logrus.New(), WebhookConfig{})
+
+			testCase.expectations(mockRepo)
+			hook, err := webhookUsecase.CreateWebhook(ctx, testCase.hook)
+			testCase.results(hook, err)
+
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestDeliverWebhooks(t *testing.T) {
+	ctx := context.Background()
+	hooks := func() []*model.Webhook {
+		return []*model.Webhook{
+			{ID: "hook-1", ClientID: "client-a", URL: "https://a.example.com", Secret: "secret-1", Enabled: true},
+			{ID: "hook-2", ClientID: "client-a", URL: "https://b.example.com", Secret: "secret-2", Enabled: false},
+		}
+	}
+	toHook1 := mock.MatchedBy(func(req *webhook.Request) bool {
+		return req.URL == "https://a.example.com" && req.Secret == "secret-1" && req.MessageID == "msg-1"
+	})
+
+	var testCases = []struct {
+		caseName        string
+		attempt         int
+		breakerFailures int
+		expectations    func(mockRepo *mockrepository.WebhookRepository, mockSender *mockservice.Sender)
+		results         func(err error)
+	}{
+		{
+			caseName: "Deliver_Success",
+			attempt:  1,
+			expectations: func(mockRepo *mockrepository.WebhookRepository, mockSender *mockservice.Sender) {
+				mockRepo.On("ListWebhooks", mock.Anything, "client-a").Return(hooks(), nil).Once()
+				mockSender.On("Send", mock.Anything, toHook1).Return(&webhook.Response{StatusCode: http.StatusOK}, nil).Once()
+				mockRepo.On("RecordDelivery", mock.Anything, mock.MatchedBy(func(delivery *model.WebhookDelivery) bool {
+					return delivery.WebhookID == "hook-1" && delivery.Succeeded && delivery.StatusCode == http.StatusOK && delivery.Retry == 0
+				})).Return(nil).Once()
+			},
+			results: func(err error) {
+				assert.Nil(t, err)
+			},
+		},
+		{
+			caseName: "Deliver_WithoutWebhooks",
+			attempt:  1,
+			expectations: func(mockRepo *mockrepository.WebhookRepository, mockSender *mockservice.Sender) {
+				mockRepo.On("ListWebhooks", mock.Anything, "client-a").Return([]*model.Webhook{}, nil).Once()
+			},
+			results: func(err error) {
+				assert.Nil(t, err)
+			},
+		},
+		{
+			caseName: "Deliver_RetriedWithBackoff",
+			attempt:  1,
+			expectations: func(mockRepo *mockrepository.WebhookRepository, mockSender *mockservice.Sender) {
+				mockRepo.On("ListWebhooks", mock.Anything, "client-a").Return(hooks(), nil).Once()
+				mockSender.On("Send", mock.Anything, toHook1).Return(nil, errors.New("connection refused")).Once()
+				mockSender.On("Send", mock.Anything, toHook1).Return(&webhook.Response{StatusCode: http.StatusBadGateway}, &webhook.StatusError{StatusCode: http.StatusBadGateway}).Once()
+				mockSender.On("Send", mock.Anything, toHook1).Return(&webhook.Response{StatusCode: http.StatusOK}, nil).Once()
+				mockRepo.On("RecordDelivery", mock.Anything, mock.MatchedBy(func(delivery *model.WebhookDelivery) bool {
+					return !delivery.Succeeded
+				})).Return(nil).Twice()
+				mockRepo.On("RecordDelivery", mock.Anything, mock.MatchedBy(func(delivery *model.WebhookDelivery) bool {
+					return delivery.Succeeded && delivery.Retry == 2
+				})).Return(nil).Once()
+			},
+			results: func(err error) {
+				assert.Nil(t, err)
+			},
+		},
+		{
+			caseName: "Deliver_RetriesExhausted",
+			attempt:  1,
+			expectations: func(mockRepo *mockrepository.WebhookRepository, mockSender *mockservice.Sender) {
+				mockRepo.On("ListWebhooks", mock.Anything, "client-a").Return(hooks(), nil).Once()
+				mockSender.On("Send", mock.Anything, toHook1).Return(&webhook.Response{StatusCode: http.StatusServiceUnavailable}, &webhook.StatusError{StatusCode: http.StatusServiceUnavailable}).Times(3)
+				// The delivery log is best effort
+				mockRepo.On("RecordDelivery", mock.Anything, mock.Anything).Return(errors.New("connection refused")).Times(3)
+			},
+			results: func(err error) {
+				assert.NotNil(t, err)
+				assert.False(t, isPermanent(err))
+			},
+		},
+		{
+			caseName: "Deliver_ClientErrorIsPermanent",
+			attempt:  1,
+			expectations: func(mockRepo *mockrepository.WebhookRepository, mockSender *mockservice.Sender) {
+				mockRepo.On("ListWebhooks", mock.Anything, "client-a").Return(hooks(), nil).Once()
+				mockSender.On("Send", mock.Anything, toHook1).Return(&webhook.Response{StatusCode: http.StatusBadRequest}, &webhook.StatusError{StatusCode: http.StatusBadRequest}).Once()
+				mockRepo.On("RecordDelivery", mock.Anything, mock.MatchedBy(func(delivery *model.WebhookDelivery) bool {
+					return !delivery.Succeeded && delivery.StatusCode == http.StatusBadRequest
+				})).Return(nil).Once()
+			},
+			results: func(err error) {
+				assert.True(t, isPermanent(err))
+			},
+		},
+		{
+			caseName:        "Deliver_OpensBreaker",
+			attempt:         1,
+			breakerFailures: 2,
+			expectations: func(mockRepo *mockrepository.WebhookRepository, mockSender *mockservice.Sender) {
+				mockRepo.On("ListWebhooks", mock.Anything, "client-a").Return(hooks(), nil).Once()
+				mockSender.On("Send", mock.Anything, toHook1).Return(&webhook.Response{StatusCode: http.StatusServiceUnavailable}, &webhook.StatusError{StatusCode: http.StatusServiceUnavailable}).Twice()
+				mockRepo.On("RecordDelivery", mock.Anything, mock.Anything).Return(nil).Twice()
+				mockRepo.On("SaveHealth", mock.Anything, mock.MatchedBy(func(health *model.WebhookHealth) bool {
+					return health.State == model.WebhookOpen && health.ConsecutiveFailures == 2 && health.RetryAt != nil
+				})).Return(nil).Once()
+			},
+			results: func(err error) {
+				until, ok := pausedUntil(err)
+				assert.True(t, ok)
+				assert.True(t, until.After(time.Now()))
+				assert.False(t, isPermanent(err))
+			},
+		},
+		{
+			caseName: "Deliver_SkipsWebhooksAlreadyDelivered",
+			attempt:  2,
+			expectations: func(mockRepo *mockrepository.WebhookRepository, mockSender *mockservice.Sender) {
+				mockRepo.On("ListWebhooks", mock.Anything, "client-a").Return(hooks(), nil).Once()
+				mockRepo.On("ListDelivered", mock.Anything, "msg-1").Return([]string{"hook-1"}, nil).Once()
+			},
+			results: func(err error) {
+				assert.Nil(t, err)
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.caseName, func(t *testing.T) {
+			mockRepo := new(mockrepository.WebhookRepository)
+			mockSender := new(mockservice.Sender)
+			webhookUsecase := NewWebhookUsecase(mockRepo, new(mockrepository.TenantRepository), mockSender, logrus.New(), WebhookConfig{
+				Retries:         2,
+				Backoff:         time.Millisecond,
+				BreakerFailures: testCase.breakerFailures,
+			})
+
+			testCase.expectations(mockRepo, mockSender)
+			// Health reports are throttled and best effort
+			mockRepo.On("SaveHealth", mock.Anything, mock.Anything).Return(nil).Maybe()
+			err := webhookUsecase.Deliver(ctx, "client-a", &messaging.Delivery{MessageID: "msg-1", Body: []byte(`{"id":1}`), Attempt: testCase.attempt})
+			testCase.results(err)
+
+			mockRepo.AssertExpectations(t)
+			mockSender.AssertExpectations(t)
+		})
+	}
+}
+
+func TestDeliverWithOpenBreaker(t *testing.T) {
+	ctx := context.Background()
+	hook := &model.Webhook{ID: "hook-1", ClientID: "client-a", URL: "https://a.example.com", Secret: "secret-1", Enabled: true}
+
+	mockRepo := new(mockrepository.WebhookRepository)
+	mockSender := new(mockservice.Sender)
+	webhookUsecase := NewWebhookUsecase(mockRepo, new(mockrepository.TenantRepository), mockSender, logrus.New(), WebhookConfig{
+		Backoff:         time.Millisecond,
+		BreakerFailures: 1,
+		BreakerCooldown: time.Minute,
+	})
+
+	mockRepo.On("ListWebhooks", mock.Anything, "client-a").Return([]*model.Webhook{hook}, nil).Twice()
+	mockSender.On("Send", mock.Anything, mock.Anything).Return(nil, errors.New("connection refused")).Once()
+	mockRepo.On("RecordDelivery", mock.Anything, mock.Anything).Return(nil).Once()
+	mockRepo.On("SaveHealth", mock.Anything, mock.Anything).Return(nil).Once()
+
+	// The first failure opens the breaker
+	err := webhookUsecase.Deliver(ctx, "client-a", &messaging.Delivery{MessageID: "msg-1", Attempt: 1})
+	opened, ok := pausedUntil(err)
+	assert.True(t, ok)
+
+	// The next messages are held back without calling the webhook
+	err = webhookUsecase.Deliver(ctx, "client-a", &messaging.Delivery{MessageID: "msg-2", Attempt: 1})
+	until, ok := pausedUntil(err)
+	assert.True(t, ok)
+	assert.Equal(t, opened, until)
+	assert.ErrorContains(t, err, "webhook hook-1 is unavailable")
+
+	mockRepo.AssertExpectations(t)
+	mockSender.AssertExpectations(t)
+}
+
+func TestGetWebhookHealth(t *testing.T) {
+	ctx := context.Background()
+	hook := &model.Webhook{ID: "hook-1", ClientID: "client-a", URL: "https://a.example.com", Enabled: true}
+
+	mockRepo := new(mockrepository.WebhookRepository)
+	webhookUsecase := NewWebhookUsecase(mockRepo, new(mockrepository.TenantRepository), new(mockservice.Sender), logrus.New(), WebhookConfig{})
+
+	mockRepo.On("GetWebhook", mock.Anything, "client-a", "hook-1").Return(hook, nil).Twice()
+	mockRepo.On("GetHealth", mock.Anything, "client-a", "hook-1").Return(nil, derrors.New(derrors.NotFound, "not found")).Once()
+	mockRepo.On("GetHealth", mock.Anything, "client-a", "hook-1").Return(&model.WebhookHealth{WebhookID: "hook-1", State: model.WebhookOpen, ConsecutiveFailures: 5}, nil).Once()
+
+	// Webhooks without reports are closed
+	health, err := webhookUsecase.GetHealth(ctx, "client-a", "hook-1")
+	assert.Nil(t, err)
+	assert.Equal(t, model.WebhookClosed, health.State)
+
+	health, err = webhookUsecase.GetHealth(ctx, "client-a", "hook-1")
+	assert.Nil(t, err)
+	assert.Equal(t, model.WebhookOpen, health.State)
+	assert.Equal(t, 5, health.ConsecutiveFailures)
+
+	mockRepo.AssertExpectations(t)
+}
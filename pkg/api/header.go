@@ -1,5 +1,10 @@
 package api
 
 const (
-	JsonHeader = "application/json"
+	JsonHeader        = "application/json"
+	ProblemJSONHeader = "application/problem+json"
+	NDJSONHeader      = "application/x-ndjson"
 )
+
+// HeaderIdempotencyKey makes retries of a request take effect only once
+const HeaderIdempotencyKey = "Idempotency-Key"
@@ -0,0 +1,149 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"sort"
+
+	"tenant/pkg/derrors"
+	"tenant/pkg/logger"
+
+	"github.com/asaskevich/govalidator"
+	"github.com/labstack/echo/v4"
+	"github.com/segmentio/ksuid"
+)
+
+// ProblemTypePrefix prefixes the error code to build the problem type URI.
+const ProblemTypePrefix = "urn:tenant:problem:"
+
+// Problem is an RFC 7807 problem details object extended with a stable error
+// code, the ID of this error occurrence and field level validation errors.
+type Problem struct {
+	Type      string       `json:"type"`
+	Title     string       `json:"title"`
+	Status    int          `json:"status"`
+	Detail    string       `json:"detail,omitempty"`
+	Instance  string       `json:"instance,omitempty"`
+	Code      string       `json:"code"`
+	ErrorID   string       `json:"error_id"`
+	RequestID string       `json:"request_id,omitempty"`
+	Errors    []FieldError `json:"errors,omitempty"`
+}
+
+// FieldError describes why a single request field was rejected.
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// ValidationError reports one or more invalid request fields.
+type ValidationError struct {
+	Fields []FieldError
+}
+
+// NewValidationError returns a ValidationError for a single field.
+func NewValidationError(field string, message string) error {
+	return &ValidationError{Fields: []FieldError{{Field: field, Message: message}}}
+}
+
+// Error implements the error interface.
+func (e *ValidationError) Error() string {
+	if len(e.Fields) == 1 {
+		return e.Fields[0].Field + ": " + e.Fields[0].Message
+	}
+	return "request validation failed"
+}
+
+// NewProblem builds the problem details describing err.
+func NewProblem(r *http.Request, err error) *Problem {
+	code := derrors.Unknown
+	status := http.StatusInternalServerError
+	detail := ""
+	var fields []FieldError
+
+	var (
+		verr    *ValidationError
+		gverrs  govalidator.Errors
+		httpErr *echo.HTTPError
+		ierr    *derrors.Error
+	)
+	switch {
+	case errors.As(err, &verr):
+		code, status, fields = derrors.InvalidArgument, http.StatusBadRequest, verr.Fields
+		detail = "request validation failed"
+	case errors.As(err, &gverrs):
+		code, status, fields = derrors.InvalidArgument, http.StatusBadRequest, validatorFields(gverrs)
+		detail = "request validation failed"
+	case errors.As(err, &httpErr):
+		status = httpErr.Code
+		code = codeForStatus(status)
+		if msg, ok := httpErr.Message.(string); ok {
+			detail = msg
+		}
+	case errors.As(err, &ierr):
+		code = ierr.Code()
+		status = derrors.ToStatus(ierr)
+		detail = ierr.Error()
+	}
+
+	// Never leak internal details of server side failures
+	if status >= http.StatusInternalServerError {
+		detail = http.StatusText(status)
+	}
+
+	return &Problem{
+		Type:      ProblemTypePrefix + code.String(),
+		Title:     http.StatusText(status),
+		Status:    status,
+		Detail:    detail,
+		Instance:  r.URL.Path,
+		Code:      code.String(),
+		ErrorID:   ksuid.New().String(),
+		RequestID: logger.RequestID(r.Context()),
+		Errors:    fields,
+	}
+}
+
+func validatorFields(errs govalidator.Errors) []FieldError {
+	var fields []FieldError
+	for _, err := range errs.Errors() {
+		var gverr govalidator.Error
+		var nested govalidator.Errors
+		switch {
+		case errors.As(err, &gverr):
+			fields = append(fields, FieldError{Field: gverr.Name, Message: gverr.Err.Error()})
+		case errors.As(err, &nested):
+			fields = append(fields, validatorFields(nested)...)
+		default:
+			fields = append(fields, FieldError{Message: err.Error()})
+		}
+	}
+	sort.SliceStable(fields, func(i, j int) bool { return fields[i].Field < fields[j].Field })
+	return fields
+}
+
+func codeForStatus(status int) derrors.ErrorCode {
+	switch status {
+	case http.StatusNotFound:
+		return derrors.NotFound
+	case http.StatusBadRequest, http.StatusUnsupportedMediaType:
+		return derrors.InvalidArgument
+	case http.StatusUnauthorized:
+		return derrors.Unauthorized
+	case http.StatusForbidden:
+		return derrors.Forbidden
+	case http.StatusConflict:
+		return derrors.Conflict
+	case http.StatusTooManyRequests:
+		return derrors.RateLimited
+	case http.StatusServiceUnavailable:
+		return derrors.Unavailable
+	case http.StatusGatewayTimeout:
+		return derrors.Timeout
+	case http.StatusRequestEntityTooLarge:
+		return derrors.PayloadTooLarge
+	case http.StatusPreconditionFailed:
+		return derrors.PreconditionFailed
+	}
+	return derrors.Unknown
+}
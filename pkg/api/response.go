@@ -1,9 +1,9 @@
 package api
 
 import (
-	"errors"
+	"encoding/json"
 	"net/http"
-	"tenant/pkg/derrors"
+	"tenant/pkg/logger"
 
 	"github.com/labstack/echo/v4"
 	log "github.com/sirupsen/logrus"
@@ -21,7 +21,7 @@ func Response(c echo.Context, data interface{}, status string, message string, h
 
 	reqID := c.Response().Header().Get(echo.HeaderXRequestID)
 
-	log.WithFields(log.Fields{
+	logger.FromContext(c.Request().Context()).WithFields(log.Fields{
 		"status":     status,
 		"msg":        message,
 		"request_id": reqID,
@@ -66,29 +66,25 @@ func ResponseError(c echo.Context, err error) error {
 	return nil
 }
 
-// RenderErrorResponse sends an error reponse back to the client.
-// Use this to get error response using derrors package
+// RenderErrorResponse sends an error reponse back to the client as
+// application/problem+json. Use this to get error response using derrors package
 func RenderErrorResponse(c echo.Context, r *http.Request, err error) error {
-	var ierr *derrors.Error
-	if errors.As(err, &ierr) {
-		status := derrors.ToStatus(ierr)
-		msg := "Internal Server Error"
-		if status < 500 {
-			msg = ierr.Error()
-		}
-
-		log.Errorf("API error: %s", ierr.Error())
-
-		if errResp := Response(c, nil, StatusError, msg, status); errResp != nil {
-			return errResp
-		}
-		return nil
+	problem := NewProblem(r, err)
+
+	entry := logger.FromContext(r.Context()).WithFields(log.Fields{
+		"error_id": problem.ErrorID,
+		"code":     problem.Code,
+		"status":   problem.Status,
+	})
+	if problem.Status >= http.StatusInternalServerError {
+		entry.Errorf("API error: %v", err)
+	} else {
+		entry.Warnf("API error: %v", err)
 	}
 
-	// If not, the handler sent any arbitrary error value so use 500.
-	log.Errorf("API error: %s", ierr.Error())
-	if err := Response(c, nil, StatusCodeInternalServerError, StatusMessageInternalServerError, http.StatusInternalServerError); err != nil {
+	body, err := json.Marshal(problem)
+	if err != nil {
 		return err
 	}
-	return nil
+	return c.Blob(problem.Status, ProblemJSONHeader, body)
 }
@@ -15,4 +15,7 @@ const (
 
 	// StatusMessageForbidden is custome status message for forbidden
 	StatusMessageForbidden string = "Forbidden"
+
+	// StatusMessageServiceUnavailable is custome status message for unavailable dependencies
+	StatusMessageServiceUnavailable string = "Service Unavailable"
 )
@@ -0,0 +1,200 @@
+// Package breaker implements circuit breakers that stop calling a failing
+// destination for a while, then let a request probe whether it recovered.
+package breaker
+
+import (
+	"sync"
+	"time"
+)
+
+// State is the state of a circuit breaker.
+type State int
+
+const (
+	// Closed lets every request through
+	Closed State = iota
+	// HalfOpen lets one request probe a destination whose cooldown elapsed,
+	// its outcome closes or opens the breaker again
+	HalfOpen
+	// Open rejects every request until the cooldown elapses
+	Open
+)
+
+func (s State) String() string {
+	switch s {
+	case Closed:
+		return "closed"
+	case HalfOpen:
+		return "half_open"
+	case Open:
+		return "open"
+	}
+	return "unknown"
+}
+
+// latencyWeight is the weight of the latest request in the average latency
+const latencyWeight = 0.2
+
+// Config tunes the breakers of a Set.
+type Config struct {
+	// Failures is how many consecutive failures open a breaker, zero never
+	// opens it
+	Failures int
+
+	// Cooldown is how long a breaker stays open before a request may probe
+	// the destination, and how long a probe may take before another one is
+	// let through
+	Cooldown time.Duration
+}
+
+// Stats describes a breaker and the requests it has seen.
+type Stats struct {
+	State               State
+	ConsecutiveFailures int
+	Successes           int64
+	Failures            int64
+
+	// AvgLatency is an exponentially weighted moving average
+	LastLatency time.Duration
+	AvgLatency  time.Duration
+	LastError   string
+
+	// OpenedAt and RetryAt are set while the breaker is open or half-open
+	OpenedAt time.Time
+	RetryAt  time.Time
+}
+
+// Transition is the outcome of a request recorded by a breaker.
+type Transition struct {
+	// From is the state before the outcome, Stats a snapshot taken with it
+	From  State
+	Stats Stats
+}
+
+// Changed reports whether the outcome changed the state of the breaker.
+func (t Transition) Changed() bool {
+	return t.From != t.Stats.State
+}
+
+// Breaker tracks the outcome of the requests made to one destination.
+type Breaker struct {
+	set *Set
+
+	mu    sync.Mutex
+	stats Stats
+}
+
+// Allow reports whether a request may be made. Once the cooldown of an open
+// breaker elapsed, a single request probes the destination, another one is
+// let through if its outcome is not recorded within the cooldown. When a
+// request may not be made, Allow returns when it may be tried again.
+func (b *Breaker) Allow() (time.Time, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.stats.State == Closed {
+		return time.Time{}, true
+	}
+	now := b.set.now()
+	if now.Before(b.stats.RetryAt) {
+		return b.stats.RetryAt, false
+	}
+	b.stats.State = HalfOpen
+	b.stats.RetryAt = now.Add(b.set.cfg.Cooldown)
+	return time.Time{}, true
+}
+
+// Success records a request that reached the destination, closing the
+// breaker.
+func (b *Breaker) Success(latency time.Duration) Transition {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	from := b.stats.State
+	b.observe(latency)
+	b.stats.Successes++
+	b.stats.ConsecutiveFailures = 0
+	b.stats.State = Closed
+	b.stats.OpenedAt, b.stats.RetryAt = time.Time{}, time.Time{}
+	return Transition{From: from, Stats: b.stats}
+}
+
+// Failure records a request that failed, opening the breaker once the
+// consecutive failures reach the threshold or when a probe fails.
+func (b *Breaker) Failure(latency time.Duration, err error) Transition {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	from := b.stats.State
+	b.observe(latency)
+	b.stats.Failures++
+	b.stats.ConsecutiveFailures++
+	if err != nil {
+		b.stats.LastError = err.Error()
+	}
+	threshold := b.set.cfg.Failures
+	if from == HalfOpen || (threshold > 0 && b.stats.ConsecutiveFailures >= threshold) {
+		now := b.set.now()
+		b.stats.State = Open
+		b.stats.OpenedAt = now
+		b.stats.RetryAt = now.Add(b.set.cfg.Cooldown)
+	}
+	return Transition{From: from, Stats: b.stats}
+}
+
+// Stats returns a snapshot of the breaker.
+func (b *Breaker) Stats() Stats {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.stats
+}
+
+func (b *Breaker) observe(latency time.Duration) {
+	b.stats.LastLatency = latency
+	if b.stats.Successes+b.stats.Failures == 0 {
+		b.stats.AvgLatency = latency
+		return
+	}
+	b.stats.AvgLatency = time.Duration(latencyWeight*float64(latency) + (1-latencyWeight)*float64(b.stats.AvgLatency))
+}
+
+// Set holds a breaker per destination, created on first use.
+type Set struct {
+	cfg Config
+	now func() time.Time
+
+	mu       sync.Mutex
+	breakers map[string]*Breaker
+}
+
+// NewSet creates a set of breakers sharing cfg.
+func NewSet(cfg Config) *Set {
+	if cfg.Cooldown <= 0 {
+		cfg.Cooldown = 30 * time.Second
+	}
+	return &Set{
+		cfg:      cfg,
+		now:      time.Now,
+		breakers: make(map[string]*Breaker),
+	}
+}
+
+// Get returns the breaker of a destination.
+func (s *Set) Get(key string) *Breaker {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	b, ok := s.breakers[key]
+	if !ok {
+		b = &Breaker{set: s}
+		s.breakers[key] = b
+	}
+	return b
+}
+
+// Remove forgets the breaker of a destination.
+func (s *Set) Remove(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.breakers, key)
+}
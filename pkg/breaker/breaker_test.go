@@ -0,0 +1,102 @@
+package breaker
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBreaker(t *testing.T) {
+	now := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	set := NewSet(Config{Failures: 3, Cooldown: time.Minute})
+	set.now = func() time.Time { return now }
+	b := set.Get("hook-1")
+	assert.Same(t, b, set.Get("hook-1"))
+
+	// Opens after three consecutive failures
+	assert.False(t, b.Success(100*time.Millisecond).Changed())
+	assert.Equal(t, Closed, b.Failure(time.Second, errors.New("503")).Stats.State)
+	assert.Equal(t, Closed, b.Failure(time.Second, errors.New("503")).Stats.State)
+	transition := b.Failure(time.Second, errors.New("timeout"))
+	assert.True(t, transition.Changed())
+	assert.Equal(t, Closed, transition.From)
+
+	stats := transition.Stats
+	assert.Equal(t, Open, stats.State)
+	assert.Equal(t, 3, stats.ConsecutiveFailures)
+	assert.Equal(t, int64(1), stats.Successes)
+	assert.Equal(t, int64(3), stats.Failures)
+	assert.Equal(t, "timeout", stats.LastError)
+	assert.Equal(t, time.Second, stats.LastLatency)
+	assert.Equal(t, now.Add(time.Minute), stats.RetryAt)
+	assert.Equal(t, stats, b.Stats())
+
+	// Rejects requests until the cooldown elapses
+	retryAt, ok := b.Allow()
+	assert.False(t, ok)
+	assert.Equal(t, now.Add(time.Minute), retryAt)
+
+	// A failed probe opens it again
+	now = now.Add(time.Minute)
+	_, ok = b.Allow()
+	assert.True(t, ok)
+	assert.Equal(t, HalfOpen, b.Stats().State)
+	transition = b.Failure(time.Second, errors.New("503"))
+	assert.Equal(t, HalfOpen, transition.From)
+	assert.Equal(t, Open, transition.Stats.State)
+
+	// A successful probe closes it
+	now = now.Add(time.Minute)
+	_, ok = b.Allow()
+	assert.True(t, ok)
+	transition = b.Success(50 * time.Millisecond)
+	assert.Equal(t, HalfOpen, transition.From)
+	stats = b.Stats()
+	assert.Equal(t, Closed, stats.State)
+	assert.Zero(t, stats.ConsecutiveFailures)
+	assert.True(t, stats.RetryAt.IsZero())
+}
+
+func TestBreakerSingleProbe(t *testing.T) {
+	now := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	set := NewSet(Config{Failures: 1, Cooldown: time.Minute})
+	set.now = func() time.Time { return now }
+	b := set.Get("hook-1")
+	b.Failure(time.Second, errors.New("503"))
+
+	// Only one request probes the destination
+	now = now.Add(time.Minute)
+	_, ok := b.Allow()
+	assert.True(t, ok)
+	retryAt, ok := b.Allow()
+	assert.False(t, ok)
+	assert.Equal(t, now.Add(time.Minute), retryAt)
+
+	// Another one is let through when the outcome of the probe is lost
+	now = now.Add(time.Minute)
+	_, ok = b.Allow()
+	assert.True(t, ok)
+	_, ok = b.Allow()
+	assert.False(t, ok)
+	assert.Equal(t, HalfOpen, b.Stats().State)
+}
+
+func TestBreakerWithoutThreshold(t *testing.T) {
+	b := NewSet(Config{}).Get("hook-1")
+	for i := 0; i < 100; i++ {
+		assert.Equal(t, Closed, b.Failure(time.Millisecond, nil).Stats.State)
+	}
+	_, ok := b.Allow()
+	assert.True(t, ok)
+}
+
+func TestAverageLatency(t *testing.T) {
+	b := NewSet(Config{}).Get("hook-1")
+	b.Success(100 * time.Millisecond)
+	assert.Equal(t, 100*time.Millisecond, b.Stats().AvgLatency)
+
+	b.Success(200 * time.Millisecond)
+	assert.Equal(t, 120*time.Millisecond, b.Stats().AvgLatency)
+}
@@ -0,0 +1,63 @@
+package derrors
+
+import (
+	"context"
+	"errors"
+
+	"github.com/rabbitmq/amqp091-go"
+)
+
+// HandleAMQPError classifies an error returned by amqp091 into a typed error.
+func HandleAMQPError(err error, format string, args ...any) error {
+	if err == nil {
+		return nil
+	}
+
+	var ierr *Error
+	if errors.As(err, &ierr) {
+		return err
+	}
+
+	if errors.Is(err, context.Canceled) {
+		// The caller went away, nothing timed out
+		return WrapStack(err, Unknown, format, args...)
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return WrapStack(err, Timeout, format, args...)
+	}
+
+	var amqpErr *amqp091.Error
+	if errors.As(err, &amqpErr) {
+		return WrapStack(err, amqpErrorCode(amqpErr), format, args...)
+	}
+
+	if isConnectionLoss(err) {
+		return WrapStack(err, Unavailable, format, args...)
+	}
+
+	return WrapStack(err, Unknown, format, args...)
+}
+
+func amqpErrorCode(amqpErr *amqp091.Error) ErrorCode {
+	switch amqpErr.Code {
+	case amqp091.NotFound:
+		return NotFound
+	case amqp091.AccessRefused:
+		return Unauthorized
+	case amqp091.NotAllowed:
+		return Forbidden
+	case amqp091.ResourceLocked:
+		return Conflict
+	case amqp091.PreconditionFailed:
+		return PreconditionFailed
+	case amqp091.ContentTooLarge:
+		return PayloadTooLarge
+	case amqp091.ConnectionForced, amqp091.ChannelError, amqp091.ResourceError,
+		amqp091.InternalError, amqp091.FrameError, amqp091.UnexpectedFrame:
+		return Unavailable
+	}
+	if amqpErr.Recover {
+		return Unavailable
+	}
+	return Unknown
+}
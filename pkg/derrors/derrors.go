@@ -27,18 +27,63 @@ const (
 	Duplicate
 	Unauthorized
 	Forbidden
+	Conflict
+	RateLimited
+	Unavailable
+	Timeout
+	PayloadTooLarge
+	PreconditionFailed
 )
 
+// codes maps every ErrorCode to its stable name, HTTP status and CLI exit
+// code. Exit codes are distinct per ErrorCode so scripts can branch on them.
 var codes = []struct {
 	code   ErrorCode
+	name   string
 	status int
+	exit   int
 }{
-	{Unknown, http.StatusInternalServerError},
-	{NotFound, http.StatusNotFound},
-	{InvalidArgument, http.StatusBadRequest},
-	{Duplicate, http.StatusBadRequest},
-	{Unauthorized, http.StatusUnauthorized},
-	{Forbidden, http.StatusForbidden},
+	{Unknown, "unknown", http.StatusInternalServerError, 1},
+	{NotFound, "not_found", http.StatusNotFound, 3},
+	{InvalidArgument, "invalid_argument", http.StatusBadRequest, 2},
+	{Duplicate, "duplicate", http.StatusConflict, 4},
+	{Unauthorized, "unauthorized", http.StatusUnauthorized, 5},
+	{Forbidden, "forbidden", http.StatusForbidden, 6},
+	{Conflict, "conflict", http.StatusConflict, 7},
+	{RateLimited, "rate_limited", http.StatusTooManyRequests, 8},
+	{Unavailable, "unavailable", http.StatusServiceUnavailable, 9},
+	{Timeout, "timeout", http.StatusGatewayTimeout, 10},
+	{PayloadTooLarge, "payload_too_large", http.StatusRequestEntityTooLarge, 11},
+	{PreconditionFailed, "precondition_failed", http.StatusPreconditionFailed, 12},
+}
+
+// String returns the stable, machine readable name of the code.
+func (c ErrorCode) String() string {
+	for _, e := range codes {
+		if c == e.code {
+			return e.name
+		}
+	}
+	return codes[Unknown].name
+}
+
+// ParseCode returns the ErrorCode named name, or Unknown.
+func ParseCode(name string) ErrorCode {
+	for _, e := range codes {
+		if name == e.name {
+			return e.code
+		}
+	}
+	return Unknown
+}
+
+// CodeOf returns the code of the first *Error in err's chain, or Unknown.
+func CodeOf(err error) ErrorCode {
+	var ierr *Error
+	if errors.As(err, &ierr) {
+		return ierr.code
+	}
+	return Unknown
 }
 
 // ToStatus returns a status code corresponding to err.
@@ -57,6 +102,20 @@ func ToStatus(err error) int {
 	return http.StatusInternalServerError
 }
 
+// ToExitCode returns the process exit code corresponding to err.
+func ToExitCode(err error) int {
+	if err == nil {
+		return 0
+	}
+	code := CodeOf(err)
+	for _, e := range codes {
+		if code == e.code {
+			return e.exit
+		}
+	}
+	return codes[Unknown].exit
+}
+
 // IsErrCode Compare error code
 func IsErrCode(err error, code ErrorCode) bool {
 	if err == nil {
@@ -0,0 +1,105 @@
+package derrors
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/rabbitmq/amqp091-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCodesAreDistinct(t *testing.T) {
+	names := map[string]bool{}
+	exits := map[int]bool{}
+	for _, e := range codes {
+		assert.False(t, names[e.name], "duplicate name %q", e.name)
+		assert.False(t, exits[e.exit], "duplicate exit code %d", e.exit)
+		assert.NotZero(t, e.exit)
+		names[e.name] = true
+		exits[e.exit] = true
+
+		assert.Equal(t, e.code, ParseCode(e.name))
+	}
+}
+
+func TestToStatus(t *testing.T) {
+	var testCases = []struct {
+		caseName string
+		err      error
+		status   int
+		exit     int
+	}{
+		{"Nil", nil, http.StatusOK, 0},
+		{"Untyped", errors.New("boom"), http.StatusInternalServerError, 1},
+		{"Duplicate", New(Duplicate, "exists"), http.StatusConflict, 4},
+		{"RateLimited", New(RateLimited, "slow down"), http.StatusTooManyRequests, 8},
+		{"Unavailable", New(Unavailable, "down"), http.StatusServiceUnavailable, 9},
+		{"Timeout", New(Timeout, "slow"), http.StatusGatewayTimeout, 10},
+		{"PayloadTooLarge", New(PayloadTooLarge, "big"), http.StatusRequestEntityTooLarge, 11},
+		{"PreconditionFailed", New(PreconditionFailed, "mismatch"), http.StatusPreconditionFailed, 12},
+		{"Wrapped", fmt.Errorf("outer: %w", New(Conflict, "retry")), http.StatusConflict, 7},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.caseName, func(t *testing.T) {
+			assert.Equal(t, tc.status, ToStatus(tc.err))
+			assert.Equal(t, tc.exit, ToExitCode(tc.err))
+		})
+	}
+}
+
+func TestHandlePgxError(t *testing.T) {
+	var testCases = []struct {
+		caseName string
+		err      error
+		code     ErrorCode
+	}{
+		{"NoRows", pgx.ErrNoRows, NotFound},
+		{"UniqueViolation", &pgconn.PgError{Code: "23505"}, Duplicate},
+		{"CheckViolation", &pgconn.PgError{Code: "23514"}, InvalidArgument},
+		{"SerializationFailure", &pgconn.PgError{Code: "40001"}, Conflict},
+		{"ConnectionFailure", &pgconn.PgError{Code: "08006"}, Unavailable},
+		{"TooManyConnections", &pgconn.PgError{Code: "53300"}, Unavailable},
+		{"DeadlineExceeded", context.DeadlineExceeded, Timeout},
+		{"Canceled", fmt.Errorf("query: %w", context.Canceled), Unknown},
+		{"Other", errors.New("boom"), Unknown},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.caseName, func(t *testing.T) {
+			err := HandlePgxError(tc.err, "query failed")
+			assert.True(t, IsErrCode(err, tc.code), "got %v", CodeOf(err))
+			assert.ErrorIs(t, err, tc.err)
+		})
+	}
+}
+
+func TestHandleAMQPError(t *testing.T) {
+	var testCases = []struct {
+		caseName string
+		err      error
+		code     ErrorCode
+	}{
+		{"Closed", amqp091.ErrClosed, Unavailable},
+		{"Credentials", amqp091.ErrCredentials, Unauthorized},
+		{"NotFound", &amqp091.Error{Code: amqp091.NotFound}, NotFound},
+		{"PreconditionFailed", &amqp091.Error{Code: amqp091.PreconditionFailed}, PreconditionFailed},
+		{"ContentTooLarge", &amqp091.Error{Code: amqp091.ContentTooLarge}, PayloadTooLarge},
+		{"Wrapped", fmt.Errorf("failed to publish message: %w", amqp091.ErrClosed), Unavailable},
+		{"DeadlineExceeded", context.DeadlineExceeded, Timeout},
+		{"Canceled", context.Canceled, Unknown},
+		{"Other", errors.New("boom"), Unknown},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.caseName, func(t *testing.T) {
+			err := HandleAMQPError(tc.err, "publish failed")
+			assert.True(t, IsErrCode(err, tc.code), "got %v", CodeOf(err))
+		})
+	}
+}
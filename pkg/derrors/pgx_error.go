@@ -0,0 +1,91 @@
+package derrors
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// Postgres SQLSTATE codes classified by HandlePgxError, see
+// https://www.postgresql.org/docs/current/errcodes-appendix.html
+const (
+	pgUniqueViolation      = "23505"
+	pgForeignKeyViolation  = "23503"
+	pgCheckViolation       = "23514"
+	pgNotNullViolation     = "23502"
+	pgSerializationFailure = "40001"
+	pgDeadlockDetected     = "40P01"
+	pgLockNotAvailable     = "55P03"
+	pgQueryCanceled        = "57014"
+	pgAdminShutdown        = "57P01"
+	pgCannotConnectNow     = "57P03"
+	pgTooManyConnections   = "53300"
+	pgConnectionException  = "08"
+)
+
+// HandlePgxError classifies an error returned by pgx into a typed error.
+func HandlePgxError(err error, format string, args ...any) error {
+	if err == nil {
+		return nil
+	}
+
+	var ierr *Error
+	if errors.As(err, &ierr) {
+		return err
+	}
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		return WrapStack(err, NotFound, format, args...)
+	}
+	if errors.Is(err, context.Canceled) {
+		// The caller went away, nothing timed out
+		return WrapStack(err, Unknown, format, args...)
+	}
+	if errors.Is(err, context.DeadlineExceeded) || pgconn.Timeout(err) {
+		return WrapStack(err, Timeout, format, args...)
+	}
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		return WrapStack(err, pgErrorCode(pgErr), format, args...)
+	}
+
+	if isConnectionLoss(err) {
+		return WrapStack(err, Unavailable, format, args...)
+	}
+
+	return WrapStack(err, Unknown, format, args...)
+}
+
+func pgErrorCode(pgErr *pgconn.PgError) ErrorCode {
+	switch pgErr.Code {
+	case pgUniqueViolation:
+		return Duplicate
+	case pgForeignKeyViolation, pgCheckViolation, pgNotNullViolation:
+		return InvalidArgument
+	case pgSerializationFailure, pgDeadlockDetected, pgLockNotAvailable:
+		return Conflict
+	case pgQueryCanceled:
+		return Timeout
+	case pgAdminShutdown, pgCannotConnectNow, pgTooManyConnections:
+		return Unavailable
+	}
+	if len(pgErr.Code) >= 2 && pgErr.Code[:2] == pgConnectionException {
+		return Unavailable
+	}
+	return Unknown
+}
+
+func isConnectionLoss(err error) bool {
+	var connectErr *pgconn.ConnectError
+	var netErr net.Error
+	return errors.As(err, &connectErr) ||
+		errors.As(err, &netErr) ||
+		errors.Is(err, net.ErrClosed) ||
+		errors.Is(err, io.EOF) ||
+		errors.Is(err, io.ErrUnexpectedEOF)
+}
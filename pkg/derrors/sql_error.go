@@ -1,10 +1,15 @@
 package derrors
 
-import "database/sql"
+import (
+	"database/sql"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+)
 
 func HandleSQLError(err error, format string, args ...any) error {
 	if err != nil {
-		if err == sql.ErrNoRows {
+		if errors.Is(err, sql.ErrNoRows) || errors.Is(err, pgx.ErrNoRows) {
 			return New(NotFound, "Not found")
 		}
 		return WrapStack(err, Unknown, format, args...)
@@ -0,0 +1,120 @@
+// Package health runs dependency checks and aggregates them into a report
+// suitable for liveness and readiness probes.
+package health
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	StatusUp   string = "up"
+	StatusDown string = "down"
+)
+
+// Checker reports the health of a single dependency.
+type Checker interface {
+	Check(ctx context.Context) error
+}
+
+// CheckerFunc adapts an ordinary function to the Checker interface.
+type CheckerFunc func(ctx context.Context) error
+
+// Check calls f(ctx).
+func (f CheckerFunc) Check(ctx context.Context) error {
+	return f(ctx)
+}
+
+// Result is the outcome of a single dependency check.
+type Result struct {
+	Status  string `json:"status"`
+	Latency string `json:"latency"`
+	Error   string `json:"error,omitempty"`
+}
+
+// Report is the aggregated outcome of every registered check.
+type Report struct {
+	Status string            `json:"status"`
+	Checks map[string]Result `json:"checks"`
+}
+
+// Up reports whether every check in the report succeeded.
+func (r Report) Up() bool {
+	return r.Status == StatusUp
+}
+
+// Health holds the checkers used to decide whether the service is ready.
+type Health struct {
+	timeout  time.Duration
+	mu       sync.RWMutex
+	checkers map[string]Checker
+}
+
+// New creates a Health whose checks are each bounded by timeout.
+func New(timeout time.Duration) *Health {
+	return &Health{
+		timeout:  timeout,
+		checkers: make(map[string]Checker),
+	}
+}
+
+// Register adds a named checker, replacing any checker with the same name.
+func (h *Health) Register(name string, checker Checker) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.checkers[name] = checker
+}
+
+// Check runs every registered checker concurrently and returns the report.
+func (h *Health) Check(ctx context.Context) Report {
+	h.mu.RLock()
+	names := make([]string, 0, len(h.checkers))
+	for name := range h.checkers {
+		names = append(names, name)
+	}
+	h.mu.RUnlock()
+	sort.Strings(names)
+
+	results := make([]Result, len(names))
+	var wg sync.WaitGroup
+	for i, name := range names {
+		h.mu.RLock()
+		checker := h.checkers[name]
+		h.mu.RUnlock()
+
+		wg.Add(1)
+		go func(i int, checker Checker) {
+			defer wg.Done()
+			results[i] = h.run(ctx, checker)
+		}(i, checker)
+	}
+	wg.Wait()
+
+	report := Report{Status: StatusUp, Checks: make(map[string]Result, len(names))}
+	for i, name := range names {
+		report.Checks[name] = results[i]
+		if results[i].Status != StatusUp {
+			report.Status = StatusDown
+		}
+	}
+	return report
+}
+
+func (h *Health) run(ctx context.Context, checker Checker) Result {
+	if h.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, h.timeout)
+		defer cancel()
+	}
+
+	start := time.Now()
+	err := checker.Check(ctx)
+	result := Result{Status: StatusUp, Latency: time.Since(start).String()}
+	if err != nil {
+		result.Status = StatusDown
+		result.Error = err.Error()
+	}
+	return result
+}
@@ -0,0 +1,88 @@
+package logger
+
+import (
+	"context"
+
+	"tenant/pkg/trace"
+
+	"github.com/sirupsen/logrus"
+)
+
+type contextKey int
+
+const (
+	requestIDKey contextKey = iota
+	clientIDKey
+	messageIDKey
+	loggerKey
+)
+
+// WithRequestID returns a copy of ctx carrying the request ID.
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey, requestID)
+}
+
+// RequestID returns the request ID stored in ctx, or an empty string.
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey).(string)
+	return id
+}
+
+// WithClientID returns a copy of ctx carrying the tenant client ID.
+func WithClientID(ctx context.Context, clientID string) context.Context {
+	return context.WithValue(ctx, clientIDKey, clientID)
+}
+
+// ClientID returns the tenant client ID stored in ctx, or an empty string.
+func ClientID(ctx context.Context) string {
+	id, _ := ctx.Value(clientIDKey).(string)
+	return id
+}
+
+// WithMessageID returns a copy of ctx carrying the ID of the message being
+// consumed.
+func WithMessageID(ctx context.Context, messageID string) context.Context {
+	return context.WithValue(ctx, messageIDKey, messageID)
+}
+
+// MessageID returns the message ID stored in ctx, or an empty string.
+func MessageID(ctx context.Context) string {
+	id, _ := ctx.Value(messageIDKey).(string)
+	return id
+}
+
+// WithContext returns a log entry annotated with the request, client, message
+// and trace IDs found in ctx. When a level override is active for the client ID the entry
+// is logged at that level instead of the level of log.
+func WithContext(ctx context.Context, log *logrus.Logger) *logrus.Entry {
+	fields := logrus.Fields{}
+	if id := RequestID(ctx); id != "" {
+		fields["request_id"] = id
+	}
+	clientID := ClientID(ctx)
+	if clientID != "" {
+		fields["client_id"] = clientID
+	}
+	if id := MessageID(ctx); id != "" {
+		fields["message_id"] = id
+	}
+	if span, ok := trace.FromContext(ctx); ok {
+		fields["trace_id"] = span.TraceID
+	}
+	return forClient(log, clientID).WithContext(ctx).WithFields(fields)
+}
+
+// NewContext returns a copy of ctx carrying log, retrieved later by FromContext.
+func NewContext(ctx context.Context, log *logrus.Logger) context.Context {
+	return context.WithValue(ctx, loggerKey, log)
+}
+
+// FromContext returns a log entry built from the logger stored in ctx by
+// NewContext, falling back to the standard logger.
+func FromContext(ctx context.Context) *logrus.Entry {
+	log, ok := ctx.Value(loggerKey).(*logrus.Logger)
+	if !ok {
+		log = logrus.StandardLogger()
+	}
+	return WithContext(ctx, log)
+}
@@ -0,0 +1,176 @@
+package logger
+
+import (
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// controllers maps a logger to its LevelController so WithContext can apply
+// per-tenant overrides without every caller holding the controller.
+var controllers sync.Map
+
+// Override is a log level applied until it expires.
+type Override struct {
+	Level     string     `json:"level"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+func (o Override) expired(now time.Time) bool {
+	return o.ExpiresAt != nil && !now.Before(*o.ExpiresAt)
+}
+
+// LevelStatus describes the active global level and tenant overrides.
+type LevelStatus struct {
+	Level     string              `json:"level"`
+	ExpiresAt *time.Time          `json:"expires_at,omitempty"`
+	Clients   map[string]Override `json:"clients"`
+}
+
+// LevelController changes the level of a logger at runtime, globally or for
+// a single tenant, reverting each change once its TTL elapses.
+type LevelController struct {
+	log *logrus.Logger
+
+	mu        sync.Mutex
+	base      logrus.Level
+	expiresAt *time.Time
+	timer     *time.Timer
+	clients   map[string]Override
+	derived   map[logrus.Level]*logrus.Logger
+}
+
+// NewLevelController creates a controller for log and registers it so that
+// WithContext honors tenant overrides.
+func NewLevelController(log *logrus.Logger) *LevelController {
+	lc := &LevelController{
+		log:     log,
+		base:    log.GetLevel(),
+		clients: make(map[string]Override),
+		derived: make(map[logrus.Level]*logrus.Logger),
+	}
+	controllers.Store(log, lc)
+	return lc
+}
+
+// SetLevel changes the global level. A positive ttl reverts to the previous
+// permanent level once it elapses, zero makes the change permanent.
+func (lc *LevelController) SetLevel(level logrus.Level, ttl time.Duration) {
+	lc.mu.Lock()
+	defer lc.mu.Unlock()
+
+	if lc.timer != nil {
+		lc.timer.Stop()
+		lc.timer = nil
+	}
+	lc.expiresAt = nil
+	lc.log.SetLevel(level)
+
+	if ttl <= 0 {
+		lc.base = level
+		return
+	}
+
+	expiresAt := time.Now().Add(ttl)
+	lc.expiresAt = &expiresAt
+	lc.timer = time.AfterFunc(ttl, lc.revert)
+}
+
+// SetClientLevel overrides the level for log entries carrying clientID.
+// A zero ttl keeps the override until it is reset.
+func (lc *LevelController) SetClientLevel(clientID string, level logrus.Level, ttl time.Duration) {
+	lc.mu.Lock()
+	defer lc.mu.Unlock()
+
+	override := Override{Level: level.String()}
+	if ttl > 0 {
+		expiresAt := time.Now().Add(ttl)
+		override.ExpiresAt = &expiresAt
+	}
+	lc.clients[clientID] = override
+}
+
+// ResetClientLevel removes the override for clientID.
+func (lc *LevelController) ResetClientLevel(clientID string) {
+	lc.mu.Lock()
+	defer lc.mu.Unlock()
+	delete(lc.clients, clientID)
+}
+
+// Status returns the current global level and every active tenant override.
+func (lc *LevelController) Status() LevelStatus {
+	lc.mu.Lock()
+	defer lc.mu.Unlock()
+
+	now := time.Now()
+	status := LevelStatus{
+		Level:     lc.log.GetLevel().String(),
+		ExpiresAt: lc.expiresAt,
+		Clients:   make(map[string]Override, len(lc.clients)),
+	}
+	for clientID, override := range lc.clients {
+		if override.expired(now) {
+			delete(lc.clients, clientID)
+			continue
+		}
+		status.Clients[clientID] = override
+	}
+	return status
+}
+
+func (lc *LevelController) revert() {
+	lc.mu.Lock()
+	defer lc.mu.Unlock()
+
+	lc.log.SetLevel(lc.base)
+	lc.expiresAt = nil
+	lc.timer = nil
+}
+
+// loggerFor returns the logger to use for clientID: the controlled logger
+// itself, or a sibling sharing its output at the overridden level.
+func (lc *LevelController) loggerFor(clientID string) *logrus.Logger {
+	lc.mu.Lock()
+	defer lc.mu.Unlock()
+
+	override, ok := lc.clients[clientID]
+	if !ok {
+		return lc.log
+	}
+	if override.expired(time.Now()) {
+		delete(lc.clients, clientID)
+		return lc.log
+	}
+
+	level, err := logrus.ParseLevel(override.Level)
+	if err != nil || level == lc.log.GetLevel() {
+		return lc.log
+	}
+
+	derived, ok := lc.derived[level]
+	if !ok {
+		derived = &logrus.Logger{
+			Out:          lc.log.Out,
+			Hooks:        lc.log.Hooks,
+			Formatter:    lc.log.Formatter,
+			ReportCaller: lc.log.ReportCaller,
+			Level:        level,
+			ExitFunc:     lc.log.ExitFunc,
+		}
+		lc.derived[level] = derived
+	}
+	return derived
+}
+
+// forClient returns the logger to use for entries carrying clientID.
+func forClient(log *logrus.Logger, clientID string) *logrus.Logger {
+	if clientID == "" {
+		return log
+	}
+	lc, ok := controllers.Load(log)
+	if !ok {
+		return log
+	}
+	return lc.(*LevelController).loggerFor(clientID)
+}
@@ -0,0 +1,53 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLevelControllerSetLevel(t *testing.T) {
+	log := logrus.New()
+	log.SetLevel(logrus.InfoLevel)
+	lc := NewLevelController(log)
+
+	lc.SetLevel(logrus.DebugLevel, 20*time.Millisecond)
+	assert.Equal(t, logrus.DebugLevel, log.GetLevel())
+	assert.NotNil(t, lc.Status().ExpiresAt)
+
+	assert.Eventually(t, func() bool {
+		return log.GetLevel() == logrus.InfoLevel
+	}, time.Second, 5*time.Millisecond)
+	assert.Nil(t, lc.Status().ExpiresAt)
+
+	lc.SetLevel(logrus.WarnLevel, 0)
+	assert.Equal(t, logrus.WarnLevel, log.GetLevel())
+	assert.Nil(t, lc.Status().ExpiresAt)
+}
+
+func TestLevelControllerSetClientLevel(t *testing.T) {
+	var buf bytes.Buffer
+	log := logrus.New()
+	log.SetOutput(&buf)
+	log.SetLevel(logrus.InfoLevel)
+	lc := NewLevelController(log)
+
+	lc.SetClientLevel("client-a", logrus.DebugLevel, 50*time.Millisecond)
+
+	WithContext(WithClientID(context.Background(), "client-a"), log).Debug("debug for a")
+	WithContext(WithClientID(context.Background(), "client-b"), log).Debug("debug for b")
+	assert.Contains(t, buf.String(), "debug for a")
+	assert.NotContains(t, buf.String(), "debug for b")
+	assert.Contains(t, lc.Status().Clients, "client-a")
+
+	time.Sleep(60 * time.Millisecond)
+	buf.Reset()
+
+	WithContext(WithClientID(context.Background(), "client-a"), log).Debug("expired debug for a")
+	assert.Empty(t, buf.String())
+	assert.NotContains(t, lc.Status().Clients, "client-a")
+}
@@ -1,34 +1,63 @@
 package logger
 
 import (
+	"io"
 	"os"
+	"strings"
 	"tenant/infrastructure/config"
 
 	"github.com/sirupsen/logrus"
 	"gopkg.in/natefinch/lumberjack.v2"
 )
 
+const (
+	FormatJSON string = "json"
+	FormatText string = "text"
+)
+
 // NewLogger initializes and returns a Logrus logger with file rotation.
 func NewLogger(cfg config.Config) *logrus.Logger {
 	// Create a new instance of Logrus logger
 	logger := logrus.New()
 
-	// Configure Logrus to use Lumberjack for log rotation
-	logger.SetOutput(&lumberjack.Logger{
-		Filename:   cfg.Logging.File,
-		MaxSize:    cfg.Logging.MaxSize,    // Maximum size in MB
-		MaxBackups: cfg.Logging.MaxBackups, // Maximum number of old log files to retain
-		MaxAge:     cfg.Logging.MaxAge,     // Maximum age in days to retain old log files
-	})
-
-	// Set the log format to JSON
-	logger.SetFormatter(&logrus.JSONFormatter{})
+	// Write to the console and/or a rotated file, whichever is enabled
+	var writers []io.Writer
+	if cfg.Logging.Console {
+		writers = append(writers, os.Stdout)
+	}
+	if cfg.Logging.File != "" {
+		writers = append(writers, &lumberjack.Logger{
+			Filename:   cfg.Logging.File,
+			MaxSize:    cfg.Logging.MaxSize,    // Maximum size in MB
+			MaxBackups: cfg.Logging.MaxBackups, // Maximum number of old log files to retain
+			MaxAge:     cfg.Logging.MaxAge,     // Maximum age in days to retain old log files
+		})
+	}
+
+	switch len(writers) {
+	case 0:
+		logger.SetOutput(io.Discard)
+	case 1:
+		logger.SetOutput(writers[0])
+	default:
+		logger.SetOutput(io.MultiWriter(writers...))
+	}
+
+	// Set the log format, JSON unless text is requested
+	switch strings.ToLower(cfg.Logging.Format) {
+	case FormatText:
+		logger.SetFormatter(&logrus.TextFormatter{FullTimestamp: true})
+	default:
+		logger.SetFormatter(&logrus.JSONFormatter{})
+	}
 
 	// Set log level (default to Info)
-	logger.SetLevel(logrus.InfoLevel)
-
-	// Also log to the console (optional)
-	logger.SetOutput(os.Stdout)
+	level, err := logrus.ParseLevel(cfg.Logging.Level)
+	if err != nil {
+		logger.Warnf("invalid log level %q, falling back to %s", cfg.Logging.Level, logrus.InfoLevel)
+		level = logrus.InfoLevel
+	}
+	logger.SetLevel(level)
 
 	return logger
 }
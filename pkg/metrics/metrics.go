@@ -0,0 +1,241 @@
+// Package metrics keeps counters, gauges and summaries in memory and serves
+// them in the Prometheus text exposition format.
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Default is the registry served by the /metrics endpoints.
+var Default = NewRegistry()
+
+// Registry holds metric families by name.
+type Registry struct {
+	mu       sync.RWMutex
+	families map[string]*family
+}
+
+// NewRegistry creates an empty registry.
+func NewRegistry() *Registry {
+	return &Registry{families: make(map[string]*family)}
+}
+
+// Counter is a value that only goes up, such as a number of requests.
+type Counter struct{ f *family }
+
+// Gauge is a value that goes up and down, such as a state.
+type Gauge struct{ f *family }
+
+// Summary tracks the count and the sum of observations, such as latencies.
+type Summary struct{ f *family }
+
+// NewCounter registers a counter on the default registry.
+func NewCounter(name, help string, labels ...string) *Counter {
+	return Default.Counter(name, help, labels...)
+}
+
+// NewGauge registers a gauge on the default registry.
+func NewGauge(name, help string, labels ...string) *Gauge {
+	return Default.Gauge(name, help, labels...)
+}
+
+// NewSummary registers a summary on the default registry.
+func NewSummary(name, help string, labels ...string) *Summary {
+	return Default.Summary(name, help, labels...)
+}
+
+// Counter registers a counter, or returns the one registered under name.
+func (r *Registry) Counter(name, help string, labels ...string) *Counter {
+	return &Counter{f: r.register(name, help, "counter", labels)}
+}
+
+// Gauge registers a gauge, or returns the one registered under name.
+func (r *Registry) Gauge(name, help string, labels ...string) *Gauge {
+	return &Gauge{f: r.register(name, help, "gauge", labels)}
+}
+
+// Summary registers a summary, or returns the one registered under name.
+func (r *Registry) Summary(name, help string, labels ...string) *Summary {
+	return &Summary{f: r.register(name, help, "summary", labels)}
+}
+
+// Inc adds one to the series of labelValues.
+func (c *Counter) Inc(labelValues ...string) {
+	c.Add(1, labelValues...)
+}
+
+// Add adds v, which must not be negative, to the series of labelValues.
+func (c *Counter) Add(v float64, labelValues ...string) {
+	c.f.update(labelValues, func(s *series) { s.value += v })
+}
+
+// Set sets the series of labelValues to v.
+func (g *Gauge) Set(v float64, labelValues ...string) {
+	g.f.update(labelValues, func(s *series) { s.value = v })
+}
+
+// Add adds v to the series of labelValues.
+func (g *Gauge) Add(v float64, labelValues ...string) {
+	g.f.update(labelValues, func(s *series) { s.value += v })
+}
+
+// Delete removes the series of labelValues, for instance once the object it
+// describes is gone.
+func (g *Gauge) Delete(labelValues ...string) {
+	g.f.delete(labelValues)
+}
+
+// Observe records v in the series of labelValues.
+func (s *Summary) Observe(v float64, labelValues ...string) {
+	s.f.update(labelValues, func(s *series) {
+		s.sum += v
+		s.count++
+	})
+}
+
+// ServeHTTP writes every metric of the registry.
+func (r *Registry) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	r.Write(w)
+}
+
+// Write writes every metric of the registry in the text exposition format,
+// sorted by name and labels.
+func (r *Registry) Write(w io.Writer) error {
+	r.mu.RLock()
+	families := make([]*family, 0, len(r.families))
+	for _, f := range r.families {
+		families = append(families, f)
+	}
+	r.mu.RUnlock()
+	sort.Slice(families, func(i, j int) bool { return families[i].name < families[j].name })
+
+	bw := bufio.NewWriter(w)
+	for _, f := range families {
+		f.write(bw)
+	}
+	return bw.Flush()
+}
+
+func (r *Registry) register(name, help, kind string, labels []string) *family {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if f, ok := r.families[name]; ok {
+		if f.kind != kind || len(f.labels) != len(labels) {
+			panic(fmt.Sprintf("metrics: %s registered again as a different metric", name))
+		}
+		return f
+	}
+	f := &family{name: name, help: help, kind: kind, labels: labels, series: make(map[string]*series)}
+	r.families[name] = f
+	return f
+}
+
+type family struct {
+	name   string
+	help   string
+	kind   string
+	labels []string
+
+	mu     sync.Mutex
+	series map[string]*series
+}
+
+type series struct {
+	labelValues []string
+	value       float64
+	sum         float64
+	count       uint64
+}
+
+func (f *family) update(labelValues []string, fn func(s *series)) {
+	if len(labelValues) != len(f.labels) {
+		panic(fmt.Sprintf("metrics: %s expects %d label values, got %d", f.name, len(f.labels), len(labelValues)))
+	}
+	key := strings.Join(labelValues, "\xff")
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	s, ok := f.series[key]
+	if !ok {
+		s = &series{labelValues: append([]string(nil), labelValues...)}
+		f.series[key] = s
+	}
+	fn(s)
+}
+
+func (f *family) delete(labelValues []string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	delete(f.series, strings.Join(labelValues, "\xff"))
+}
+
+func (f *family) write(w *bufio.Writer) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	keys := make([]string, 0, len(f.series))
+	for key := range f.series {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	fmt.Fprintf(w, "# HELP %s %s\n", f.name, escapeHelp(f.help))
+	fmt.Fprintf(w, "# TYPE %s %s\n", f.name, f.kind)
+	for _, key := range keys {
+		s := f.series[key]
+		labels := f.formatLabels(s.labelValues)
+		if f.kind == "summary" {
+			fmt.Fprintf(w, "%s_sum%s %s\n", f.name, labels, formatValue(s.sum))
+			fmt.Fprintf(w, "%s_count%s %d\n", f.name, labels, s.count)
+			continue
+		}
+		fmt.Fprintf(w, "%s%s %s\n", f.name, labels, formatValue(s.value))
+	}
+}
+
+func (f *family) formatLabels(values []string) string {
+	if len(values) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteByte('{')
+	for i, value := range values {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(f.labels[i])
+		b.WriteString(`="`)
+		b.WriteString(labelEscaper.Replace(value))
+		b.WriteByte('"')
+	}
+	b.WriteByte('}')
+	return b.String()
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func escapeHelp(help string) string {
+	return strings.NewReplacer(`\`, `\\`, "\n", `\n`).Replace(help)
+}
+
+func formatValue(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
@@ -0,0 +1,58 @@
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegistry(t *testing.T) {
+	r := NewRegistry()
+	requests := r.Counter("webhook_requests_total", "Requests made to webhooks", "webhook_id", "result")
+	state := r.Gauge("webhook_breaker_state", "State of the breaker", "webhook_id")
+	latency := r.Summary("webhook_request_duration_seconds", "Duration of the requests")
+
+	requests.Inc("hook-1", "success")
+	requests.Add(2, "hook-1", "success")
+	requests.Inc("hook-2", "failure")
+	state.Set(2, `hook-"quoted"`)
+	state.Set(1, "hook-1")
+	state.Delete("hook-1")
+	latency.Observe(0.25)
+	latency.Observe(0.5)
+
+	// Registering again returns the same metric
+	r.Counter("webhook_requests_total", "Requests made to webhooks", "webhook_id", "result").Inc("hook-2", "failure")
+
+	var out strings.Builder
+	assert.Nil(t, r.Write(&out))
+	assert.Equal(t, `# HELP webhook_breaker_state State of the breaker
+# TYPE webhook_breaker_state gauge
+webhook_breaker_state{webhook_id="hook-\"quoted\""} 2
+# HELP webhook_request_duration_seconds Duration of the requests
+# TYPE webhook_request_duration_seconds summary
+webhook_request_duration_seconds_sum 0.75
+webhook_request_duration_seconds_count 2
+# HELP webhook_requests_total Requests made to webhooks
+# TYPE webhook_requests_total counter
+webhook_requests_total{webhook_id="hook-1",result="success"} 3
+webhook_requests_total{webhook_id="hook-2",result="failure"} 2
+`, out.String())
+
+	assert.Panics(t, func() { r.Gauge("webhook_requests_total", "") })
+	assert.Panics(t, func() { requests.Inc("hook-1") })
+}
+
+func TestServeHTTP(t *testing.T) {
+	r := NewRegistry()
+	r.Counter("jobs_total", "Jobs").Inc()
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Header().Get("Content-Type"), "text/plain")
+	assert.Contains(t, rec.Body.String(), "jobs_total 1\n")
+}
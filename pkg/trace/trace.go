@@ -0,0 +1,82 @@
+// Package trace carries W3C trace contexts across processes, so the logs of a
+// message can be followed from its publisher to every consumer.
+package trace
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"strings"
+)
+
+// Header is the header carrying the trace context, as defined by
+// https://www.w3.org/TR/trace-context/
+const Header = "traceparent"
+
+// Span identifies an operation within a trace
+type Span struct {
+	TraceID  string
+	SpanID   string
+	ParentID string
+}
+
+type contextKey struct{}
+
+// NewRoot starts a new trace
+func NewRoot() Span {
+	return Span{TraceID: randomHex(16), SpanID: randomHex(8)}
+}
+
+// Child starts an operation caused by s
+func (s Span) Child() Span {
+	return Span{TraceID: s.TraceID, SpanID: randomHex(8), ParentID: s.SpanID}
+}
+
+// Traceparent formats s for the trace context header
+func (s Span) Traceparent() string {
+	return "00-" + s.TraceID + "-" + s.SpanID + "-01"
+}
+
+// Parse reads a trace context header, the returned span is the remote
+// parent of the operations it causes
+func Parse(traceparent string) (Span, bool) {
+	parts := strings.Split(strings.TrimSpace(traceparent), "-")
+	if len(parts) != 4 || len(parts[0]) != 2 || parts[0] == "ff" {
+		return Span{}, false
+	}
+	traceID, spanID := strings.ToLower(parts[1]), strings.ToLower(parts[2])
+	if !isHex(traceID, 32) || !isHex(spanID, 16) || isZero(traceID) || isZero(spanID) {
+		return Span{}, false
+	}
+	return Span{TraceID: traceID, SpanID: spanID}, true
+}
+
+// WithSpan returns a copy of ctx carrying span
+func WithSpan(ctx context.Context, span Span) context.Context {
+	return context.WithValue(ctx, contextKey{}, span)
+}
+
+// FromContext returns the span stored in ctx
+func FromContext(ctx context.Context) (Span, bool) {
+	span, ok := ctx.Value(contextKey{}).(Span)
+	return span, ok
+}
+
+func randomHex(n int) string {
+	b := make([]byte, n)
+	// crypto/rand never fails on supported platforms
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+func isHex(s string, length int) bool {
+	if len(s) != length {
+		return false
+	}
+	_, err := hex.DecodeString(s)
+	return err == nil
+}
+
+func isZero(s string) bool {
+	return strings.Trim(s, "0") == ""
+}
@@ -0,0 +1,55 @@
+package trace
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	var testCases = []struct {
+		caseName    string
+		traceparent string
+		ok          bool
+	}{
+		{caseName: "Parse_Valid", traceparent: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", ok: true},
+		{caseName: "Parse_Uppercase", traceparent: "00-4BF92F3577B34DA6A3CE929D0E0E4736-00F067AA0BA902B7-00", ok: true},
+		{caseName: "Parse_ZeroTraceID", traceparent: "00-00000000000000000000000000000000-00f067aa0ba902b7-01"},
+		{caseName: "Parse_InvalidVersion", traceparent: "ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"},
+		{caseName: "Parse_ShortSpanID", traceparent: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa-01"},
+		{caseName: "Parse_Empty", traceparent: ""},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.caseName, func(t *testing.T) {
+			span, ok := Parse(testCase.traceparent)
+			assert.Equal(t, testCase.ok, ok)
+			if ok {
+				assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", span.TraceID)
+				assert.Equal(t, "00f067aa0ba902b7", span.SpanID)
+			}
+		})
+	}
+}
+
+func TestChild(t *testing.T) {
+	root := NewRoot()
+	assert.Len(t, root.TraceID, 32)
+	assert.Len(t, root.SpanID, 16)
+
+	child := root.Child()
+	assert.Equal(t, root.TraceID, child.TraceID)
+	assert.Equal(t, root.SpanID, child.ParentID)
+	assert.NotEqual(t, root.SpanID, child.SpanID)
+
+	parsed, ok := Parse(child.Traceparent())
+	assert.True(t, ok)
+	assert.Equal(t, child.TraceID, parsed.TraceID)
+	assert.Equal(t, child.SpanID, parsed.SpanID)
+
+	ctx := WithSpan(context.Background(), child)
+	span, ok := FromContext(ctx)
+	assert.True(t, ok)
+	assert.Equal(t, child, span)
+}
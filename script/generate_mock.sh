@@ -2,9 +2,27 @@
 
 # Generate mocks for repository interfaces
 mockery --name=TenantRepository --dir=internal/repository --output=internal/test/mockrepository --outpkg=mockrepository
+mockery --name=LeaseRepository --dir=internal/repository --output=internal/test/mockrepository --outpkg=mockrepository
+mockery --name=IdempotencyRepository --dir=internal/repository --output=internal/test/mockrepository --outpkg=mockrepository
+mockery --name=MessageRepository --dir=internal/repository --output=internal/test/mockrepository --outpkg=mockrepository
+mockery --name=ArchiveRepository --dir=internal/repository --output=internal/test/mockrepository --outpkg=mockrepository
+mockery --name=ReplayRepository --dir=internal/repository --output=internal/test/mockrepository --outpkg=mockrepository
+mockery --name=ScheduledRepository --dir=internal/repository --output=internal/test/mockrepository --outpkg=mockrepository
+mockery --name=CronRepository --dir=internal/repository --output=internal/test/mockrepository --outpkg=mockrepository
+mockery --name=WebhookRepository --dir=internal/repository --output=internal/test/mockrepository --outpkg=mockrepository
+mockery --name=ProcessorRepository --dir=internal/repository --output=internal/test/mockrepository --outpkg=mockrepository
 
 # Generate mocks for service interfaces
 mockery --name=Messagging --dir=internal/service/messaging --output=internal/test/mockservice --outpkg=mockservice
+mockery --name=Sender --dir=internal/service/webhook --output=internal/test/mockservice --outpkg=mockservice
 
 # Generate mocks for usecase interfaces
-mockery --name=TenantUsecase --dir=internal/usecase --output=internal/test/mockusecase --outpkg=mockusecase
\ No newline at end of file
+mockery --name=TenantUsecase --dir=internal/usecase --output=internal/test/mockusecase --outpkg=mockusecase
+mockery --name=ConsumerUsecase --dir=internal/usecase --output=internal/test/mockusecase --outpkg=mockusecase
+mockery --name=MessageUsecase --dir=internal/usecase --output=internal/test/mockusecase --outpkg=mockusecase
+mockery --name=ArchiveUsecase --dir=internal/usecase --output=internal/test/mockusecase --outpkg=mockusecase
+mockery --name=ReplayUsecase --dir=internal/usecase --output=internal/test/mockusecase --outpkg=mockusecase
+mockery --name=ScheduledUsecase --dir=internal/usecase --output=internal/test/mockusecase --outpkg=mockusecase
+mockery --name=CronUsecase --dir=internal/usecase --output=internal/test/mockusecase --outpkg=mockusecase
+mockery --name=WebhookUsecase --dir=internal/usecase --output=internal/test/mockusecase --outpkg=mockusecase
+mockery --name=ProcessorUsecase --dir=internal/usecase --output=internal/test/mockusecase --outpkg=mockusecase
\ No newline at end of file